@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Optum/dce/pkg/budget"
+	"github.com/Optum/dce/pkg/common"
+	"github.com/Optum/dce/pkg/db"
+	"github.com/Optum/dce/pkg/email"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/lease/leaseiface"
+	"github.com/Optum/dce/pkg/usage"
+	"github.com/pkg/errors"
+)
+
+type fulfillLeaseRequestInput struct {
+	accountID             string
+	dbSvc                 db.DBer
+	leaseSvc              leaseiface.Servicer
+	usageSvc              usage.DBer
+	emailSvc              email.Service
+	s3Svc                 common.Storager
+	principalBudgetPeriod *budget.Period
+	fromEmailAddress      string
+	templatesBucket       string
+	templateHTMLKey       string
+	templateTextKey       string
+	templateSubject       string
+}
+
+// fulfillLeaseRequest leases the account to the oldest pending
+// lease request, once the account is back to "Status=Ready"
+func fulfillLeaseRequest(input *fulfillLeaseRequestInput) error {
+	requests, err := input.leaseSvc.ListRequests(&lease.Request{
+		Status: lease.RequestStatusPending.RequestStatusPtr(),
+	})
+	if err != nil {
+		return err
+	}
+	if requests == nil || len(*requests) == 0 {
+		log.Printf("No pending lease requests for account %s", input.accountID)
+		return nil
+	}
+
+	// Claim the account, so it can't be leased by anyone else
+	// while we're fulfilling the request
+	_, err = input.dbSvc.TransitionAccountStatus(input.accountID, db.Ready, db.Leased)
+	if err != nil {
+		if _, ok := err.(*db.StatusTransitionError); ok {
+			log.Printf("Account %s is not Ready, skipping lease requests", input.accountID)
+			return nil
+		}
+		return err
+	}
+
+	newLease, request, err := input.leaseSvc.FulfillRequest(input.accountID, func(principalID string) (float64, error) {
+		return getPrincipalSpend(input.usageSvc, principalID, input.principalBudgetPeriod)
+	})
+	if newLease == nil {
+		// Nothing was leased, so put the account back in the pool
+		_, rollbackErr := input.dbSvc.TransitionAccountStatus(input.accountID, db.Leased, db.Ready)
+		if rollbackErr != nil {
+			log.Printf("Failed to return account %s to Ready: %s", input.accountID, rollbackErr)
+		}
+		return err
+	}
+	if err != nil {
+		// The lease was saved, so the account stays Leased
+		log.Printf("Created lease %s for account %s, but failed after saving it: %s", *newLease.ID, input.accountID, err)
+		return err
+	}
+	log.Printf("Fulfilled lease request %s with lease %s for account %s", *request.ID, *newLease.ID, input.accountID)
+
+	err = sendLeaseRequestFulfilledEmail(input, newLease, request)
+	if err != nil {
+		// The lease is already created, so don't fail the reset on a notification error
+		log.Printf("Failed to send lease request fulfilled email for %s: %s", *request.ID, err)
+	}
+	return nil
+}
+
+func sendLeaseRequestFulfilledEmail(input *fulfillLeaseRequestInput, newLease *lease.Lease, request *lease.Request) error {
+	toAddresses := []string{}
+	if newLease.BudgetNotificationEmails != nil {
+		for _, address := range *newLease.BudgetNotificationEmails {
+			if address != "" {
+				toAddresses = append(toAddresses, address)
+			}
+		}
+	}
+	if len(toAddresses) == 0 {
+		log.Printf("No email addresses for lease request %s, skipping notification", *request.ID)
+		return nil
+	}
+
+	templateData := struct {
+		Lease     lease.Lease
+		Request   lease.Request
+		ExpiresOn string
+		IsPending bool
+	}{
+		Lease:     *newLease,
+		Request:   *request,
+		ExpiresOn: time.Unix(*newLease.ExpiresOn, 0).UTC().Format(time.RFC1123),
+		IsPending: newLease.Status != nil && *newLease.Status == lease.StatusPending,
+	}
+
+	bodyHTML, _, err := input.s3Svc.GetTemplateObject(input.templatesBucket, input.templateHTMLKey, templateData)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to render lease request fulfilled template at s3://%s/%s",
+			input.templatesBucket, input.templateHTMLKey)
+	}
+	bodyText, _, err := input.s3Svc.GetTemplateObject(input.templatesBucket, input.templateTextKey, templateData)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to render lease request fulfilled template at s3://%s/%s",
+			input.templatesBucket, input.templateTextKey)
+	}
+	subject, err := renderTemplate("leaseRequestFulfilledSubject", input.templateSubject, templateData)
+	if err != nil {
+		return err
+	}
+
+	return input.emailSvc.SendEmail(&email.SendEmailInput{
+		FromAddress: input.fromEmailAddress,
+		ToAddresses: toAddresses,
+		Subject:     subject,
+		BodyText:    bodyText,
+		BodyHTML:    bodyHTML,
+	})
+}
+
+func renderTemplate(id string, templateStr string, data interface{}) (string, error) {
+	tmpl, err := template.New(id).Parse(templateStr)
+	if err != nil {
+		return "", err
+	}
+
+	buf := &bytes.Buffer{}
+	err = tmpl.Execute(buf, data)
+
+	return strings.TrimSpace(buf.String()), err
+}
+
+// getPrincipalSpend returns the amount spent by the principal for the current billing period
+func getPrincipalSpend(usageSvc usage.DBer, principalID string, budgetPeriod *budget.Period) (float64, error) {
+	return usageSvc.GetPrincipalSpend(principalID, budgetPeriod, time.Now())
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Optum/dce/pkg/budget"
+	commonMocks "github.com/Optum/dce/pkg/common/mocks"
+	"github.com/Optum/dce/pkg/db"
+	dbMocks "github.com/Optum/dce/pkg/db/mocks"
+	"github.com/Optum/dce/pkg/email"
+	emailMocks "github.com/Optum/dce/pkg/email/mocks"
+	"github.com/Optum/dce/pkg/lease"
+	leaseMocks "github.com/Optum/dce/pkg/lease/leaseiface/mocks"
+	usageMocks "github.com/Optum/dce/pkg/usage/mocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFulfillLeaseRequest(t *testing.T) {
+	expiresOn := time.Now().AddDate(0, 0, 7).Unix()
+	pendingRequests := &lease.Requests{
+		lease.Request{
+			ID:          aws.String("request-1"),
+			PrincipalID: aws.String("user1"),
+			Status:      lease.RequestStatusPending.RequestStatusPtr(),
+		},
+	}
+	fulfilledLease := &lease.Lease{
+		ID:                       aws.String("lease-1"),
+		AccountID:                aws.String("123456789012"),
+		PrincipalID:              aws.String("user1"),
+		ExpiresOn:                &expiresOn,
+		BudgetNotificationEmails: &[]string{"user1@example.com"},
+	}
+
+	tests := []struct {
+		name           string
+		requests       *lease.Requests
+		claimErr       error
+		retLease       *lease.Lease
+		fulfillErr     error
+		expErr         error
+		expFulfill     bool
+		expRollback    bool
+		expEmail       bool
+		expTransitions int
+	}{
+		{
+			name:     "should skip when there are no pending requests",
+			requests: &lease.Requests{},
+		},
+		{
+			name:           "should skip when the account is not Ready",
+			requests:       pendingRequests,
+			claimErr:       &db.StatusTransitionError{},
+			expTransitions: 1,
+		},
+		{
+			name:           "should fulfill the request and email the requester",
+			requests:       pendingRequests,
+			retLease:       fulfilledLease,
+			expFulfill:     true,
+			expEmail:       true,
+			expTransitions: 1,
+		},
+		{
+			name:           "should return the account to Ready when no request is fulfilled",
+			requests:       pendingRequests,
+			expFulfill:     true,
+			expRollback:    true,
+			expTransitions: 2,
+		},
+		{
+			name:           "should return the account to Ready when fulfillment fails",
+			requests:       pendingRequests,
+			fulfillErr:     errors.New("failure"),
+			expErr:         errors.New("failure"),
+			expFulfill:     true,
+			expRollback:    true,
+			expTransitions: 2,
+		},
+		{
+			name:           "should keep the account Leased when fulfillment fails after the lease is created",
+			requests:       pendingRequests,
+			retLease:       fulfilledLease,
+			fulfillErr:     errors.New("failure"),
+			expErr:         errors.New("failure"),
+			expFulfill:     true,
+			expTransitions: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbSvc := &dbMocks.DBer{}
+			leaseSvc := &leaseMocks.Servicer{}
+			usageSvc := &usageMocks.DBer{}
+			emailSvc := &emailMocks.Service{}
+			s3Svc := &commonMocks.Storager{}
+
+			leaseSvc.On("ListRequests", mock.AnythingOfType("*lease.Request")).Return(tt.requests, nil)
+			dbSvc.On("TransitionAccountStatus", "123456789012", db.Ready, db.Leased).Return(&db.Account{}, tt.claimErr)
+			dbSvc.On("TransitionAccountStatus", "123456789012", db.Leased, db.Ready).Return(&db.Account{}, nil)
+			leaseSvc.On("FulfillRequest", "123456789012", mock.Anything).Return(tt.retLease, &(*pendingRequests)[0], tt.fulfillErr)
+			s3Svc.On("GetTemplateObject", "dce-templates", "lease_request_fulfilled_templates/html.tmpl", mock.Anything).
+				Return("<p>Lease request fulfilled</p>", "", nil)
+			s3Svc.On("GetTemplateObject", "dce-templates", "lease_request_fulfilled_templates/text.tmpl", mock.Anything).
+				Return("Lease request fulfilled", "", nil)
+			emailSvc.On("SendEmail", mock.MatchedBy(func(input *email.SendEmailInput) bool {
+				return input.FromAddress == "dce@example.com" &&
+					assert.Equal(t, []string{"user1@example.com"}, input.ToAddresses) &&
+					assert.Equal(t, "Lease request fulfilled for user1", input.Subject) &&
+					assert.Equal(t, "<p>Lease request fulfilled</p>", input.BodyHTML) &&
+					assert.Equal(t, "Lease request fulfilled", input.BodyText)
+			})).Return(nil)
+
+			err := fulfillLeaseRequest(&fulfillLeaseRequestInput{
+				accountID:             "123456789012",
+				dbSvc:                 dbSvc,
+				leaseSvc:              leaseSvc,
+				usageSvc:              usageSvc,
+				emailSvc:              emailSvc,
+				s3Svc:                 s3Svc,
+				principalBudgetPeriod: &budget.Period{Type: budget.PeriodWeekly, Location: time.UTC},
+				fromEmailAddress:      "dce@example.com",
+				templatesBucket:       "dce-templates",
+				templateHTMLKey:       "lease_request_fulfilled_templates/html.tmpl",
+				templateTextKey:       "lease_request_fulfilled_templates/text.tmpl",
+				templateSubject:       "Lease request fulfilled for {{.Lease.PrincipalID}}",
+			})
+
+			assert.Equal(t, tt.expErr, err)
+			dbSvc.AssertNumberOfCalls(t, "TransitionAccountStatus", tt.expTransitions)
+			if tt.expFulfill {
+				leaseSvc.AssertCalled(t, "FulfillRequest", "123456789012", mock.Anything)
+			} else {
+				leaseSvc.AssertNotCalled(t, "FulfillRequest", "123456789012", mock.Anything)
+			}
+			if tt.expRollback {
+				dbSvc.AssertCalled(t, "TransitionAccountStatus", "123456789012", db.Leased, db.Ready)
+			}
+			if tt.expEmail {
+				emailSvc.AssertNumberOfCalls(t, "SendEmail", 1)
+			} else {
+				emailSvc.AssertNotCalled(t, "SendEmail", mock.Anything)
+			}
+		})
+	}
+}
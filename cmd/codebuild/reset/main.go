@@ -56,6 +56,27 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to update the DB post-reset for account %s:  %s", config.childAccountID, err)
 	}
+
+	// Hand the account to the oldest waiting lease request, if any
+	err = fulfillLeaseRequest(&fulfillLeaseRequestInput{
+		accountID:             config.childAccountID,
+		dbSvc:                 svc.db(),
+		leaseSvc:              svc.leaseService(),
+		usageSvc:              svc.usageDB(),
+		emailSvc:              svc.emailService(),
+		s3Svc:                 svc.s3Service(),
+		principalBudgetPeriod: config.principalBudgetPeriod,
+		fromEmailAddress:      config.leaseRequestFromEmail,
+		templatesBucket:       config.leaseRequestTemplatesBucket,
+		templateHTMLKey:       config.leaseRequestTemplateHTMLKey,
+		templateTextKey:       config.leaseRequestTemplateTextKey,
+		templateSubject:       config.leaseRequestTemplateSubject,
+	})
+	if err != nil {
+		// The account is already reset, so don't fail the build:
+		// the request stays pending, and is retried on the next reset.
+		log.Printf("Failed to fulfill lease request for account %s:  %s", config.childAccountID, err)
+	}
 }
 
 // updateDBPostReset changes any leases for the Account
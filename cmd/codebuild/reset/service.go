@@ -4,11 +4,17 @@ import (
 	"log"
 	"os"
 
+	"github.com/Optum/dce/pkg/budget"
 	"github.com/Optum/dce/pkg/common"
+	"github.com/Optum/dce/pkg/config"
 	"github.com/Optum/dce/pkg/db"
+	"github.com/Optum/dce/pkg/email"
+	"github.com/Optum/dce/pkg/lease/leaseiface"
+	"github.com/Optum/dce/pkg/usage"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/ses"
 	"github.com/aws/aws-sdk-go/service/sns"
 	"github.com/aws/aws-sdk-go/service/sts"
 )
@@ -22,6 +28,9 @@ var (
 	_s3Service    *common.S3
 	_snsService   *common.SNS
 	_db           *db.DB
+	_usageDB      *usage.DB
+	_emailService *email.SESEmailService
+	_leaseService leaseiface.Servicer
 )
 
 // service struct holds all the services to be used by
@@ -44,6 +53,13 @@ type serviceConfig struct {
 	nukeTemplateDefault string
 	nukeTemplateBucket  string
 	nukeTemplateKey     string
+
+	principalBudgetPeriod       *budget.Period
+	leaseRequestFromEmail       string
+	leaseRequestTemplatesBucket string
+	leaseRequestTemplateHTMLKey string
+	leaseRequestTemplateTextKey string
+	leaseRequestTemplateSubject string
 }
 
 func (svc *service) config() *serviceConfig {
@@ -52,6 +68,10 @@ func (svc *service) config() *serviceConfig {
 	}
 	accountAdminRoleName := common.RequireEnv("RESET_ACCOUNT_ADMIN_ROLE_NAME")
 	childAccountID := common.RequireEnv("RESET_ACCOUNT")
+	principalBudgetPeriod, err := budget.NewPeriodFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
 	_config = &serviceConfig{
 		childAccountID:             childAccountID,
 		accountPrincipalRoleName:   common.RequireEnv("RESET_ACCOUNT_PRINCIPAL_ROLE_NAME"),
@@ -64,6 +84,13 @@ func (svc *service) config() *serviceConfig {
 		nukeTemplateBucket:  common.RequireEnv("RESET_NUKE_TEMPLATE_BUCKET"),
 		nukeTemplateKey:     common.RequireEnv("RESET_NUKE_TEMPLATE_KEY"),
 		nukeRegions:         common.RequireEnvStringSlice("RESET_NUKE_REGIONS", ","),
+
+		principalBudgetPeriod:       principalBudgetPeriod,
+		leaseRequestFromEmail:       common.RequireEnv("LEASE_REQUEST_FROM_EMAIL"),
+		leaseRequestTemplatesBucket: common.RequireEnv("BUDGET_NOTIFICATION_TEMPLATES_BUCKET"),
+		leaseRequestTemplateHTMLKey: common.RequireEnv("LEASE_REQUEST_FULFILLED_TEMPLATE_HTML_KEY"),
+		leaseRequestTemplateTextKey: common.RequireEnv("LEASE_REQUEST_FULFILLED_TEMPLATE_TEXT_KEY"),
+		leaseRequestTemplateSubject: common.RequireEnv("LEASE_REQUEST_FULFILLED_TEMPLATE_SUBJECT"),
 	}
 
 	return _config
@@ -127,3 +154,45 @@ func (svc *service) snsService() *common.SNS {
 
 	return _snsService
 }
+
+func (svc *service) usageDB() *usage.DB {
+	if _usageDB != nil {
+		return _usageDB
+	}
+	var err error
+	_usageDB, err = usage.NewFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to initialize Usage DB Service:  %s", err)
+	}
+	return _usageDB
+}
+
+func (svc *service) emailService() *email.SESEmailService {
+	if _emailService == nil {
+		_emailService = &email.SESEmailService{
+			SES: ses.New(svc.awsSession()),
+		}
+	}
+
+	return _emailService
+}
+
+func (svc *service) leaseService() leaseiface.Servicer {
+	if _leaseService != nil {
+		return _leaseService
+	}
+	cfgBldr := &config.ConfigurationBuilder{}
+	err := cfgBldr.WithEnv("AWS_CURRENT_REGION", "AWS_CURRENT_REGION", "us-east-1").Build()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %s", err)
+	}
+	svcBldr := &config.ServiceBuilder{Config: cfgBldr}
+	_, err = svcBldr.
+		WithLeaseService().
+		Build()
+	if err != nil {
+		log.Fatalf("Failed to initialize Lease Service: %s", err)
+	}
+	_leaseService = svcBldr.LeaseService()
+	return _leaseService
+}
@@ -20,6 +20,11 @@ func TestService(t *testing.T) {
 		"RESET_NUKE_TEMPLATE_DEFAULT",
 		"RESET_NUKE_TEMPLATE_BUCKET",
 		"RESET_NUKE_TEMPLATE_KEY",
+		"LEASE_REQUEST_FROM_EMAIL",
+		"BUDGET_NOTIFICATION_TEMPLATES_BUCKET",
+		"LEASE_REQUEST_FULFILLED_TEMPLATE_HTML_KEY",
+		"LEASE_REQUEST_FULFILLED_TEMPLATE_TEXT_KEY",
+		"LEASE_REQUEST_FULFILLED_TEMPLATE_SUBJECT",
 	}
 	for _, envKey := range envVars {
 		_ = os.Setenv(envKey, envKey+"_VAL")
@@ -47,6 +52,11 @@ func TestService(t *testing.T) {
 			require.Equal(t, "RESET_NUKE_TEMPLATE_DEFAULT_VAL", config.nukeTemplateDefault)
 			require.Equal(t, "RESET_NUKE_TEMPLATE_BUCKET_VAL", config.nukeTemplateBucket)
 			require.Equal(t, "RESET_NUKE_TEMPLATE_KEY_VAL", config.nukeTemplateKey)
+			require.Equal(t, "LEASE_REQUEST_FROM_EMAIL_VAL", config.leaseRequestFromEmail)
+			require.Equal(t, "BUDGET_NOTIFICATION_TEMPLATES_BUCKET_VAL", config.leaseRequestTemplatesBucket)
+			require.Equal(t, "LEASE_REQUEST_FULFILLED_TEMPLATE_HTML_KEY_VAL", config.leaseRequestTemplateHTMLKey)
+			require.Equal(t, "LEASE_REQUEST_FULFILLED_TEMPLATE_TEXT_KEY_VAL", config.leaseRequestTemplateTextKey)
+			require.Equal(t, "LEASE_REQUEST_FULFILLED_TEMPLATE_SUBJECT_VAL", config.leaseRequestTemplateSubject)
 			require.Equal(t, []string{"us-east-1", "us-west-1"}, config.nukeRegions)
 
 			// Check computed config vals
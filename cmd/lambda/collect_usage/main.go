@@ -0,0 +1,166 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/Optum/dce/pkg/budget"
+	"github.com/Optum/dce/pkg/common"
+	"github.com/Optum/dce/pkg/config"
+	"github.com/Optum/dce/pkg/currency"
+	"github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/lease/leaseiface"
+	"github.com/Optum/dce/pkg/usage"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/costexplorer"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// collect_usage records the recent usage of every leased account, from a single
+// Cost Explorer call in the consolidated billing (master) account, or from its
+// Cost and Usage Report, so update_lease_status can check lease budgets against the Usage cache alone.
+func main() {
+	lambda.Start(func(cloudWatchEvent events.CloudWatchEvent) error {
+		awsSession := session.Must(session.NewSession())
+
+		usageSvc, err := usage.NewFromEnv()
+		if err != nil {
+			log.Fatalf("Failed to configure Usage service %s", err)
+		}
+
+		s3Svc := &common.S3{
+			Client:  s3.New(awsSession),
+			Manager: s3manager.NewDownloader(awsSession),
+		}
+
+		// Cost Explorer in the master account, or the Cost and Usage Report it delivers,
+		// has the spend of every linked account
+		budgetSvc, err := budget.NewServiceFromEnv(s3Svc)
+		if err != nil {
+			log.Fatalf("Failed to configure Budget service %s", err)
+		}
+		budgetSvc.SetCostExplorer(costexplorer.New(awsSession))
+
+		cfgBldr := &config.ConfigurationBuilder{}
+		err = cfgBldr.WithEnv("AWS_CURRENT_REGION", "AWS_CURRENT_REGION", "us-east-1").Build()
+		if err != nil {
+			log.Fatalf("Failed to configure services %s", err)
+		}
+		svcBldr := &config.ServiceBuilder{Config: cfgBldr}
+		_, err = svcBldr.WithLeaseService().Build()
+		if err != nil {
+			log.Fatalf("Failed to configure Lease service %s", err)
+		}
+
+		currencyConverter, err := currency.NewConverterFromEnv(s3Svc)
+		if err != nil {
+			log.Fatalf("Failed to configure currency converter %s", err)
+		}
+
+		return collectUsage(&collectUsageInput{
+			leaseSvc:          svcBldr.LeaseService(),
+			budgetSvc:         budgetSvc,
+			usageSvc:          usageSvc,
+			currencyConverter: currencyConverter,
+			usageTTL:          common.RequireEnvInt("USAGE_TTL"),
+			usageLookbackDays: common.GetEnvInt("USAGE_LOOKBACK_DAYS", 1),
+		}, time.Now())
+	})
+}
+
+type collectUsageInput struct {
+	leaseSvc          leaseiface.Servicer
+	budgetSvc         budget.Service
+	usageSvc          usage.DBer
+	currencyConverter *currency.Converter
+	usageTTL          int // TTL in seconds for Usage DynamoDB records
+	usageLookbackDays int // Days of usage to collect, up to today, as spend may be reported late
+}
+
+// collectUsage writes the usage of the active and frozen leases, and the leases which ended during the lookback days,
+// for each of the lookback days they were in use, in a single batch.
+// Leased accounts without any spend on a day get a usage record too, so idle leases show up as idle.
+func collectUsage(input *collectUsageInput, currentTime time.Time) error {
+	currentTime = currentTime.UTC()
+	today := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), 0, 0, 0, 0, time.UTC)
+
+	lookbackDays := input.usageLookbackDays
+	if lookbackDays < 1 {
+		lookbackDays = 1
+	}
+	lookbackStart := today.AddDate(0, 0, 1-lookbackDays)
+
+	leases := lease.Leases{}
+	for _, status := range []lease.Status{lease.StatusActive, lease.StatusFrozen, lease.StatusInactive} {
+		err := input.leaseSvc.ListPages(&lease.Lease{Status: status.StatusPtr()},
+			func(page *lease.Leases) bool {
+				for _, l := range *page {
+					// Ended leases still get usage for the lookback days before they ended
+					if status == lease.StatusInactive && (l.StatusModifiedOn == nil || *l.StatusModifiedOn < lookbackStart.Unix()) {
+						continue
+					}
+					leases = append(leases, l)
+				}
+				return true // always continue
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Only the leased accounts' spend is read
+	accountIDs := []string{}
+	for _, l := range leases {
+		if l.AccountID != nil {
+			accountIDs = append(accountIDs, *l.AccountID)
+		}
+	}
+	input.budgetSvc.SetAccounts(accountIDs)
+
+	var errs []error
+	usageRecords := []usage.Usage{}
+	for day := lookbackStart; !day.After(today); day = day.AddDate(0, 0, 1) {
+		usageStartTime := day
+		usageEndTime := time.Date(day.Year(), day.Month(), day.Day(), 23, 59, 59, 0, time.UTC)
+
+		accountCosts, err := input.budgetSvc.CalculateSpendByAccount(usageStartTime, usageStartTime.AddDate(0, 0, 1))
+		if err != nil {
+			return err
+		}
+		log.Printf("Retrieved usage for %d accounts on %s", len(accountCosts), day.Format("2006-01-02"))
+
+		// Leases only get usage for the days since they were created
+		for _, l := range usage.LeasesOnDay(leases, usageStartTime, usageEndTime) {
+			usageRecord, err := usage.NewLeaseUsage(usage.NewLeaseUsageInput{
+				Lease:        l,
+				StartDate:    usageStartTime,
+				EndDate:      usageEndTime,
+				TimeToLive:   usageStartTime.Add(time.Duration(input.usageTTL) * time.Second).Unix(),
+				ServiceCosts: accountCosts[*l.AccountID],
+				Converter:    input.currencyConverter,
+			})
+			if err != nil {
+				log.Printf("Failed to collect usage for lease %s @ %s: %s", *l.PrincipalID, *l.AccountID, err)
+				errs = append(errs, err)
+				continue
+			}
+			usageRecords = append(usageRecords, *usageRecord)
+		}
+	}
+
+	log.Printf("Writing %d usage records", len(usageRecords))
+	err := input.usageSvc.PutUsageBatch(usageRecords)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.NewMultiError("error when collecting usage", errs)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	budgetMocks "github.com/Optum/dce/pkg/budget/mocks"
+	"github.com/Optum/dce/pkg/currency"
+	"github.com/Optum/dce/pkg/lease"
+	leaseMocks "github.com/Optum/dce/pkg/lease/leaseiface/mocks"
+	"github.com/Optum/dce/pkg/usage"
+	usageMocks "github.com/Optum/dce/pkg/usage/mocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCollectUsage(t *testing.T) {
+	currentTime := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	startDate := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, 5, 15, 23, 59, 59, 0, time.UTC)
+	createdOn := aws.Int64(startDate.AddDate(0, 0, -5).Unix())
+
+	leasesByStatus := map[lease.Status]lease.Leases{
+		lease.StatusActive: {
+			{PrincipalID: aws.String("user1"), AccountID: aws.String("123456789012"), BudgetCurrency: aws.String("EUR"),
+				Status: lease.StatusActive.StatusPtr(), CreatedOn: createdOn},
+			{PrincipalID: aws.String("user2"), AccountID: aws.String("210987654321"),
+				Status: lease.StatusActive.StatusPtr(), CreatedOn: createdOn},
+		},
+		lease.StatusFrozen: {
+			{PrincipalID: aws.String("user3"), AccountID: aws.String("111111111111"), BudgetCurrency: aws.String("GBP"),
+				Status: lease.StatusFrozen.StatusPtr(), CreatedOn: createdOn},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		accountCosts map[string]map[string]float64
+		costErr      error
+		expUsage     []usage.Usage
+		expErr       bool
+	}{
+		{
+			name: "should write usage for every leased account, and fail for leases it can't convert",
+			accountCosts: map[string]map[string]float64{
+				"123456789012": {"Amazon SageMaker": 100, "EC2 - Other": 20},
+				"999999999999": {"Amazon S3": 5},
+			},
+			expUsage: []usage.Usage{
+				{
+					PrincipalID:           aws.String("user1"),
+					AccountID:             aws.String("123456789012"),
+					StartDate:             aws.Int64(startDate.Unix()),
+					EndDate:               aws.Int64(endDate.Unix()),
+					CostAmount:            aws.Float64(120),
+					CostCurrency:          aws.String("USD"),
+					ConvertedCostAmount:   aws.Float64(60),
+					ConvertedCostCurrency: aws.String("EUR"),
+					TimeToLive:            aws.Int64(startDate.Unix() + 3600),
+					ServiceCosts:          map[string]float64{"Amazon SageMaker": 100, "EC2 - Other": 20},
+				},
+				{
+					PrincipalID:           aws.String("user2"),
+					AccountID:             aws.String("210987654321"),
+					StartDate:             aws.Int64(startDate.Unix()),
+					EndDate:               aws.Int64(endDate.Unix()),
+					CostAmount:            aws.Float64(0),
+					CostCurrency:          aws.String("USD"),
+					ConvertedCostAmount:   aws.Float64(0),
+					ConvertedCostCurrency: aws.String("USD"),
+					TimeToLive:            aws.Int64(startDate.Unix() + 3600),
+				},
+			},
+			// No exchange rate for the frozen lease's GBP budget
+			expErr: true,
+		},
+		{
+			name:    "should fail when Cost Explorer fails",
+			costErr: errors.New("access denied"),
+			expErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budgetSvc := &budgetMocks.Service{}
+			budgetSvc.On("SetAccounts", mock.Anything)
+			leaseSvc := &leaseMocks.Servicer{}
+			usageSvc := &usageMocks.DBer{}
+
+			budgetSvc.On("CalculateSpendByAccount", startDate, startDate.AddDate(0, 0, 1)).Return(tt.accountCosts, tt.costErr)
+			leaseSvc.On("ListPages", mock.AnythingOfType("*lease.Lease"), mock.Anything).
+				Run(func(args mock.Arguments) {
+					query := args.Get(0).(*lease.Lease)
+					leases := leasesByStatus[*query.Status]
+					args.Get(1).(func(*lease.Leases) bool)(&leases)
+				}).
+				Return(nil)
+			usageSvc.On("PutUsageBatch", mock.Anything).Return(nil)
+
+			err := collectUsage(&collectUsageInput{
+				leaseSvc:  leaseSvc,
+				budgetSvc: budgetSvc,
+				usageSvc:  usageSvc,
+				currencyConverter: &currency.Converter{
+					BaseCurrency: "USD",
+					Provider:     currency.StaticRates{"EUR": 0.5},
+				},
+				usageTTL: 3600,
+			}, currentTime)
+
+			if tt.expErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			if tt.expUsage != nil {
+				usageSvc.AssertCalled(t, "PutUsageBatch", tt.expUsage)
+			} else {
+				usageSvc.AssertNotCalled(t, "PutUsageBatch", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestCollectUsageLookback(t *testing.T) {
+	currentTime := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	today := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+
+	budgetSvc := &budgetMocks.Service{}
+	leaseSvc := &leaseMocks.Servicer{}
+	usageSvc := &usageMocks.DBer{}
+
+	budgetSvc.On("SetAccounts", []string{"123456789012", "210987654321", "333333333333"})
+	budgetSvc.On("CalculateSpendByAccount", yesterday, today).
+		Return(map[string]map[string]float64{"123456789012": {"Amazon SageMaker": 30}, "210987654321": {"Amazon S3": 5},
+			"333333333333": {"Amazon EC2": 12}}, nil)
+	budgetSvc.On("CalculateSpendByAccount", today, today.AddDate(0, 0, 1)).
+		Return(map[string]map[string]float64{}, nil)
+	leaseSvc.On("ListPages", mock.AnythingOfType("*lease.Lease"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			leases := lease.Leases{}
+			switch *args.Get(0).(*lease.Lease).Status {
+			case lease.StatusActive:
+				leases = lease.Leases{
+					{PrincipalID: aws.String("user1"), AccountID: aws.String("123456789012"),
+						Status: lease.StatusActive.StatusPtr(), CreatedOn: aws.Int64(yesterday.AddDate(0, 0, -3).Unix())},
+					// Created this morning, so it doesn't get yesterday's usage
+					{PrincipalID: aws.String("user2"), AccountID: aws.String("210987654321"),
+						Status: lease.StatusActive.StatusPtr(), CreatedOn: aws.Int64(today.Add(8 * time.Hour).Unix())},
+				}
+			case lease.StatusInactive:
+				leases = lease.Leases{
+					// Ended yesterday, so it only gets yesterday's usage
+					{PrincipalID: aws.String("user3"), AccountID: aws.String("333333333333"),
+						Status: lease.StatusInactive.StatusPtr(), CreatedOn: aws.Int64(yesterday.AddDate(0, 0, -3).Unix()),
+						StatusModifiedOn: aws.Int64(yesterday.Add(12 * time.Hour).Unix())},
+					// Ended before the lookback days
+					{PrincipalID: aws.String("user4"), AccountID: aws.String("444444444444"),
+						Status: lease.StatusInactive.StatusPtr(), CreatedOn: aws.Int64(yesterday.AddDate(0, 0, -5).Unix()),
+						StatusModifiedOn: aws.Int64(yesterday.AddDate(0, 0, -2).Unix())},
+				}
+			}
+			args.Get(1).(func(*lease.Leases) bool)(&leases)
+		}).
+		Return(nil)
+	usageSvc.On("PutUsageBatch", mock.Anything).Return(nil)
+
+	err := collectUsage(&collectUsageInput{
+		leaseSvc:  leaseSvc,
+		budgetSvc: budgetSvc,
+		usageSvc:  usageSvc,
+		currencyConverter: &currency.Converter{
+			BaseCurrency: "USD",
+			Provider:     currency.StaticRates{},
+		},
+		usageTTL:          3600,
+		usageLookbackDays: 2,
+	}, currentTime)
+
+	assert.Nil(t, err)
+	budgetSvc.AssertExpectations(t)
+	records := usageSvc.Calls[0].Arguments.Get(0).([]usage.Usage)
+	assert.Len(t, records, 4)
+	assert.Equal(t, yesterday.Unix(), *records[0].StartDate)
+	assert.Equal(t, "user1", *records[0].PrincipalID)
+	assert.Equal(t, 30.0, *records[0].CostAmount)
+	assert.Equal(t, yesterday.Unix(), *records[1].StartDate)
+	assert.Equal(t, "user3", *records[1].PrincipalID)
+	assert.Equal(t, 12.0, *records[1].CostAmount)
+	for _, record := range records[2:] {
+		assert.Equal(t, today.Unix(), *record.StartDate)
+		assert.Equal(t, 0.0, *record.CostAmount)
+	}
+}
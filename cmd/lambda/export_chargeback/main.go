@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Optum/dce/pkg/common"
+	"github.com/Optum/dce/pkg/config"
+	"github.com/Optum/dce/pkg/email"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/lease/leaseiface"
+	"github.com/Optum/dce/pkg/usage"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/ses"
+)
+
+// export_chargeback writes the usage of the previous month, joined with the
+// metadata of each lease (eg. its cost center), to S3 as CSV and Parquet,
+// so it can be charged back to the teams who spent it.
+func main() {
+	lambda.Start(func(cloudWatchEvent events.CloudWatchEvent) error {
+		awsSession := session.Must(session.NewSession())
+
+		usageSvc, err := usage.NewFromEnv()
+		if err != nil {
+			log.Fatalf("Failed to configure Usage service %s", err)
+		}
+
+		cfgBldr := &config.ConfigurationBuilder{}
+		err = cfgBldr.WithEnv("AWS_CURRENT_REGION", "AWS_CURRENT_REGION", "us-east-1").Build()
+		if err != nil {
+			log.Fatalf("Failed to configure services %s", err)
+		}
+		svcBldr := &config.ServiceBuilder{Config: cfgBldr}
+		_, err = svcBldr.WithLeaseService().Build()
+		if err != nil {
+			log.Fatalf("Failed to configure Lease service %s", err)
+		}
+
+		return exportChargeback(&exportChargebackInput{
+			usageSvc: usageSvc,
+			leaseSvc: svcBldr.LeaseService(),
+			storage: &common.S3{
+				Client:  s3.New(awsSession),
+				Manager: s3manager.NewDownloader(awsSession),
+			},
+			emailSvc:     &email.SESEmailService{SES: ses.New(awsSession)},
+			bucket:       common.RequireEnv("CHARGEBACK_BUCKET"),
+			prefix:       common.GetEnv("CHARGEBACK_PREFIX", "chargeback"),
+			metadataKeys: common.RequireEnvStringSlice("CHARGEBACK_METADATA_KEYS", ","),
+			emails:       common.RequireEnvStringSlice("CHARGEBACK_EMAILS", ","),
+			fromEmail:    common.GetEnv("CHARGEBACK_FROM_EMAIL", ""),
+		}, time.Now())
+	})
+}
+
+type exportChargebackInput struct {
+	usageSvc     usage.DBer
+	leaseSvc     leaseiface.Servicer
+	storage      common.Storager
+	emailSvc     email.Service
+	bucket       string
+	prefix       string   // Reports are written under <prefix>/<YYYY-MM>/
+	metadataKeys []string // Lease Metadata keys to add as report columns, eg. costCenter
+	emails       []string // Addresses to email the CSV report to, if any
+	fromEmail    string
+}
+
+// chargebackManifest describes a billing period's report.
+// It's written after the report files, so its presence means the report is complete.
+type chargebackManifest struct {
+	BillingPeriod   string   `json:"billingPeriod"`   // Billing period, eg. 2024-05
+	StartDate       int64    `json:"startDate"`       // Billing period start date Epoch Timestamp
+	EndDate         int64    `json:"endDate"`         // Billing period end date Epoch Timestamp
+	CreatedOn       int64    `json:"createdOn"`       // Report creation Epoch Timestamp
+	RecordCount     int      `json:"recordCount"`     // Rows in the report
+	TotalCostAmount float64  `json:"totalCostAmount"` // Total cost of the report's rows
+	CostCurrency    string   `json:"costCurrency"`    // Cost currency
+	Columns         []string `json:"columns"`         // Report columns, in order
+	Files           []string `json:"files"`           // S3 keys of the report files
+}
+
+// exportChargeback exports the usage of the calendar month before the current time
+func exportChargeback(input *exportChargebackInput, currentTime time.Time) error {
+	currentTime = currentTime.UTC()
+	periodEnd := time.Date(currentTime.Year(), currentTime.Month(), 1, 0, 0, 0, 0, time.UTC)
+	periodStart := periodEnd.AddDate(0, -1, 0)
+	billingPeriod := periodStart.Format("2006-01")
+
+	// The end date is the last day of the period, which is included
+	records, err := input.usageSvc.GetUsageByDateRange(periodStart, periodEnd.AddDate(0, 0, -1))
+	if err != nil {
+		return err
+	}
+
+	leases := lease.Leases{}
+	err = input.leaseSvc.ListPages(&lease.Lease{}, func(page *lease.Leases) bool {
+		leases = append(leases, *page...)
+		return true // always continue
+	})
+	if err != nil {
+		return err
+	}
+
+	rows := newChargebackRows(billingPeriod, records, leases, input.metadataKeys)
+	log.Printf("Exporting %d usage records for billing period %s", len(rows), billingPeriod)
+
+	csvReport, err := writeChargebackCSV(rows, input.metadataKeys)
+	if err != nil {
+		return err
+	}
+	parquetReport, err := writeChargebackParquet(rows, input.metadataKeys)
+	if err != nil {
+		return err
+	}
+
+	keyPrefix := billingPeriod + "/"
+	if input.prefix != "" {
+		keyPrefix = input.prefix + "/" + keyPrefix
+	}
+	csvKey := keyPrefix + "chargeback.csv"
+	parquetKey := keyPrefix + "chargeback.parquet"
+	reports := []struct{ key, body string }{{csvKey, csvReport}, {parquetKey, parquetReport}}
+	for _, report := range reports {
+		err = input.storage.PutObject(input.bucket, report.key, report.body)
+		if err != nil {
+			return fmt.Errorf("failed to write chargeback report s3://%s/%s: %s", input.bucket, report.key, err)
+		}
+	}
+
+	manifest := chargebackManifest{
+		BillingPeriod: billingPeriod,
+		StartDate:     periodStart.Unix(),
+		EndDate:       periodEnd.Unix() - 1,
+		CreatedOn:     currentTime.Unix(),
+		RecordCount:   len(rows),
+		Columns:       chargebackColumns(input.metadataKeys),
+		Files:         []string{csvKey, parquetKey},
+	}
+	for _, row := range rows {
+		manifest.TotalCostAmount += row.CostAmount
+		if manifest.CostCurrency == "" {
+			manifest.CostCurrency = row.CostCurrency
+		}
+	}
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	err = input.storage.PutObject(input.bucket, keyPrefix+"manifest.json", string(manifestJSON))
+	if err != nil {
+		return fmt.Errorf("failed to write chargeback manifest s3://%s/%smanifest.json: %s", input.bucket, keyPrefix, err)
+	}
+
+	if len(input.emails) == 0 {
+		return nil
+	}
+	return emailChargeback(input, &manifest, csvReport)
+}
+
+// emailChargeback sends the CSV report as an attachment.
+// SES attaches files from disk, so the report is written to a temporary file first.
+func emailChargeback(input *exportChargebackInput, manifest *chargebackManifest, csvReport string) error {
+	dir, err := ioutil.TempDir("", "chargeback")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, fmt.Sprintf("chargeback-%s.csv", manifest.BillingPeriod))
+	err = ioutil.WriteFile(fileName, []byte(csvReport), 0600)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("DCE usage for %s: %d records totalling %.2f %s.\n\n"+
+		"The report is attached, and in S3 at s3://%s/%s.",
+		manifest.BillingPeriod, manifest.RecordCount, manifest.TotalCostAmount, manifest.CostCurrency,
+		input.bucket, manifest.Files[0])
+	log.Printf("Emailing chargeback report for %s to %v", manifest.BillingPeriod, input.emails)
+	return input.emailSvc.SendRawEmailWithAttachment(&email.SendEmailWithAttachmentInput{
+		FromAddress:        input.fromEmail,
+		ToAddresses:        input.emails,
+		Subject:            fmt.Sprintf("DCE chargeback report [%s]", manifest.BillingPeriod),
+		BodyHTML:           "<p>" + strings.ReplaceAll(body, "\n", "<br/>") + "</p>",
+		BodyText:           body,
+		AttachmentFileName: fileName,
+	})
+}
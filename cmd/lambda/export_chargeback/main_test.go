@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	commonMocks "github.com/Optum/dce/pkg/common/mocks"
+	"github.com/Optum/dce/pkg/email"
+	emailMocks "github.com/Optum/dce/pkg/email/mocks"
+	"github.com/Optum/dce/pkg/lease"
+	leaseMocks "github.com/Optum/dce/pkg/lease/leaseiface/mocks"
+	"github.com/Optum/dce/pkg/usage"
+	usageMocks "github.com/Optum/dce/pkg/usage/mocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportChargeback(t *testing.T) {
+	currentTime := time.Date(2024, 6, 1, 6, 0, 0, 0, time.UTC)
+	periodStart := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	periodLastDay := time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+
+	records := []*usage.Usage{
+		{
+			PrincipalID:           aws.String("user2"),
+			AccountID:             aws.String("210987654321"),
+			LeaseID:               aws.String("lease2"),
+			StartDate:             aws.Int64(time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC).Unix()),
+			CostAmount:            aws.Float64(7),
+			CostCurrency:          aws.String("USD"),
+			ConvertedCostAmount:   aws.Float64(3.5),
+			ConvertedCostCurrency: aws.String("EUR"),
+		},
+		{
+			PrincipalID:  aws.String("user1"),
+			AccountID:    aws.String("123456789012"),
+			LeaseID:      aws.String("lease1"),
+			StartDate:    aws.Int64(time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC).Unix()),
+			CostAmount:   aws.Float64(10.25),
+			CostCurrency: aws.String("USD"),
+		},
+	}
+	leases := lease.Leases{
+		{
+			ID:          aws.String("lease1"),
+			PrincipalID: aws.String("user1"),
+			AccountID:   aws.String("123456789012"),
+			Status:      lease.StatusActive.StatusPtr(),
+			CreatedOn:   aws.Int64(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC).Unix()),
+			Metadata:    map[string]interface{}{"costCenter": "1234", "team": "data"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		emails   []string
+		usageErr error
+		putErr   error
+		emailErr error
+		expErr   bool
+	}{
+		{
+			name: "should export the previous month's usage",
+		},
+		{
+			name:   "should email the report",
+			emails: []string{"finance@example.com", "ops@example.com"},
+		},
+		{
+			name:     "should fail when the usage can't be read",
+			usageErr: errors.New("throttled"),
+			expErr:   true,
+		},
+		{
+			name:   "should fail when the report can't be written",
+			putErr: errors.New("access denied"),
+			expErr: true,
+		},
+		{
+			name:     "should fail when the email can't be sent",
+			emails:   []string{"finance@example.com"},
+			emailErr: errors.New("not verified"),
+			expErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usageSvc := &usageMocks.DBer{}
+			leaseSvc := &leaseMocks.Servicer{}
+			storage := &commonMocks.Storager{}
+			emailSvc := &emailMocks.Service{}
+
+			usageSvc.On("GetUsageByDateRange", periodStart, periodLastDay).Return(records, tt.usageErr)
+			leaseSvc.On("ListPages", &lease.Lease{}, mock.Anything).
+				Run(func(args mock.Arguments) {
+					args.Get(1).(func(*lease.Leases) bool)(&leases)
+				}).
+				Return(nil)
+			objects := map[string]string{}
+			storage.On("PutObject", "chargeback-bucket", mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) {
+					objects[args.String(1)] = args.String(2)
+				}).
+				Return(tt.putErr)
+			var attachment string
+			emailSvc.On("SendRawEmailWithAttachment", mock.Anything).
+				Run(func(args mock.Arguments) {
+					input := args.Get(0).(*email.SendEmailWithAttachmentInput)
+					assert.Equal(t, tt.emails, input.ToAddresses)
+					assert.Equal(t, "DCE chargeback report [2024-05]", input.Subject)
+					content, err := ioutil.ReadFile(input.AttachmentFileName)
+					assert.Nil(t, err)
+					attachment = string(content)
+				}).
+				Return(tt.emailErr)
+
+			err := exportChargeback(&exportChargebackInput{
+				usageSvc:     usageSvc,
+				leaseSvc:     leaseSvc,
+				storage:      storage,
+				emailSvc:     emailSvc,
+				bucket:       "chargeback-bucket",
+				prefix:       "chargeback",
+				metadataKeys: []string{"costCenter"},
+				emails:       tt.emails,
+				fromEmail:    "dce@example.com",
+			}, currentTime)
+
+			if tt.expErr {
+				assert.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+
+			expCSV := "billing_period,date,principal_id,account_id,lease_id,cost_amount,cost_currency,converted_cost_amount,converted_cost_currency,metadata_costCenter\n" +
+				"2024-05,2024-05-14,user1,123456789012,lease1,10.25,USD,10.25,USD,1234\n" +
+				"2024-05,2024-05-14,user2,210987654321,lease2,7,USD,3.5,EUR,\n"
+			assert.Equal(t, expCSV, objects["chargeback/2024-05/chargeback.csv"])
+
+			manifest := chargebackManifest{}
+			require.Nil(t, json.Unmarshal([]byte(objects["chargeback/2024-05/manifest.json"]), &manifest))
+			assert.Equal(t, chargebackManifest{
+				BillingPeriod:   "2024-05",
+				StartDate:       periodStart.Unix(),
+				EndDate:         currentTime.Truncate(24*time.Hour).Unix() - 1,
+				CreatedOn:       currentTime.Unix(),
+				RecordCount:     2,
+				TotalCostAmount: 17.25,
+				CostCurrency:    "USD",
+				Columns:         chargebackColumns([]string{"costCenter"}),
+				Files:           []string{"chargeback/2024-05/chargeback.csv", "chargeback/2024-05/chargeback.parquet"},
+			}, manifest)
+
+			if len(tt.emails) > 0 {
+				assert.Equal(t, expCSV, attachment)
+			} else {
+				emailSvc.AssertNotCalled(t, "SendRawEmailWithAttachment", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestWriteChargebackParquet(t *testing.T) {
+	type chargebackRecord struct {
+		BillingPeriod         string  `parquet:"billing_period"`
+		Date                  string  `parquet:"date"`
+		PrincipalID           string  `parquet:"principal_id"`
+		AccountID             string  `parquet:"account_id"`
+		LeaseID               string  `parquet:"lease_id"`
+		CostAmount            float64 `parquet:"cost_amount"`
+		CostCurrency          string  `parquet:"cost_currency"`
+		ConvertedCostAmount   float64 `parquet:"converted_cost_amount"`
+		ConvertedCostCurrency string  `parquet:"converted_cost_currency"`
+		CostCenter            string  `parquet:"metadata_costCenter"`
+		Team                  string  `parquet:"metadata_team"`
+	}
+
+	rows := []chargebackRow{
+		{"2024-05", "2024-05-14", "user1", "123456789012", "lease1", 10.25, "USD", 9.5, "EUR", []string{"1234", "data"}},
+		{"2024-05", "2024-05-15", "user2", "210987654321", "", 7, "USD", 7, "USD", []string{"", ""}},
+	}
+
+	report, err := writeChargebackParquet(rows, []string{"costCenter", "team"})
+	require.Nil(t, err)
+
+	records, err := parquet.Read[chargebackRecord](bytes.NewReader([]byte(report)), int64(len(report)))
+	require.Nil(t, err)
+	assert.Equal(t, []chargebackRecord{
+		{"2024-05", "2024-05-14", "user1", "123456789012", "lease1", 10.25, "USD", 9.5, "EUR", "1234", "data"},
+		{"2024-05", "2024-05-15", "user2", "210987654321", "", 7, "USD", 7, "USD", "", ""},
+	}, records)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/usage"
+	"github.com/parquet-go/parquet-go"
+)
+
+// metadataColumnPrefix prefixes the columns of the lease Metadata keys in the report
+const metadataColumnPrefix = "metadata_"
+
+// chargebackRow is the cost of a principal's usage of an account on a day,
+// with the metadata of the lease it was attributed to
+type chargebackRow struct {
+	BillingPeriod         string
+	Date                  string
+	PrincipalID           string
+	AccountID             string
+	LeaseID               string
+	CostAmount            float64
+	CostCurrency          string
+	ConvertedCostAmount   float64
+	ConvertedCostCurrency string
+	Metadata              []string // Value of each of the report's metadata keys
+}
+
+// chargebackColumns are the report's columns, in order
+func chargebackColumns(metadataKeys []string) []string {
+	columns := []string{
+		"billing_period",
+		"date",
+		"principal_id",
+		"account_id",
+		"lease_id",
+		"cost_amount",
+		"cost_currency",
+		"converted_cost_amount",
+		"converted_cost_currency",
+	}
+	for _, key := range metadataKeys {
+		columns = append(columns, metadataColumnPrefix+key)
+	}
+	return columns
+}
+
+// newChargebackRows joins the usage records with their leases, ordered by date, principal and account
+func newChargebackRows(billingPeriod string, records []*usage.Usage, leases lease.Leases, metadataKeys []string) []chargebackRow {
+	rows := []chargebackRow{}
+	for _, record := range records {
+		if record.PrincipalID == nil || record.AccountID == nil || record.StartDate == nil || record.CostAmount == nil {
+			continue
+		}
+
+		row := chargebackRow{
+			BillingPeriod: billingPeriod,
+			Date:          time.Unix(*record.StartDate, 0).UTC().Format("2006-01-02"),
+			PrincipalID:   *record.PrincipalID,
+			AccountID:     *record.AccountID,
+			CostAmount:    *record.CostAmount,
+			Metadata:      make([]string, len(metadataKeys)),
+		}
+		if record.CostCurrency != nil {
+			row.CostCurrency = *record.CostCurrency
+		}
+		// Usage recorded before currency conversion is in the cost currency
+		row.ConvertedCostAmount, row.ConvertedCostCurrency = row.CostAmount, row.CostCurrency
+		if record.ConvertedCostAmount != nil && record.ConvertedCostCurrency != nil {
+			row.ConvertedCostAmount, row.ConvertedCostCurrency = *record.ConvertedCostAmount, *record.ConvertedCostCurrency
+		}
+
+		if record.LeaseID != nil {
+			row.LeaseID = *record.LeaseID
+		}
+		// Deleted leases have no metadata
+		if l := usage.FindLease(record, leases); l != nil {
+			for i, key := range metadataKeys {
+				if value, ok := l.Metadata[key]; ok && value != nil {
+					row.Metadata[i] = fmt.Sprint(value)
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Date != rows[j].Date {
+			return rows[i].Date < rows[j].Date
+		}
+		if rows[i].PrincipalID != rows[j].PrincipalID {
+			return rows[i].PrincipalID < rows[j].PrincipalID
+		}
+		return rows[i].AccountID < rows[j].AccountID
+	})
+	return rows
+}
+
+// values are the row's values, in the order of the report's columns
+func (row *chargebackRow) values() []interface{} {
+	values := []interface{}{
+		row.BillingPeriod,
+		row.Date,
+		row.PrincipalID,
+		row.AccountID,
+		row.LeaseID,
+		row.CostAmount,
+		row.CostCurrency,
+		row.ConvertedCostAmount,
+		row.ConvertedCostCurrency,
+	}
+	for _, value := range row.Metadata {
+		values = append(values, value)
+	}
+	return values
+}
+
+// writeChargebackCSV encodes the report as CSV, with a header row
+func writeChargebackCSV(rows []chargebackRow, metadataKeys []string) (string, error) {
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+	err := writer.Write(chargebackColumns(metadataKeys))
+	if err != nil {
+		return "", err
+	}
+
+	for _, row := range rows {
+		record := []string{}
+		for _, value := range row.values() {
+			switch v := value.(type) {
+			case float64:
+				record = append(record, strconv.FormatFloat(v, 'f', -1, 64))
+			default:
+				record = append(record, fmt.Sprint(v))
+			}
+		}
+		err = writer.Write(record)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	writer.Flush()
+	return buf.String(), writer.Error()
+}
+
+// writeChargebackParquet encodes the report as Parquet.
+// The metadata columns depend on the configured keys, so the schema is built at runtime.
+func writeChargebackParquet(rows []chargebackRow, metadataKeys []string) (string, error) {
+	columns := chargebackColumns(metadataKeys)
+	group := parquet.Group{}
+	for _, column := range columns {
+		switch column {
+		case "cost_amount", "converted_cost_amount":
+			group[column] = parquet.Leaf(parquet.DoubleType)
+		default:
+			group[column] = parquet.String()
+		}
+	}
+	schema := parquet.NewSchema("chargeback", group)
+
+	// Parquet orders a group's fields by name, rather than the report's column order
+	leafIndexes := map[string]int{}
+	for i, field := range schema.Fields() {
+		leafIndexes[field.Name()] = i
+	}
+
+	parquetRows := make([]parquet.Row, 0, len(rows))
+	for _, row := range rows {
+		parquetRow := make(parquet.Row, len(columns))
+		for i, value := range row.values() {
+			leafIndex := leafIndexes[columns[i]]
+			parquetRow[leafIndex] = parquet.ValueOf(value).Level(0, 0, leafIndex)
+		}
+		parquetRows = append(parquetRows, parquetRow)
+	}
+
+	buf := &bytes.Buffer{}
+	writer := parquet.NewWriter(buf, schema)
+	_, err := writer.WriteRows(parquetRows)
+	if err != nil {
+		return "", err
+	}
+	err = writer.Close()
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
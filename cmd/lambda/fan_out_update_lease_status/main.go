@@ -65,6 +65,24 @@ func handler(cloudWatchEvent events.CloudWatchEvent) error {
 
 	var errs []error
 
+	// End the leases nobody approved in time, so their accounts go back to the pool
+	expired, err := services.LeaseService().ExpirePendingApprovals()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if expired != nil {
+		log.Printf("Expired %d leases pending approval", len(*expired))
+	}
+
+	// Reset the accounts of the frozen leases which are past their frozenUntil date
+	reclaimed, err := services.LeaseService().ReclaimFrozenLeases()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if reclaimed != nil {
+		log.Printf("Reclaimed %d frozen leases", len(*reclaimed))
+	}
+
 	err = services.LeaseService().ListPages(query,
 		func(leases *lease.Leases) bool {
 			for _, ls := range *leases {
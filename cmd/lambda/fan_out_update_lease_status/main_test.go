@@ -130,6 +130,12 @@ func TestLambdaHandler(t *testing.T) {
 			dataSvc.On("List", &lease.Lease{
 				Status: lease.StatusActive.StatusPtr(),
 			}).Return(tt.retLeases, tt.retLeasesErr)
+			dataSvc.On("List", &lease.Lease{
+				Status: lease.StatusPending.StatusPtr(),
+			}).Return(&lease.Leases{}, nil)
+			dataSvc.On("List", &lease.Lease{
+				Status: lease.StatusFrozen.StatusPtr(),
+			}).Return(&lease.Leases{}, nil)
 			lambdaSvc := awsMocks.LambdaAPI{}
 			for _, m := range tt.retLambda {
 				lambdaSvc.On("Invoke", m.input).Return(nil, m.err)
@@ -46,8 +46,9 @@ func (controller CreateController) Call(ctx context.Context, req *events.APIGate
 		log.Printf("Error Getting Lease (%s) by Id: %s", leaseID, err)
 		return response.NotFoundError(), nil
 	}
-	// Don't return any lease information if the lease isn't active
-	if lease.LeaseStatus != db.Active {
+	// Don't return any lease information if the lease isn't active.
+	// Frozen leases can still log in, to export their data.
+	if lease.LeaseStatus != db.Active && lease.LeaseStatus != db.Frozen {
 		log.Printf("Lease (%s) isn't in an active state", leaseID)
 		return response.UnauthorizedError(), nil
 	}
@@ -73,6 +73,31 @@ func TestGetLeaseAuth(t *testing.T) {
 				userRole:         api.AdminGroupName,
 				principalRoleArn: "arn:aws:iam::Account123:role/Principal",
 			},
+			{
+				name:      "FrozenLease",
+				accountID: "Account123",
+				leaseID:   "LeaseABC",
+				expectedResponse: &events.APIGatewayProxyResponse{
+					StatusCode: 201,
+					Headers: map[string]string{
+						"Content-Type":                "application/json",
+						"Access-Control-Allow-Origin": "*",
+					},
+					Body: fmt.Sprintf(
+						`{"accessKeyId":"ExampleKey","secretAccessKey":"ExampleSecret","sessionToken":"ExampleSession","consoleUrl":"%s"}`,
+						fmt.Sprintf(
+							`%s?Action=login\u0026Destination=%s\u0026Issuer=DCE\u0026SigninToken=ExampleSigninToken`,
+							federationURL,
+							url.QueryEscape(consoleURL)),
+					),
+				},
+				assumeRoleErr:    nil,
+				leaseStatus:      db.Frozen,
+				expectedErr:      nil,
+				userName:         "TestUser",
+				userRole:         api.AdminGroupName,
+				principalRoleArn: "arn:aws:iam::Account123:role/Principal",
+			},
 			{
 				name:            "LeaseNotFound",
 				getLeaseByIDErr: nil,
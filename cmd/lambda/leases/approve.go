@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Optum/dce/pkg/api"
+	"github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
+)
+
+// reviewRequest is the request body for approving or rejecting a lease
+type reviewRequest struct {
+	Comment *string `json:"comment,omitempty"`
+}
+
+// ApproveLeaseByID - Approves the given lease, which is pending approval
+func ApproveLeaseByID(w http.ResponseWriter, r *http.Request) {
+	reviewLeaseByID(w, r, "approve", Services.LeaseService().Approve)
+}
+
+// RejectLeaseByID - Rejects the given lease, which is pending approval
+func RejectLeaseByID(w http.ResponseWriter, r *http.Request) {
+	reviewLeaseByID(w, r, "reject", Services.LeaseService().Reject)
+}
+
+func reviewLeaseByID(w http.ResponseWriter, r *http.Request, action string,
+	review func(ID string, reviewedBy string, comment *string) (*lease.Lease, error)) {
+	leaseID := mux.Vars(r)["leaseID"]
+
+	// Only admins may approve or reject leases
+	user := r.Context().Value(api.User{}).(*api.User)
+	if user.Role != api.AdminGroupName {
+		api.WriteAPIErrorResponse(w,
+			errors.NewUnathorizedError(fmt.Sprintf("User [%s] with role: [%s] attempted to %s lease [%s], but only admins can %s leases",
+				user.Username, user.Role, action, leaseID, action)))
+		return
+	}
+
+	// The comment is optional, so an empty body is fine
+	request := &reviewRequest{}
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil && err != io.EOF {
+		api.WriteAPIErrorResponse(w,
+			errors.NewBadRequest("invalid request parameters"))
+		return
+	}
+
+	reviewedLease, err := review(leaseID, user.Username, request.Comment)
+	if err != nil {
+		api.WriteAPIErrorResponse(w, err)
+		return
+	}
+
+	api.WriteAPIResponse(w, http.StatusOK, reviewedLease)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Optum/dce/pkg/api"
+	apiMocks "github.com/Optum/dce/pkg/api/mocks"
+	"github.com/Optum/dce/pkg/config"
+	"github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/lease/leaseiface/mocks"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReviewLeaseByID(t *testing.T) {
+
+	type response struct {
+		StatusCode int
+		Body       string
+	}
+	tests := []struct {
+		name      string
+		user      *api.User
+		action    string
+		body      string
+		retLease  *lease.Lease
+		reviewErr error
+		expResp   response
+	}{
+		{
+			name: "admin approves a pending lease with a comment",
+			user: &api.User{
+				Username: "admin1",
+				Role:     api.AdminGroupName,
+			},
+			action: "approve",
+			body:   "{\"comment\": \"approved by finance\"}",
+			retLease: &lease.Lease{
+				ID:            ptrString("abc123"),
+				Status:        lease.StatusActive.StatusPtr(),
+				ReviewedBy:    ptrString("admin1"),
+				ReviewComment: ptrString("approved by finance"),
+			},
+			expResp: response{
+				StatusCode: 200,
+				Body:       "{\"id\":\"abc123\",\"leaseStatus\":\"Active\",\"reviewedBy\":\"admin1\",\"reviewComment\":\"approved by finance\"}\n",
+			},
+		},
+		{
+			name: "admin rejects a pending lease without a comment",
+			user: &api.User{
+				Username: "admin1",
+				Role:     api.AdminGroupName,
+			},
+			action: "reject",
+			retLease: &lease.Lease{
+				ID:         ptrString("abc123"),
+				Status:     lease.StatusInactive.StatusPtr(),
+				ReviewedBy: ptrString("admin1"),
+			},
+			expResp: response{
+				StatusCode: 200,
+				Body:       "{\"id\":\"abc123\",\"leaseStatus\":\"Inactive\",\"reviewedBy\":\"admin1\"}\n",
+			},
+		},
+		{
+			name: "user cannot approve a lease",
+			user: &api.User{
+				Username: "user1",
+				Role:     api.UserGroupName,
+			},
+			action: "approve",
+			expResp: response{
+				StatusCode: 401,
+				Body:       "{\"error\":{\"message\":\"User [user1] with role: [User] attempted to approve lease [abc123], but only admins can approve leases\",\"code\":\"UnauthorizedError\"}}\n",
+			},
+		},
+		{
+			name: "lease service returns a conflict",
+			user: &api.User{
+				Username: "admin1",
+				Role:     api.AdminGroupName,
+			},
+			action:    "reject",
+			reviewErr: errors.NewConflict("lease", "abc123", fmt.Errorf("leaseStatus: must be pending lease.")),
+			expResp: response{
+				StatusCode: 409,
+				Body:       "{\"error\":{\"message\":\"operation cannot be fulfilled on lease \\\"abc123\\\": leaseStatus: must be pending lease.\",\"code\":\"ConflictError\"}}\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfgBldr := &config.ConfigurationBuilder{}
+			svcBldr := &config.ServiceBuilder{Config: cfgBldr}
+
+			leaseSvc := mocks.Servicer{}
+			leaseSvc.On("Approve", "abc123", "admin1", mock.Anything).Return(
+				tt.retLease, tt.reviewErr,
+			)
+			leaseSvc.On("Reject", "abc123", "admin1", mock.Anything).Return(
+				tt.retLease, tt.reviewErr,
+			)
+
+			userDetailSvc := apiMocks.UserDetailer{}
+			userDetailSvc.On("GetUser", mock.Anything).Return(tt.user)
+
+			svcBldr.Config.WithService(&userDetailSvc)
+			svcBldr.Config.WithService(&leaseSvc)
+			_, err := svcBldr.Build()
+
+			assert.Nil(t, err)
+			if err == nil {
+				Services = svcBldr
+			}
+
+			mockRequest := events.APIGatewayProxyRequest{
+				Path:           "/leases/abc123/" + tt.action,
+				HTTPMethod:     http.MethodPost,
+				Body:           tt.body,
+				RequestContext: events.APIGatewayProxyRequestContext{},
+			}
+			actualResponse, err := Handler(context.TODO(), mockRequest)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expResp.StatusCode, actualResponse.StatusCode)
+			assert.Equal(t, tt.expResp.Body, actualResponse.Body)
+		})
+	}
+}
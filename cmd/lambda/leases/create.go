@@ -2,7 +2,9 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/Optum/dce/pkg/api"
+	"log"
 	"net/http"
 	"time"
 
@@ -11,10 +13,6 @@ import (
 	"github.com/Optum/dce/pkg/lease"
 )
 
-const (
-	Weekly = "WEEKLY"
-)
-
 // CreateLease - Function to validate the lease request and create lease
 func CreateLease(w http.ResponseWriter, r *http.Request) {
 	// Deserialize the request JSON as an request object
@@ -43,37 +41,125 @@ func CreateLease(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get the First available Ready Account
-	query := &account.Account{
-		Status: account.StatusReady.StatusPtr(),
+	// The principal's groups decide how many active leases they may have.
+	// Admins creating leases for other principals don't know their groups, so the default applies.
+	if user.Username == *newLease.PrincipalID && user.Groups != nil {
+		newLease.PrincipalGroups = *user.Groups
 	}
+	newLease.Actor = &user.Username
 
-	accounts, err := Services.AccountService().List(query)
+	accounts, err := getCandidateAccounts(newLease, user)
+	if err != nil {
+		api.WriteAPIErrorResponse(w, err)
+		return
+	}
+
+	// Get user principal's current spend
+	spent, err := getPrincipalSpend(*newLease.PrincipalID)
 	if err != nil {
 		api.WriteAPIErrorResponse(w, err)
 		return
 	}
-	if (accounts == nil) || (accounts != nil && len(*accounts) == 0) {
+
+	// Claim the first Ready account that no one else has leased in the meantime
+	var availableAccount *account.Account
+	if accounts != nil {
+		availableAccount, err = claimAccount(accounts)
+		if err != nil {
+			api.WriteAPIErrorResponse(w, err)
+			return
+		}
+	}
+
+	// An account pinned by an admin is either leased right away or not at all
+	if availableAccount == nil && newLease.AccountID != nil {
 		api.WriteAPIErrorResponse(w,
-			errors.NewInternalServer("No Available accounts at this moment", nil))
+			errors.NewConflict("account", *newLease.AccountID, fmt.Errorf("account is not %s", account.StatusReady)))
 		return
 	}
-	availableAccount := (*accounts)[0]
 
-	// Get user principal's current spend
-	usageStartTime := getBeginningOfCurrentBillingPeriod(Settings.PrincipalBudgetPeriod)
-	usageRecords, err := usageSvc.GetUsageByPrincipal(usageStartTime, *newLease.PrincipalID)
+	// Queue the request until an account becomes available
+	if availableAccount == nil {
+		request, err := Services.LeaseService().CreateRequest(newLease, spent)
+		if err != nil {
+			api.WriteAPIErrorResponse(w, err)
+			return
+		}
+		api.WriteAPIResponse(w, http.StatusAccepted, request)
+		return
+	}
+
+	leaseCreated, err := createLeaseForAccount(newLease, availableAccount, spent)
+	if err != nil && leaseCreated != nil {
+		// The lease was saved, so the account stays Leased
+		log.Printf("Created lease %s for account %s, but failed after saving it: %s", *leaseCreated.ID, *availableAccount.ID, err)
+		api.WriteAPIErrorResponse(w, err)
+		return
+	}
 	if err != nil {
+		// Put the account back in the pool, so it isn't stuck as Leased without a lease
+		_, rollbackErr := Services.AccountService().TransitionStatus(*availableAccount.ID, account.StatusLeased, account.StatusReady)
+		if rollbackErr != nil {
+			log.Printf("Failed to return account %s to Ready: %s", *availableAccount.ID, rollbackErr)
+		}
 		api.WriteAPIErrorResponse(w, err)
 		return
 	}
 
-	// Group by PrincipalID to get sum of total spent for current billing period
-	spent := 0.0
-	for _, usageItem := range usageRecords {
-		spent = spent + *usageItem.CostAmount
+	api.WriteAPIResponse(w, http.StatusCreated, leaseCreated)
+}
+
+// getCandidateAccounts returns the accounts the lease may be created on, most preferred first.
+// Admins may pin the lease to a specific account by setting the accountId.
+func getCandidateAccounts(newLease *lease.Lease, user *api.User) (*account.Accounts, error) {
+	if newLease.AccountID != nil {
+		if user.Role != api.AdminGroupName {
+			return nil, errors.NewUnathorizedError(fmt.Sprintf("User [%s] with role: [%s] attempted to lease account [%s], but only admins can choose the account",
+				user.Username, user.Role, *newLease.AccountID))
+		}
+		pinnedAccount, err := Services.AccountService().Get(*newLease.AccountID)
+		if err != nil {
+			return nil, err
+		}
+		return &account.Accounts{*pinnedAccount}, nil
+	}
+
+	// Get the available Ready Accounts
+	query := &account.Account{
+		Status: account.StatusReady.StatusPtr(),
+	}
+
+	accounts, err := Services.AccountService().List(query)
+	if err != nil {
+		return nil, err
+	}
+	if accounts == nil {
+		return nil, nil
+	}
+
+	return Services.LeaseService().SelectAccounts(newLease, accounts)
+}
+
+// claimAccount marks the first of the accounts which is still Ready as Leased.
+// Accounts claimed by a concurrent request are skipped.
+// Returns nil when none of the accounts could be claimed.
+func claimAccount(accounts *account.Accounts) (*account.Account, error) {
+	for _, acct := range *accounts {
+		claimed, err := Services.AccountService().TransitionStatus(*acct.ID, account.StatusReady, account.StatusLeased)
+		if err != nil {
+			if errors.HTTPCodeForError(err) == http.StatusConflict {
+				log.Printf("Account %s is no longer Ready, trying the next account", *acct.ID)
+				continue
+			}
+			return nil, err
+		}
+		return claimed, nil
 	}
+	return nil, nil
+}
 
+// createLeaseForAccount creates the lease for an account which has already been claimed
+func createLeaseForAccount(newLease *lease.Lease, availableAccount *account.Account, spent float64) (*lease.Lease, error) {
 	// Check if an inactive lease already exists with same principal id and account id
 	// if an inactive lease exists, then get the lastModifiedOn value from it
 	queryLeases := &lease.Lease{}
@@ -83,8 +169,7 @@ func CreateLease(w http.ResponseWriter, r *http.Request) {
 
 	foundLeases, err := Services.LeaseService().List(queryLeases)
 	if err != nil {
-		api.WriteAPIErrorResponse(w, err)
-		return
+		return nil, err
 	}
 
 	// Since we are using primary key to query, the number of leases that match the query should be one
@@ -97,34 +182,10 @@ func CreateLease(w http.ResponseWriter, r *http.Request) {
 
 	// Create lease
 	newLease.AccountID = availableAccount.ID
-	leaseCreated, err := Services.LeaseService().Create(newLease, spent)
-	if err != nil {
-		api.WriteAPIErrorResponse(w, err)
-		return
-	}
-
-	// Mark the account as Status=Leased
-	availableAccount.Status = account.StatusLeased.StatusPtr()
-	_, err = Services.AccountService().Update(*availableAccount.ID, &availableAccount)
-	if err != nil {
-		api.WriteAPIErrorResponse(w, err)
-		return
-	}
-
-	api.WriteAPIResponse(w, http.StatusCreated, leaseCreated)
+	return Services.LeaseService().Create(newLease, spent)
 }
 
-// getBeginningOfCurrentBillingPeriod returns starts of the billing period based on budget period
-func getBeginningOfCurrentBillingPeriod(input string) time.Time {
-	currentTime := time.Now()
-	if input == Weekly {
-
-		for currentTime.Weekday() != time.Sunday { // iterate back to Sunday
-			currentTime = currentTime.AddDate(0, 0, -1)
-		}
-
-		return time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), 0, 0, 0, 0, time.UTC)
-	}
-
-	return time.Date(currentTime.Year(), currentTime.Month(), 1, 0, 0, 0, 0, time.UTC)
+// getPrincipalSpend returns the amount spent by the principal for the current billing period
+func getPrincipalSpend(principalID string) (float64, error) {
+	return usageSvc.GetPrincipalSpend(principalID, principalBudgetPeriod, time.Now())
 }
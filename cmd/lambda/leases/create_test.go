@@ -12,10 +12,12 @@ import (
 	accountmocks "github.com/Optum/dce/pkg/account/accountiface/mocks"
 	apiMocks "github.com/Optum/dce/pkg/api/mocks"
 	"github.com/Optum/dce/pkg/config"
+	"github.com/Optum/dce/pkg/errors"
 	"github.com/Optum/dce/pkg/lease"
 	leasemocks "github.com/Optum/dce/pkg/lease/leaseiface/mocks"
 	mockUsage "github.com/Optum/dce/pkg/usage/mocks"
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -31,7 +33,7 @@ func TestWhenCreateSuccess(t *testing.T) {
 	}
 
 	usageSvcMock := &mockUsage.DBer{}
-	usageSvcMock.On("GetUsageByPrincipal", mock.Anything, mock.Anything).Return(nil, nil)
+	usageSvcMock.On("GetPrincipalSpend", mock.Anything, mock.Anything, mock.Anything).Return(0.0, nil)
 
 	tests := []struct {
 		name                 string
@@ -39,12 +41,13 @@ func TestWhenCreateSuccess(t *testing.T) {
 		expResp              events.APIGatewayProxyResponse
 		request              events.APIGatewayProxyRequest
 		retLease             *lease.Lease
+		retRequest           *lease.Request
 		retAccounts          *account.Accounts
 		retAccount           *account.Account
 		getExistingLeases    *lease.Leases
 		getExistingLeasesErr error
 		retListErr           error
-		retUpdateErr         error
+		retTransitionErr     error
 		retCreateErr         error
 	}{
 		{
@@ -78,7 +81,7 @@ func TestWhenCreateSuccess(t *testing.T) {
 			getExistingLeases:    nil,
 			getExistingLeasesErr: nil,
 			retListErr:           nil,
-			retUpdateErr:         nil,
+			retTransitionErr:     nil,
 			retCreateErr:         nil,
 		},
 		{
@@ -119,9 +122,33 @@ func TestWhenCreateSuccess(t *testing.T) {
 			},
 			getExistingLeasesErr: nil,
 			retListErr:           nil,
-			retUpdateErr:         nil,
+			retTransitionErr:     nil,
 			retCreateErr:         nil,
 		},
+		{
+			name: "When no available accounts to lease. Then the lease request is queued.",
+			user: &api.User{
+				Username: "admin1",
+				Role:     api.AdminGroupName,
+			},
+			expResp: events.APIGatewayProxyResponse{
+				StatusCode:        http.StatusAccepted,
+				Body:              "{\"id\":\"5e9d3b2a-2f5e-4a4c-8d3c-7b1b5c0f1a2b\",\"principalId\":\"User1\",\"requestStatus\":\"Pending\"}\n",
+				Headers:           standardHeaders,
+				MultiValueHeaders: standardMultiValueHeaders,
+			},
+			request: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodPost,
+				Path:       "/leases",
+				Body:       "{ \"principalId\": \"User1\", \"budgetAmount\": 200.00 }",
+			},
+			retAccounts: &account.Accounts{},
+			retRequest: &lease.Request{
+				ID:          ptrString("5e9d3b2a-2f5e-4a4c-8d3c-7b1b5c0f1a2b"),
+				PrincipalID: ptrString("User1"),
+				Status:      lease.RequestStatusPending.RequestStatusPtr(),
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -138,8 +165,14 @@ func TestWhenCreateSuccess(t *testing.T) {
 			accountSvc.On("List", mock.Anything).Return(
 				tt.retAccounts, tt.retListErr,
 			)
-			accountSvc.On("Update", mock.Anything, mock.Anything).Return(
-				tt.retAccount, tt.retUpdateErr,
+			leaseSvc.On("SelectAccounts", mock.AnythingOfType("*lease.Lease"), mock.Anything).Return(
+				tt.retAccounts, nil,
+			)
+			accountSvc.On("TransitionStatus", mock.Anything, account.StatusReady, account.StatusLeased).Return(
+				tt.retAccount, tt.retTransitionErr,
+			)
+			accountSvc.On("TransitionStatus", mock.Anything, account.StatusLeased, account.StatusReady).Return(
+				tt.retAccount, nil,
 			)
 			leaseSvc.On("List", mock.AnythingOfType("*lease.Lease"), mock.Anything).Return(
 				tt.getExistingLeases, tt.getExistingLeasesErr,
@@ -147,6 +180,9 @@ func TestWhenCreateSuccess(t *testing.T) {
 			leaseSvc.On("Create", mock.AnythingOfType("*lease.Lease"), mock.Anything).Return(
 				tt.retLease, tt.retCreateErr,
 			)
+			leaseSvc.On("CreateRequest", mock.AnythingOfType("*lease.Lease"), mock.Anything).Return(
+				tt.retRequest, nil,
+			)
 
 			svcBldr.Config.WithService(&accountSvc).WithService(&leaseSvc).WithEnv("PrincipalBudgetPeriod", "PRINCIPAL_BUDGET_PERIOD", "Weekly").WithService(&userDetailSvc)
 			_, err := svcBldr.Build()
@@ -177,19 +213,19 @@ func TestWhenCreateError(t *testing.T) {
 	}
 
 	usageSvcMock := &mockUsage.DBer{}
-	usageSvcMock.On("GetUsageByPrincipal", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("Error"))
+	usageSvcMock.On("GetPrincipalSpend", mock.Anything, mock.Anything, mock.Anything).Return(0.0, fmt.Errorf("Error"))
 
 	tests := []struct {
-		name         string
-		user         *api.User
-		expResp      events.APIGatewayProxyResponse
-		request      events.APIGatewayProxyRequest
-		retLease     *lease.Lease
-		retAccounts  *account.Accounts
-		retAccount   *account.Account
-		retListErr   error
-		retUpdateErr error
-		retCreateErr error
+		name             string
+		user             *api.User
+		expResp          events.APIGatewayProxyResponse
+		request          events.APIGatewayProxyRequest
+		retLease         *lease.Lease
+		retAccounts      *account.Accounts
+		retAccount       *account.Account
+		retListErr       error
+		retTransitionErr error
+		retCreateErr     error
 	}{
 		{
 			name: "When principalId is missing. Then a client error is returned.",
@@ -208,10 +244,10 @@ func TestWhenCreateError(t *testing.T) {
 				Headers:           standardHeaders,
 				MultiValueHeaders: standardMultiValueHeaders,
 			},
-			retLease:     nil,
-			retListErr:   nil,
-			retUpdateErr: nil,
-			retCreateErr: nil,
+			retLease:         nil,
+			retListErr:       nil,
+			retTransitionErr: nil,
+			retCreateErr:     nil,
 		},
 		{
 			name: "When given bad values like budget amount is a string. Then a syntax error is returned.",
@@ -236,10 +272,10 @@ func TestWhenCreateError(t *testing.T) {
 					Status: account.StatusReady.StatusPtr(),
 				},
 			},
-			retLease:     &lease.Lease{},
-			retListErr:   nil,
-			retUpdateErr: nil,
-			retCreateErr: nil,
+			retLease:         &lease.Lease{},
+			retListErr:       nil,
+			retTransitionErr: nil,
+			retCreateErr:     nil,
 		},
 		{
 			name: "When non admin makes creates lease request. Then an unauthorized error is returned.",
@@ -264,10 +300,10 @@ func TestWhenCreateError(t *testing.T) {
 					Status: account.StatusReady.StatusPtr(),
 				},
 			},
-			retLease:     &lease.Lease{},
-			retListErr:   nil,
-			retUpdateErr: nil,
-			retCreateErr: nil,
+			retLease:         &lease.Lease{},
+			retListErr:       nil,
+			retTransitionErr: nil,
+			retCreateErr:     nil,
 		},
 		{
 			name: "When checking for first available ready account fails. Then an internal server error is returned.",
@@ -286,36 +322,13 @@ func TestWhenCreateError(t *testing.T) {
 				Headers:           standardHeaders,
 				MultiValueHeaders: standardMultiValueHeaders,
 			},
-			retLease:     nil,
-			retListErr:   fmt.Errorf("failure"),
-			retUpdateErr: nil,
-			retCreateErr: nil,
+			retLease:         nil,
+			retListErr:       fmt.Errorf("failure"),
+			retTransitionErr: nil,
+			retCreateErr:     nil,
 		},
 		{
-			name: "When no available accounts to lease. Then an internal server error is returned.",
-			user: &api.User{
-				Username: "admin1",
-				Role:     api.AdminGroupName,
-			},
-			request: events.APIGatewayProxyRequest{
-				HTTPMethod: http.MethodPost,
-				Path:       "/leases",
-				Body:       "{ \"principalId\": \"User1\", \"budgetAmount\": 200.00 }",
-			},
-			expResp: events.APIGatewayProxyResponse{
-				StatusCode:        http.StatusInternalServerError,
-				Body:              "{\"error\":{\"message\":\"No Available accounts at this moment\",\"code\":\"ServerError\"}}\n",
-				Headers:           standardHeaders,
-				MultiValueHeaders: standardMultiValueHeaders,
-			},
-			retAccounts:  &account.Accounts{},
-			retLease:     nil,
-			retListErr:   nil,
-			retUpdateErr: nil,
-			retCreateErr: nil,
-		},
-		{
-			name: "When updating account status to leased fails. Then an internal server error is returned.",
+			name: "When claiming the account fails. Then an internal server error is returned.",
 			user: &api.User{
 				Username: "admin1",
 				Role:     api.AdminGroupName,
@@ -337,10 +350,10 @@ func TestWhenCreateError(t *testing.T) {
 					Status: account.StatusReady.StatusPtr(),
 				},
 			},
-			retLease:     nil,
-			retListErr:   nil,
-			retUpdateErr: fmt.Errorf("failure"),
-			retCreateErr: nil,
+			retLease:         nil,
+			retListErr:       nil,
+			retTransitionErr: fmt.Errorf("failure"),
+			retCreateErr:     nil,
 		},
 		{
 			name: "When getting principal spend fails. Then an internal server error is returned.",
@@ -365,10 +378,10 @@ func TestWhenCreateError(t *testing.T) {
 					Status: account.StatusReady.StatusPtr(),
 				},
 			},
-			retLease:     nil,
-			retListErr:   nil,
-			retUpdateErr: nil,
-			retCreateErr: nil,
+			retLease:         nil,
+			retListErr:       nil,
+			retTransitionErr: nil,
+			retCreateErr:     nil,
 		},
 		{
 			name: "When creating lease fails. Then an internal server error is returned.",
@@ -393,10 +406,10 @@ func TestWhenCreateError(t *testing.T) {
 					Status: account.StatusReady.StatusPtr(),
 				},
 			},
-			retLease:     nil,
-			retListErr:   nil,
-			retUpdateErr: nil,
-			retCreateErr: fmt.Errorf("Error"),
+			retLease:         nil,
+			retListErr:       nil,
+			retTransitionErr: nil,
+			retCreateErr:     fmt.Errorf("Error"),
 		},
 	}
 
@@ -414,8 +427,14 @@ func TestWhenCreateError(t *testing.T) {
 			accountSvc.On("List", mock.Anything).Return(
 				tt.retAccounts, tt.retListErr,
 			)
-			accountSvc.On("Update", mock.Anything, mock.Anything).Return(
-				tt.retAccount, tt.retUpdateErr,
+			leaseSvc.On("SelectAccounts", mock.AnythingOfType("*lease.Lease"), mock.Anything).Return(
+				tt.retAccounts, nil,
+			)
+			accountSvc.On("TransitionStatus", mock.Anything, account.StatusReady, account.StatusLeased).Return(
+				tt.retAccount, tt.retTransitionErr,
+			)
+			accountSvc.On("TransitionStatus", mock.Anything, account.StatusLeased, account.StatusReady).Return(
+				tt.retAccount, nil,
 			)
 			leaseSvc.On("Create", mock.AnythingOfType("*lease.Lease"), mock.Anything).Return(
 				tt.retLease, tt.retCreateErr,
@@ -438,3 +457,244 @@ func TestWhenCreateError(t *testing.T) {
 	}
 
 }
+
+func TestWhenCreateClaimsAccount(t *testing.T) {
+
+	usageSvcMock := &mockUsage.DBer{}
+	usageSvcMock.On("GetPrincipalSpend", mock.Anything, mock.Anything, mock.Anything).Return(0.0, nil)
+
+	readyAccounts := &account.Accounts{
+		account.Account{
+			ID:     ptrString("1234567890"),
+			Status: account.StatusReady.StatusPtr(),
+		},
+		account.Account{
+			ID:     ptrString("2345678901"),
+			Status: account.StatusReady.StatusPtr(),
+		},
+	}
+	conflictErr := errors.NewConflict("account", "1234567890", fmt.Errorf("account status is not \"Ready\""))
+
+	tests := []struct {
+		name              string
+		retTransitionErrs map[string]error
+		retCreateErr      error
+		retCreateSaved    bool
+		expStatusCode     int
+		expLeaseAccountID string
+		expRollback       bool
+	}{
+		{
+			name: "When the first account is claimed by another request. Then the next account is leased.",
+			retTransitionErrs: map[string]error{
+				"1234567890": conflictErr,
+			},
+			expStatusCode:     http.StatusCreated,
+			expLeaseAccountID: "2345678901",
+		},
+		{
+			name: "When every account is claimed by another request. Then the lease request is queued.",
+			retTransitionErrs: map[string]error{
+				"1234567890": conflictErr,
+				"2345678901": conflictErr,
+			},
+			expStatusCode: http.StatusAccepted,
+		},
+		{
+			name:              "When creating the lease fails after claiming the account. Then the account is returned to Ready.",
+			retCreateErr:      errors.NewInternalServer("failure", nil),
+			expStatusCode:     http.StatusInternalServerError,
+			expLeaseAccountID: "1234567890",
+			expRollback:       true,
+		},
+		{
+			name:              "When creating the lease fails after saving it. Then the account stays Leased.",
+			retCreateErr:      errors.NewInternalServer("failure", nil),
+			retCreateSaved:    true,
+			expStatusCode:     http.StatusInternalServerError,
+			expLeaseAccountID: "1234567890",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfgBldr := &config.ConfigurationBuilder{}
+			svcBldr := &config.ServiceBuilder{Config: cfgBldr}
+
+			leaseSvc := leasemocks.Servicer{}
+			accountSvc := accountmocks.Servicer{}
+
+			userDetailSvc := apiMocks.UserDetailer{}
+			userDetailSvc.On("GetUser", mock.Anything).Return(&api.User{
+				Username: "admin1",
+				Role:     api.AdminGroupName,
+			})
+
+			accountSvc.On("List", mock.Anything).Return(readyAccounts, nil)
+			leaseSvc.On("SelectAccounts", mock.AnythingOfType("*lease.Lease"), readyAccounts).Return(readyAccounts, nil)
+			for _, acct := range *readyAccounts {
+				accountSvc.On("TransitionStatus", *acct.ID, account.StatusReady, account.StatusLeased).Return(
+					&account.Account{
+						ID:     acct.ID,
+						Status: account.StatusLeased.StatusPtr(),
+					}, tt.retTransitionErrs[*acct.ID],
+				)
+			}
+			if tt.expRollback {
+				accountSvc.On("TransitionStatus", tt.expLeaseAccountID, account.StatusLeased, account.StatusReady).Return(
+					&account.Account{
+						ID:     &tt.expLeaseAccountID,
+						Status: account.StatusReady.StatusPtr(),
+					}, nil,
+				)
+			}
+			leaseSvc.On("List", mock.AnythingOfType("*lease.Lease")).Return(nil, nil)
+			leaseSvc.On("Create", mock.AnythingOfType("*lease.Lease"), mock.Anything).Return(
+				func(l *lease.Lease, spent float64) *lease.Lease {
+					if tt.retCreateErr != nil && !tt.retCreateSaved {
+						return nil
+					}
+					l.ID = aws.String("lease-1")
+					return l
+				}, tt.retCreateErr,
+			)
+			leaseSvc.On("CreateRequest", mock.AnythingOfType("*lease.Lease"), mock.Anything).Return(
+				&lease.Request{}, nil,
+			)
+
+			svcBldr.Config.WithService(&accountSvc).WithService(&leaseSvc).WithEnv("PrincipalBudgetPeriod", "PRINCIPAL_BUDGET_PERIOD", "Weekly").WithService(&userDetailSvc)
+			_, err := svcBldr.Build()
+
+			assert.Nil(t, err)
+			if err == nil {
+				Services = svcBldr
+			}
+
+			usageSvc = usageSvcMock
+			resp, err := Handler(context.TODO(), events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodPost,
+				Path:       "/leases",
+				Body:       "{ \"principalId\": \"User1\", \"budgetAmount\": 200.00 }",
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expStatusCode, resp.StatusCode)
+			if tt.expLeaseAccountID != "" {
+				leaseSvc.AssertCalled(t, "Create", mock.MatchedBy(func(l *lease.Lease) bool {
+					return *l.AccountID == tt.expLeaseAccountID
+				}), mock.Anything)
+			} else {
+				leaseSvc.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			}
+			if tt.expRollback {
+				accountSvc.AssertCalled(t, "TransitionStatus", tt.expLeaseAccountID, account.StatusLeased, account.StatusReady)
+			} else {
+				accountSvc.AssertNotCalled(t, "TransitionStatus", mock.Anything, account.StatusLeased, account.StatusReady)
+			}
+		})
+	}
+}
+
+func TestWhenCreatePinnedAccount(t *testing.T) {
+
+	usageSvcMock := &mockUsage.DBer{}
+	usageSvcMock.On("GetPrincipalSpend", mock.Anything, mock.Anything, mock.Anything).Return(0.0, nil)
+
+	tests := []struct {
+		name             string
+		user             *api.User
+		retGetErr        error
+		retTransitionErr error
+		expStatusCode    int
+	}{
+		{
+			name: "When an admin pins a Ready account. Then the lease is created on that account.",
+			user: &api.User{
+				Username: "admin1",
+				Role:     api.AdminGroupName,
+			},
+			expStatusCode: http.StatusCreated,
+		},
+		{
+			name: "When an admin pins an account which isn't Ready. Then a conflict error is returned.",
+			user: &api.User{
+				Username: "admin1",
+				Role:     api.AdminGroupName,
+			},
+			retTransitionErr: errors.NewConflict("account", "2345678901", fmt.Errorf("account status is not \"Ready\"")),
+			expStatusCode:    http.StatusConflict,
+		},
+		{
+			name: "When an admin pins an account which doesn't exist. Then a not found error is returned.",
+			user: &api.User{
+				Username: "admin1",
+				Role:     api.AdminGroupName,
+			},
+			retGetErr:     errors.NewNotFound("account", "2345678901"),
+			expStatusCode: http.StatusNotFound,
+		},
+		{
+			name: "When a non admin pins an account. Then an unauthorized error is returned.",
+			user: &api.User{
+				Username: "User1",
+				Role:     api.UserGroupName,
+			},
+			expStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfgBldr := &config.ConfigurationBuilder{}
+			svcBldr := &config.ServiceBuilder{Config: cfgBldr}
+
+			leaseSvc := leasemocks.Servicer{}
+			accountSvc := accountmocks.Servicer{}
+
+			userDetailSvc := apiMocks.UserDetailer{}
+			userDetailSvc.On("GetUser", mock.Anything).Return(tt.user)
+
+			pinnedAccount := &account.Account{
+				ID:     ptrString("2345678901"),
+				Status: account.StatusReady.StatusPtr(),
+			}
+			accountSvc.On("Get", "2345678901").Return(pinnedAccount, tt.retGetErr)
+			accountSvc.On("TransitionStatus", "2345678901", account.StatusReady, account.StatusLeased).Return(
+				pinnedAccount, tt.retTransitionErr,
+			)
+			leaseSvc.On("List", mock.AnythingOfType("*lease.Lease")).Return(nil, nil)
+			leaseSvc.On("Create", mock.AnythingOfType("*lease.Lease"), mock.Anything).Return(
+				func(l *lease.Lease, spent float64) *lease.Lease {
+					return l
+				}, nil,
+			)
+
+			svcBldr.Config.WithService(&accountSvc).WithService(&leaseSvc).WithEnv("PrincipalBudgetPeriod", "PRINCIPAL_BUDGET_PERIOD", "Weekly").WithService(&userDetailSvc)
+			_, err := svcBldr.Build()
+
+			assert.Nil(t, err)
+			if err == nil {
+				Services = svcBldr
+			}
+
+			usageSvc = usageSvcMock
+			resp, err := Handler(context.TODO(), events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodPost,
+				Path:       "/leases",
+				Body:       "{ \"principalId\": \"User1\", \"accountId\": \"2345678901\" }",
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expStatusCode, resp.StatusCode)
+			accountSvc.AssertNotCalled(t, "List", mock.Anything)
+			leaseSvc.AssertNotCalled(t, "CreateRequest", mock.Anything, mock.Anything)
+			if tt.expStatusCode == http.StatusCreated {
+				leaseSvc.AssertCalled(t, "Create", mock.MatchedBy(func(l *lease.Lease) bool {
+					return *l.AccountID == "2345678901"
+				}), mock.Anything)
+			} else {
+				leaseSvc.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -27,7 +27,7 @@ func DeleteLeaseByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	deletedLease, err := Services.LeaseService().Delete(leaseID)
+	deletedLease, err := Services.LeaseService().Delete(leaseID, user.Username)
 
 	if err != nil {
 		api.WriteAPIErrorResponse(w, err)
@@ -76,7 +76,7 @@ func DeleteLease(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	deletedLease, err := Services.LeaseService().Delete(*lease.ID)
+	deletedLease, err := Services.LeaseService().Delete(*lease.ID, user.Username)
 	if err != nil {
 		api.WriteAPIErrorResponse(w, err)
 		return
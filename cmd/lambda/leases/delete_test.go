@@ -124,7 +124,7 @@ func TestDeleteLeaseByLeaseID(t *testing.T) {
 			leaseSvc.On("Get", tt.leaseID).Return(
 				tt.expLease, tt.getErr,
 			)
-			leaseSvc.On("Delete", tt.leaseID).Return(
+			leaseSvc.On("Delete", tt.leaseID, tt.user.Username).Return(
 				tt.expLease, tt.getErr,
 			)
 
@@ -363,7 +363,7 @@ func TestDeleteLeaseByPrincipalIDAndAccountID(t *testing.T) {
 				tt.getLease, tt.getErr,
 			)
 
-			leaseSvc.On("Delete", *tt.expLease.ID).Return(
+			leaseSvc.On("Delete", *tt.expLease.ID, tt.user.Username).Return(
 				tt.expLease, tt.getErr,
 			)
 			userDetailSvc := apiMocks.UserDetailer{}
@@ -8,6 +8,7 @@ import (
 	"log"
 
 	"github.com/Optum/dce/pkg/api"
+	"github.com/Optum/dce/pkg/budget"
 	"github.com/Optum/dce/pkg/config"
 	"github.com/Optum/dce/pkg/usage"
 	"github.com/aws/aws-lambda-go/events"
@@ -16,16 +17,20 @@ import (
 )
 
 type leaseControllerConfiguration struct {
-	Debug                    string  `env:"DEBUG" defaultEnv:"false"`
-	LeaseAddedTopicARN       string  `env:"LEASE_ADDED_TOPIC" defaultEnv:"DCEDefaultProvisionTopic"`
-	DecommissionTopicARN     string  `env:"DECOMMISSION_TOPIC" defaultEnv:"DefaultDecommissionTopicArn"`
-	CognitoUserPoolID        string  `env:"COGNITO_USER_POOL_ID" defaultEnv:"DefaultCognitoUserPoolId"`
-	CognitoAdminName         string  `env:"COGNITO_ROLES_ATTRIBUTE_ADMIN_NAME" defaultEnv:"DefaultCognitoAdminName"`
-	PrincipalBudgetAmount    float64 `env:"PRINCIPAL_BUDGET_AMOUNT" defaultEnv:"1000.00"`
-	PrincipalBudgetPeriod    string  `env:"PRINCIPAL_BUDGET_PERIOD" defaultEnv:"Weekly"`
-	MaxLeaseBudgetAmount     float64 `env:"MAX_LEASE_BUDGET_AMOUNT" defaultEnv:"1000.00"`
-	MaxLeasePeriod           int64   `env:"MAX_LEASE_PERIOD" defaultEnv:"704800"`
-	DefaultLeaseLengthInDays int     `env:"DEFAULT_LEASE_LENGTH_IN_DAYS" defaultEnv:"7"`
+	Debug                      string  `env:"DEBUG" defaultEnv:"false"`
+	LeaseAddedTopicARN         string  `env:"LEASE_ADDED_TOPIC" defaultEnv:"DCEDefaultProvisionTopic"`
+	DecommissionTopicARN       string  `env:"DECOMMISSION_TOPIC" defaultEnv:"DefaultDecommissionTopicArn"`
+	CognitoUserPoolID          string  `env:"COGNITO_USER_POOL_ID" defaultEnv:"DefaultCognitoUserPoolId"`
+	CognitoAdminName           string  `env:"COGNITO_ROLES_ATTRIBUTE_ADMIN_NAME" defaultEnv:"DefaultCognitoAdminName"`
+	PrincipalBudgetAmount      float64 `env:"PRINCIPAL_BUDGET_AMOUNT" defaultEnv:"1000.00"`
+	PrincipalBudgetPeriod      string  `env:"PRINCIPAL_BUDGET_PERIOD" defaultEnv:"Weekly"`
+	PrincipalBudgetWeekStart   string  `env:"PRINCIPAL_BUDGET_PERIOD_WEEK_START" defaultEnv:"SUNDAY"`
+	PrincipalBudgetRollingDays int     `env:"PRINCIPAL_BUDGET_PERIOD_ROLLING_DAYS" defaultEnv:"30"`
+	PrincipalBudgetTimeZone    string  `env:"PRINCIPAL_BUDGET_TIME_ZONE" defaultEnv:"UTC"`
+	MaxLeaseBudgetAmount       float64 `env:"MAX_LEASE_BUDGET_AMOUNT" defaultEnv:"1000.00"`
+	MaxLeasePeriod             int64   `env:"MAX_LEASE_PERIOD" defaultEnv:"704800"`
+	DefaultLeaseLengthInDays   int     `env:"DEFAULT_LEASE_LENGTH_IN_DAYS" defaultEnv:"7"`
+	MaxLeaseExtensions         int64   `env:"MAX_LEASE_EXTENSIONS" defaultEnv:"3"`
 }
 
 var (
@@ -38,8 +43,9 @@ var (
 )
 
 var (
-	baseRequest url.URL
-	usageSvc    usage.DBer
+	baseRequest           url.URL
+	usageSvc              usage.DBer
+	principalBudgetPeriod *budget.Period
 	// Soon to be deprecated - Legacy support
 	//cognitoUserPoolId        string
 	//cognitoAdminName         string
@@ -58,6 +64,34 @@ func init() {
 			Queries:     api.EmptyQueryString,
 			HandlerFunc: GetLeases,
 		},
+		api.Route{
+			Name:        "GetLeaseProfiles",
+			Method:      "GET",
+			Pattern:     "/leases/profiles",
+			Queries:     api.EmptyQueryString,
+			HandlerFunc: GetLeaseProfiles,
+		},
+		api.Route{
+			Name:        "GetLeaseProfileByName",
+			Method:      "GET",
+			Pattern:     "/leases/profiles/{profileName}",
+			Queries:     api.EmptyQueryString,
+			HandlerFunc: GetLeaseProfileByName,
+		},
+		api.Route{
+			Name:        "GetLeaseHistoryByID",
+			Method:      "GET",
+			Pattern:     "/leases/{leaseID}/history",
+			Queries:     api.EmptyQueryString,
+			HandlerFunc: GetLeaseHistoryByID,
+		},
+		api.Route{
+			Name:        "GetLeaseUsageByID",
+			Method:      "GET",
+			Pattern:     "/leases/{leaseID}/usage",
+			Queries:     api.EmptyQueryString,
+			HandlerFunc: GetLeaseUsageByID,
+		},
 		api.Route{
 			Name:        "GetLeaseByID",
 			Method:      "GET",
@@ -65,6 +99,13 @@ func init() {
 			Queries:     api.EmptyQueryString,
 			HandlerFunc: GetLeaseByID,
 		},
+		api.Route{
+			Name:        "GetLeaseRequestByID",
+			Method:      "GET",
+			Pattern:     "/leases/requests/{requestID}",
+			Queries:     api.EmptyQueryString,
+			HandlerFunc: GetLeaseRequestByID,
+		},
 		api.Route{
 			Name:        "DeleteLeaseByID",
 			Method:      "DELETE",
@@ -72,6 +113,27 @@ func init() {
 			Queries:     api.EmptyQueryString,
 			HandlerFunc: DeleteLeaseByID,
 		},
+		api.Route{
+			Name:        "UpdateLeaseByID",
+			Method:      "PATCH",
+			Pattern:     "/leases/{leaseID}",
+			Queries:     api.EmptyQueryString,
+			HandlerFunc: UpdateLeaseByID,
+		},
+		api.Route{
+			Name:        "ApproveLeaseByID",
+			Method:      "POST",
+			Pattern:     "/leases/{leaseID}/approve",
+			Queries:     api.EmptyQueryString,
+			HandlerFunc: ApproveLeaseByID,
+		},
+		api.Route{
+			Name:        "RejectLeaseByID",
+			Method:      "POST",
+			Pattern:     "/leases/{leaseID}/reject",
+			Queries:     api.EmptyQueryString,
+			HandlerFunc: RejectLeaseByID,
+		},
 		api.Route{
 			Name:        "DeleteLease",
 			Method:      "DELETE",
@@ -102,8 +164,19 @@ func initConfig() {
 		log.Fatalf("Could not load configuration: %s", err.Error())
 	}
 
+	period, err := budget.NewPeriod(budget.NewPeriodInput{
+		Period:      Settings.PrincipalBudgetPeriod,
+		WeekStart:   Settings.PrincipalBudgetWeekStart,
+		RollingDays: Settings.PrincipalBudgetRollingDays,
+		TimeZone:    Settings.PrincipalBudgetTimeZone,
+	})
+	if err != nil {
+		log.Fatalf("Could not load principal budget period: %s", err.Error())
+	}
+	principalBudgetPeriod = period
+
 	// load up the values into the various settings...
-	err := cfgBldr.WithEnv("AWS_CURRENT_REGION", "AWS_CURRENT_REGION", "us-east-1").Build()
+	err = cfgBldr.WithEnv("AWS_CURRENT_REGION", "AWS_CURRENT_REGION", "us-east-1").Build()
 	if err != nil {
 		log.Printf("Error: %+v", err)
 	}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Optum/dce/pkg/api"
+	"github.com/Optum/dce/pkg/lease"
+)
+
+// GetLeaseProfiles - Returns the lease profiles a lease may be created with
+func GetLeaseProfiles(w http.ResponseWriter, r *http.Request) {
+
+	profiles, err := Services.LeaseService().ListProfiles(&lease.Profile{})
+	if err != nil {
+		api.WriteAPIErrorResponse(w, err)
+		return
+	}
+
+	api.WriteAPIResponse(w, http.StatusOK, profiles)
+}
+
+// GetLeaseProfileByName - Returns the single lease profile by name
+func GetLeaseProfileByName(w http.ResponseWriter, r *http.Request) {
+
+	profileName := mux.Vars(r)["profileName"]
+
+	profile, err := Services.LeaseService().GetProfile(profileName)
+	if err != nil {
+		api.WriteAPIErrorResponse(w, err)
+		return
+	}
+
+	api.WriteAPIResponse(w, http.StatusOK, profile)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Optum/dce/pkg/api"
+	apiMocks "github.com/Optum/dce/pkg/api/mocks"
+	"github.com/Optum/dce/pkg/config"
+	"github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/lease/leaseiface/mocks"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetLeaseProfileByName(t *testing.T) {
+
+	type response struct {
+		StatusCode int
+		Body       string
+	}
+	tests := []struct {
+		name       string
+		expResp    response
+		retProfile *lease.Profile
+		retErr     error
+	}{
+		{
+			name: "When Get lease profile service returns a success",
+			expResp: response{
+				StatusCode: 200,
+				Body:       "{\"name\":\"sandbox-small\",\"maxBudgetAmount\":100}\n",
+			},
+			retProfile: &lease.Profile{
+				Name:            ptrString("sandbox-small"),
+				MaxBudgetAmount: aws.Float64(100),
+			},
+		},
+		{
+			name: "When Get lease profile service returns not found",
+			expResp: response{
+				StatusCode: 404,
+				Body:       "{\"error\":{\"message\":\"lease profile \\\"sandbox-small\\\" not found\",\"code\":\"NotFoundError\"}}\n",
+			},
+			retErr: errors.NewNotFound("lease profile", "sandbox-small"),
+		},
+		{
+			name: "When Get lease profile service returns a failure",
+			expResp: response{
+				StatusCode: 500,
+				Body:       "{\"error\":{\"message\":\"unknown error\",\"code\":\"ServerError\"}}\n",
+			},
+			retErr: fmt.Errorf("failure"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfgBldr := &config.ConfigurationBuilder{}
+			svcBldr := &config.ServiceBuilder{Config: cfgBldr}
+
+			leaseSvc := mocks.Servicer{}
+			leaseSvc.On("GetProfile", "sandbox-small").Return(
+				tt.retProfile, tt.retErr,
+			)
+			userDetailSvc := apiMocks.UserDetailer{}
+			userDetailSvc.On("GetUser", mock.Anything).Return(&api.User{
+				Username: "user1",
+				Role:     api.UserGroupName,
+			})
+			svcBldr.Config.WithService(&userDetailSvc)
+			svcBldr.Config.WithService(&leaseSvc)
+			_, err := svcBldr.Build()
+
+			assert.Nil(t, err)
+			if err == nil {
+				Services = svcBldr
+			}
+
+			mockRequest := events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Path: "/leases/profiles/sandbox-small"}
+			actualResponse, err := Handler(context.TODO(), mockRequest)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expResp.StatusCode, actualResponse.StatusCode)
+			assert.Equal(t, tt.expResp.Body, actualResponse.Body)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Optum/dce/pkg/api"
+)
+
+// GetLeaseRequestByID - Returns the single lease request by ID
+func GetLeaseRequestByID(w http.ResponseWriter, r *http.Request) {
+
+	requestID := mux.Vars(r)["requestID"]
+
+	request, err := Services.LeaseService().GetRequest(requestID)
+	if err != nil {
+		api.WriteAPIErrorResponse(w, err)
+		return
+	}
+
+	// If user is not an admin, they can't get lease requests for other users
+	user := r.Context().Value(api.User{}).(*api.User)
+	err = user.Authorize(*request.PrincipalID)
+	if err != nil {
+		api.WriteAPIErrorResponse(w, err)
+		return
+	}
+
+	api.WriteAPIResponse(w, http.StatusOK, request)
+}
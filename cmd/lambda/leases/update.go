@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Optum/dce/pkg/api"
+	"github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/gorilla/mux"
+)
+
+// UpdateLeaseByID - Extends the given lease by Lease ID
+func UpdateLeaseByID(w http.ResponseWriter, r *http.Request) {
+	leaseID := mux.Vars(r)["leaseID"]
+
+	// Deserialize the request JSON as an request object
+	newLease := &lease.Lease{}
+	decoder := json.NewDecoder(r.Body)
+	err := decoder.Decode(newLease)
+	if err != nil {
+		api.WriteAPIErrorResponse(w,
+			errors.NewBadRequest("invalid request parameters"))
+		return
+	}
+
+	_lease, err := Services.LeaseService().Get(leaseID)
+	if err != nil {
+		api.WriteAPIErrorResponse(w, err)
+		return
+	}
+
+	// If user is not an admin, they can't update leases for other users
+	user := r.Context().Value(api.User{}).(*api.User)
+	err = user.Authorize(*_lease.PrincipalID)
+	if err != nil {
+		api.WriteAPIErrorResponse(w, err)
+		return
+	}
+
+	// Get user principal's current spend
+	spent, err := getPrincipalSpend(*_lease.PrincipalID)
+	if err != nil {
+		api.WriteAPIErrorResponse(w, err)
+		return
+	}
+
+	newLease.Actor = &user.Username
+	updatedLease, err := Services.LeaseService().Update(leaseID, newLease, spent)
+	if err != nil {
+		api.WriteAPIErrorResponse(w, err)
+		return
+	}
+
+	api.WriteAPIResponse(w, http.StatusOK, updatedLease)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Optum/dce/pkg/api"
+	apiMocks "github.com/Optum/dce/pkg/api/mocks"
+	"github.com/Optum/dce/pkg/config"
+	"github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/lease/leaseiface/mocks"
+	mockUsage "github.com/Optum/dce/pkg/usage/mocks"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateLeaseByID(t *testing.T) {
+
+	type response struct {
+		StatusCode int
+		Body       string
+	}
+	tests := []struct {
+		name      string
+		user      *api.User
+		body      string
+		leaseID   string
+		getLease  *lease.Lease
+		getErr    error
+		retLease  *lease.Lease
+		updateErr error
+		expResp   response
+	}{
+		{
+			name: "user successfully extends their own lease",
+			user: &api.User{
+				Username: "user1",
+				Role:     api.UserGroupName,
+			},
+			body:    "{\"expiresOn\": 1600000000, \"budgetAmount\": 300}",
+			leaseID: "abc123",
+			getLease: &lease.Lease{
+				ID:          ptrString("abc123"),
+				PrincipalID: ptrString("user1"),
+				AccountID:   ptrString("123456789012"),
+			},
+			retLease: &lease.Lease{
+				ID:             ptrString("abc123"),
+				PrincipalID:    ptrString("user1"),
+				AccountID:      ptrString("123456789012"),
+				ExtensionCount: ptrInt64(1),
+			},
+			expResp: response{
+				StatusCode: 200,
+				Body:       "{\"accountId\":\"123456789012\",\"principalId\":\"user1\",\"id\":\"abc123\",\"extensionCount\":1}\n",
+			},
+		},
+		{
+			name: "user cannot extend other users lease",
+			user: &api.User{
+				Username: "user1",
+				Role:     api.UserGroupName,
+			},
+			body:    "{\"expiresOn\": 1600000000}",
+			leaseID: "abc123",
+			getLease: &lease.Lease{
+				ID:          ptrString("abc123"),
+				PrincipalID: ptrString("user2"),
+				AccountID:   ptrString("123456789012"),
+			},
+			expResp: response{
+				StatusCode: 401,
+				Body:       "{\"error\":{\"message\":\"User [user1] with role: [User] attempted to act on a lease for [user2], but was not authorized\",\"code\":\"UnauthorizedError\"}}\n",
+			},
+		},
+		{
+			name: "invalid request body",
+			user: &api.User{
+				Username: "admin1",
+				Role:     api.AdminGroupName,
+			},
+			body:    "{\"expiresOn\": \"tomorrow\"}",
+			leaseID: "abc123",
+			expResp: response{
+				StatusCode: 400,
+				Body:       "{\"error\":{\"message\":\"invalid request parameters\",\"code\":\"ClientError\"}}\n",
+			},
+		},
+		{
+			name: "lease service returns a conflict",
+			user: &api.User{
+				Username: "admin1",
+				Role:     api.AdminGroupName,
+			},
+			body:    "{\"expiresOn\": 1600000000}",
+			leaseID: "abc123",
+			getLease: &lease.Lease{
+				ID:          ptrString("abc123"),
+				PrincipalID: ptrString("user1"),
+				AccountID:   ptrString("123456789012"),
+			},
+			updateErr: errors.NewConflict("lease", "abc123", fmt.Errorf("lease has already been extended 3 times, which is the max lease extensions of 3")),
+			expResp: response{
+				StatusCode: 409,
+				Body:       "{\"error\":{\"message\":\"operation cannot be fulfilled on lease \\\"abc123\\\": lease has already been extended 3 times, which is the max lease extensions of 3\",\"code\":\"ConflictError\"}}\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfgBldr := &config.ConfigurationBuilder{}
+			svcBldr := &config.ServiceBuilder{Config: cfgBldr}
+
+			leaseSvc := mocks.Servicer{}
+			leaseSvc.On("Get", tt.leaseID).Return(
+				tt.getLease, tt.getErr,
+			)
+			leaseSvc.On("Update", tt.leaseID, mock.AnythingOfType("*lease.Lease"), mock.AnythingOfType("float64")).Return(
+				tt.retLease, tt.updateErr,
+			)
+
+			userDetailSvc := apiMocks.UserDetailer{}
+			userDetailSvc.On("GetUser", mock.Anything).Return(tt.user)
+
+			svcBldr.Config.WithService(&userDetailSvc)
+			svcBldr.Config.WithService(&leaseSvc)
+			_, err := svcBldr.Build()
+
+			assert.Nil(t, err)
+			if err == nil {
+				Services = svcBldr
+			}
+
+			usageSvcMock := &mockUsage.DBer{}
+			usageSvcMock.On("GetPrincipalSpend", mock.Anything, mock.Anything, mock.Anything).Return(0.0, nil)
+			usageSvc = usageSvcMock
+
+			mockRequest := events.APIGatewayProxyRequest{
+				Path:           "/leases/" + tt.leaseID,
+				HTTPMethod:     http.MethodPatch,
+				Body:           tt.body,
+				RequestContext: events.APIGatewayProxyRequestContext{},
+			}
+			actualResponse, err := Handler(context.TODO(), mockRequest)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expResp.StatusCode, actualResponse.StatusCode)
+			assert.Equal(t, tt.expResp.Body, actualResponse.Body)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/Optum/dce/pkg/api"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/usage"
+)
+
+// GetLeaseUsageByID - Returns the daily usage of a lease, with its cost by AWS service, and its total
+func GetLeaseUsageByID(w http.ResponseWriter, r *http.Request) {
+
+	leaseID := mux.Vars(r)["leaseID"]
+
+	lease, err := Services.LeaseService().Get(leaseID)
+	if err != nil {
+		api.WriteAPIErrorResponse(w, err)
+		return
+	}
+
+	//If user is not an admin, they can't get the usage of leases for other users
+	user := r.Context().Value(api.User{}).(*api.User)
+	err = user.Authorize(*lease.PrincipalID)
+	if err != nil {
+		api.WriteAPIErrorResponse(w, err)
+		return
+	}
+
+	startDate, endDate := leaseUsagePeriod(lease, time.Now())
+	usageRecords, err := usageSvc.GetUsageByLease(leaseID, startDate, endDate)
+	if err != nil {
+		api.WriteAPIErrorResponse(w, err)
+		return
+	}
+
+	api.WriteAPIResponse(w, http.StatusOK, usage.NewLeaseSpend(lease, usageRecords, startDate, endDate))
+}
+
+// leaseUsagePeriod returns the dates the lease was in use.
+// Leases which have ended were last in use when their status changed.
+func leaseUsagePeriod(l *lease.Lease, now time.Time) (time.Time, time.Time) {
+	startDate := now
+	if l.CreatedOn != nil {
+		startDate = time.Unix(*l.CreatedOn, 0)
+	}
+
+	endDate := now
+	inUse := l.Status != nil && (*l.Status == lease.StatusActive || *l.Status == lease.StatusFrozen)
+	if !inUse && l.StatusModifiedOn != nil {
+		endDate = time.Unix(*l.StatusModifiedOn, 0)
+	}
+
+	return startDate.UTC(), endDate.UTC()
+}
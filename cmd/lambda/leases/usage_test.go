@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Optum/dce/pkg/api"
+	apiMocks "github.com/Optum/dce/pkg/api/mocks"
+	"github.com/Optum/dce/pkg/config"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/lease/leaseiface/mocks"
+	"github.com/Optum/dce/pkg/usage"
+	mockUsage "github.com/Optum/dce/pkg/usage/mocks"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetLeaseUsageByID(t *testing.T) {
+
+	type response struct {
+		StatusCode int
+		Body       string
+	}
+	tests := []struct {
+		name     string
+		user     *api.User
+		expResp  response
+		retLease *lease.Lease
+		retUsage []*usage.Usage
+		retErr   error
+	}{
+		{
+			name: "When user Get usage of own lease service returns the lease's usage",
+			user: &api.User{
+				Username: "user1",
+				Role:     api.UserGroupName,
+			},
+			expResp: response{
+				StatusCode: 200,
+				Body: "{\"leaseId\":\"abc123\",\"principalId\":\"user1\",\"accountId\":\"123456789012\",\"startDate\":1715644800,\"endDate\":1715817599," +
+					"\"costAmount\":35,\"costCurrency\":\"USD\",\"usage\":[" +
+					"{\"principalId\":\"user1\",\"accountId\":\"123456789012\",\"leaseId\":\"abc123\",\"startDate\":1715644800,\"costAmount\":25,\"costCurrency\":\"USD\",\"serviceCosts\":{\"Amazon SageMaker\":20,\"EC2 - Other\":5}}," +
+					"{\"principalId\":\"user1\",\"accountId\":\"123456789012\",\"leaseId\":\"abc123\",\"startDate\":1715731200,\"costAmount\":10,\"costCurrency\":\"USD\"}]}\n",
+			},
+			retLease: &lease.Lease{
+				ID:               ptrString("abc123"),
+				PrincipalID:      ptrString("user1"),
+				AccountID:        ptrString("123456789012"),
+				Status:           lease.StatusInactive.StatusPtr(),
+				CreatedOn:        aws.Int64(time.Date(2024, 5, 14, 6, 0, 0, 0, time.UTC).Unix()),
+				StatusModifiedOn: aws.Int64(time.Date(2024, 5, 15, 2, 0, 0, 0, time.UTC).Unix()),
+			},
+			retUsage: []*usage.Usage{
+				{
+					PrincipalID:  aws.String("user1"),
+					AccountID:    aws.String("123456789012"),
+					LeaseID:      aws.String("abc123"),
+					StartDate:    aws.Int64(time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC).Unix()),
+					CostAmount:   aws.Float64(25),
+					CostCurrency: aws.String("USD"),
+					ServiceCosts: map[string]float64{"Amazon SageMaker": 20, "EC2 - Other": 5},
+				},
+				{
+					PrincipalID:  aws.String("user1"),
+					AccountID:    aws.String("123456789012"),
+					LeaseID:      aws.String("abc123"),
+					StartDate:    aws.Int64(time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC).Unix()),
+					CostAmount:   aws.Float64(10),
+					CostCurrency: aws.String("USD"),
+				},
+			},
+		},
+		{
+			name: "When user Get usage of other user's lease service returns 401",
+			user: &api.User{
+				Username: "user1",
+				Role:     api.UserGroupName,
+			},
+			expResp: response{
+				StatusCode: 401,
+				Body:       "{\"error\":{\"message\":\"User [user1] with role: [User] attempted to act on a lease for [user2], but was not authorized\",\"code\":\"UnauthorizedError\"}}\n",
+			},
+			retLease: &lease.Lease{
+				PrincipalID: ptrString("user2"),
+				AccountID:   ptrString("123456789012"),
+			},
+		},
+		{
+			name: "When usage service returns a failure",
+			user: &api.User{
+				Username: "admin1",
+				Role:     api.AdminGroupName,
+			},
+			expResp: response{
+				StatusCode: 500,
+				Body:       "{\"error\":{\"message\":\"unknown error\",\"code\":\"ServerError\"}}\n",
+			},
+			retLease: &lease.Lease{
+				PrincipalID: ptrString("user1"),
+				AccountID:   ptrString("123456789012"),
+			},
+			retErr: fmt.Errorf("failure"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfgBldr := &config.ConfigurationBuilder{}
+			svcBldr := &config.ServiceBuilder{Config: cfgBldr}
+
+			leaseSvc := mocks.Servicer{}
+			leaseSvc.On("Get", "abc123").Return(tt.retLease, nil)
+			userDetailSvc := apiMocks.UserDetailer{}
+			userDetailSvc.On("GetUser", mock.Anything).Return(tt.user)
+			svcBldr.Config.WithService(&userDetailSvc)
+			svcBldr.Config.WithService(&leaseSvc)
+			_, err := svcBldr.Build()
+
+			assert.Nil(t, err)
+			if err == nil {
+				Services = svcBldr
+			}
+
+			usageSvcMock := &mockUsage.DBer{}
+			usageSvcMock.On("GetUsageByLease", "abc123", mock.Anything, mock.Anything).Return(tt.retUsage, tt.retErr)
+			usageSvc = usageSvcMock
+
+			mockRequest := events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Path: "/leases/abc123/usage"}
+			actualResponse, err := Handler(context.TODO(), mockRequest)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expResp.StatusCode, actualResponse.StatusCode)
+			assert.Equal(t, tt.expResp.Body, actualResponse.Body)
+		})
+	}
+}
+
+func TestLeaseUsagePeriod(t *testing.T) {
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	createdOn := now.AddDate(0, 0, -10).Unix()
+	endedOn := now.AddDate(0, 0, -2).Unix()
+
+	startDate, endDate := leaseUsagePeriod(&lease.Lease{
+		CreatedOn:        &createdOn,
+		Status:           lease.StatusActive.StatusPtr(),
+		StatusModifiedOn: &endedOn,
+	}, now)
+	assert.Equal(t, time.Unix(createdOn, 0).UTC(), startDate)
+	assert.Equal(t, now, endDate, "active leases are in use until now")
+
+	startDate, endDate = leaseUsagePeriod(&lease.Lease{
+		CreatedOn:        &createdOn,
+		Status:           lease.StatusInactive.StatusPtr(),
+		StatusModifiedOn: &endedOn,
+	}, now)
+	assert.Equal(t, time.Unix(createdOn, 0).UTC(), startDate)
+	assert.Equal(t, time.Unix(endedOn, 0).UTC(), endDate, "inactive leases were in use until they ended")
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Optum/dce/pkg/db"
+	"github.com/Optum/dce/pkg/email"
+	"github.com/pkg/errors"
+)
+
+// dueExpiryNotificationThresholds returns the thresholds, in hours before the lease expires,
+// which were crossed and the principal wasn't notified of yet. Smallest threshold first.
+func dueExpiryNotificationThresholds(lease *db.Lease, thresholds []float64, currentTime time.Time) []float64 {
+	secondsRemaining := lease.ExpiresOn - currentTime.Unix()
+
+	due := []float64{}
+	for _, threshold := range thresholds {
+		if float64(secondsRemaining) > threshold*3600 {
+			continue
+		}
+		notified := false
+		for _, sent := range lease.ExpiryNotificationsSent {
+			if sent == threshold {
+				notified = true
+				break
+			}
+		}
+		if !notified {
+			due = append(due, threshold)
+		}
+	}
+	sort.Float64s(due)
+
+	return due
+}
+
+// handleExpiryNotification lets the principal know their lease is about to expire.
+// Each threshold is only notified once per lease. When several thresholds are crossed
+// in the same run (eg. a lease created shortly before it expires), only one email is sent.
+func handleExpiryNotification(input *lambdaHandlerInput, currentTime time.Time) error {
+	due := dueExpiryNotificationThresholds(input.lease, input.expiryNotificationThresholds, currentTime)
+	if len(due) == 0 {
+		return nil
+	}
+
+	if len(input.lease.BudgetNotificationEmails)+len(input.budgetNotificationBCCEmails) == 0 {
+		log.Printf("Skipping lease expiry notification email: "+
+			"no notification emails addressses were provided for lease %s @ %s",
+			input.lease.PrincipalID, input.lease.AccountID)
+	} else {
+		err := sendExpiryNotificationEmail(input, due[0], currentTime)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := input.leaseSvc.MarkExpiryNotified(input.lease.ID, due)
+	return err
+}
+
+// sendExpiryNotificationEmail renders the expiry notification templates from S3,
+// and emails them to the principal
+func sendExpiryNotificationEmail(input *lambdaHandlerInput, threshold float64, currentTime time.Time) error {
+	log.Printf("Lease %s @ %s expires within %g hours.  Notifying principal...",
+		input.lease.PrincipalID, input.lease.AccountID, threshold)
+
+	templateData := struct {
+		Lease          db.Lease
+		ExpiresOn      string
+		HoursRemaining int
+		ThresholdHours float64
+	}{
+		Lease:          *input.lease,
+		ExpiresOn:      time.Unix(input.lease.ExpiresOn, 0).UTC().Format(time.RFC1123),
+		HoursRemaining: int(math.Ceil(float64(input.lease.ExpiresOn-currentTime.Unix()) / 3600)),
+		ThresholdHours: threshold,
+	}
+
+	bodyHTML, _, err := input.s3Svc.GetTemplateObject(input.budgetNotificationTemplatesBucket, input.expiryNotificationTemplateHTMLKey, templateData)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to render expiry notification template at s3://%s/%s",
+			input.budgetNotificationTemplatesBucket, input.expiryNotificationTemplateHTMLKey)
+	}
+	bodyText, _, err := input.s3Svc.GetTemplateObject(input.budgetNotificationTemplatesBucket, input.expiryNotificationTemplateTextKey, templateData)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to render expiry notification template at s3://%s/%s",
+			input.budgetNotificationTemplatesBucket, input.expiryNotificationTemplateTextKey)
+	}
+	subject, err := renderTemplate("expirySubject", input.expiryNotificationTemplateSubject, templateData)
+	if err != nil {
+		return err
+	}
+
+	return input.emailSvc.SendEmail(&email.SendEmailInput{
+		FromAddress:  input.budgetNotificationFromEmail,
+		ToAddresses:  input.lease.BudgetNotificationEmails,
+		BCCAddresses: input.budgetNotificationBCCEmails,
+		BodyHTML:     bodyHTML,
+		BodyText:     bodyText,
+		Subject:      subject,
+	})
+}
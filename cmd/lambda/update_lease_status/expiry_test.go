@@ -0,0 +1,120 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	commonMocks "github.com/Optum/dce/pkg/common/mocks"
+	"github.com/Optum/dce/pkg/db"
+	"github.com/Optum/dce/pkg/email"
+	emailMocks "github.com/Optum/dce/pkg/email/mocks"
+	"github.com/Optum/dce/pkg/lease"
+	leaseMocks "github.com/Optum/dce/pkg/lease/leaseiface/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandleExpiryNotification(t *testing.T) {
+	currentTime := time.Now()
+
+	tests := []struct {
+		name                    string
+		expiresOn               int64
+		expiryNotificationsSent []float64
+		notificationEmails      []string
+		expNotified             []float64
+		expSubject              string
+	}{
+		{
+			name:               "should notify the principal once a threshold is crossed",
+			expiresOn:          currentTime.Add(70 * time.Hour).Unix(),
+			notificationEmails: []string{"recipA@example.com"},
+			expNotified:        []float64{72},
+			expSubject:         "Lease expires in 70 hours [1234567890]",
+		},
+		{
+			name:                    "should not notify the principal of a threshold twice",
+			expiresOn:               currentTime.Add(70 * time.Hour).Unix(),
+			expiryNotificationsSent: []float64{72},
+			notificationEmails:      []string{"recipA@example.com"},
+		},
+		{
+			name:                    "should notify the principal of the next threshold",
+			expiresOn:               currentTime.Add(20 * time.Hour).Unix(),
+			expiryNotificationsSent: []float64{72},
+			notificationEmails:      []string{"recipA@example.com"},
+			expNotified:             []float64{24},
+			expSubject:              "Lease expires in 20 hours [1234567890]",
+		},
+		{
+			name:               "should send a single email when several thresholds are crossed",
+			expiresOn:          currentTime.Add(30 * time.Minute).Unix(),
+			notificationEmails: []string{"recipA@example.com"},
+			expNotified:        []float64{1, 24, 72},
+			expSubject:         "Lease expires in 1 hours [1234567890]",
+		},
+		{
+			name:      "should not notify the principal before the first threshold",
+			expiresOn: currentTime.Add(100 * time.Hour).Unix(),
+		},
+		{
+			name:        "should mark thresholds as notified when there's nobody to email",
+			expiresOn:   currentTime.Add(70 * time.Hour).Unix(),
+			expNotified: []float64{72},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaseSvc := &leaseMocks.Servicer{}
+			emailSvc := &emailMocks.Service{}
+			s3Svc := &commonMocks.Storager{}
+			input := &lambdaHandlerInput{
+				lease: &db.Lease{
+					ID:                       "abc123",
+					AccountID:                "1234567890",
+					PrincipalID:              "test-user",
+					LeaseStatus:              db.Active,
+					BudgetNotificationEmails: tt.notificationEmails,
+					ExpiresOn:                tt.expiresOn,
+					ExpiryNotificationsSent:  tt.expiryNotificationsSent,
+				},
+				leaseSvc:                          leaseSvc,
+				emailSvc:                          emailSvc,
+				s3Svc:                             s3Svc,
+				budgetNotificationFromEmail:       "from@example.com",
+				budgetNotificationTemplatesBucket: "test-bucket",
+				expiryNotificationThresholds:      []float64{72, 24, 1},
+				expiryNotificationTemplateHTMLKey: "expiry.html",
+				expiryNotificationTemplateTextKey: "expiry.txt",
+				expiryNotificationTemplateSubject: "Lease expires in {{.HoursRemaining}} hours [{{.Lease.AccountID}}]",
+			}
+
+			leaseSvc.On("MarkExpiryNotified", "abc123", mock.Anything).Return(&lease.Lease{}, nil)
+			s3Svc.On("GetTemplateObject", "test-bucket", "expiry.html", mock.Anything).Return("<p>expiring</p>", "", nil)
+			s3Svc.On("GetTemplateObject", "test-bucket", "expiry.txt", mock.Anything).Return("expiring", "", nil)
+			emailSvc.On("SendEmail", mock.AnythingOfType("*email.SendEmailInput")).Return(nil)
+
+			err := handleExpiryNotification(input, currentTime)
+			assert.Nil(t, err)
+
+			if tt.expNotified != nil {
+				leaseSvc.AssertCalled(t, "MarkExpiryNotified", "abc123", tt.expNotified)
+			} else {
+				leaseSvc.AssertNotCalled(t, "MarkExpiryNotified", mock.Anything, mock.Anything)
+			}
+			if tt.expSubject != "" {
+				emailSvc.AssertCalled(t, "SendEmail", &email.SendEmailInput{
+					FromAddress: "from@example.com",
+					ToAddresses: tt.notificationEmails,
+					BodyHTML:    "<p>expiring</p>",
+					BodyText:    "expiring",
+					Subject:     tt.expSubject,
+				})
+				emailSvc.AssertNumberOfCalls(t, "SendEmail", 1)
+			} else {
+				emailSvc.AssertNotCalled(t, "SendEmail", mock.Anything)
+			}
+		})
+	}
+}
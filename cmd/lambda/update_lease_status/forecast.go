@@ -0,0 +1,160 @@
+package main
+
+import (
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Optum/dce/pkg/budget"
+	"github.com/Optum/dce/pkg/db"
+	"github.com/Optum/dce/pkg/email"
+	"github.com/pkg/errors"
+)
+
+const (
+	// forecastMethodLinear projects the lease's spend so far across its remaining days
+	forecastMethodLinear = "LINEAR"
+	// forecastMethodCostExplorer uses Cost Explorer's forecast for the remaining days,
+	// falling back to a linear forecast when Cost Explorer can't forecast the account
+	forecastMethodCostExplorer = "COST_EXPLORER"
+
+	// forecastActionNotify warns the principal once, when their lease is forecast to go over budget
+	forecastActionNotify = "NOTIFY"
+	// forecastActionFreeze freezes leases forecast to go over budget
+	forecastActionFreeze = "FREEZE"
+)
+
+// forecastLeaseSpend forecasts the lease's spend by the time it expires,
+// in the lease's budget currency
+func forecastLeaseSpend(input *lambdaHandlerInput, actualLeaseSpend float64, currentTime time.Time) float64 {
+	expiresOn := time.Unix(input.lease.ExpiresOn, 0)
+	if !currentTime.Before(expiresOn) {
+		return actualLeaseSpend
+	}
+
+	// With consolidated billing, the lambda never assumes the account's role to call Cost Explorer
+	if strings.ToUpper(input.forecastMethod) == forecastMethodCostExplorer && !input.consolidatedBilling {
+		forecast, err := forecastCostExplorerSpend(input, actualLeaseSpend, currentTime, expiresOn)
+		if err == nil {
+			return forecast
+		}
+		// Cost Explorer needs some history before it can forecast an account's spend
+		log.Printf("Failed to forecast spend for lease %s @ %s with Cost Explorer, using a linear forecast instead: %s",
+			input.lease.PrincipalID, input.lease.AccountID, err)
+	}
+
+	leaseStart := time.Unix(input.lease.LeaseStatusModifiedOn, 0)
+	return budget.LinearForecast(actualLeaseSpend, leaseStart, currentTime, expiresOn)
+}
+
+// forecastCostExplorerSpend adds Cost Explorer's forecast, from tomorrow
+// until the lease expires, to the lease's spend so far.
+// Cost Explorer only forecasts whole days in the future.
+func forecastCostExplorerSpend(input *lambdaHandlerInput, actualLeaseSpend float64, currentTime time.Time, expiresOn time.Time) (float64, error) {
+	currentTime = currentTime.UTC()
+	tomorrow := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day()+1, 0, 0, 0, 0, time.UTC)
+	expiresOn = expiresOn.UTC()
+	forecastEnd := time.Date(expiresOn.Year(), expiresOn.Month(), expiresOn.Day()+1, 0, 0, 0, 0, time.UTC)
+	if !forecastEnd.After(tomorrow) {
+		return actualLeaseSpend, nil
+	}
+
+	remainingSpend, err := input.budgetSvc.ForecastSpend(tomorrow, forecastEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	remainingSpend, err = input.currencyConverter.Convert(remainingSpend, input.currencyConverter.BaseCurrency, input.lease.BudgetCurrency)
+	if err != nil {
+		return 0, err
+	}
+
+	return actualLeaseSpend + remainingSpend, nil
+}
+
+// handleSpendForecast records the lease's spend forecast, and warns the principal,
+// or freezes the lease, when it's forecast to go over budget by more than the forecast margin.
+func handleSpendForecast(input *lambdaHandlerInput, actualLeaseSpend float64, currentTime time.Time) error {
+	forecast := math.Round(forecastLeaseSpend(input, actualLeaseSpend, currentTime)*100) / 100
+	log.Printf("Lease %s @ %s is forecast to spend %.2f of its %v budget",
+		input.lease.PrincipalID, input.lease.AccountID, forecast, input.lease.BudgetAmount)
+
+	if input.lease.ForecastSpend == nil || *input.lease.ForecastSpend != forecast {
+		_, err := input.leaseSvc.SetForecast(input.lease.ID, forecast)
+		if err != nil {
+			return err
+		}
+		input.lease.ForecastSpend = &forecast
+	}
+
+	if forecast <= input.lease.BudgetAmount*(1+input.forecastMargin/100) {
+		return nil
+	}
+
+	switch strings.ToUpper(input.forecastAction) {
+	case forecastActionNotify:
+		if input.lease.ForecastWarnedOn != nil {
+			return nil
+		}
+		if len(input.lease.BudgetNotificationEmails)+len(input.budgetNotificationBCCEmails) == 0 {
+			log.Printf("Skipping lease forecast email: "+
+				"no notification emails addressses were provided for lease %s @ %s",
+				input.lease.PrincipalID, input.lease.AccountID)
+		} else {
+			err := sendLeaseForecastEmail(input, forecast)
+			if err != nil {
+				return err
+			}
+		}
+		_, err := input.leaseSvc.WarnForecast(input.lease.ID)
+		return err
+	case forecastActionFreeze:
+		log.Printf("Lease %s @ %s is forecast to go over budget.  Freezing lease until it's reclaimed...",
+			input.lease.PrincipalID, input.lease.AccountID)
+		return handleLeaseFreeze(input, currentTime.Unix()+int64(input.leaseFreezePeriod), &forecast)
+	}
+
+	return nil
+}
+
+// sendLeaseForecastEmail renders the forecast notification templates from S3,
+// and lets the principal know their lease is forecast to go over budget before it expires
+func sendLeaseForecastEmail(input *lambdaHandlerInput, forecastSpend float64) error {
+	log.Printf("Lease %s @ %s is forecast to go over budget.  Warning principal...",
+		input.lease.PrincipalID, input.lease.AccountID)
+
+	templateData := struct {
+		Lease         db.Lease
+		ForecastSpend float64
+		ExpiresOn     string
+	}{
+		Lease:         *input.lease,
+		ForecastSpend: forecastSpend,
+		ExpiresOn:     time.Unix(input.lease.ExpiresOn, 0).UTC().Format(time.RFC1123),
+	}
+
+	bodyHTML, _, err := input.s3Svc.GetTemplateObject(input.budgetNotificationTemplatesBucket, input.forecastNotificationTemplateHTMLKey, templateData)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to render forecast notification template at s3://%s/%s",
+			input.budgetNotificationTemplatesBucket, input.forecastNotificationTemplateHTMLKey)
+	}
+	bodyText, _, err := input.s3Svc.GetTemplateObject(input.budgetNotificationTemplatesBucket, input.forecastNotificationTemplateTextKey, templateData)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to render forecast notification template at s3://%s/%s",
+			input.budgetNotificationTemplatesBucket, input.forecastNotificationTemplateTextKey)
+	}
+	subject, err := renderTemplate("forecastSubject", input.forecastNotificationTemplateSubject, templateData)
+	if err != nil {
+		return err
+	}
+
+	return input.emailSvc.SendEmail(&email.SendEmailInput{
+		FromAddress:  input.budgetNotificationFromEmail,
+		ToAddresses:  input.lease.BudgetNotificationEmails,
+		BCCAddresses: input.budgetNotificationBCCEmails,
+		BodyHTML:     bodyHTML,
+		BodyText:     bodyText,
+		Subject:      subject,
+	})
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	budgetMocks "github.com/Optum/dce/pkg/budget/mocks"
+	commonMocks "github.com/Optum/dce/pkg/common/mocks"
+	"github.com/Optum/dce/pkg/currency"
+	"github.com/Optum/dce/pkg/db"
+	"github.com/Optum/dce/pkg/email"
+	emailMocks "github.com/Optum/dce/pkg/email/mocks"
+	"github.com/Optum/dce/pkg/lease"
+	leaseMocks "github.com/Optum/dce/pkg/lease/leaseiface/mocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestForecastLeaseSpend(t *testing.T) {
+	currentTime := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	tomorrow := time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC)
+	// Lease started 10 days ago, and expires 10 days from now
+	leaseStart := currentTime.AddDate(0, 0, -10)
+	expiresOn := currentTime.AddDate(0, 0, 10)
+
+	tests := []struct {
+		name             string
+		method           string
+		budgetCurrency   string
+		costExplorerCost float64
+		costExplorerErr  error
+		expForecast      float64
+	}{
+		{
+			name:        "should project spend so far across the rest of the lease",
+			method:      "LINEAR",
+			expForecast: 200,
+		},
+		{
+			name:             "should add Cost Explorer's forecast to spend so far",
+			method:           "COST_EXPLORER",
+			costExplorerCost: 50,
+			expForecast:      150,
+		},
+		{
+			name:             "should convert Cost Explorer's forecast to the budget currency",
+			method:           "COST_EXPLORER",
+			budgetCurrency:   "EUR",
+			costExplorerCost: 50,
+			expForecast:      125,
+		},
+		{
+			name:            "should fall back to a linear forecast when Cost Explorer fails",
+			method:          "COST_EXPLORER",
+			costExplorerErr: errors.New("data unavailable"),
+			expForecast:     200,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budgetSvc := &budgetMocks.Service{}
+			budgetSvc.On("ForecastSpend", tomorrow, time.Date(2024, 5, 26, 0, 0, 0, 0, time.UTC)).
+				Return(tt.costExplorerCost, tt.costExplorerErr)
+
+			input := &lambdaHandlerInput{
+				lease: &db.Lease{
+					ExpiresOn:             expiresOn.Unix(),
+					LeaseStatusModifiedOn: leaseStart.Unix(),
+					BudgetCurrency:        tt.budgetCurrency,
+				},
+				budgetSvc: budgetSvc,
+				currencyConverter: &currency.Converter{
+					BaseCurrency: "USD",
+					Provider:     currency.StaticRates{"EUR": 0.5},
+				},
+				forecastMethod: tt.method,
+			}
+
+			forecast := forecastLeaseSpend(input, 100, currentTime)
+			assert.InDelta(t, tt.expForecast, forecast, 0.0001)
+		})
+	}
+}
+
+func TestHandleSpendForecast(t *testing.T) {
+	currentTime := time.Now()
+
+	tests := []struct {
+		name             string
+		action           string
+		margin           float64
+		forecastSpend    *float64
+		forecastWarnedOn *int64
+		shouldSet        bool
+		shouldWarn       bool
+		shouldFreeze     bool
+		emailErr         error
+	}{
+		{
+			name:      "should record the forecast",
+			shouldSet: true,
+		},
+		{
+			name:          "should not record an unchanged forecast",
+			forecastSpend: aws.Float64(200),
+		},
+		{
+			name:       "should warn the principal of a lease forecast to go over budget",
+			action:     "NOTIFY",
+			shouldSet:  true,
+			shouldWarn: true,
+		},
+		{
+			name:      "should not record the warning when it can't be emailed",
+			action:    "NOTIFY",
+			shouldSet: true,
+			emailErr:  errors.New("email failed"),
+		},
+		{
+			name:             "should only warn the principal once",
+			action:           "NOTIFY",
+			forecastWarnedOn: aws.Int64(currentTime.AddDate(0, 0, -1).Unix()),
+			shouldSet:        true,
+		},
+		{
+			name:      "should not act on forecasts within the margin",
+			action:    "NOTIFY",
+			margin:    100,
+			shouldSet: true,
+		},
+		{
+			name:         "should freeze a lease forecast to go over budget",
+			action:       "FREEZE",
+			shouldSet:    true,
+			shouldFreeze: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaseSvc := &leaseMocks.Servicer{}
+			emailSvc := &emailMocks.Service{}
+			s3Svc := &commonMocks.Storager{}
+			input := &lambdaHandlerInput{
+				lease: &db.Lease{
+					ID:                       "abc123",
+					AccountID:                "1234567890",
+					PrincipalID:              "test-user",
+					LeaseStatus:              db.Active,
+					BudgetAmount:             150,
+					BudgetNotificationEmails: []string{"recipA@example.com"},
+					LeaseStatusModifiedOn:    currentTime.AddDate(0, 0, -10).Unix(),
+					ExpiresOn:                currentTime.AddDate(0, 0, 10).Unix(),
+					ForecastSpend:            tt.forecastSpend,
+					ForecastWarnedOn:         tt.forecastWarnedOn,
+				},
+				leaseSvc:                            leaseSvc,
+				emailSvc:                            emailSvc,
+				s3Svc:                               s3Svc,
+				budgetNotificationFromEmail:         "from@example.com",
+				leaseFreezePeriod:                   259200,
+				forecastNotificationTemplateHTMLKey: "forecast.html",
+				forecastNotificationTemplateTextKey: "forecast.txt",
+				forecastNotificationTemplateSubject: "Lease forecast to go over budget [{{.Lease.AccountID}}]",
+				leaseFrozenTemplateHTMLKey:          "frozen.html",
+				leaseFrozenTemplateTextKey:          "frozen.txt",
+				leaseFrozenTemplateSubject:          `Lease frozen until {{.FrozenUntil}}{{if .IsForecast}}, forecast to spend ${{printf "%.2f" .ForecastSpend}}{{end}}`,
+				forecastMethod:                      "LINEAR",
+				forecastMargin:                      tt.margin,
+				forecastAction:                      tt.action,
+			}
+
+			leaseSvc.On("SetForecast", "abc123", float64(200)).Return(&lease.Lease{}, nil)
+			leaseSvc.On("WarnForecast", "abc123").Return(&lease.Lease{}, nil)
+			leaseSvc.On("Freeze", "abc123", currentTime.Unix()+259200).Return(&lease.Lease{}, nil)
+			s3Svc.On("GetTemplateObject", "", "forecast.html", mock.Anything).Return("<p>forecast</p>", "", nil)
+			s3Svc.On("GetTemplateObject", "", "forecast.txt", mock.Anything).Return("forecast", "", nil)
+			emailSvc.On("SendEmail", mock.MatchedBy(func(input *email.SendEmailInput) bool {
+				return input.Subject == "Lease forecast to go over budget [1234567890]" && input.BodyText == "forecast"
+			})).Return(tt.emailErr)
+			s3Svc.On("GetTemplateObject", "", "frozen.html", mock.Anything).Return("<p>frozen</p>", "", nil)
+			s3Svc.On("GetTemplateObject", "", "frozen.txt", mock.Anything).Return("frozen", "", nil)
+			emailSvc.On("SendEmail", mock.MatchedBy(func(input *email.SendEmailInput) bool {
+				return strings.HasPrefix(input.Subject, "Lease frozen until") &&
+					strings.HasSuffix(input.Subject, "forecast to spend $200.00")
+			})).Return(nil)
+
+			err := handleSpendForecast(input, 100, currentTime)
+			assert.Equal(t, tt.emailErr, err)
+
+			if tt.shouldSet {
+				leaseSvc.AssertCalled(t, "SetForecast", "abc123", float64(200))
+			} else {
+				leaseSvc.AssertNotCalled(t, "SetForecast", mock.Anything, mock.Anything)
+			}
+			if tt.shouldWarn {
+				leaseSvc.AssertCalled(t, "WarnForecast", "abc123")
+			} else {
+				leaseSvc.AssertNotCalled(t, "WarnForecast", "abc123")
+			}
+			if tt.shouldFreeze {
+				leaseSvc.AssertCalled(t, "Freeze", "abc123", currentTime.Unix()+259200)
+				assert.Equal(t, db.Frozen, input.lease.LeaseStatus)
+			} else {
+				leaseSvc.AssertNotCalled(t, "Freeze", mock.Anything, mock.Anything)
+			}
+			if tt.shouldWarn || tt.shouldFreeze || tt.emailErr != nil {
+				emailSvc.AssertNumberOfCalls(t, "SendEmail", 1)
+			} else {
+				emailSvc.AssertNotCalled(t, "SendEmail", mock.Anything)
+			}
+		})
+	}
+}
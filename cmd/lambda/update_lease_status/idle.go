@@ -0,0 +1,121 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/Optum/dce/pkg/db"
+	"github.com/Optum/dce/pkg/email"
+	"github.com/pkg/errors"
+)
+
+// isLeaseIdle returns true if the lease spent less than the idle spend floor
+// on each of the last idleDays days (not counting today).
+// Days without a usage record don't count as idle, so leases are only ended
+// when we know they weren't used.
+func isLeaseIdle(input *lambdaHandlerInput, currentTime time.Time) (bool, error) {
+	today := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), 0, 0, 0, 0, time.UTC)
+	idleStartTime := today.AddDate(0, 0, -input.leaseIdleDays)
+
+	// The lease has to be active for the whole period, to be idle for it
+	if input.lease.LeaseStatusModifiedOn > idleStartTime.Unix() {
+		return false, nil
+	}
+
+	usageRecords, err := input.usageSvc.GetUsageByLease(input.lease.ID, idleStartTime, today.AddDate(0, 0, -1))
+	if err != nil {
+		return false, errors.Wrapf(err, "Failed to retrieve usage for account %s", input.lease.AccountID)
+	}
+
+	idleDays := map[int64]bool{}
+	for _, usage := range usageRecords {
+		if *usage.CostAmount >= input.leaseIdleSpendFloor {
+			return false, nil
+		}
+		idleDays[*usage.StartDate] = true
+	}
+
+	return len(idleDays) >= input.leaseIdleDays, nil
+}
+
+// handleIdleLease warns the principal of a lease that's idle, and ends the lease
+// if it's still idle once the warning period is over.
+// The idle warning is cleared as soon as the lease is used again.
+func handleIdleLease(input *lambdaHandlerInput, prevLeaseStatus db.LeaseStatus, currentTime time.Time) error {
+	idle, err := isLeaseIdle(input, currentTime)
+	if err != nil {
+		return err
+	}
+
+	if !idle {
+		if input.lease.IdleWarnedOn != nil {
+			log.Printf("Lease %s @ %s is in use again, clearing idle warning", input.lease.PrincipalID, input.lease.AccountID)
+			_, err = input.leaseSvc.ClearIdleWarning(input.lease.ID)
+			return err
+		}
+		return nil
+	}
+
+	if input.lease.IdleWarnedOn == nil {
+		log.Printf("Lease %s @ %s has been idle for %d days.  Warning principal...",
+			input.lease.PrincipalID, input.lease.AccountID, input.leaseIdleDays)
+		_, err = input.leaseSvc.WarnIdle(input.lease.ID)
+		if err != nil {
+			return err
+		}
+		return sendLeaseIdleEmail(input, currentTime.Unix()+int64(input.leaseIdleWarningPeriod))
+	}
+
+	if currentTime.Unix() < *input.lease.IdleWarnedOn+int64(input.leaseIdleWarningPeriod) {
+		return nil
+	}
+
+	log.Printf("%s.  Updating lease as ready to be reclaimed...", db.LeaseIdle)
+	input.lease.LeaseStatus = db.Inactive
+	return handleLeaseExpire(input, prevLeaseStatus, db.LeaseIdle)
+}
+
+// sendLeaseIdleEmail lets the principal know their lease is idle,
+// and when it will be ended unless they use it
+func sendLeaseIdleEmail(input *lambdaHandlerInput, endsOn int64) error {
+	if len(input.lease.BudgetNotificationEmails)+len(input.budgetNotificationBCCEmails) == 0 {
+		log.Printf("Skipping lease idle email: "+
+			"no notification emails addressses were provided for lease %s @ %s",
+			input.lease.PrincipalID, input.lease.AccountID)
+		return nil
+	}
+
+	templateData := struct {
+		Lease    db.Lease
+		IdleDays int
+		EndsOn   string
+	}{
+		Lease:    *input.lease,
+		IdleDays: input.leaseIdleDays,
+		EndsOn:   time.Unix(endsOn, 0).UTC().Format(time.RFC1123),
+	}
+
+	bodyHTML, _, err := input.s3Svc.GetTemplateObject(input.budgetNotificationTemplatesBucket, input.leaseIdleTemplateHTMLKey, templateData)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to render lease idle template at s3://%s/%s",
+			input.budgetNotificationTemplatesBucket, input.leaseIdleTemplateHTMLKey)
+	}
+	bodyText, _, err := input.s3Svc.GetTemplateObject(input.budgetNotificationTemplatesBucket, input.leaseIdleTemplateTextKey, templateData)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to render lease idle template at s3://%s/%s",
+			input.budgetNotificationTemplatesBucket, input.leaseIdleTemplateTextKey)
+	}
+	subject, err := renderTemplate("idleSubject", input.leaseIdleTemplateSubject, templateData)
+	if err != nil {
+		return err
+	}
+
+	return input.emailSvc.SendEmail(&email.SendEmailInput{
+		FromAddress:  input.budgetNotificationFromEmail,
+		ToAddresses:  input.lease.BudgetNotificationEmails,
+		BCCAddresses: input.budgetNotificationBCCEmails,
+		BodyHTML:     bodyHTML,
+		BodyText:     bodyText,
+		Subject:      subject,
+	})
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	commonMocks "github.com/Optum/dce/pkg/common/mocks"
+	"github.com/Optum/dce/pkg/db"
+	dbMocks "github.com/Optum/dce/pkg/db/mocks"
+	"github.com/Optum/dce/pkg/email"
+	emailMocks "github.com/Optum/dce/pkg/email/mocks"
+	"github.com/Optum/dce/pkg/lease"
+	leaseMocks "github.com/Optum/dce/pkg/lease/leaseiface/mocks"
+	"github.com/Optum/dce/pkg/usage"
+	usageMocks "github.com/Optum/dce/pkg/usage/mocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandleIdleLease(t *testing.T) {
+	currentTime := time.Now()
+	today := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), 0, 0, 0, 0, time.UTC)
+
+	// usageFor returns a usage record per day, for the days before today
+	usageFor := func(accountID string, costs ...float64) []*usage.Usage {
+		records := []*usage.Usage{}
+		for i, cost := range costs {
+			records = append(records, &usage.Usage{
+				PrincipalID: aws.String("test-user"),
+				AccountID:   aws.String(accountID),
+				StartDate:   aws.Int64(today.AddDate(0, 0, -(i + 1)).Unix()),
+				CostAmount:  aws.Float64(cost),
+			})
+		}
+		return records
+	}
+
+	tests := []struct {
+		name                  string
+		leaseStatusModifiedOn int64
+		idleWarnedOn          *int64
+		usageRecords          []*usage.Usage
+		shouldWarn            bool
+		shouldClearWarning    bool
+		shouldEnd             bool
+	}{
+		{
+			name:                  "should warn the principal of an idle lease",
+			leaseStatusModifiedOn: today.AddDate(0, 0, -10).Unix(),
+			usageRecords:          usageFor("1234567890", 0.10, 0, 0.25),
+			shouldWarn:            true,
+		},
+		{
+			name:                  "should end an idle lease after the warning period",
+			leaseStatusModifiedOn: today.AddDate(0, 0, -10).Unix(),
+			idleWarnedOn:          aws.Int64(currentTime.AddDate(0, 0, -3).Unix()),
+			usageRecords:          usageFor("1234567890", 0.10, 0, 0.25),
+			shouldEnd:             true,
+		},
+		{
+			name:                  "should wait for the warning period to end",
+			leaseStatusModifiedOn: today.AddDate(0, 0, -10).Unix(),
+			idleWarnedOn:          aws.Int64(currentTime.Add(-time.Hour).Unix()),
+			usageRecords:          usageFor("1234567890", 0.10, 0, 0.25),
+		},
+		{
+			name:                  "should clear the warning of a lease in use again",
+			leaseStatusModifiedOn: today.AddDate(0, 0, -10).Unix(),
+			idleWarnedOn:          aws.Int64(currentTime.AddDate(0, 0, -1).Unix()),
+			usageRecords:          usageFor("1234567890", 5.00, 0, 0.25),
+			shouldClearWarning:    true,
+		},
+		{
+			name:                  "should not count days without usage records as idle",
+			leaseStatusModifiedOn: today.AddDate(0, 0, -10).Unix(),
+			usageRecords:          usageFor("1234567890", 0.10, 0),
+		},
+		{
+			name:                  "should not warn a lease without usage records",
+			leaseStatusModifiedOn: today.AddDate(0, 0, -10).Unix(),
+			usageRecords:          usageFor("1234567890"),
+		},
+		{
+			name:                  "should not check leases newer than the idle period",
+			leaseStatusModifiedOn: today.AddDate(0, 0, -1).Unix(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbSvc := &dbMocks.DBer{}
+			usageSvc := &usageMocks.DBer{}
+			leaseSvc := &leaseMocks.Servicer{}
+			emailSvc := &emailMocks.Service{}
+			s3Svc := &commonMocks.Storager{}
+			input := &lambdaHandlerInput{
+				dbSvc: dbSvc,
+				lease: &db.Lease{
+					ID:                       "abc123",
+					AccountID:                "1234567890",
+					PrincipalID:              "test-user",
+					LeaseStatus:              db.Active,
+					BudgetNotificationEmails: []string{"recipA@example.com"},
+					LeaseStatusModifiedOn:    tt.leaseStatusModifiedOn,
+					IdleWarnedOn:             tt.idleWarnedOn,
+				},
+				usageSvc:                    usageSvc,
+				leaseSvc:                    leaseSvc,
+				emailSvc:                    emailSvc,
+				s3Svc:                       s3Svc,
+				budgetNotificationFromEmail: "from@example.com",
+				leaseIdleDays:               3,
+				leaseIdleSpendFloor:         1,
+				leaseIdleWarningPeriod:      172800,
+				leaseIdleTemplateHTMLKey:    "idle.html",
+				leaseIdleTemplateTextKey:    "idle.txt",
+				leaseIdleTemplateSubject:    "Lease idle, ending on {{.EndsOn}} [{{.Lease.AccountID}}]",
+			}
+
+			usageSvc.On("GetUsageByLease", "abc123", today.AddDate(0, 0, -3), today.AddDate(0, 0, -1)).Return(tt.usageRecords, nil)
+			leaseSvc.On("WarnIdle", "abc123").Return(&lease.Lease{}, nil)
+			leaseSvc.On("ClearIdleWarning", "abc123").Return(&lease.Lease{}, nil)
+			s3Svc.On("GetTemplateObject", "", "idle.html", mock.Anything).Return("<p>idle</p>", "", nil)
+			s3Svc.On("GetTemplateObject", "", "idle.txt", mock.Anything).Return("idle", "", nil)
+			emailSvc.On("SendEmail", mock.MatchedBy(func(input *email.SendEmailInput) bool {
+				return strings.HasPrefix(input.Subject, "Lease idle, ending on") &&
+					strings.HasSuffix(input.Subject, "[1234567890]") && input.BodyText == "idle"
+			})).Return(nil)
+			dbSvc.On("TransitionLeaseStatus", "1234567890", "test-user", db.Active, db.Inactive, db.LeaseIdle).Return(input.lease, nil)
+			dbSvc.On("TransitionAccountStatus", "1234567890", db.Leased, db.NotReady).Return(nil, nil)
+
+			err := handleIdleLease(input, db.Active, currentTime)
+			assert.Nil(t, err)
+
+			if tt.shouldWarn {
+				leaseSvc.AssertCalled(t, "WarnIdle", "abc123")
+				emailSvc.AssertNumberOfCalls(t, "SendEmail", 1)
+			} else {
+				leaseSvc.AssertNotCalled(t, "WarnIdle", "abc123")
+				emailSvc.AssertNotCalled(t, "SendEmail", mock.Anything)
+			}
+			if tt.shouldClearWarning {
+				leaseSvc.AssertCalled(t, "ClearIdleWarning", "abc123")
+			} else {
+				leaseSvc.AssertNotCalled(t, "ClearIdleWarning", "abc123")
+			}
+			if tt.shouldEnd {
+				dbSvc.AssertCalled(t, "TransitionLeaseStatus", "1234567890", "test-user", db.Active, db.Inactive, db.LeaseIdle)
+				assert.Equal(t, db.Inactive, input.lease.LeaseStatus)
+			} else {
+				dbSvc.AssertNotCalled(t, "TransitionLeaseStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
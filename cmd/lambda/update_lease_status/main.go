@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -12,9 +13,12 @@ import (
 	"github.com/Optum/dce/pkg/awsiface"
 	"github.com/Optum/dce/pkg/budget"
 	"github.com/Optum/dce/pkg/common"
+	"github.com/Optum/dce/pkg/config"
+	"github.com/Optum/dce/pkg/currency"
 	"github.com/Optum/dce/pkg/db"
 	"github.com/Optum/dce/pkg/email"
 	multierrors "github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease/leaseiface"
 	"github.com/Optum/dce/pkg/usage"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -57,18 +61,49 @@ func main() {
 			log.Fatalf("Failed to configure Usage service %s", err)
 		}
 
+		principalBudgetPeriod, err := budget.NewPeriodFromEnv()
+		if err != nil {
+			log.Fatalf("Failed to configure principal budget period %s", err)
+		}
+
+		// Configure the Lease service, for freezing over budget leases
+		cfgBldr := &config.ConfigurationBuilder{}
+		err = cfgBldr.WithEnv("AWS_CURRENT_REGION", "AWS_CURRENT_REGION", "us-east-1").Build()
+		if err != nil {
+			log.Fatalf("Failed to configure services %s", err)
+		}
+		svcBldr := &config.ServiceBuilder{Config: cfgBldr}
+		_, err = svcBldr.WithLeaseService().Build()
+		if err != nil {
+			log.Fatalf("Failed to configure Lease service %s", err)
+		}
+
 		// Configure the S3 service
 		s3Svc := &common.S3{
 			Client:  s3.New(awsSession),
 			Manager: s3manager.NewDownloader(awsSession),
 		}
 
+		// Configure the Budget service, with the spend source, cost metric and record types to leave out
+		budgetSvc, err := budget.NewServiceFromEnv(s3Svc)
+		if err != nil {
+			log.Fatalf("Failed to configure Budget service %s", err)
+		}
+		// The Cost and Usage Report covers every linked account, so collect_usage reads it into the Usage cache
+		consolidatedBilling := common.GetEnv("CONSOLIDATED_BILLING", "false") == "true" ||
+			strings.EqualFold(common.GetEnv("SPEND_SOURCE", budget.SpendSourceCostExplorer), budget.SpendSourceCUR)
+
+		currencyConverter, err := currency.NewConverterFromEnv(s3Svc)
+		if err != nil {
+			log.Fatalf("Failed to configure currency converter %s", err)
+		}
+
 		err = lambdaHandler(&lambdaHandlerInput{
 			dbSvc:                                  dbSvc,
 			lease:                                  lease,
 			awsSession:                             awsSession,
 			tokenSvc:                               tokenSvc,
-			budgetSvc:                              &budget.AWSBudgetService{},
+			budgetSvc:                              budgetSvc,
 			usageSvc:                               usageSvc,
 			sqsSvc:                                 sqs.New(awsSession),
 			snsSvc:                                 &common.SNS{Client: sns.New(awsSession)},
@@ -82,9 +117,36 @@ func main() {
 			budgetNotificationTemplateTextKey:      common.RequireEnv("BUDGET_NOTIFICATION_TEMPLATE_TEXT_KEY"),
 			budgetNotificationTemplateSubject:      common.RequireEnv("BUDGET_NOTIFICATION_TEMPLATE_SUBJECT"),
 			budgetNotificationThresholdPercentiles: common.RequireEnvFloatSlice("BUDGET_NOTIFICATION_THRESHOLD_PERCENTILES", ","),
-			principalBudgetAmount:                  common.RequireEnvFloat("PRINCIPAL_BUDGET_AMOUNT"),
-			principalBudgetPeriod:                  common.RequireEnv("PRINCIPAL_BUDGET_PERIOD"),
-			usageTTL:                               common.RequireEnvInt("USAGE_TTL"),
+			principalBudgetNotificationTemplateHTMLKey:      common.RequireEnv("PRINCIPAL_BUDGET_NOTIFICATION_TEMPLATE_HTML_KEY"),
+			principalBudgetNotificationTemplateTextKey:      common.RequireEnv("PRINCIPAL_BUDGET_NOTIFICATION_TEMPLATE_TEXT_KEY"),
+			principalBudgetNotificationTemplateSubject:      common.RequireEnv("PRINCIPAL_BUDGET_NOTIFICATION_TEMPLATE_SUBJECT"),
+			principalBudgetNotificationThresholdPercentiles: common.RequireEnvFloatSlice("PRINCIPAL_BUDGET_NOTIFICATION_THRESHOLD_PERCENTILES", ","),
+			principalBudgetAmount:                           common.RequireEnvFloat("PRINCIPAL_BUDGET_AMOUNT"),
+			principalBudgetPeriod:                           principalBudgetPeriod,
+			usageTTL:                                        common.RequireEnvInt("USAGE_TTL"),
+			currencyConverter:                               currencyConverter,
+			leaseSvc:                                        svcBldr.LeaseService(),
+			leaseFreezePeriod:                               common.GetEnvInt("LEASE_FREEZE_PERIOD", 0),
+			leaseFrozenTemplateHTMLKey:                      common.RequireEnv("LEASE_FROZEN_TEMPLATE_HTML_KEY"),
+			leaseFrozenTemplateTextKey:                      common.RequireEnv("LEASE_FROZEN_TEMPLATE_TEXT_KEY"),
+			leaseFrozenTemplateSubject:                      common.RequireEnv("LEASE_FROZEN_TEMPLATE_SUBJECT"),
+			leaseIdleDays:                                   common.GetEnvInt("LEASE_IDLE_DAYS", 0),
+			leaseIdleSpendFloor:                             common.GetEnvFloat("LEASE_IDLE_SPEND_FLOOR", 1),
+			leaseIdleWarningPeriod:                          common.GetEnvInt("LEASE_IDLE_WARNING_PERIOD", 172800),
+			leaseIdleTemplateHTMLKey:                        common.RequireEnv("LEASE_IDLE_TEMPLATE_HTML_KEY"),
+			leaseIdleTemplateTextKey:                        common.RequireEnv("LEASE_IDLE_TEMPLATE_TEXT_KEY"),
+			leaseIdleTemplateSubject:                        common.RequireEnv("LEASE_IDLE_TEMPLATE_SUBJECT"),
+			forecastMethod:                                  common.GetEnv("FORECAST_METHOD", ""),
+			forecastMargin:                                  common.GetEnvFloat("FORECAST_MARGIN", 0),
+			forecastAction:                                  common.GetEnv("FORECAST_ACTION", ""),
+			forecastNotificationTemplateHTMLKey:             common.RequireEnv("FORECAST_NOTIFICATION_TEMPLATE_HTML_KEY"),
+			forecastNotificationTemplateTextKey:             common.RequireEnv("FORECAST_NOTIFICATION_TEMPLATE_TEXT_KEY"),
+			forecastNotificationTemplateSubject:             common.RequireEnv("FORECAST_NOTIFICATION_TEMPLATE_SUBJECT"),
+			consolidatedBilling:                             consolidatedBilling,
+			expiryNotificationThresholds:                    common.RequireEnvFloatSlice("EXPIRY_NOTIFICATION_THRESHOLD_HOURS", ","),
+			expiryNotificationTemplateHTMLKey:               common.RequireEnv("EXPIRY_NOTIFICATION_TEMPLATE_HTML_KEY"),
+			expiryNotificationTemplateTextKey:               common.RequireEnv("EXPIRY_NOTIFICATION_TEMPLATE_TEXT_KEY"),
+			expiryNotificationTemplateSubject:               common.RequireEnv("EXPIRY_NOTIFICATION_TEMPLATE_SUBJECT"),
 		})
 		if err != nil {
 			log.Fatalf("Failed check budget: %s", err)
@@ -111,27 +173,54 @@ func eventToLease(leaseEvent interface{}) (*db.Lease, error) {
 }
 
 type lambdaHandlerInput struct {
-	dbSvc                                  db.DBer
-	lease                                  *db.Lease
-	awsSession                             awsiface.AwsSession
-	tokenSvc                               common.TokenService
-	budgetSvc                              budget.Service
-	usageSvc                               usage.DBer
-	snsSvc                                 common.Notificationer
-	leaseLockedTopicArn                    string
-	sqsSvc                                 awsiface.SQSAPI
-	emailSvc                               email.Service
-	s3Svc                                  common.Storager
-	budgetNotificationFromEmail            string
-	budgetNotificationBCCEmails            []string
-	budgetNotificationTemplatesBucket      string
-	budgetNotificationTemplateHTMLKey      string
-	budgetNotificationTemplateTextKey      string
-	budgetNotificationTemplateSubject      string
-	budgetNotificationThresholdPercentiles []float64
-	principalBudgetAmount                  float64
-	principalBudgetPeriod                  string
-	usageTTL                               int // TTL in seconds for Usage DynamoDB records
+	dbSvc                                           db.DBer
+	lease                                           *db.Lease
+	awsSession                                      awsiface.AwsSession
+	tokenSvc                                        common.TokenService
+	budgetSvc                                       budget.Service
+	usageSvc                                        usage.DBer
+	snsSvc                                          common.Notificationer
+	leaseLockedTopicArn                             string
+	sqsSvc                                          awsiface.SQSAPI
+	emailSvc                                        email.Service
+	s3Svc                                           common.Storager
+	budgetNotificationFromEmail                     string
+	budgetNotificationBCCEmails                     []string
+	budgetNotificationTemplatesBucket               string
+	budgetNotificationTemplateHTMLKey               string
+	budgetNotificationTemplateTextKey               string
+	budgetNotificationTemplateSubject               string
+	budgetNotificationThresholdPercentiles          []float64
+	principalBudgetNotificationTemplateHTMLKey      string // Key of the principal budget notification HTML template, in the budget notification templates bucket
+	principalBudgetNotificationTemplateTextKey      string // Key of the principal budget notification text template, in the budget notification templates bucket
+	principalBudgetNotificationTemplateSubject      string
+	principalBudgetNotificationThresholdPercentiles []float64
+	principalBudgetAmount                           float64
+	principalBudgetPeriod                           *budget.Period
+	usageTTL                                        int // TTL in seconds for Usage DynamoDB records
+	currencyConverter                               *currency.Converter
+	leaseSvc                                        leaseiface.Servicer
+	leaseFreezePeriod                               int    // Seconds an over budget lease stays frozen before it's reclaimed. 0 reclaims it right away.
+	leaseFrozenTemplateHTMLKey                      string // Key of the lease frozen HTML template, in the budget notification templates bucket
+	leaseFrozenTemplateTextKey                      string // Key of the lease frozen text template, in the budget notification templates bucket
+	leaseFrozenTemplateSubject                      string
+	leaseIdleDays                                   int     // Days a lease may spend less than leaseIdleSpendFloor before it's idle. 0 disables idle detection.
+	leaseIdleSpendFloor                             float64 // Daily spend below which a lease is idle
+	leaseIdleWarningPeriod                          int     // Seconds between warning the principal an idle lease and ending it
+	leaseIdleTemplateHTMLKey                        string  // Key of the lease idle HTML template, in the budget notification templates bucket
+	leaseIdleTemplateTextKey                        string  // Key of the lease idle text template, in the budget notification templates bucket
+	leaseIdleTemplateSubject                        string
+	expiryNotificationThresholds                    []float64 // Hours before a lease expires to notify the principal. Empty disables expiry notifications.
+	expiryNotificationTemplateHTMLKey               string    // Key of the expiry notification HTML template, in the budget notification templates bucket
+	expiryNotificationTemplateTextKey               string    // Key of the expiry notification text template, in the budget notification templates bucket
+	expiryNotificationTemplateSubject               string
+	forecastMethod                                  string  // LINEAR or COST_EXPLORER. Empty disables spend forecasts.
+	forecastMargin                                  float64 // Percent the forecast may go over budget before forecastAction is taken
+	forecastAction                                  string  // NOTIFY or FREEZE leases forecast to go over budget. Empty only records the forecast.
+	forecastNotificationTemplateHTMLKey             string  // Key of the forecast notification HTML template, in the budget notification templates bucket
+	forecastNotificationTemplateTextKey             string  // Key of the forecast notification text template, in the budget notification templates bucket
+	forecastNotificationTemplateSubject             string
+	consolidatedBilling                             bool // Usage is collected from the master account by collect_usage, so only read it from the Usage cache
 }
 
 func lambdaHandler(input *lambdaHandlerInput) error {
@@ -150,7 +239,7 @@ func lambdaHandler(input *lambdaHandlerInput) error {
 	}
 
 	// Calculate actual spend for the lease
-	actualLeaseSpend, err := calculateLeaseSpend(&calculateSpendInput{
+	actualLeaseSpend, actualLeaseServiceCosts, err := calculateLeaseSpend(&calculateSpendInput{
 		account:               account,
 		lease:                 input.lease,
 		tokenSvc:              input.tokenSvc,
@@ -159,6 +248,8 @@ func lambdaHandler(input *lambdaHandlerInput) error {
 		awsSession:            input.awsSession,
 		principalBudgetPeriod: input.principalBudgetPeriod,
 		usageTTL:              input.usageTTL,
+		currencyConverter:     input.currencyConverter,
+		consolidatedBilling:   input.consolidatedBilling,
 	})
 	if err != nil {
 		return errors.Wrapf(err, "Failed to calculate spend for lease %s", leaseLogID)
@@ -173,6 +264,7 @@ func lambdaHandler(input *lambdaHandlerInput) error {
 		usageSvc:              input.usageSvc,
 		awsSession:            input.awsSession,
 		principalBudgetPeriod: input.principalBudgetPeriod,
+		currencyConverter:     input.currencyConverter,
 	})
 	if err != nil {
 		return errors.Wrapf(err, "Failed to calculate spend for principal %s", leaseLogID)
@@ -184,7 +276,14 @@ func lambdaHandler(input *lambdaHandlerInput) error {
 
 	expired, reason := isLeaseExpired(input.lease, &leaseContext{currentTimeEpoch, actualLeaseSpend}, actualPrincipalSpend, input.principalBudgetAmount)
 
-	if expired {
+	if expired && reason == db.LeaseOverBudget && input.leaseFreezePeriod > 0 {
+		// Give the principal a chance to export their data before the account is reset
+		log.Printf("%s.  Freezing lease until it's reclaimed...", reason)
+		err := handleLeaseFreeze(input, currentTimeEpoch+int64(input.leaseFreezePeriod), nil)
+		if err != nil {
+			deferredErrors = append(deferredErrors, err)
+		}
+	} else if expired {
 		// Update the lease status with the inactive status and current end time.
 		input.lease.LeaseStatus = db.Inactive
 		log.Printf("%s.  Updating lease as ready to be reclaimed...", reason)
@@ -192,6 +291,31 @@ func lambdaHandler(input *lambdaHandlerInput) error {
 		if err != nil {
 			deferredErrors = append(deferredErrors, err)
 		}
+	} else if input.leaseIdleDays > 0 {
+		// Reclaim accounts sitting leased with no activity
+		err := handleIdleLease(input, prevLeaseStatus, time.Unix(currentTimeEpoch, 0))
+		if err != nil {
+			log.Printf("Failed to check if lease %s @ %s is idle: %s", input.lease.PrincipalID, input.lease.AccountID, err)
+			deferredErrors = append(deferredErrors, err)
+		}
+	}
+
+	// Act on leases forecast to go over budget, before they do
+	if input.lease.LeaseStatus == db.Active && input.forecastMethod != "" {
+		err := handleSpendForecast(input, actualLeaseSpend, time.Unix(currentTimeEpoch, 0))
+		if err != nil {
+			log.Printf("Failed to forecast spend for lease %s @ %s: %s", input.lease.PrincipalID, input.lease.AccountID, err)
+			deferredErrors = append(deferredErrors, err)
+		}
+	}
+
+	// Let the principal know ahead of time that their lease is expiring
+	if input.lease.LeaseStatus == db.Active && len(input.expiryNotificationThresholds) > 0 {
+		err := handleExpiryNotification(input, time.Unix(currentTimeEpoch, 0))
+		if err != nil {
+			log.Printf("Failed to send expiry notification for lease %s @ %s: %s", input.lease.PrincipalID, input.lease.AccountID, err)
+			deferredErrors = append(deferredErrors, err)
+		}
 	}
 
 	// Send notification emails, for budget thresholds
@@ -199,6 +323,7 @@ func lambdaHandler(input *lambdaHandlerInput) error {
 		lease:                                  input.lease,
 		emailSvc:                               input.emailSvc,
 		s3Svc:                                  input.s3Svc,
+		leaseSvc:                               input.leaseSvc,
 		budgetNotificationFromEmail:            input.budgetNotificationFromEmail,
 		budgetNotificationBCCEmails:            input.budgetNotificationBCCEmails,
 		budgetNotificationTemplatesBucket:      input.budgetNotificationTemplatesBucket,
@@ -207,7 +332,7 @@ func lambdaHandler(input *lambdaHandlerInput) error {
 		budgetNotificationTemplateSubject:      input.budgetNotificationTemplateSubject,
 		budgetNotificationThresholdPercentiles: input.budgetNotificationThresholdPercentiles,
 		actualLeaseSpend:                       actualLeaseSpend,
-		actualPrincipalSpend:                   actualPrincipalSpend,
+		actualLeaseServiceCosts:                actualLeaseServiceCosts,
 	})
 	if err != nil {
 		log.Printf("Failed to send budget notification emails for lease %s @ %s: %s",
@@ -215,6 +340,29 @@ func lambdaHandler(input *lambdaHandlerInput) error {
 		deferredErrors = append(deferredErrors, err)
 	}
 
+	// Send notification emails, for principal budget thresholds
+	err = sendPrincipalBudgetNotificationEmail(&sendPrincipalBudgetNotificationEmailInput{
+		lease:                             input.lease,
+		emailSvc:                          input.emailSvc,
+		s3Svc:                             input.s3Svc,
+		usageSvc:                          input.usageSvc,
+		budgetNotificationFromEmail:       input.budgetNotificationFromEmail,
+		budgetNotificationBCCEmails:       input.budgetNotificationBCCEmails,
+		budgetNotificationTemplatesBucket: input.budgetNotificationTemplatesBucket,
+		principalBudgetNotificationTemplateHTMLKey:      input.principalBudgetNotificationTemplateHTMLKey,
+		principalBudgetNotificationTemplateTextKey:      input.principalBudgetNotificationTemplateTextKey,
+		principalBudgetNotificationTemplateSubject:      input.principalBudgetNotificationTemplateSubject,
+		principalBudgetNotificationThresholdPercentiles: input.principalBudgetNotificationThresholdPercentiles,
+		principalBudgetAmount:                           input.principalBudgetAmount,
+		principalBudgetPeriod:                           input.principalBudgetPeriod,
+		actualPrincipalSpend:                            actualPrincipalSpend,
+	})
+	if err != nil {
+		log.Printf("Failed to send principal budget notification emails for lease %s @ %s: %s",
+			input.lease.PrincipalID, input.lease.AccountID, err)
+		deferredErrors = append(deferredErrors, err)
+	}
+
 	// Return deferred errors
 	if len(deferredErrors) > 0 {
 		return multierrors.NewMultiError("Budget check failed: ", deferredErrors)
@@ -282,3 +430,26 @@ func handleLeaseExpire(input *lambdaHandlerInput, prevLeaseStatus db.LeaseStatus
 
 	return nil
 }
+
+// handleLeaseFreeze handles the case where a lease is over budget, or forecast to go over budget,
+// and leases are frozen before they're reclaimed:
+// - Limits the principal to reading and exporting data from the account
+// - Sets Lease DB status to Frozen, until frozenUntil
+// - Lets the principal know how long they have to export their data
+// The account is reset by the fan_out_update_lease_status lambda, once the lease is past frozenUntil.
+func handleLeaseFreeze(input *lambdaHandlerInput, frozenUntil int64, forecastSpend *float64) error {
+	_, err := input.leaseSvc.Freeze(input.lease.ID, frozenUntil)
+	if err != nil {
+		log.Printf("Failed to freeze lease %s @ %s: %s", input.lease.PrincipalID, input.lease.AccountID, err)
+		return err
+	}
+	input.lease.LeaseStatus = db.Frozen
+
+	err = sendLeaseFrozenEmail(input, frozenUntil, forecastSpend)
+	if err != nil {
+		log.Printf("Failed to send lease frozen email for lease %s @ %s: %s", input.lease.PrincipalID, input.lease.AccountID, err)
+		return err
+	}
+
+	return nil
+}
@@ -7,12 +7,16 @@ import (
 	"time"
 
 	awsMocks "github.com/Optum/dce/pkg/awsiface/mocks"
+	"github.com/Optum/dce/pkg/budget"
 	budgetMocks "github.com/Optum/dce/pkg/budget/mocks"
 	commonMocks "github.com/Optum/dce/pkg/common/mocks"
+	"github.com/Optum/dce/pkg/currency"
 	"github.com/Optum/dce/pkg/db"
 	dbMocks "github.com/Optum/dce/pkg/db/mocks"
 	"github.com/Optum/dce/pkg/email"
 	emailMocks "github.com/Optum/dce/pkg/email/mocks"
+	"github.com/Optum/dce/pkg/lease"
+	leaseMocks "github.com/Optum/dce/pkg/lease/leaseiface/mocks"
 	"github.com/Optum/dce/pkg/usage"
 	usageMocks "github.com/Optum/dce/pkg/usage/mocks"
 	"github.com/stretchr/testify/assert"
@@ -78,6 +82,10 @@ has exceeded its budget of $100. Actual spend is $150
 		expectedEmailBodyText         string
 		expectedError                 string
 		LeaseStatusModifiedOn         int64
+		leaseFreezePeriod             int
+		shouldFreeze                  bool
+		budgetNotificationsSent       []float64
+		shouldMarkNotified            bool
 	}
 
 	checkBudgetTest := func(test *checkBudgetTestInput) {
@@ -89,9 +97,11 @@ has exceeded its budget of $100. Actual spend is $150
 		sqsSvc := &awsMocks.SQSAPI{}
 		emailSvc := &emailMocks.Service{}
 		s3Svc := &commonMocks.Storager{}
+		leaseSvc := &leaseMocks.Servicer{}
 		input := &lambdaHandlerInput{
 			dbSvc: dbSvc,
 			lease: &db.Lease{
+				ID:                       "lease-1",
 				AccountID:                "1234567890",
 				PrincipalID:              "test-user",
 				LeaseStatus:              test.leaseStatus,
@@ -100,6 +110,7 @@ has exceeded its budget of $100. Actual spend is $150
 				BudgetNotificationEmails: []string{"recipA@example.com", "recipB@example.com"},
 				LeaseStatusModifiedOn:    map[bool]int64{true: time.Unix(100, 0).Unix(), false: test.LeaseStatusModifiedOn}[test.LeaseStatusModifiedOn == 0],
 				ExpiresOn:                time.Now().AddDate(0, 0, +1000).Unix(), //Make sure it expires in the distant future as we aren't testing that
+				BudgetNotificationsSent:  test.budgetNotificationsSent,
 			},
 			awsSession:                             &awsMocks.AwsSession{},
 			tokenSvc:                               tokenSvc,
@@ -118,7 +129,14 @@ has exceeded its budget of $100. Actual spend is $150
 			budgetNotificationTemplateSubject:      emailTemplateSubject,
 			budgetNotificationThresholdPercentiles: []float64{75, 100},
 			principalBudgetAmount:                  1000,
+			principalBudgetPeriod:                  &budget.Period{Type: budget.PeriodWeekly, Location: time.UTC},
 			usageTTL:                               3600,
+			currencyConverter:                      &currency.Converter{BaseCurrency: "USD", Provider: currency.StaticRates{}},
+			leaseSvc:                               leaseSvc,
+			leaseFreezePeriod:                      test.leaseFreezePeriod,
+			leaseFrozenTemplateHTMLKey:             "frozen_templates/html.tmpl",
+			leaseFrozenTemplateTextKey:             "frozen_templates/text.tmpl",
+			leaseFrozenTemplateSubject:             "Lease frozen until {{.FrozenUntil}} [{{.Lease.AccountID}}]",
 		}
 
 		// Should grab the account from the DB, to get it's adminRoleArn
@@ -138,29 +156,34 @@ has exceeded its budget of $100. Actual spend is $150
 		startDate := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), 0, 0, 0, 0, time.UTC)
 		usageEndDate := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), 23, 59, 59, 0, time.UTC)
 		endDate := startDate.AddDate(0, 0, 1)
-		budgetSvc.On("CalculateTotalSpend",
+		budgetSvc.On("CalculateSpendByService",
 			startDate,
 			endDate,
-		).Return(test.actualSpend, nil)
+		).Return(map[string]float64{"Amazon Elastic Compute Cloud - Compute": test.actualSpend}, nil)
 
 		// Expected Usage DB entry
 		inputUsage, err := usage.NewUsage(
 			usage.NewUsageInput{
-				PrincipalID:  "test-user",
-				AccountID:    "",
-				StartDate:    startDate.Unix(),
-				EndDate:      usageEndDate.Unix(),
-				CostAmount:   test.actualSpend,
-				CostCurrency: "USD",
-				TimeToLive:   startDate.Add(time.Duration(3600) * time.Second).Unix(),
+				PrincipalID:           "test-user",
+				AccountID:             "",
+				LeaseID:               "lease-1",
+				StartDate:             startDate.Unix(),
+				EndDate:               usageEndDate.Unix(),
+				CostAmount:            test.actualSpend,
+				CostCurrency:          "USD",
+				ConvertedCostAmount:   test.actualSpend,
+				ConvertedCostCurrency: "USD",
+				TimeToLive:            startDate.Add(time.Duration(3600) * time.Second).Unix(),
+				ServiceCosts:          map[string]float64{"Amazon Elastic Compute Cloud - Compute": test.actualSpend},
 			},
 		)
 		assert.Nil(t, err)
 
 		budgetStartTime := time.Unix(input.lease.LeaseStatusModifiedOn, 0)
 		usageSvc.On("PutUsage", *inputUsage).Return(nil)
-		usageSvc.On("GetUsageByDateRange", budgetStartTime, usageEndDate.AddDate(0, 0, -1)).Return(nil, nil)
-		usageSvc.On("GetUsageByDateRange", mock.Anything, mock.Anything).Return(nil, nil)
+		usageSvc.On("GetUsageByLease", "lease-1", budgetStartTime, usageEndDate.AddDate(0, 0, -1)).Return(nil, nil)
+		usageSvc.On("GetPrincipalSpend", "test-user", mock.Anything, mock.Anything).Return(0.0, nil)
+		usageSvc.On("GetPrincipalBudgetNotified", "test-user", mock.Anything, mock.Anything).Return(nil, nil)
 
 		// Should transition from "Active" --> "FinanceLock"
 		if test.shouldTransitionLeaseStatus {
@@ -177,6 +200,21 @@ has exceeded its budget of $100. Actual spend is $150
 			dbSvc.On("TransitionAccountStatus", "1234567890", db.Leased, db.NotReady).Return(nil, nil)
 		}
 
+		// Should freeze the lease, and let the principal know
+		if test.shouldFreeze {
+			leaseSvc.On("Freeze", input.lease.ID, mock.AnythingOfType("int64")).
+				Return(&lease.Lease{Status: lease.StatusFrozen.StatusPtr()}, nil)
+			s3Svc.On("GetTemplateObject", "artifacts-bucket", "frozen_templates/html.tmpl", mock.Anything).
+				Return("<p>frozen</p>", "", nil)
+			s3Svc.On("GetTemplateObject", "artifacts-bucket", "frozen_templates/text.tmpl", mock.Anything).
+				Return("frozen", "", nil)
+			emailSvc.On("SendEmail", mock.MatchedBy(func(input *email.SendEmailInput) bool {
+				return strings.HasPrefix(input.Subject, "Lease frozen until") &&
+					strings.HasSuffix(input.Subject, "[1234567890]") &&
+					input.BodyText == "frozen"
+			})).Return(nil)
+		}
+
 		// Should send a notification email
 		if test.shouldSendEmail {
 			// Mock templates in S3
@@ -195,6 +233,12 @@ has exceeded its budget of $100. Actual spend is $150
 			}).Return(nil)
 		}
 
+		// Should remember the thresholds notified
+		if test.shouldMarkNotified {
+			leaseSvc.On("MarkBudgetNotified", input.lease.ID, []float64{75}).
+				Return(&lease.Lease{}, nil)
+		}
+
 		// Call Lambda handler
 		err = lambdaHandler(input)
 		if test.expectedError == "" {
@@ -210,6 +254,7 @@ has exceeded its budget of $100. Actual spend is $150
 		snsSvc.AssertExpectations(t)
 		sqsSvc.AssertExpectations(t)
 		emailSvc.AssertExpectations(t)
+		leaseSvc.AssertExpectations(t)
 	}
 
 	t.Run("Scenario: Over Budget Lease", func(t *testing.T) {
@@ -232,6 +277,24 @@ has exceeded its budget of $100. Actual spend is $150
 		})
 	})
 
+	t.Run("Scenario: Over Budget Lease with a freeze period", func(t *testing.T) {
+		checkBudgetTest(&checkBudgetTestInput{
+			// Over budget
+			budgetAmount:      100,
+			actualSpend:       150,
+			leaseStatus:       db.Active,
+			leaseFreezePeriod: 259200,
+			// Should freeze, instead of resetting the account
+			shouldFreeze:                true,
+			shouldTransitionLeaseStatus: false,
+			// Should send notification email
+			shouldSendEmail:       true,
+			expectedEmailSubject:  expectedOverBudgetText,
+			expectedEmailBodyHTML: expectedOverBudgetEmailHTML,
+			expectedEmailBodyText: expectedOverBudgetEmailText,
+		})
+	})
+
 	t.Run("Scenario: Over Threshold Lease", func(t *testing.T) {
 		checkBudgetTest(&checkBudgetTestInput{
 			// >75% of budget
@@ -242,8 +305,9 @@ has exceeded its budget of $100. Actual spend is $150
 			shouldTransitionLeaseStatus: false,
 			shouldSNS:                   false,
 			shouldSQSReset:              false,
-			// Should send notification email
+			// Should send notification email, once
 			shouldSendEmail:      true,
+			shouldMarkNotified:   true,
 			expectedEmailSubject: "Lease at 75% of budget [1234567890]",
 			expectedEmailBodyHTML: strings.TrimSpace(`
 <p>
@@ -262,6 +326,18 @@ Actual spend is $76
 		})
 	})
 
+	t.Run("Scenario: Over Threshold Lease, already notified", func(t *testing.T) {
+		checkBudgetTest(&checkBudgetTestInput{
+			// >75% of budget
+			budgetAmount:            100,
+			actualSpend:             80,
+			leaseStatus:             db.Active,
+			budgetNotificationsSent: []float64{75},
+			// Should not send the same notification email again
+			shouldSendEmail: false,
+		})
+	})
+
 	t.Run("Scenario: Under Budget Lease", func(t *testing.T) {
 		checkBudgetTest(&checkBudgetTestInput{
 			// <75% of budget
@@ -394,9 +470,11 @@ func Test_isLeaseExpired(t *testing.T) {
 
 func TestGetBeginningOfCurrentBillingPeriod(t *testing.T) {
 
-	actualOutput := getBeginningOfCurrentBillingPeriod("WEEKLY")
+	principalBudgetPeriod, err := budget.NewPeriodFromEnv()
+	require.Nil(t, err)
+	actualOutput := principalBudgetPeriod.Start(time.Now())
 
-	currentTime := time.Now()
+	currentTime := time.Now().UTC()
 	for currentTime.Weekday() != time.Sunday { // iterate back to Sunday
 		currentTime = currentTime.AddDate(0, 0, -1)
 	}
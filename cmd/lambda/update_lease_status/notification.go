@@ -2,20 +2,26 @@ package main
 
 import (
 	"bytes"
+	"github.com/Optum/dce/pkg/budget"
 	"github.com/Optum/dce/pkg/common"
 	"github.com/Optum/dce/pkg/db"
 	"github.com/Optum/dce/pkg/email"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/lease/leaseiface"
+	"github.com/Optum/dce/pkg/usage"
 	"github.com/pkg/errors"
 	"html/template"
 	"log"
 	"sort"
 	"strings"
+	"time"
 )
 
 type sendBudgetNotificationEmailInput struct {
 	lease                                  *db.Lease
 	emailSvc                               email.Service
 	s3Svc                                  common.Storager
+	leaseSvc                               leaseiface.Servicer
 	budgetNotificationFromEmail            string
 	budgetNotificationBCCEmails            []string
 	budgetNotificationTemplatesBucket      string
@@ -24,26 +30,21 @@ type sendBudgetNotificationEmailInput struct {
 	budgetNotificationTemplateSubject      string
 	budgetNotificationThresholdPercentiles []float64
 	actualLeaseSpend                       float64
-	actualPrincipalSpend                   float64
+	actualLeaseServiceCosts                map[string]float64 // Lease spend by AWS service
 }
 
+// sendBudgetNotificationEmail notifies the principal of the lease budget thresholds their lease spend crossed.
+// The thresholds notified are recorded on the lease, so each one is only notified once.
 func sendBudgetNotificationEmail(input *sendBudgetNotificationEmailInput) error {
 
-	// Determine the highest lease budget threshold passed
-	thresholdLeasePercentile := determineThresholdPercentile(&determineThresholdPercentileInput{
+	// Determine the lease budget thresholds passed, which weren't notified yet
+	thresholds := newThresholdPercentiles(determineThresholdPercentiles(&determineThresholdPercentileInput{
 		thresholdPercentiles: input.budgetNotificationThresholdPercentiles,
 		budgetAmount:         input.lease.BudgetAmount,
 		actualSpend:          input.actualLeaseSpend,
-	})
+	}), input.lease.BudgetNotificationsSent)
 
-	// Determine the highest principal budget threshold passed
-	thresholdPrincipalPercentile := determineThresholdPercentile(&determineThresholdPercentileInput{
-		thresholdPercentiles: input.budgetNotificationThresholdPercentiles,
-		budgetAmount:         input.lease.BudgetAmount,
-		actualSpend:          input.actualPrincipalSpend,
-	})
-
-	if thresholdLeasePercentile == 0 && thresholdPrincipalPercentile == 0 {
+	if len(thresholds) == 0 {
 		return nil
 	}
 
@@ -51,19 +52,11 @@ func sendBudgetNotificationEmail(input *sendBudgetNotificationEmailInput) error
 		log.Printf("Skipping budget notification emails: "+
 			"no notification emails addressses were provided for lease %s @ %s",
 			input.lease.PrincipalID, input.lease.AccountID)
-		return nil
+		return markBudgetNotified(input.lease, input.leaseSvc.MarkBudgetNotified, thresholds)
 	}
 
-	// if both lease budget threshold and principal budget threshold passed, notify for lease budget threshold only
-	thresholdPercentile := 0.0
-	actualSpend := 0.0
-	if (thresholdLeasePercentile > 0 && thresholdPrincipalPercentile > 0) || thresholdLeasePercentile > 0 {
-		thresholdPercentile = thresholdLeasePercentile
-		actualSpend = input.actualLeaseSpend
-	} else if thresholdPrincipalPercentile > 0 {
-		thresholdPercentile = thresholdPrincipalPercentile
-		actualSpend = input.actualPrincipalSpend
-	}
+	// Notify the highest threshold passed
+	thresholdPercentile := thresholds[len(thresholds)-1]
 
 	log.Printf("Budget notification threshold hit at %.0f%%", thresholdPercentile)
 	log.Printf("Sending budget notification emails for lease %s @ %s to %s",
@@ -81,7 +74,7 @@ func sendBudgetNotificationEmail(input *sendBudgetNotificationEmailInput) error
 			input.budgetNotificationTemplatesBucket, input.budgetNotificationTemplateHTMLKey)
 	}
 
-	return sendEmail(&sendEmailInput{
+	err = sendEmail(&sendEmailInput{
 		lease:                             input.lease,
 		emailSvc:                          input.emailSvc,
 		budgetNotificationFromEmail:       input.budgetNotificationFromEmail,
@@ -89,8 +82,133 @@ func sendBudgetNotificationEmail(input *sendBudgetNotificationEmailInput) error
 		budgetNotificationTemplateHTML:    templateHTML,
 		budgetNotificationTemplateText:    templateText,
 		budgetNotificationTemplateSubject: input.budgetNotificationTemplateSubject,
-		actualSpend:                       actualSpend,
+		actualSpend:                       input.actualLeaseSpend,
+		serviceCosts:                      input.actualLeaseServiceCosts,
 	}, thresholdPercentile)
+	if err != nil {
+		return err
+	}
+
+	return markBudgetNotified(input.lease, input.leaseSvc.MarkBudgetNotified, thresholds)
+}
+
+type sendPrincipalBudgetNotificationEmailInput struct {
+	lease                                           *db.Lease
+	emailSvc                                        email.Service
+	s3Svc                                           common.Storager
+	usageSvc                                        usage.DBer
+	budgetNotificationFromEmail                     string
+	budgetNotificationBCCEmails                     []string
+	budgetNotificationTemplatesBucket               string
+	principalBudgetNotificationTemplateHTMLKey      string
+	principalBudgetNotificationTemplateTextKey      string
+	principalBudgetNotificationTemplateSubject      string
+	principalBudgetNotificationThresholdPercentiles []float64
+	principalBudgetAmount                           float64
+	principalBudgetPeriod                           *budget.Period
+	actualPrincipalSpend                            float64
+}
+
+// sendPrincipalBudgetNotificationEmail notifies the principal of the principal budget thresholds
+// their spend crossed in the current budget period.
+// The thresholds notified are recorded with the principal's usage for the budget period, so each one
+// is only notified once per budget period, however many leases the principal has.
+func sendPrincipalBudgetNotificationEmail(input *sendPrincipalBudgetNotificationEmailInput) error {
+	currentTime := time.Now()
+	notificationsSent, err := input.usageSvc.GetPrincipalBudgetNotified(input.lease.PrincipalID, input.principalBudgetPeriod, currentTime)
+	if err != nil {
+		return err
+	}
+	markNotified := func(thresholds []float64) error {
+		return input.usageSvc.MarkPrincipalBudgetNotified(input.lease.PrincipalID, input.principalBudgetPeriod, currentTime, thresholds)
+	}
+
+	thresholds := determineThresholdPercentiles(&determineThresholdPercentileInput{
+		thresholdPercentiles: input.principalBudgetNotificationThresholdPercentiles,
+		budgetAmount:         input.principalBudgetAmount,
+		actualSpend:          input.actualPrincipalSpend,
+	})
+	newThresholds := newThresholdPercentiles(thresholds, notificationsSent)
+
+	if len(newThresholds) == 0 {
+		// Spend only goes down in ROLLING budget periods, as older usage drops out of them,
+		// so forget the thresholds which aren't crossed anymore
+		if len(newThresholdPercentiles(notificationsSent, thresholds)) > 0 {
+			return markNotified(thresholds)
+		}
+		return nil
+	}
+
+	if len(input.lease.BudgetNotificationEmails)+len(input.budgetNotificationBCCEmails) == 0 {
+		log.Printf("Skipping principal budget notification emails: "+
+			"no notification emails addressses were provided for lease %s @ %s",
+			input.lease.PrincipalID, input.lease.AccountID)
+		return markNotified(thresholds)
+	}
+
+	// Notify the highest threshold passed
+	thresholdPercentile := newThresholds[len(newThresholds)-1]
+	log.Printf("Principal budget notification threshold hit at %.0f%% for principal %s",
+		thresholdPercentile, input.lease.PrincipalID)
+
+	periodStart := input.principalBudgetPeriod.Start(currentTime)
+	periodEnd := input.principalBudgetPeriod.End(currentTime)
+	templateData := struct {
+		Lease                 db.Lease
+		ActualSpend           float64
+		PrincipalBudgetAmount float64
+		IsOverBudget          bool
+		ThresholdPercentile   int
+		PeriodStart           string
+		PeriodEnd             string
+	}{
+		Lease:                 *input.lease,
+		ActualSpend:           input.actualPrincipalSpend,
+		PrincipalBudgetAmount: input.principalBudgetAmount,
+		IsOverBudget:          input.actualPrincipalSpend >= input.principalBudgetAmount,
+		ThresholdPercentile:   int(thresholdPercentile),
+		PeriodStart:           periodStart.Format("2006-01-02"),
+		PeriodEnd:             periodEnd.AddDate(0, 0, -1).Format("2006-01-02"),
+	}
+
+	bodyHTML, _, err := input.s3Svc.GetTemplateObject(input.budgetNotificationTemplatesBucket, input.principalBudgetNotificationTemplateHTMLKey, templateData)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to render principal budget notification template at s3://%s/%s",
+			input.budgetNotificationTemplatesBucket, input.principalBudgetNotificationTemplateHTMLKey)
+	}
+	bodyText, _, err := input.s3Svc.GetTemplateObject(input.budgetNotificationTemplatesBucket, input.principalBudgetNotificationTemplateTextKey, templateData)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to render principal budget notification template at s3://%s/%s",
+			input.budgetNotificationTemplatesBucket, input.principalBudgetNotificationTemplateTextKey)
+	}
+	subject, err := renderTemplate("principalBudgetSubject", input.principalBudgetNotificationTemplateSubject, templateData)
+	if err != nil {
+		return err
+	}
+
+	err = input.emailSvc.SendEmail(&email.SendEmailInput{
+		FromAddress:  input.budgetNotificationFromEmail,
+		ToAddresses:  input.lease.BudgetNotificationEmails,
+		BCCAddresses: input.budgetNotificationBCCEmails,
+		BodyHTML:     bodyHTML,
+		BodyText:     bodyText,
+		Subject:      subject,
+	})
+	if err != nil {
+		return err
+	}
+
+	return markNotified(thresholds)
+}
+
+// markBudgetNotified records the thresholds notified on the lease.
+// Leases which were just ended or frozen aren't checked again, so there's nothing to record for them.
+func markBudgetNotified(l *db.Lease, mark func(ID string, thresholds []float64) (*lease.Lease, error), thresholds []float64) error {
+	if l.LeaseStatus != db.Active {
+		return nil
+	}
+	_, err := mark(l.ID, thresholds)
+	return err
 }
 
 func renderTemplate(id string, templateStr string, data interface{}) (string, error) {
@@ -111,20 +229,38 @@ type determineThresholdPercentileInput struct {
 	actualSpend          float64
 }
 
-func determineThresholdPercentile(input *determineThresholdPercentileInput) float64 {
+// determineThresholdPercentiles returns the thresholds reached, in increasing order
+func determineThresholdPercentiles(input *determineThresholdPercentileInput) []float64 {
 	// Sort threshold percentiles in increasing order
 	sort.Float64s(input.thresholdPercentiles)
 
-	// Find the highest threshold that we've reached
-	var thresholdPassed float64
+	thresholdsPassed := []float64{}
 	for _, thresholdPercentile := range input.thresholdPercentiles {
 		thresholdAmount := input.budgetAmount * (thresholdPercentile / 100)
 		if input.actualSpend >= thresholdAmount {
-			thresholdPassed = thresholdPercentile
+			thresholdsPassed = append(thresholdsPassed, thresholdPercentile)
 		}
 	}
 
-	return thresholdPassed
+	return thresholdsPassed
+}
+
+// newThresholdPercentiles returns the thresholds which weren't notified yet
+func newThresholdPercentiles(thresholds []float64, sent []float64) []float64 {
+	newThresholds := []float64{}
+	for _, threshold := range thresholds {
+		notified := false
+		for _, s := range sent {
+			if s == threshold {
+				notified = true
+				break
+			}
+		}
+		if !notified {
+			newThresholds = append(newThresholds, threshold)
+		}
+	}
+	return newThresholds
 }
 
 type sendEmailInput struct {
@@ -136,6 +272,36 @@ type sendEmailInput struct {
 	budgetNotificationTemplateText    string
 	budgetNotificationTemplateSubject string
 	actualSpend                       float64
+	serviceCosts                      map[string]float64
+}
+
+// serviceCost is the spend on a single AWS service, for email templates
+type serviceCost struct {
+	Service string
+	Amount  float64
+}
+
+// maxEmailServiceCosts is the most services listed in budget notification emails
+const maxEmailServiceCosts = 10
+
+// topServiceCosts returns the services with the highest spend, highest first
+func topServiceCosts(serviceCosts map[string]float64, limit int) []serviceCost {
+	costs := []serviceCost{}
+	for service, amount := range serviceCosts {
+		if amount > 0 {
+			costs = append(costs, serviceCost{Service: service, Amount: amount})
+		}
+	}
+	sort.Slice(costs, func(i, j int) bool {
+		if costs[i].Amount == costs[j].Amount {
+			return costs[i].Service < costs[j].Service
+		}
+		return costs[i].Amount > costs[j].Amount
+	})
+	if len(costs) > limit {
+		costs = costs[:limit]
+	}
+	return costs
 }
 
 func sendEmail(input *sendEmailInput, thresholdPercentile float64) error {
@@ -146,11 +312,13 @@ func sendEmail(input *sendEmailInput, thresholdPercentile float64) error {
 		ActualSpend         float64
 		IsOverBudget        bool
 		ThresholdPercentile int
+		ServiceCosts        []serviceCost // Services with the highest lease spend, highest first
 	}{
 		Lease:               *input.lease,
 		ActualSpend:         input.actualSpend,
 		IsOverBudget:        input.actualSpend >= input.lease.BudgetAmount,
 		ThresholdPercentile: int(thresholdPercentile),
+		ServiceCosts:        topServiceCosts(input.serviceCosts, maxEmailServiceCosts),
 	}
 	bodyHTML, err := renderTemplate("htmlEmail", input.budgetNotificationTemplateHTML, templateData)
 	if err != nil {
@@ -175,3 +343,53 @@ func sendEmail(input *sendEmailInput, thresholdPercentile float64) error {
 		Subject:      subject,
 	})
 }
+
+// sendLeaseFrozenEmail lets the principal know their lease is frozen,
+// and how long they have to export their data.
+// forecastSpend is set when the lease was frozen for its forecast, rather than its actual spend.
+func sendLeaseFrozenEmail(input *lambdaHandlerInput, frozenUntil int64, forecastSpend *float64) error {
+	if len(input.lease.BudgetNotificationEmails)+len(input.budgetNotificationBCCEmails) == 0 {
+		log.Printf("Skipping lease frozen email: "+
+			"no notification emails addressses were provided for lease %s @ %s",
+			input.lease.PrincipalID, input.lease.AccountID)
+		return nil
+	}
+
+	templateData := struct {
+		Lease         db.Lease
+		FrozenUntil   string
+		IsForecast    bool
+		ForecastSpend float64
+	}{
+		Lease:       *input.lease,
+		FrozenUntil: time.Unix(frozenUntil, 0).UTC().Format(time.RFC1123),
+	}
+	if forecastSpend != nil {
+		templateData.IsForecast = true
+		templateData.ForecastSpend = *forecastSpend
+	}
+
+	bodyHTML, _, err := input.s3Svc.GetTemplateObject(input.budgetNotificationTemplatesBucket, input.leaseFrozenTemplateHTMLKey, templateData)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to render lease frozen template at s3://%s/%s",
+			input.budgetNotificationTemplatesBucket, input.leaseFrozenTemplateHTMLKey)
+	}
+	bodyText, _, err := input.s3Svc.GetTemplateObject(input.budgetNotificationTemplatesBucket, input.leaseFrozenTemplateTextKey, templateData)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to render lease frozen template at s3://%s/%s",
+			input.budgetNotificationTemplatesBucket, input.leaseFrozenTemplateTextKey)
+	}
+	subject, err := renderTemplate("frozenSubject", input.leaseFrozenTemplateSubject, templateData)
+	if err != nil {
+		return err
+	}
+
+	return input.emailSvc.SendEmail(&email.SendEmailInput{
+		FromAddress:  input.budgetNotificationFromEmail,
+		ToAddresses:  input.lease.BudgetNotificationEmails,
+		BCCAddresses: input.budgetNotificationBCCEmails,
+		BodyHTML:     bodyHTML,
+		BodyText:     bodyText,
+		Subject:      subject,
+	})
+}
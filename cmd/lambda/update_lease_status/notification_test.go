@@ -0,0 +1,144 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Optum/dce/pkg/budget"
+	commonMocks "github.com/Optum/dce/pkg/common/mocks"
+	"github.com/Optum/dce/pkg/db"
+	"github.com/Optum/dce/pkg/email"
+	emailMocks "github.com/Optum/dce/pkg/email/mocks"
+	usageMocks "github.com/Optum/dce/pkg/usage/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSendPrincipalBudgetNotificationEmail(t *testing.T) {
+	tests := []struct {
+		name                             string
+		actualPrincipalSpend             float64
+		leaseStatus                      db.LeaseStatus
+		principalBudgetNotificationsSent []float64
+		expNotified                      []float64
+		expSubject                       string
+	}{
+		{
+			name:                 "should notify the principal once a threshold is crossed",
+			actualPrincipalSpend: 800,
+			leaseStatus:          db.Active,
+			expNotified:          []float64{75},
+			expSubject:           "Principal budget at 75% [test-user]",
+		},
+		{
+			name:                             "should not notify the principal of a threshold twice",
+			actualPrincipalSpend:             800,
+			leaseStatus:                      db.Active,
+			principalBudgetNotificationsSent: []float64{75},
+		},
+		{
+			name:                             "should notify the principal of the next threshold",
+			actualPrincipalSpend:             1200,
+			leaseStatus:                      db.Active,
+			principalBudgetNotificationsSent: []float64{75},
+			expNotified:                      []float64{75, 100},
+			expSubject:                       "Principal budget exhausted [test-user]",
+		},
+		{
+			name:                             "should forget the thresholds of the previous budget period",
+			actualPrincipalSpend:             100,
+			leaseStatus:                      db.Active,
+			principalBudgetNotificationsSent: []float64{100, 75},
+			expNotified:                      []float64{},
+		},
+		{
+			name:                 "should record the thresholds notified for the principal, once the lease has ended",
+			actualPrincipalSpend: 1200,
+			leaseStatus:          db.Inactive,
+			expNotified:          []float64{75, 100},
+			expSubject:           "Principal budget exhausted [test-user]",
+		},
+		{
+			name:                 "should not notify the principal under the first threshold",
+			actualPrincipalSpend: 500,
+			leaseStatus:          db.Active,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usageSvc := &usageMocks.DBer{}
+			emailSvc := &emailMocks.Service{}
+			s3Svc := &commonMocks.Storager{}
+			input := &sendPrincipalBudgetNotificationEmailInput{
+				lease: &db.Lease{
+					ID:                       "abc123",
+					AccountID:                "1234567890",
+					PrincipalID:              "test-user",
+					LeaseStatus:              tt.leaseStatus,
+					BudgetNotificationEmails: []string{"recipA@example.com"},
+				},
+				emailSvc:                          emailSvc,
+				s3Svc:                             s3Svc,
+				usageSvc:                          usageSvc,
+				budgetNotificationFromEmail:       "from@example.com",
+				budgetNotificationTemplatesBucket: "test-bucket",
+				principalBudgetNotificationTemplateHTMLKey:      "principal.html",
+				principalBudgetNotificationTemplateTextKey:      "principal.txt",
+				principalBudgetNotificationTemplateSubject:      "Principal budget {{if .IsOverBudget}}exhausted{{else}}at {{.ThresholdPercentile}}%{{end}} [{{.Lease.PrincipalID}}]",
+				principalBudgetNotificationThresholdPercentiles: []float64{75, 100},
+				principalBudgetAmount:                           1000,
+				principalBudgetPeriod:                           &budget.Period{Type: budget.PeriodWeekly, Location: time.UTC},
+				actualPrincipalSpend:                            tt.actualPrincipalSpend,
+			}
+
+			usageSvc.On("GetPrincipalBudgetNotified", "test-user", input.principalBudgetPeriod, mock.AnythingOfType("time.Time")).
+				Return(tt.principalBudgetNotificationsSent, nil)
+			usageSvc.On("MarkPrincipalBudgetNotified", "test-user", input.principalBudgetPeriod, mock.AnythingOfType("time.Time"), mock.Anything).Return(nil)
+			s3Svc.On("GetTemplateObject", "test-bucket", "principal.html", mock.Anything).Return("<p>budget</p>", "", nil)
+			s3Svc.On("GetTemplateObject", "test-bucket", "principal.txt", mock.Anything).Return("budget", "", nil)
+			emailSvc.On("SendEmail", mock.AnythingOfType("*email.SendEmailInput")).Return(nil)
+
+			err := sendPrincipalBudgetNotificationEmail(input)
+			assert.Nil(t, err)
+
+			if tt.expNotified != nil {
+				usageSvc.AssertCalled(t, "MarkPrincipalBudgetNotified", "test-user", input.principalBudgetPeriod, mock.AnythingOfType("time.Time"), tt.expNotified)
+			} else {
+				usageSvc.AssertNotCalled(t, "MarkPrincipalBudgetNotified", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+			if tt.expSubject != "" {
+				emailSvc.AssertCalled(t, "SendEmail", &email.SendEmailInput{
+					FromAddress: "from@example.com",
+					ToAddresses: []string{"recipA@example.com"},
+					BodyHTML:    "<p>budget</p>",
+					BodyText:    "budget",
+					Subject:     tt.expSubject,
+				})
+			} else {
+				emailSvc.AssertNotCalled(t, "SendEmail", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestTopServiceCosts(t *testing.T) {
+	serviceCosts := map[string]float64{
+		"Amazon SageMaker":      120,
+		"EC2 - Other":           30.5,
+		"Amazon S3":             0.25,
+		"AWS Key Management":    0,
+		"Amazon CloudWatch":     30.5,
+		"Amazon Virtual Cloud":  5,
+		"Amazon Simple Storage": -1,
+	}
+
+	assert.Equal(t, []serviceCost{
+		{Service: "Amazon SageMaker", Amount: 120},
+		{Service: "Amazon CloudWatch", Amount: 30.5},
+		{Service: "EC2 - Other", Amount: 30.5},
+	}, topServiceCosts(serviceCosts, 3))
+
+	assert.Len(t, topServiceCosts(serviceCosts, 10), 5, "should skip services without spend")
+	assert.Empty(t, topServiceCosts(nil, 10))
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/Optum/dce/pkg/awsiface"
 	"github.com/Optum/dce/pkg/budget"
 	"github.com/Optum/dce/pkg/common"
+	"github.com/Optum/dce/pkg/currency"
 	"github.com/Optum/dce/pkg/db"
 	"github.com/Optum/dce/pkg/usage"
 	"github.com/aws/aws-sdk-go/service/costexplorer"
@@ -20,17 +21,24 @@ type calculateSpendInput struct {
 	budgetSvc             budget.Service
 	usageSvc              usage.DBer
 	awsSession            awsiface.AwsSession
-	principalBudgetPeriod string
+	principalBudgetPeriod *budget.Period
 	usageTTL              int // TTL in seconds for Usage DynamoDB records
+	currencyConverter     *currency.Converter
+	consolidatedBilling   bool // Read lease spend from the Usage cache alone, rather than Cost Explorer
 }
 
-// calculateLeaseSpend calculates amount spent by User principal for current lease
-func calculateLeaseSpend(input *calculateSpendInput) (float64, error) {
+// calculateLeaseSpend calculates amount spent by User principal for current lease,
+// in total and by AWS service, in the lease's budget currency
+func calculateLeaseSpend(input *calculateSpendInput) (float64, map[string]float64, error) {
+	if input.consolidatedBilling {
+		return calculateCachedLeaseSpend(input)
+	}
+
 	adminRoleArn := input.account.AdminRoleArn
 	log.Printf("Assuming role %s for budget check", adminRoleArn)
 	assumedSession, err := input.tokenSvc.NewSession(input.awsSession, adminRoleArn)
 	if err != nil {
-		return 0, errors.Wrapf(err, "Failed to assume role %s", adminRoleArn)
+		return 0, nil, errors.Wrapf(err, "Failed to assume role %s", adminRoleArn)
 	}
 
 	// Configure the CostExplorer SDK for the Service
@@ -44,30 +52,49 @@ func calculateLeaseSpend(input *calculateSpendInput) (float64, error) {
 	usageEndTime := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), 23, 59, 59, 0, time.UTC)
 
 	log.Printf("usageStart: %d and usageEnd :%d", usageStartTime.Unix(), usageEndTime.Unix())
-	todayCostAmount, err := input.budgetSvc.CalculateTotalSpend(usageStartTime, usageStartTime.AddDate(0, 0, 1))
+	todayServiceCosts, err := input.budgetSvc.CalculateSpendByService(usageStartTime, usageStartTime.AddDate(0, 0, 1))
 	if err != nil {
-		return 0, errors.Wrapf(err, "Failed to calculate spend for account %s", input.lease.AccountID)
+		return 0, nil, errors.Wrapf(err, "Failed to calculate spend for account %s", input.lease.AccountID)
+	}
+	todayCostAmount := 0.0
+	for _, cost := range todayServiceCosts {
+		todayCostAmount = todayCostAmount + cost
 	}
 
 	log.Printf("usage for today: %f", todayCostAmount)
 
+	// Cost Explorer reports costs in the cost currency, but the lease may budget in another one
+	costCurrency := input.currencyConverter.BaseCurrency
+	leaseCurrency := input.lease.BudgetCurrency
+	if leaseCurrency == "" {
+		leaseCurrency = costCurrency
+	}
+	todayLeaseCostAmount, err := input.currencyConverter.Convert(todayCostAmount, costCurrency, leaseCurrency)
+	if err != nil {
+		return 0, nil, errors.Wrapf(err, "Failed to convert spend for lease %s @ %s", input.lease.PrincipalID, input.lease.AccountID)
+	}
+
 	// Write today's usage to DynamoDB
 	usageItem, err := usage.NewUsage(usage.NewUsageInput{
-		StartDate:    usageStartTime.Unix(),
-		EndDate:      usageEndTime.Unix(),
-		PrincipalID:  input.lease.PrincipalID,
-		AccountID:    input.account.ID,
-		CostAmount:   todayCostAmount,
-		CostCurrency: "USD",
-		TimeToLive:   usageStartTime.Add(time.Duration(input.usageTTL) * time.Second).Unix(),
+		StartDate:             usageStartTime.Unix(),
+		EndDate:               usageEndTime.Unix(),
+		PrincipalID:           input.lease.PrincipalID,
+		AccountID:             input.account.ID,
+		LeaseID:               input.lease.ID,
+		CostAmount:            todayCostAmount,
+		CostCurrency:          costCurrency,
+		ConvertedCostAmount:   todayLeaseCostAmount,
+		ConvertedCostCurrency: leaseCurrency,
+		TimeToLive:            usageStartTime.Add(time.Duration(input.usageTTL) * time.Second).Unix(),
+		ServiceCosts:          todayServiceCosts,
 	})
 	if err != nil {
-		return 0, nil
+		return 0, nil, nil
 	}
 
 	err = input.usageSvc.PutUsage(*usageItem)
 	if err != nil {
-		return 0, nil
+		return 0, nil, nil
 	}
 
 	// Budget period starts last time the lease was reset.
@@ -93,69 +120,134 @@ func calculateLeaseSpend(input *calculateSpendInput) (float64, error) {
 	}
 
 	// Query Usage cache DB
-	usageRecords, err := input.usageSvc.GetUsageByDateRange(budgetStartTime, budgetEndTime)
+	usageRecords, err := input.usageSvc.GetUsageByLease(input.lease.ID, budgetStartTime, budgetEndTime)
 	if err != nil {
-		return 0, errors.Wrapf(err, "Failed to retrieve usage for account %s", input.lease.AccountID)
+		return 0, nil, errors.Wrapf(err, "Failed to retrieve usage for account %s", input.lease.AccountID)
 	}
 
 	// DynDB is eventually consistent. Pull cache DB for SUN-->yesterday, then add the known value for today
-	spend := todayCostAmount
-	for _, usage := range usageRecords {
-		log.Printf("usage records retrieved: %v", usage)
-		if *usage.PrincipalID == input.lease.PrincipalID && *usage.AccountID == input.lease.AccountID {
-			spend = spend + *usage.CostAmount
-		}
+	spend := todayLeaseCostAmount
+	serviceCosts := map[string]float64{}
+	err = addServiceCosts(input.currencyConverter, serviceCosts, todayServiceCosts, costCurrency, leaseCurrency)
+	if err != nil {
+		return 0, nil, errors.Wrapf(err, "Failed to convert spend for lease %s @ %s", input.lease.PrincipalID, input.lease.AccountID)
+	}
+	spend, err = addLeaseUsage(input, usageRecords, leaseCurrency, spend, serviceCosts)
+	if err != nil {
+		return 0, nil, err
 	}
 
-	log.Printf("Lease for %s @ %s has spent $%.2f of their $%.2f budget",
-		input.lease.PrincipalID, input.lease.AccountID, spend, input.lease.BudgetAmount)
+	log.Printf("Lease for %s @ %s has spent %.2f %s of their %.2f %s budget",
+		input.lease.PrincipalID, input.lease.AccountID, spend, leaseCurrency, input.lease.BudgetAmount, leaseCurrency)
 
-	return spend, nil
+	return spend, serviceCosts, nil
 }
 
-// calculatePrincipalSpend calculates the amount spent by User principal for current billing period
-func calculatePrincipalSpend(input *calculateSpendInput) (float64, error) {
-
-	// Budget period starts based on principal_budget_period variable value
-	currentTime := time.Now()
-	budgetStartTime := getBeginningOfCurrentBillingPeriod(input.principalBudgetPeriod)
-	budgetEndTime := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), 23, 59, 59, 0, time.UTC)
+// calculateCachedLeaseSpend calculates amount spent by User principal for current lease
+// from the Usage cache alone, including today's usage.
+// With consolidated billing, the collect_usage lambda writes the usage of every leased account.
+func calculateCachedLeaseSpend(input *calculateSpendInput) (float64, map[string]float64, error) {
+	leaseCurrency := input.lease.BudgetCurrency
+	if leaseCurrency == "" {
+		leaseCurrency = input.currencyConverter.BaseCurrency
+	}
 
+	budgetStartTime := time.Unix(input.lease.LeaseStatusModifiedOn, 0)
+	budgetEndTime := time.Now()
 	log.Printf("Retrieving usage for lease %s @ %s for period %s to %s...",
 		input.lease.PrincipalID, input.lease.AccountID,
 		budgetStartTime.Format("2006-01-02"), budgetEndTime.Format("2006-01-02"),
 	)
 
 	// Query Usage cache DB
-	usageRecords, err := input.usageSvc.GetUsageByDateRange(budgetStartTime, budgetEndTime)
+	usageRecords, err := input.usageSvc.GetUsageByLease(input.lease.ID, budgetStartTime, budgetEndTime)
 	if err != nil {
-		return 0, errors.Wrapf(err, "Failed to retrieve usage for account %s", input.lease.AccountID)
+		return 0, nil, errors.Wrapf(err, "Failed to retrieve usage for account %s", input.lease.AccountID)
 	}
 
-	spend := 0.0
+	serviceCosts := map[string]float64{}
+	spend, err := addLeaseUsage(input, usageRecords, leaseCurrency, 0, serviceCosts)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	log.Printf("Lease for %s @ %s has spent %.2f %s of their %.2f %s budget",
+		input.lease.PrincipalID, input.lease.AccountID, spend, leaseCurrency, input.lease.BudgetAmount, leaseCurrency)
+
+	return spend, serviceCosts, nil
+}
+
+// addLeaseUsage adds the lease's usage records to its spend, and to its spend by AWS service,
+// in the lease's budget currency. Returns the new spend.
+func addLeaseUsage(input *calculateSpendInput, usageRecords []*usage.Usage, leaseCurrency string, spend float64, serviceCosts map[string]float64) (float64, error) {
 	for _, usage := range usageRecords {
 		log.Printf("usage records retrieved: %v", usage)
-		if *usage.PrincipalID == input.lease.PrincipalID {
-			spend = spend + *usage.CostAmount
+		cost, err := usageCostIn(input.currencyConverter, usage, leaseCurrency)
+		if err != nil {
+			return 0, errors.Wrapf(err, "Failed to convert spend for lease %s @ %s", input.lease.PrincipalID, input.lease.AccountID)
+		}
+		spend = spend + cost
+
+		from := ""
+		if usage.CostCurrency != nil {
+			from = *usage.CostCurrency
+		}
+		err = addServiceCosts(input.currencyConverter, serviceCosts, usage.ServiceCosts, from, leaseCurrency)
+		if err != nil {
+			return 0, errors.Wrapf(err, "Failed to convert spend for lease %s @ %s", input.lease.PrincipalID, input.lease.AccountID)
 		}
 	}
+	return spend, nil
+}
+
+// calculatePrincipalSpend calculates the amount spent by User principal for current billing period
+func calculatePrincipalSpend(input *calculateSpendInput) (float64, error) {
+
+	// Budget period starts based on principal_budget_period variable value.
+	// Usage is recorded per day, so query up to today in the budget period's time zone.
+	currentTime := time.Now()
+	budgetStartTime := input.principalBudgetPeriod.Start(currentTime)
+	budgetEndTime := currentTime.In(input.principalBudgetPeriod.Location)
+
+	log.Printf("Retrieving usage for lease %s @ %s for period %s to %s...",
+		input.lease.PrincipalID, input.lease.AccountID,
+		budgetStartTime.Format("2006-01-02"), budgetEndTime.Format("2006-01-02"),
+	)
+
+	// Read the principal's usage total for the period, which is in the cost currency,
+	// as is the principal budget
+	spend, err := input.usageSvc.GetPrincipalSpend(input.lease.PrincipalID, input.principalBudgetPeriod, currentTime)
+	if err != nil {
+		return 0, errors.Wrapf(err, "Failed to retrieve usage for principal %s", input.lease.PrincipalID)
+	}
 
 	log.Printf("Principal %s has spent $%.2f of their current principal budget amount",
 		input.lease.PrincipalID, spend)
 	return spend, nil
 }
 
-// getBeginningOfCurrentBillingPeriod returns starts of the billing period based on budget period
-func getBeginningOfCurrentBillingPeriod(input string) time.Time {
-	currentTime := time.Now()
-	if input == "WEEKLY" {
-
-		for currentTime.Weekday() != time.Sunday { // iterate back to Sunday
-			currentTime = currentTime.AddDate(0, 0, -1)
-		}
+// usageCostIn returns the cost of the usage record in the given currency.
+// Records written before currency conversion was supported are in the cost currency.
+func usageCostIn(converter *currency.Converter, u *usage.Usage, to string) (float64, error) {
+	if u.ConvertedCostAmount != nil && u.ConvertedCostCurrency != nil && *u.ConvertedCostCurrency == to {
+		return *u.ConvertedCostAmount, nil
+	}
 
-		return time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), 0, 0, 0, 0, time.UTC)
+	from := ""
+	if u.CostCurrency != nil {
+		from = *u.CostCurrency
 	}
+	return converter.Convert(*u.CostAmount, from, to)
+}
 
-	return time.Date(currentTime.Year(), currentTime.Month(), 1, 0, 0, 0, 0, time.UTC)
+// addServiceCosts adds the cost of each AWS service to the totals, converted to the given currency
+func addServiceCosts(converter *currency.Converter, totals map[string]float64, serviceCosts map[string]float64, from string, to string) error {
+	for service, cost := range serviceCosts {
+		converted, err := converter.Convert(cost, from, to)
+		if err != nil {
+			return err
+		}
+		totals[service] = totals[service] + converted
+	}
+	return nil
 }
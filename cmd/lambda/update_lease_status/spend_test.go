@@ -0,0 +1,148 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Optum/dce/pkg/currency"
+	"github.com/Optum/dce/pkg/db"
+	"github.com/Optum/dce/pkg/usage"
+	usageMocks "github.com/Optum/dce/pkg/usage/mocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUsageCostIn(t *testing.T) {
+	converter := &currency.Converter{
+		BaseCurrency: "USD",
+		Provider:     currency.StaticRates{"EUR": 0.5},
+	}
+
+	tests := []struct {
+		name     string
+		usage    *usage.Usage
+		currency string
+		expCost  float64
+		expErr   bool
+	}{
+		{
+			name: "should use the converted cost when it's in the right currency",
+			usage: &usage.Usage{
+				CostAmount:            aws.Float64(100),
+				CostCurrency:          aws.String("USD"),
+				ConvertedCostAmount:   aws.Float64(40),
+				ConvertedCostCurrency: aws.String("EUR"),
+			},
+			currency: "EUR",
+			expCost:  40,
+		},
+		{
+			name: "should convert the cost when the converted cost is in another currency",
+			usage: &usage.Usage{
+				CostAmount:            aws.Float64(100),
+				CostCurrency:          aws.String("USD"),
+				ConvertedCostAmount:   aws.Float64(100),
+				ConvertedCostCurrency: aws.String("USD"),
+			},
+			currency: "EUR",
+			expCost:  50,
+		},
+		{
+			name: "should convert costs recorded before conversion was supported",
+			usage: &usage.Usage{
+				CostAmount: aws.Float64(100),
+			},
+			currency: "EUR",
+			expCost:  50,
+		},
+		{
+			name: "should not convert costs in the cost currency",
+			usage: &usage.Usage{
+				CostAmount:   aws.Float64(100),
+				CostCurrency: aws.String("USD"),
+			},
+			currency: "USD",
+			expCost:  100,
+		},
+		{
+			name: "should fail on currencies without an exchange rate",
+			usage: &usage.Usage{
+				CostAmount:   aws.Float64(100),
+				CostCurrency: aws.String("USD"),
+			},
+			currency: "GBP",
+			expErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cost, err := usageCostIn(converter, tt.usage, tt.currency)
+			if tt.expErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.InDelta(t, tt.expCost, cost, 0.0001)
+		})
+	}
+}
+
+func TestAddServiceCosts(t *testing.T) {
+	converter := &currency.Converter{
+		BaseCurrency: "USD",
+		Provider:     currency.StaticRates{"EUR": 0.5},
+	}
+
+	totals := map[string]float64{"Amazon SageMaker": 10}
+	err := addServiceCosts(converter, totals, map[string]float64{"Amazon SageMaker": 100, "EC2 - Other": 20}, "USD", "EUR")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]float64{"Amazon SageMaker": 60, "EC2 - Other": 10}, totals)
+
+	err = addServiceCosts(converter, totals, map[string]float64{"Amazon SageMaker": 100}, "USD", "GBP")
+	assert.NotNil(t, err)
+}
+
+func TestCalculateLeaseSpendConsolidatedBilling(t *testing.T) {
+	usageSvc := &usageMocks.DBer{}
+	usageSvc.On("GetUsageByLease", "lease1", mock.Anything, mock.Anything).Return([]*usage.Usage{
+		{
+			PrincipalID:  aws.String("user1"),
+			AccountID:    aws.String("123456789012"),
+			LeaseID:      aws.String("lease1"),
+			CostAmount:   aws.Float64(100),
+			CostCurrency: aws.String("USD"),
+			ServiceCosts: map[string]float64{"Amazon SageMaker": 100},
+		},
+		{
+			PrincipalID:  aws.String("user1"),
+			AccountID:    aws.String("123456789012"),
+			LeaseID:      aws.String("lease1"),
+			CostAmount:   aws.Float64(20),
+			CostCurrency: aws.String("USD"),
+			ServiceCosts: map[string]float64{"EC2 - Other": 20},
+		},
+	}, nil)
+
+	// No role is assumed, and Cost Explorer isn't called: the spend only comes from the Usage cache
+	spend, serviceCosts, err := calculateLeaseSpend(&calculateSpendInput{
+		lease: &db.Lease{
+			ID:                    "lease1",
+			PrincipalID:           "user1",
+			AccountID:             "123456789012",
+			BudgetAmount:          500,
+			LeaseStatusModifiedOn: time.Now().AddDate(0, 0, -3).Unix(),
+		},
+		usageSvc: usageSvc,
+		currencyConverter: &currency.Converter{
+			BaseCurrency: "USD",
+			Provider:     currency.StaticRates{},
+		},
+		consolidatedBilling: true,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 120.0, spend)
+	assert.Equal(t, map[string]float64{"Amazon SageMaker": 100, "EC2 - Other": 20}, serviceCosts)
+}
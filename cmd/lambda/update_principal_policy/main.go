@@ -38,6 +38,7 @@ func init() {
 
 	_, err = svcBldr.
 		WithAccountService().
+		WithLeaseProfileDataService().
 		Build()
 	if err != nil {
 		panic(err)
@@ -51,27 +52,45 @@ func main() {
 }
 
 func handler(ctx context.Context, snsEvent events.SNSEvent) error {
-	var lease lease.Lease
+	var l lease.Lease
 
 	for _, record := range snsEvent.Records {
 		snsRecord := record.SNS
 
-		err := json.Unmarshal([]byte(snsRecord.Message), &lease)
+		err := json.Unmarshal([]byte(snsRecord.Message), &l)
 		if err != nil {
 			log.Printf("Failed to read SNS message %s: %s", snsRecord.Message, err.Error())
 			return errors.NewInternalServer("unexpected error parsing SNS message", err)
 		}
 
-		acct, err := services.AccountService().Get(*lease.AccountID)
+		acct, err := services.AccountService().Get(*l.AccountID)
 		if err != nil {
 			return err
 		}
 
+		// Leases created with a profile get the profile's policy and regions
+		if l.Profile != nil {
+			profile, err := services.LeaseProfileData().Get(*l.Profile)
+			if err != nil {
+				return err
+			}
+			acct.PrincipalPolicyKey = profile.PrincipalPolicyS3Key
+			acct.AllowedRegions = profile.AllowedRegions
+		}
+
 		err = services.AccountService().UpsertPrincipalAccess(acct)
 		if err != nil {
 			return err
 		}
 
+		// A new lease shouldn't inherit the freeze from a previous lease,
+		// but a frozen lease stays frozen when its access is updated
+		if l.Status != nil && *l.Status == lease.StatusActive {
+			err = services.AccountService().UnfreezePrincipalAccess(acct)
+			if err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
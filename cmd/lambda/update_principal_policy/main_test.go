@@ -8,7 +8,10 @@ import (
 	"github.com/Optum/dce/pkg/account"
 	"github.com/Optum/dce/pkg/account/accountiface/mocks"
 	"github.com/Optum/dce/pkg/config"
+	dataMocks "github.com/Optum/dce/pkg/data/dataiface/mocks"
 	"github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -17,13 +20,17 @@ import (
 func TestUpdatePrincipalPolicy(t *testing.T) {
 
 	tests := []struct {
-		name      string
-		acctID    string
-		input     events.SNSEvent
-		getAcct   *account.Account
-		getErr    error
-		upsertErr error
-		expErr    error
+		name        string
+		acctID      string
+		input       events.SNSEvent
+		getAcct     *account.Account
+		getErr      error
+		getProfile  *lease.Profile
+		getProfErr  error
+		upsertErr   error
+		unfreezeErr error
+		expUnfreeze bool
+		expErr      error
 	}{
 		{
 			name:   "when valid lease provided upsert happens",
@@ -38,6 +45,45 @@ func TestUpdatePrincipalPolicy(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "when lease with a profile provided upsert happens with the profile's policy",
+			acctID: "123456789012",
+			input: events.SNSEvent{
+				Records: []events.SNSEventRecord{
+					{
+						SNS: events.SNSEntity{
+							Message: "{\"accountId\": \"123456789012\", \"profile\": \"ml-large\"}",
+						},
+					},
+				},
+			},
+			getAcct: &account.Account{
+				ID: aws.String("123456789012"),
+			},
+			getProfile: &lease.Profile{
+				Name:                 aws.String("ml-large"),
+				AllowedRegions:       &[]string{"us-west-2"},
+				PrincipalPolicyS3Key: aws.String("ml_large_policy.tmpl"),
+			},
+		},
+		{
+			name:   "when lease with a missing profile provided return error",
+			acctID: "123456789012",
+			input: events.SNSEvent{
+				Records: []events.SNSEventRecord{
+					{
+						SNS: events.SNSEntity{
+							Message: "{\"accountId\": \"123456789012\", \"profile\": \"ml-large\"}",
+						},
+					},
+				},
+			},
+			getAcct: &account.Account{
+				ID: aws.String("123456789012"),
+			},
+			getProfErr: errors.NewNotFound("lease profile", "ml-large"),
+			expErr:     errors.NewNotFound("lease profile", "ml-large"),
+		},
 		{
 			name: "when invalid lease provided an error occurs",
 			input: events.SNSEvent{
@@ -81,6 +127,49 @@ func TestUpdatePrincipalPolicy(t *testing.T) {
 			upsertErr: errors.NewInternalServer("failure", fmt.Errorf("error")),
 			expErr:    errors.NewInternalServer("failure", fmt.Errorf("error")),
 		},
+		{
+			name:   "when an active lease provided the freeze from a previous lease is lifted",
+			acctID: "123456789012",
+			input: events.SNSEvent{
+				Records: []events.SNSEventRecord{
+					{
+						SNS: events.SNSEntity{
+							Message: "{\"accountId\": \"123456789012\", \"leaseStatus\": \"Active\"}",
+						},
+					},
+				},
+			},
+			expUnfreeze: true,
+		},
+		{
+			name:   "when a frozen lease provided the freeze is kept",
+			acctID: "123456789012",
+			input: events.SNSEvent{
+				Records: []events.SNSEventRecord{
+					{
+						SNS: events.SNSEntity{
+							Message: "{\"accountId\": \"123456789012\", \"leaseStatus\": \"Frozen\"}",
+						},
+					},
+				},
+			},
+		},
+		{
+			name:   "when an active lease provided but there is an error lifting the freeze",
+			acctID: "123456789012",
+			input: events.SNSEvent{
+				Records: []events.SNSEventRecord{
+					{
+						SNS: events.SNSEntity{
+							Message: "{\"accountId\": \"123456789012\", \"leaseStatus\": \"Active\"}",
+						},
+					},
+				},
+			},
+			unfreezeErr: errors.NewInternalServer("failure", fmt.Errorf("error")),
+			expUnfreeze: true,
+			expErr:      errors.NewInternalServer("failure", fmt.Errorf("error")),
+		},
 	}
 
 	// Iterate through each test in the list
@@ -92,8 +181,12 @@ func TestUpdatePrincipalPolicy(t *testing.T) {
 		acctServiceMock := mocks.Servicer{}
 		acctServiceMock.On("Get", tt.acctID).Return(tt.getAcct, tt.getErr)
 		acctServiceMock.On("UpsertPrincipalAccess", tt.getAcct).Return(tt.upsertErr)
+		acctServiceMock.On("UnfreezePrincipalAccess", tt.getAcct).Return(tt.unfreezeErr)
 
-		svcBldr.Config.WithService(&acctServiceMock)
+		profileDataMock := dataMocks.LeaseProfileData{}
+		profileDataMock.On("Get", "ml-large").Return(tt.getProfile, tt.getProfErr)
+
+		svcBldr.Config.WithService(&acctServiceMock).WithService(&profileDataMock)
 		_, err := svcBldr.Build()
 		assert.Nil(t, err)
 		if err == nil {
@@ -102,5 +195,14 @@ func TestUpdatePrincipalPolicy(t *testing.T) {
 
 		err = handler(context.TODO(), tt.input)
 		assert.True(t, errors.Is(err, tt.expErr))
+		if tt.expUnfreeze {
+			acctServiceMock.AssertCalled(t, "UnfreezePrincipalAccess", tt.getAcct)
+		} else {
+			acctServiceMock.AssertNotCalled(t, "UnfreezePrincipalAccess", tt.getAcct)
+		}
+		if tt.getProfile != nil {
+			assert.Equal(t, tt.getProfile.PrincipalPolicyS3Key, tt.getAcct.PrincipalPolicyKey)
+			assert.Equal(t, tt.getProfile.AllowedRegions, tt.getAcct.AllowedRegions)
+		}
 	}
 }
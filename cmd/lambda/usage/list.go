@@ -35,7 +35,7 @@ func GetUsage(w http.ResponseWriter, r *http.Request) {
 	// Serialize them for the JSON response.
 	usageResponseItems := []response.UsageResponse{}
 	for _, usageItem := range result.Results {
-		usageResponseItems = append(usageResponseItems, response.UsageResponse{
+		usageResponseItem := response.UsageResponse{
 			PrincipalID:  *usageItem.PrincipalID,
 			AccountID:    *usageItem.AccountID,
 			StartDate:    *usageItem.StartDate,
@@ -43,7 +43,11 @@ func GetUsage(w http.ResponseWriter, r *http.Request) {
 			CostAmount:   *usageItem.CostAmount,
 			CostCurrency: *usageItem.CostCurrency,
 			TimeToLive:   *usageItem.TimeToLive,
-		})
+		}
+		if usageItem.LeaseID != nil {
+			usageResponseItem.LeaseID = *usageItem.LeaseID
+		}
+		usageResponseItems = append(usageResponseItems, usageResponseItem)
 	}
 
 	// If the DB result has next keys, then the URL to retrieve the next page is put into the Link header.
@@ -101,9 +105,9 @@ func parseGetUsageInput(r *http.Request) (usage.GetUsageInput, error) {
 		query.StartKeys["StartDate"] = nextStartDate
 	}
 
-	nextPrincipalID := r.FormValue(NextPrincipalIDParam)
-	if len(nextPrincipalID) > 0 {
-		query.StartKeys["PrincipalId"] = nextPrincipalID
+	nextUsageID := r.FormValue(NextUsageIDParam)
+	if len(nextUsageID) > 0 {
+		query.StartKeys["UsageId"] = nextUsageID
 	}
 
 	return query, nil
@@ -8,6 +8,8 @@ import (
 	"log"
 
 	"github.com/Optum/dce/pkg/api"
+	"github.com/Optum/dce/pkg/config"
+	"github.com/Optum/dce/pkg/lease/leaseiface"
 	"github.com/Optum/dce/pkg/usage"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -16,20 +18,24 @@ import (
 )
 
 const (
-	StartDateParam       = "startDate"
-	EndDateParam         = "endDate"
-	PrincipalIDParam     = "principalId"
-	AccountIDParam       = "accountId"
-	NextPrincipalIDParam = "nextPrincipalId"
-	NextStartDateParam   = "nextStartDate"
-	LimitParam           = "limit"
+	StartDateParam     = "startDate"
+	EndDateParam       = "endDate"
+	PrincipalIDParam   = "principalId"
+	AccountIDParam     = "accountId"
+	NextUsageIDParam   = "nextUsageId"
+	NextStartDateParam = "nextStartDate"
+	LimitParam         = "limit"
+	GroupByParam       = "groupBy"
+	NextOffsetParam    = "nextOffset"
 )
 
 var muxLambda *gorillamux.GorillaMuxAdapter
 
 var (
 	// UsageSvc - Service for getting usage
-	UsageSvc    *usage.DB
+	UsageSvc *usage.DB
+	// LeaseSvc - Service for listing the leases usage is grouped by
+	LeaseSvc    usage.LeaseLister
 	baseRequest url.URL
 )
 
@@ -38,6 +44,13 @@ func init() {
 
 	usageRoutes := api.Routes{
 
+		api.Route{
+			Name:        "GetUsageSummary",
+			Method:      "GET",
+			Pattern:     "/usage/summary",
+			Queries:     api.EmptyQueryString,
+			HandlerFunc: GetUsageSummary,
+		},
 		api.Route{
 			Name:        "GetUsageByStartDateAndEndDate",
 			Method:      "GET",
@@ -80,6 +93,7 @@ func Handler(ctx context.Context, req events.APIGatewayProxyRequest) (events.API
 func main() {
 
 	UsageSvc = newUsage()
+	LeaseSvc = newLeaseService()
 
 	lambda.Start(Handler)
 }
@@ -93,3 +107,19 @@ func newUsage() *usage.DB {
 
 	return usageSvc
 }
+
+func newLeaseService() leaseiface.Servicer {
+	cfgBldr := &config.ConfigurationBuilder{}
+	err := cfgBldr.WithEnv("AWS_CURRENT_REGION", "AWS_CURRENT_REGION", "us-east-1").Build()
+	if err != nil {
+		log.Fatalf("Failed to initialize configuration: %s", err)
+	}
+
+	svcBldr := &config.ServiceBuilder{Config: cfgBldr}
+	_, err = svcBldr.WithLeaseService().Build()
+	if err != nil {
+		log.Fatalf("Failed to initialize lease service: %s", err)
+	}
+
+	return svcBldr.LeaseService()
+}
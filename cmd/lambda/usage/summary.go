@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Optum/dce/pkg/api"
+	"github.com/Optum/dce/pkg/api/response"
+	"github.com/Optum/dce/pkg/usage"
+)
+
+// GetUsageSummary - Returns the total cost of usage, grouped by principal, account, lease,
+// day, week, month or lease metadata
+func GetUsageSummary(w http.ResponseWriter, r *http.Request) {
+
+	summaryInput, err := parseSummaryInput(r, time.Now())
+	if err != nil {
+		response.WriteRequestValidationError(w, fmt.Sprintf("Error parsing query params: %s", err))
+		return
+	}
+
+	summary, err := UsageSvc.GetUsageSummary(summaryInput, LeaseSvc)
+	if err != nil {
+		api.WriteAPIErrorResponse(w, err)
+		return
+	}
+
+	// If there are more groups, then the URL to retrieve the next page is put into the Link header.
+	if summary.NextOffset != nil {
+		nextURL := response.BuildNextURL(r, map[string]string{"Offset": strconv.FormatInt(*summary.NextOffset, 10)}, baseRequest)
+		w.Header().Add("Link", fmt.Sprintf("<%s>; rel=\"next\"", nextURL.String()))
+	}
+
+	api.WriteAPIResponse(w, http.StatusOK, summary)
+}
+
+// parseSummaryInput creates a SummaryInput from the query parameters.
+// Without dates, usage is summarized from the start of the month until now.
+func parseSummaryInput(r *http.Request, now time.Time) (usage.SummaryInput, error) {
+	now = now.UTC()
+	input := usage.SummaryInput{
+		StartDate:   time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+		EndDate:     now,
+		PrincipalID: r.FormValue(PrincipalIDParam),
+		AccountID:   r.FormValue(AccountIDParam),
+		GroupBy:     []string{},
+	}
+
+	for param, value := range map[string]*time.Time{StartDateParam: &input.StartDate, EndDateParam: &input.EndDate} {
+		if r.FormValue(param) == "" {
+			continue
+		}
+		i, err := strconv.ParseInt(r.FormValue(param), 10, 64)
+		if err != nil {
+			return input, fmt.Errorf("invalid %s: %s", param, err)
+		}
+		*value = time.Unix(i, 0).UTC()
+	}
+
+	for param, value := range map[string]*int64{LimitParam: &input.Limit, NextOffsetParam: &input.Offset} {
+		if r.FormValue(param) == "" {
+			continue
+		}
+		i, err := strconv.ParseInt(r.FormValue(param), 10, 64)
+		if err != nil {
+			return input, fmt.Errorf("invalid %s: %s", param, err)
+		}
+		*value = i
+	}
+
+	for _, groupBy := range strings.Split(r.FormValue(GroupByParam), ",") {
+		if strings.TrimSpace(groupBy) != "" {
+			input.GroupBy = append(input.GroupBy, strings.TrimSpace(groupBy))
+		}
+	}
+
+	return input, nil
+}
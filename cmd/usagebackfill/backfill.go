@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Optum/dce/pkg/budget"
+	"github.com/Optum/dce/pkg/currency"
+	"github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/lease/leaseiface"
+	"github.com/Optum/dce/pkg/usage"
+)
+
+// Actions taken to correct a usage record
+const (
+	changeAdd    = "ADD"
+	changeUpdate = "UPDATE"
+)
+
+type backfillInput struct {
+	leaseSvc          leaseiface.Servicer
+	budgetSvc         budget.Service
+	usageSvc          usage.DBer
+	currencyConverter *currency.Converter
+	usageTTL          int       // TTL in seconds for new Usage DynamoDB records
+	startDate         time.Time // First day to backfill
+	endDate           time.Time // Last day to backfill, included
+	accountID         string    // Only backfill this account's usage, if set
+	principalID       string    // Only backfill this principal's usage, if set
+	dryRun            bool      // Report the changes, without writing them
+}
+
+// usageChange is a usage record which is missing, or differs from the recomputed one
+type usageChange struct {
+	action   string
+	existing *usage.Usage // Existing record, for updates
+	record   usage.Usage  // Recomputed record
+}
+
+// backfillUsage recomputes the usage of each lease, for each day, from the budget service,
+// and writes the records which are missing or differ from the existing ones.
+// Usage records are keyed by StartDate and UsageId, which identifies the lease, so they're reconciled by those too.
+func backfillUsage(input *backfillInput) ([]usageChange, error) {
+	query := &lease.Lease{}
+	if input.accountID != "" {
+		query.AccountID = &input.accountID
+	}
+	if input.principalID != "" {
+		query.PrincipalID = &input.principalID
+	}
+	leases := lease.Leases{}
+	err := input.leaseSvc.ListPages(query, func(page *lease.Leases) bool {
+		leases = append(leases, *page...)
+		return true // always continue
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the leased accounts' spend is read
+	accountIDs := []string{}
+	for _, l := range leases {
+		if l.AccountID != nil {
+			accountIDs = append(accountIDs, *l.AccountID)
+		}
+	}
+	input.budgetSvc.SetAccounts(accountIDs)
+
+	existingRecords, err := input.usageSvc.GetUsageByDateRange(input.startDate, input.endDate)
+	if err != nil {
+		return nil, err
+	}
+	existing := map[string]*usage.Usage{}
+	for _, record := range existingRecords {
+		if record.StartDate != nil && record.PrincipalID != nil {
+			existing[usageKey(*record.StartDate, record.ID())] = record
+		}
+	}
+
+	var errs []error
+	changes := []usageChange{}
+	startDate := input.startDate.UTC()
+	day := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, time.UTC)
+	for ; !day.After(input.endDate); day = day.AddDate(0, 0, 1) {
+		dayEnd := time.Date(day.Year(), day.Month(), day.Day(), 23, 59, 59, 0, time.UTC)
+
+		accountCosts, err := input.budgetSvc.CalculateSpendByAccount(day, day.AddDate(0, 0, 1))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, l := range usage.LeasesOnDay(leases, day, dayEnd) {
+			record, err := usage.NewLeaseUsage(usage.NewLeaseUsageInput{
+				Lease:        l,
+				StartDate:    day,
+				EndDate:      dayEnd,
+				TimeToLive:   day.Add(time.Duration(input.usageTTL) * time.Second).Unix(),
+				ServiceCosts: accountCosts[*l.AccountID],
+				Converter:    input.currencyConverter,
+			})
+			if err != nil {
+				log.Printf("Failed to recompute usage for lease %s @ %s on %s: %s",
+					*l.PrincipalID, *l.AccountID, day.Format("2006-01-02"), err)
+				errs = append(errs, err)
+				continue
+			}
+
+			old, ok := existing[usageKey(*record.StartDate, record.ID())]
+			if !ok {
+				changes = append(changes, usageChange{action: changeAdd, record: *record})
+				continue
+			}
+			if usageDiffers(old, record) {
+				// Keep the record expiring when it would have
+				if old.TimeToLive != nil {
+					record.TimeToLive = old.TimeToLive
+				}
+				changes = append(changes, usageChange{action: changeUpdate, existing: old, record: *record})
+			}
+		}
+	}
+
+	if !input.dryRun && len(changes) > 0 {
+		records := make([]usage.Usage, 0, len(changes))
+		for _, change := range changes {
+			records = append(records, change.record)
+		}
+		err = input.usageSvc.PutUsageBatch(records)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return changes, errors.NewMultiError("error when backfilling usage", errs)
+	}
+	return changes, nil
+}
+
+// usageKey identifies a usage record by the usage table's keys
+func usageKey(startDate int64, usageID string) string {
+	return fmt.Sprintf("%d-%s", startDate, usageID)
+}
+
+// usageDiffers is true when the existing record's account or costs differ from the recomputed record's
+func usageDiffers(existing *usage.Usage, record *usage.Usage) bool {
+	return stringValue(existing.AccountID) != stringValue(record.AccountID) ||
+		!costEqual(existing.CostAmount, record.CostAmount) ||
+		stringValue(existing.CostCurrency) != stringValue(record.CostCurrency) ||
+		!costEqual(existing.ConvertedCostAmount, record.ConvertedCostAmount) ||
+		stringValue(existing.ConvertedCostCurrency) != stringValue(record.ConvertedCostCurrency)
+}
+
+// costEqual compares costs to the cent
+func costEqual(a *float64, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return math.Abs(*a-*b) < 0.005
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// writeReport prints each change, and how many records were, or would be, written
+func writeReport(w io.Writer, changes []usageChange, dryRun bool) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "DATE\tPRINCIPAL\tACCOUNT\tACTION\tOLD COST\tNEW COST\tDIFFERENCE")
+	adds, updates := 0, 0
+	for _, change := range changes {
+		oldCost := 0.0
+		oldCostText := "-"
+		if change.existing != nil && change.existing.CostAmount != nil {
+			oldCost = *change.existing.CostAmount
+			oldCostText = fmt.Sprintf("%.2f", oldCost)
+		}
+		if change.action == changeAdd {
+			adds++
+		} else {
+			updates++
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%.2f\t%+.2f\n",
+			time.Unix(*change.record.StartDate, 0).UTC().Format("2006-01-02"),
+			*change.record.PrincipalID,
+			*change.record.AccountID,
+			change.action,
+			oldCostText,
+			*change.record.CostAmount,
+			*change.record.CostAmount-oldCost,
+		)
+	}
+	err := table.Flush()
+	if err != nil {
+		return err
+	}
+
+	summary := fmt.Sprintf("%d usage records added, %d updated", adds, updates)
+	if dryRun {
+		summary = fmt.Sprintf("Dry run: %d usage records would be added, %d updated", adds, updates)
+	}
+	_, err = fmt.Fprintln(w, summary)
+	return err
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	budgetMocks "github.com/Optum/dce/pkg/budget/mocks"
+	"github.com/Optum/dce/pkg/currency"
+	"github.com/Optum/dce/pkg/lease"
+	leaseMocks "github.com/Optum/dce/pkg/lease/leaseiface/mocks"
+	"github.com/Optum/dce/pkg/usage"
+	usageMocks "github.com/Optum/dce/pkg/usage/mocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackfillUsage(t *testing.T) {
+	may14 := time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC)
+	may15 := may14.AddDate(0, 0, 1)
+	may16 := may14.AddDate(0, 0, 2)
+
+	leases := lease.Leases{
+		{
+			ID:               aws.String("lease1"),
+			PrincipalID:      aws.String("user1"),
+			AccountID:        aws.String("123456789012"),
+			Status:           lease.StatusInactive.StatusPtr(),
+			CreatedOn:        aws.Int64(may14.AddDate(0, 0, -10).Unix()),
+			StatusModifiedOn: aws.Int64(may15.Add(2 * time.Hour).Unix()),
+		},
+		{
+			// Leased the account when user1's lease ended
+			ID:             aws.String("lease2"),
+			PrincipalID:    aws.String("user2"),
+			AccountID:      aws.String("123456789012"),
+			Status:         lease.StatusActive.StatusPtr(),
+			CreatedOn:      aws.Int64(may15.Add(3 * time.Hour).Unix()),
+			BudgetCurrency: aws.String("EUR"),
+		},
+	}
+	existing := []*usage.Usage{
+		{
+			PrincipalID:  aws.String("user1"),
+			AccountID:    aws.String("123456789012"),
+			LeaseID:      aws.String("lease1"),
+			StartDate:    aws.Int64(may14.Unix()),
+			CostAmount:   aws.Float64(10),
+			CostCurrency: aws.String("USD"),
+			TimeToLive:   aws.Int64(42),
+		},
+	}
+	spend := map[time.Time]map[string]map[string]float64{
+		// Restated from 10 to 12
+		may14: {"123456789012": {"Amazon SageMaker": 12}},
+		may15: {"123456789012": {"Amazon SageMaker": 20}},
+		may16: {"123456789012": {"Amazon SageMaker": 4}},
+	}
+
+	tests := []struct {
+		name        string
+		dryRun      bool
+		expChanges  []string
+		expWritten  bool
+		expPutError error
+		expErr      bool
+	}{
+		{
+			name:       "should add missing usage and update restated usage",
+			expChanges: []string{"UPDATE user1 2024-05-14 12", "ADD user2 2024-05-15 20", "ADD user2 2024-05-16 4"},
+			expWritten: true,
+		},
+		{
+			name:       "should only report changes in a dry run",
+			dryRun:     true,
+			expChanges: []string{"UPDATE user1 2024-05-14 12", "ADD user2 2024-05-15 20", "ADD user2 2024-05-16 4"},
+		},
+		{
+			name:        "should fail when the records can't be written",
+			expChanges:  []string{"UPDATE user1 2024-05-14 12", "ADD user2 2024-05-15 20", "ADD user2 2024-05-16 4"},
+			expWritten:  true,
+			expPutError: errors.New("throttled"),
+			expErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaseSvc := &leaseMocks.Servicer{}
+			budgetSvc := &budgetMocks.Service{}
+			budgetSvc.On("SetAccounts", mock.Anything)
+			usageSvc := &usageMocks.DBer{}
+
+			leaseSvc.On("ListPages", &lease.Lease{AccountID: aws.String("123456789012")}, mock.Anything).
+				Run(func(args mock.Arguments) {
+					args.Get(1).(func(*lease.Leases) bool)(&leases)
+				}).
+				Return(nil)
+			usageSvc.On("GetUsageByDateRange", may14, may16).Return(existing, nil)
+			for day, accountCosts := range spend {
+				budgetSvc.On("CalculateSpendByAccount", day, day.AddDate(0, 0, 1)).Return(accountCosts, nil)
+			}
+			usageSvc.On("PutUsageBatch", mock.Anything).Return(tt.expPutError)
+
+			changes, err := backfillUsage(&backfillInput{
+				leaseSvc:  leaseSvc,
+				budgetSvc: budgetSvc,
+				usageSvc:  usageSvc,
+				currencyConverter: &currency.Converter{
+					BaseCurrency: "USD",
+					Provider:     currency.StaticRates{"EUR": 0.5},
+				},
+				usageTTL:  3600,
+				startDate: may14,
+				endDate:   may16,
+				accountID: "123456789012",
+				dryRun:    tt.dryRun,
+			})
+
+			if tt.expErr {
+				assert.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+			}
+
+			actual := []string{}
+			for _, change := range changes {
+				actual = append(actual, change.action+" "+*change.record.PrincipalID+" "+
+					time.Unix(*change.record.StartDate, 0).UTC().Format("2006-01-02")+" "+
+					formatCost(*change.record.CostAmount))
+			}
+			assert.Equal(t, tt.expChanges, actual)
+			assert.Equal(t, "lease2", *changes[1].record.LeaseID)
+			// Updated records keep their TTL, new records expire after the usage TTL
+			assert.Equal(t, int64(42), *changes[0].record.TimeToLive)
+			assert.Equal(t, may15.Unix()+3600, *changes[1].record.TimeToLive)
+			assert.Equal(t, 10.0, *changes[1].record.ConvertedCostAmount)
+
+			if tt.expWritten {
+				usageSvc.AssertCalled(t, "PutUsageBatch", mock.MatchedBy(func(records []usage.Usage) bool {
+					return len(records) == 3
+				}))
+			} else {
+				usageSvc.AssertNotCalled(t, "PutUsageBatch", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	changes := []usageChange{
+		{
+			action:   changeUpdate,
+			existing: &usage.Usage{CostAmount: aws.Float64(10)},
+			record: usage.Usage{PrincipalID: aws.String("user1"), AccountID: aws.String("123456789012"),
+				StartDate: aws.Int64(time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC).Unix()), CostAmount: aws.Float64(12)},
+		},
+		{
+			action: changeAdd,
+			record: usage.Usage{PrincipalID: aws.String("user2"), AccountID: aws.String("123456789012"),
+				StartDate: aws.Int64(time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC).Unix()), CostAmount: aws.Float64(20)},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	err := writeReport(buf, changes, true)
+	assert.Nil(t, err)
+	assert.Equal(t, ""+
+		"DATE        PRINCIPAL  ACCOUNT       ACTION  OLD COST  NEW COST  DIFFERENCE\n"+
+		"2024-05-14  user1      123456789012  UPDATE  10.00     12.00     +2.00\n"+
+		"2024-05-15  user2      123456789012  ADD     -         20.00     +20.00\n"+
+		"Dry run: 1 usage records would be added, 1 updated\n", buf.String())
+}
+
+func formatCost(cost float64) string {
+	return strconv.FormatFloat(cost, 'f', -1, 64)
+}
@@ -0,0 +1,106 @@
+// Package main is the usagebackfill command, which recomputes the usage records of leases
+// for a date range, to fill in days the LeaseUsage table is missing, or correct restated spend.
+//
+// It reads the same environment variables as the collect_usage lambda, eg. USAGE_CACHE_DB and LEASE_DB,
+// and reads spend from consolidated billing, so run it with the master account's credentials:
+//
+//	usagebackfill -start 2024-05-01 -end 2024-05-31 [-account 123456789012] [-principal jdoe] [-dry-run]
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Optum/dce/pkg/budget"
+	"github.com/Optum/dce/pkg/common"
+	"github.com/Optum/dce/pkg/config"
+	"github.com/Optum/dce/pkg/currency"
+	"github.com/Optum/dce/pkg/usage"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/costexplorer"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// dateLayout is the layout of the -start and -end dates
+const dateLayout = "2006-01-02"
+
+func main() {
+	start := flag.String("start", "", "First day to backfill, eg. 2024-05-01 (required)")
+	end := flag.String("end", "", "Last day to backfill, included. Defaults to the start date")
+	accountID := flag.String("account", "", "Only backfill the usage of this account")
+	principalID := flag.String("principal", "", "Only backfill the usage of this principal")
+	dryRun := flag.Bool("dry-run", false, "Report the changes, without writing them")
+	flag.Parse()
+
+	startDate, err := time.Parse(dateLayout, *start)
+	if err != nil {
+		log.Fatalf("Invalid -start date %q, expected YYYY-MM-DD: %s", *start, err)
+	}
+	endDate := startDate
+	if *end != "" {
+		endDate, err = time.Parse(dateLayout, *end)
+		if err != nil {
+			log.Fatalf("Invalid -end date %q, expected YYYY-MM-DD: %s", *end, err)
+		}
+	}
+	if endDate.Before(startDate) {
+		log.Fatalf("The -end date must not be before the -start date")
+	}
+
+	awsSession := session.Must(session.NewSession())
+
+	usageSvc, err := usage.NewFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure Usage service %s", err)
+	}
+
+	s3Svc := &common.S3{
+		Client:  s3.New(awsSession),
+		Manager: s3manager.NewDownloader(awsSession),
+	}
+
+	budgetSvc, err := budget.NewServiceFromEnv(s3Svc)
+	if err != nil {
+		log.Fatalf("Failed to configure Budget service %s", err)
+	}
+	budgetSvc.SetCostExplorer(costexplorer.New(awsSession))
+
+	cfgBldr := &config.ConfigurationBuilder{}
+	err = cfgBldr.WithEnv("AWS_CURRENT_REGION", "AWS_CURRENT_REGION", "us-east-1").Build()
+	if err != nil {
+		log.Fatalf("Failed to configure services %s", err)
+	}
+	svcBldr := &config.ServiceBuilder{Config: cfgBldr}
+	_, err = svcBldr.WithLeaseService().Build()
+	if err != nil {
+		log.Fatalf("Failed to configure Lease service %s", err)
+	}
+
+	currencyConverter, err := currency.NewConverterFromEnv(s3Svc)
+	if err != nil {
+		log.Fatalf("Failed to configure currency converter %s", err)
+	}
+
+	changes, err := backfillUsage(&backfillInput{
+		leaseSvc:          svcBldr.LeaseService(),
+		budgetSvc:         budgetSvc,
+		usageSvc:          usageSvc,
+		currencyConverter: currencyConverter,
+		usageTTL:          common.RequireEnvInt("USAGE_TTL"),
+		startDate:         startDate,
+		endDate:           endDate,
+		accountID:         *accountID,
+		principalID:       *principalID,
+		dryRun:            *dryRun,
+	})
+	reportErr := writeReport(os.Stdout, changes, *dryRun)
+	if reportErr != nil {
+		log.Printf("Failed to write the report: %s", reportErr)
+	}
+	if err != nil {
+		log.Fatalf("Failed to backfill usage: %s", err)
+	}
+}
@@ -0,0 +1,47 @@
+module "export_chargeback_lambda" {
+  source          = "./lambda"
+  name            = "export_chargeback-${var.namespace}"
+  namespace       = var.namespace
+  description     = "Exports the previous month's usage, with the metadata of each lease, to S3 as CSV and Parquet"
+  global_tags     = var.global_tags
+  handler         = "export_chargeback"
+  alarm_topic_arn = aws_sns_topic.alarms_topic.arn
+
+  environment = {
+    AWS_CURRENT_REGION       = var.aws_region
+    ACCOUNT_DB               = aws_dynamodb_table.accounts.id
+    LEASE_DB                 = aws_dynamodb_table.leases.id
+    LEASE_HISTORY_DB         = aws_dynamodb_table.lease_history.id
+    USAGE_CACHE_DB           = aws_dynamodb_table.lease_usage.id
+    CHARGEBACK_BUCKET        = var.chargeback_bucket != "" ? var.chargeback_bucket : aws_s3_bucket.artifacts.id
+    CHARGEBACK_PREFIX        = var.chargeback_prefix
+    CHARGEBACK_METADATA_KEYS = join(",", var.chargeback_metadata_keys)
+    CHARGEBACK_EMAILS        = join(",", var.chargeback_emails)
+    CHARGEBACK_FROM_EMAIL    = var.budget_notification_from_email
+  }
+}
+
+// Allow export_chargeback lambda to email the report, with its attachment
+resource "aws_iam_role_policy" "export_chargeback_ses" {
+  role   = module.export_chargeback_lambda.execution_role_name
+  policy = <<POLICY
+{
+    "Version": "2012-10-17",
+    "Statement": [{
+      "Effect": "Allow",
+      "Action": ["ses:SendRawEmail"],
+      "Resource": "*"
+    }]
+}
+POLICY
+}
+
+// Run the export_chargeback lambda on a timer (cloudwatch event), after each month
+module "export_chargeback_lambda_schedule" {
+  source              = "./cloudwatch_event"
+  name                = "export_chargeback-${var.namespace}"
+  lambda_function_arn = module.export_chargeback_lambda.arn
+  schedule_expression = var.chargeback_export_schedule_expression
+  description         = "Exports the previous month's usage for chargeback"
+  enabled             = var.chargeback_export_enabled
+}
@@ -22,6 +22,7 @@ resource "aws_cloudwatch_event_target" "dbbackup" {
   target_id = var.name
 }
 resource "aws_lambda_permission" "dbbackup" {
+  count         = var.enabled ? 1 : 0
   statement_id  = "AllowExecutionFromCloudWatch"
   action        = "lambda:InvokeFunction"
   function_name = local.lambda_function_name
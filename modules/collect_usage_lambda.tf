@@ -0,0 +1,61 @@
+module "collect_usage_lambda" {
+  source          = "./lambda"
+  name            = "collect_usage-${var.namespace}"
+  namespace       = var.namespace
+  description     = "Records the usage of every leased account, from the consolidated billing account's Cost Explorer or Cost and Usage Report"
+  global_tags     = var.global_tags
+  handler         = "collect_usage"
+  alarm_topic_arn = aws_sns_topic.alarms_topic.arn
+
+  environment = {
+    AWS_CURRENT_REGION                   = var.aws_region
+    ACCOUNT_DB                           = aws_dynamodb_table.accounts.id
+    LEASE_DB                             = aws_dynamodb_table.leases.id
+    LEASE_HISTORY_DB                     = aws_dynamodb_table.lease_history.id
+    USAGE_CACHE_DB                       = aws_dynamodb_table.lease_usage.id
+    PRINCIPAL_USAGE_DB                   = aws_dynamodb_table.principal_usage.id
+    PRINCIPAL_BUDGET_PERIOD              = var.principal_budget_period
+    PRINCIPAL_BUDGET_PERIOD_WEEK_START   = var.principal_budget_period_week_start
+    PRINCIPAL_BUDGET_PERIOD_ROLLING_DAYS = var.principal_budget_period_rolling_days
+    PRINCIPAL_BUDGET_TIME_ZONE           = var.principal_budget_time_zone
+    USAGE_TTL                            = var.usage_ttl
+    USAGE_LOOKBACK_DAYS                  = var.usage_lookback_days
+    SPEND_SOURCE                         = var.spend_source
+    CUR_BUCKET                           = var.cur_bucket
+    CUR_PREFIX                           = var.cur_prefix
+    CUR_REPORT_NAME                      = var.cur_report_name
+    CUR_FORMAT                           = var.cur_format
+    COST_CURRENCY                        = var.cost_currency
+    COST_METRIC                          = var.cost_metric
+    COST_EXCLUDED_RECORD_TYPES           = join(",", var.cost_excluded_record_types)
+    CURRENCY_RATES                       = join(",", [for currency, rate in var.currency_rates : "${currency}=${rate}"])
+    CURRENCY_RATES_BUCKET                = local.budget_notification_templates_bucket
+    CURRENCY_RATES_KEY                   = var.currency_rates_s3_key
+  }
+}
+
+// Allow collect_usage lambda to read the spend of linked accounts from Cost Explorer
+resource "aws_iam_role_policy" "collect_usage_cost_explorer" {
+  role   = module.collect_usage_lambda.execution_role_name
+  policy = <<POLICY
+{
+    "Version": "2012-10-17",
+    "Statement": [{
+      "Effect": "Allow",
+      "Action": ["ce:GetCostAndUsage"],
+      "Resource": "*"
+    }]
+}
+POLICY
+}
+
+// Run the collect_usage lambda on a timer (cloudwatch event), when using consolidated billing
+// or the Cost and Usage Report
+module "collect_usage_lambda_schedule" {
+  source              = "./cloudwatch_event"
+  name                = "collect_usage-${var.namespace}"
+  lambda_function_arn = module.collect_usage_lambda.arn
+  schedule_expression = var.collect_usage_schedule_expression
+  description         = "Records the usage of every leased account from consolidated billing"
+  enabled             = var.consolidated_billing || var.spend_source == "CUR"
+}
@@ -139,6 +139,126 @@ resource "aws_dynamodb_table" "leases" {
   */
 }
 
+# Lease Request table
+# Queues lease requests made while there are no Ready accounts
+resource "aws_dynamodb_table" "lease_requests" {
+  name           = "LeaseRequests${local.table_suffix}"
+  read_capacity  = var.leases_table_rcu
+  write_capacity = var.leases_table_wcu
+  hash_key       = "Id"
+
+  server_side_encryption {
+    enabled = true
+  }
+
+  global_secondary_index {
+    name            = "RequestStatusCreatedOn"
+    hash_key        = "RequestStatus"
+    range_key       = "CreatedOn"
+    projection_type = "ALL"
+    read_capacity   = var.leases_table_rcu
+    write_capacity  = var.leases_table_wcu
+  }
+
+  # Lease Request ID
+  attribute {
+    name = "Id"
+    type = "S"
+  }
+
+  # Lease Request status.
+  # May be one of:
+  # - Pending
+  # - Fulfilled
+  # - Failed
+  attribute {
+    name = "RequestStatus"
+    type = "S"
+  }
+
+  # Created On
+  attribute {
+    name = "CreatedOn"
+    type = "N"
+  }
+
+  tags = var.global_tags
+  /*
+  Other attributes:
+    - PrincipalId (string)
+    - LeaseId (string)
+    - AccountId (string)
+    - LastModifiedOn (Integer, epoch timestamps)
+  */
+}
+
+resource "aws_dynamodb_table" "lease_profiles" {
+  name           = "LeaseProfiles${local.table_suffix}"
+  read_capacity  = var.leases_table_rcu
+  write_capacity = var.leases_table_wcu
+  hash_key       = "Name"
+
+  server_side_encryption {
+    enabled = true
+  }
+
+  # Lease Profile name, eg. "sandbox-small"
+  attribute {
+    name = "Name"
+    type = "S"
+  }
+
+  tags = var.global_tags
+  /*
+  Other attributes:
+    - DefaultBudgetAmount (Number)
+    - MaxBudgetAmount (Number)
+    - MaxLeasePeriod (Integer, seconds)
+    - AllowedRegions (List of strings)
+    - PrincipalPolicyS3Key (string)
+    - CreatedOn (Integer, epoch timestamps)
+    - LastModifiedOn (Integer, epoch timestamps)
+  */
+}
+
+resource "aws_dynamodb_table" "lease_history" {
+  name           = "LeaseHistory${local.table_suffix}"
+  read_capacity  = var.leases_table_rcu
+  write_capacity = var.leases_table_wcu
+  hash_key       = "LeaseId"
+  range_key      = "Id"
+
+  server_side_encryption {
+    enabled = true
+  }
+
+  # Lease ID
+  attribute {
+    name = "LeaseId"
+    type = "S"
+  }
+
+  # Lease History record ID
+  attribute {
+    name = "Id"
+    type = "S"
+  }
+
+  tags = var.global_tags
+  /*
+  Other attributes:
+    - CreatedOn (Integer, epoch timestamps)
+    - Actor (string, the user who made the change or "system")
+    - PrevLeaseStatus / LeaseStatus (string)
+    - PrevLeaseStatusReason / LeaseStatusReason (string)
+    - PrevExpiresOn / ExpiresOn (Integer, epoch timestamps)
+    - PrevBudgetAmount / BudgetAmount (Number)
+  */
+}
+
+# Usage recorded by principal, before it was recorded by lease.
+# Deprecated: kept so its records can be copied to the LeaseUsage table
+# with scripts/migrations/v0.42.0_db_usage_per_lease, and will be destroyed in a subsequent release.
 resource "aws_dynamodb_table" "usage" {
   name             = "Usage${local.table_suffix}"
   read_capacity    = var.usage_table_rcu
@@ -164,6 +284,117 @@ resource "aws_dynamodb_table" "usage" {
     type = "N"
   }
 
+  # Query a principal's usage for a date range
+  global_secondary_index {
+    name            = "PrincipalIdStartDate"
+    hash_key        = "PrincipalId"
+    range_key       = "StartDate"
+    projection_type = "ALL"
+    read_capacity   = var.usage_table_rcu
+    write_capacity  = var.usage_table_wcu
+  }
+
+  # TTL enabled attribute
+  ttl {
+    attribute_name = "TimeToLive"
+    enabled        = true
+  }
+
+  tags = var.global_tags
+}
+
+# Each lease's usage, by day
+resource "aws_dynamodb_table" "lease_usage" {
+  name             = "LeaseUsage${local.table_suffix}"
+  read_capacity    = var.usage_table_rcu
+  write_capacity   = var.usage_table_wcu
+  hash_key         = "StartDate"
+  range_key        = "UsageId"
+  stream_enabled   = true
+  stream_view_type = "NEW_AND_OLD_IMAGES"
+
+  server_side_encryption {
+    enabled = true
+  }
+
+  # User Principal ID
+  attribute {
+    name = "PrincipalId"
+    type = "S"
+  }
+
+  # AWS usage cost amount for start date as epoch timestamp
+  attribute {
+    name = "StartDate"
+    type = "N"
+  }
+
+  # Identifies the record among the day's usage, eg. <PrincipalId>#<LeaseId>
+  attribute {
+    name = "UsageId"
+    type = "S"
+  }
+
+  # Lease ID
+  attribute {
+    name = "LeaseId"
+    type = "S"
+  }
+
+  # Query a principal's usage for a date range
+  global_secondary_index {
+    name            = "PrincipalIdStartDate"
+    hash_key        = "PrincipalId"
+    range_key       = "StartDate"
+    projection_type = "ALL"
+    read_capacity   = var.usage_table_rcu
+    write_capacity  = var.usage_table_wcu
+  }
+
+  # Query a lease's usage for a date range
+  global_secondary_index {
+    name            = "LeaseIdStartDate"
+    hash_key        = "LeaseId"
+    range_key       = "StartDate"
+    projection_type = "ALL"
+    read_capacity   = var.usage_table_rcu
+    write_capacity  = var.usage_table_wcu
+  }
+
+  # TTL enabled attribute
+  ttl {
+    attribute_name = "TimeToLive"
+    enabled        = true
+  }
+
+  tags = var.global_tags
+}
+
+# Each principal's usage total for a principal budget period,
+# updated whenever their usage is written
+resource "aws_dynamodb_table" "principal_usage" {
+  name           = "PrincipalUsage${local.table_suffix}"
+  read_capacity  = var.usage_table_rcu
+  write_capacity = var.usage_table_wcu
+  hash_key       = "PrincipalId"
+  range_key      = "PeriodKey"
+
+  server_side_encryption {
+    enabled = true
+  }
+
+  # User Principal ID
+  attribute {
+    name = "PrincipalId"
+    type = "S"
+  }
+
+  # Budget period type, start date and time zone, eg. WEEKLY#2024-05-12#UTC
+  attribute {
+    name = "PeriodKey"
+    type = "S"
+  }
+
   # TTL enabled attribute
   ttl {
     attribute_name = "TimeToLive"
@@ -171,4 +402,12 @@ resource "aws_dynamodb_table" "usage" {
   }
 
   tags = var.global_tags
+  /*
+  Other attributes:
+    - PeriodStart (Integer, epoch timestamp)
+    - DailyCosts (Map of usage start date epoch timestamp and lease ID to cost amount)
+    - TotalCostAmount (Number)
+    - CostCurrency (String)
+    - Version (Integer)
+  */
 }
@@ -8,24 +8,77 @@ module "leases_lambda" {
   alarm_topic_arn = aws_sns_topic.alarms_topic.arn
 
   environment = {
-    DEBUG                              = "false"
-    NAMESPACE                          = var.namespace
-    AWS_CURRENT_REGION                 = var.aws_region
-    RESET_SQS_URL                      = aws_sqs_queue.account_reset.id
-    ACCOUNT_DB                         = aws_dynamodb_table.accounts.id
-    LEASE_DB                           = aws_dynamodb_table.leases.id
-    LEASE_ADDED_TOPIC                  = aws_sns_topic.lease_added.arn
-    DECOMMISSION_TOPIC                 = aws_sns_topic.lease_removed.arn
-    COGNITO_USER_POOL_ID               = module.api_gateway_authorizer.user_pool_id
-    COGNITO_ROLES_ATTRIBUTE_ADMIN_NAME = var.cognito_roles_attribute_admin_name
-    MAX_LEASE_BUDGET_AMOUNT            = var.max_lease_budget_amount
-    MAX_LEASE_PERIOD                   = var.max_lease_period
-    PRINCIPAL_BUDGET_AMOUNT            = var.principal_budget_amount
-    PRINCIPAL_BUDGET_PERIOD            = var.principal_budget_period
-    USAGE_CACHE_DB                     = aws_dynamodb_table.usage.id
+    DEBUG                                = "false"
+    NAMESPACE                            = var.namespace
+    AWS_CURRENT_REGION                   = var.aws_region
+    RESET_SQS_URL                        = aws_sqs_queue.account_reset.id
+    ACCOUNT_DB                           = aws_dynamodb_table.accounts.id
+    LEASE_DB                             = aws_dynamodb_table.leases.id
+    LEASE_REQUEST_DB                     = aws_dynamodb_table.lease_requests.id
+    LEASE_PROFILE_DB                     = aws_dynamodb_table.lease_profiles.id
+    LEASE_HISTORY_DB                     = aws_dynamodb_table.lease_history.id
+    LEASE_ADDED_TOPIC                    = aws_sns_topic.lease_added.arn
+    DECOMMISSION_TOPIC                   = aws_sns_topic.lease_removed.arn
+    COGNITO_USER_POOL_ID                 = module.api_gateway_authorizer.user_pool_id
+    COGNITO_ROLES_ATTRIBUTE_ADMIN_NAME   = var.cognito_roles_attribute_admin_name
+    MAX_LEASE_BUDGET_AMOUNT              = var.max_lease_budget_amount
+    MAX_LEASE_PERIOD                     = var.max_lease_period
+    MAX_LEASE_EXTENSIONS                 = var.max_lease_extensions
+    PRINCIPAL_BUDGET_AMOUNT              = var.principal_budget_amount
+    PRINCIPAL_BUDGET_PERIOD              = var.principal_budget_period
+    PRINCIPAL_BUDGET_PERIOD_WEEK_START   = var.principal_budget_period_week_start
+    PRINCIPAL_BUDGET_PERIOD_ROLLING_DAYS = var.principal_budget_period_rolling_days
+    PRINCIPAL_BUDGET_TIME_ZONE           = var.principal_budget_time_zone
+    USAGE_CACHE_DB                       = aws_dynamodb_table.lease_usage.id
+    PRINCIPAL_USAGE_DB                   = aws_dynamodb_table.principal_usage.id
+    ACCOUNT_SELECTION_STRATEGY           = var.account_selection_strategy
+    ACCOUNT_SELECTION_METADATA_KEYS      = join(",", var.account_selection_metadata_keys)
+    MAX_ACTIVE_LEASES                    = var.max_active_leases
+    MAX_ACTIVE_LEASES_BY_GROUP           = join(",", [for group, max in var.max_active_leases_by_group : "${group}:${max}"])
+    AUTO_APPROVE_BUDGET_AMOUNT           = var.auto_approve_budget_amount
+    AUTO_APPROVE_LEASE_PERIOD            = var.auto_approve_lease_period
+    LEASE_APPROVAL_TIMEOUT               = var.lease_approval_timeout
+    LEASE_APPROVER_EMAILS                = join(",", var.lease_approver_emails)
+    LEASE_APPROVAL_FROM_EMAIL            = var.budget_notification_from_email
+    BUDGET_NOTIFICATION_TEMPLATES_BUCKET = local.budget_notification_templates_bucket
+    LEASE_APPROVAL_TEMPLATE_HTML_KEY     = aws_s3_object.lease_approval_template_html.key
+    LEASE_APPROVAL_TEMPLATE_TEXT_KEY     = aws_s3_object.lease_approval_template_text.key
+    LEASE_APPROVAL_TEMPLATE_SUBJECT      = var.lease_approval_template_subject
+    SUPPORTED_CURRENCIES                 = join(",", var.supported_currencies)
+    COST_CURRENCY                        = var.cost_currency
+    CURRENCY_RATES                       = join(",", [for currency, rate in var.currency_rates : "${currency}=${rate}"])
+    CURRENCY_RATES_BUCKET                = local.budget_notification_templates_bucket
+    CURRENCY_RATES_KEY                   = var.currency_rates_s3_key
   }
 }
 
+// Allow the leases lambda to email approvers with SES
+resource "aws_iam_role_policy" "leases_ses" {
+  role   = module.leases_lambda.execution_role_name
+  policy = <<POLICY
+{
+    "Version": "2012-10-17",
+    "Statement": [{
+      "Effect": "Allow",
+      "Action": ["ses:SendEmail"],
+      "Resource": "*"
+    }]
+}
+POLICY
+}
+
+// Upload lease approval email templates to S3
+resource "aws_s3_object" "lease_approval_template_html" {
+  bucket  = local.budget_notification_templates_bucket
+  key     = "lease_approval_templates/html.tmpl"
+  content = var.lease_approval_template_html
+}
+resource "aws_s3_object" "lease_approval_template_text" {
+  bucket  = local.budget_notification_templates_bucket
+  key     = "lease_approval_templates/text.tmpl"
+  content = var.lease_approval_template_text
+}
+
 resource "aws_sns_topic" "lease_added" {
   name              = "lease-added-${var.namespace}"
   kms_master_key_id = local.sns_encryption_key_id
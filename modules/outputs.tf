@@ -14,12 +14,36 @@ output "leases_table_arn" {
   value = aws_dynamodb_table.leases.arn
 }
 
+output "lease_requests_table_name" {
+  value = aws_dynamodb_table.lease_requests.name
+}
+
+output "lease_requests_table_arn" {
+  value = aws_dynamodb_table.lease_requests.arn
+}
+
+output "lease_profiles_table_name" {
+  value = aws_dynamodb_table.lease_profiles.name
+}
+
+output "lease_profiles_table_arn" {
+  value = aws_dynamodb_table.lease_profiles.arn
+}
+
+output "lease_history_table_name" {
+  value = aws_dynamodb_table.lease_history.name
+}
+
+output "lease_history_table_arn" {
+  value = aws_dynamodb_table.lease_history.arn
+}
+
 output "usage_table_name" {
-  value = aws_dynamodb_table.usage.name
+  value = aws_dynamodb_table.lease_usage.name
 }
 
 output "usage_table_arn" {
-  value = aws_dynamodb_table.usage.arn
+  value = aws_dynamodb_table.lease_usage.arn
 }
 
 output "sqs_reset_queue_url" {
@@ -95,6 +95,192 @@ resource "aws_codebuild_project" "reset_build" {
       type  = "PLAINTEXT"
     }
 
+    environment_variable {
+      name  = "LEASE_REQUEST_DB"
+      value = aws_dynamodb_table.lease_requests.id
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "LEASE_PROFILE_DB"
+      value = aws_dynamodb_table.lease_profiles.id
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "LEASE_HISTORY_DB"
+      value = aws_dynamodb_table.lease_history.id
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "USAGE_CACHE_DB"
+      value = aws_dynamodb_table.lease_usage.id
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "PRINCIPAL_USAGE_DB"
+      value = aws_dynamodb_table.principal_usage.id
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "LEASE_ADDED_TOPIC"
+      value = aws_sns_topic.lease_added.arn
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "LEASE_REQUEST_FROM_EMAIL"
+      value = var.budget_notification_from_email
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "LEASE_REQUEST_FULFILLED_TEMPLATE_HTML_KEY"
+      value = aws_s3_object.lease_request_fulfilled_template_html.key
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "LEASE_REQUEST_FULFILLED_TEMPLATE_TEXT_KEY"
+      value = aws_s3_object.lease_request_fulfilled_template_text.key
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "LEASE_REQUEST_FULFILLED_TEMPLATE_SUBJECT"
+      value = var.lease_request_fulfilled_template_subject
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "PRINCIPAL_BUDGET_AMOUNT"
+      value = var.principal_budget_amount
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "PRINCIPAL_BUDGET_PERIOD"
+      value = var.principal_budget_period
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "PRINCIPAL_BUDGET_PERIOD_WEEK_START"
+      value = var.principal_budget_period_week_start
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "PRINCIPAL_BUDGET_PERIOD_ROLLING_DAYS"
+      value = var.principal_budget_period_rolling_days
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "PRINCIPAL_BUDGET_TIME_ZONE"
+      value = var.principal_budget_time_zone
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "MAX_LEASE_BUDGET_AMOUNT"
+      value = var.max_lease_budget_amount
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "MAX_LEASE_PERIOD"
+      value = var.max_lease_period
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "AUTO_APPROVE_BUDGET_AMOUNT"
+      value = var.auto_approve_budget_amount
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "AUTO_APPROVE_LEASE_PERIOD"
+      value = var.auto_approve_lease_period
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "LEASE_APPROVAL_TIMEOUT"
+      value = var.lease_approval_timeout
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "LEASE_APPROVER_EMAILS"
+      value = join(",", var.lease_approver_emails)
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "LEASE_APPROVAL_FROM_EMAIL"
+      value = var.budget_notification_from_email
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "BUDGET_NOTIFICATION_TEMPLATES_BUCKET"
+      value = local.budget_notification_templates_bucket
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "LEASE_APPROVAL_TEMPLATE_HTML_KEY"
+      value = aws_s3_object.lease_approval_template_html.key
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "LEASE_APPROVAL_TEMPLATE_TEXT_KEY"
+      value = aws_s3_object.lease_approval_template_text.key
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "LEASE_APPROVAL_TEMPLATE_SUBJECT"
+      value = var.lease_approval_template_subject
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "SUPPORTED_CURRENCIES"
+      value = join(",", var.supported_currencies)
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "COST_CURRENCY"
+      value = var.cost_currency
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "CURRENCY_RATES"
+      value = join(",", [for currency, rate in var.currency_rates : "${currency}=${rate}"])
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "CURRENCY_RATES_BUCKET"
+      value = local.budget_notification_templates_bucket
+      type  = "PLAINTEXT"
+    }
+
+    environment_variable {
+      name  = "CURRENCY_RATES_KEY"
+      value = var.currency_rates_s3_key
+      type  = "PLAINTEXT"
+    }
+
     environment_variable {
       name  = "AWS_CURRENT_REGION"
       value = var.aws_region
@@ -175,7 +361,9 @@ resource "aws_iam_role_policy" "codebuild_reset" {
         "dynamodb:Scan",
         "dynamodb:Query",
         "dynamodb:UpdateItem",
-        "sns:Publish"
+        "dynamodb:PutItem",
+        "sns:Publish",
+        "ses:SendEmail"
       ]
     },
     {
@@ -198,6 +386,18 @@ POLICY
 
 }
 
+// Upload lease request fulfilled email templates to S3
+resource "aws_s3_object" "lease_request_fulfilled_template_html" {
+  bucket  = local.budget_notification_templates_bucket
+  key     = "lease_request_fulfilled_templates/html.tmpl"
+  content = var.lease_request_fulfilled_template_html
+}
+resource "aws_s3_object" "lease_request_fulfilled_template_text" {
+  bucket  = local.budget_notification_templates_bucket
+  key     = "lease_request_fulfilled_templates/text.tmpl"
+  content = var.lease_request_fulfilled_template_text
+}
+
 # Cloudwatch alarm, for Reset CodeBuild failure
 resource "aws_cloudwatch_metric_alarm" "reset_failed_builds" {
   alarm_name = "reset-codebuild-failures-${var.namespace}"
@@ -15,6 +15,9 @@ module "fan_out_update_lease_status_lambda" {
     AWS_CURRENT_REGION                = var.aws_region
     ACCOUNT_DB                        = aws_dynamodb_table.accounts.id
     LEASE_DB                          = aws_dynamodb_table.leases.id
+    LEASE_HISTORY_DB                  = aws_dynamodb_table.lease_history.id
+    RESET_SQS_URL                     = aws_sqs_queue.account_reset.id
+    LEASE_APPROVAL_TIMEOUT            = var.lease_approval_timeout
     UPDATE_LEASE_STATUS_FUNCTION_NAME = module.update_lease_status_lambda.name
   }
 }
@@ -35,22 +38,63 @@ module "update_lease_status_lambda" {
   alarm_topic_arn = aws_sns_topic.alarms_topic.arn
 
   environment = {
-    AWS_CURRENT_REGION                        = var.aws_region
-    ACCOUNT_DB                                = aws_dynamodb_table.accounts.id
-    LEASE_DB                                  = aws_dynamodb_table.leases.id
-    USAGE_CACHE_DB                            = aws_dynamodb_table.usage.id
-    RESET_QUEUE_URL                           = aws_sqs_queue.account_reset.id
-    LEASE_LOCKED_TOPIC_ARN                    = aws_sns_topic.lease_locked.arn
-    BUDGET_NOTIFICATION_FROM_EMAIL            = var.budget_notification_from_email
-    BUDGET_NOTIFICATION_BCC_EMAILS            = join(",", var.budget_notification_bcc_emails)
-    BUDGET_NOTIFICATION_TEMPLATES_BUCKET      = local.budget_notification_templates_bucket
-    BUDGET_NOTIFICATION_TEMPLATE_HTML_KEY     = aws_s3_object.budget_notification_template_html.key
-    BUDGET_NOTIFICATION_TEMPLATE_TEXT_KEY     = aws_s3_object.budget_notification_template_text.key
-    BUDGET_NOTIFICATION_TEMPLATE_SUBJECT      = var.budget_notification_template_subject
-    BUDGET_NOTIFICATION_THRESHOLD_PERCENTILES = join(",", var.budget_notification_threshold_percentiles)
-    PRINCIPAL_BUDGET_AMOUNT                   = var.principal_budget_amount
-    PRINCIPAL_BUDGET_PERIOD                   = var.principal_budget_period
-    USAGE_TTL                                 = var.usage_ttl
+    AWS_CURRENT_REGION                                  = var.aws_region
+    ACCOUNT_DB                                          = aws_dynamodb_table.accounts.id
+    LEASE_DB                                            = aws_dynamodb_table.leases.id
+    LEASE_HISTORY_DB                                    = aws_dynamodb_table.lease_history.id
+    USAGE_CACHE_DB                                      = aws_dynamodb_table.lease_usage.id
+    PRINCIPAL_USAGE_DB                                  = aws_dynamodb_table.principal_usage.id
+    RESET_QUEUE_URL                                     = aws_sqs_queue.account_reset.id
+    LEASE_LOCKED_TOPIC_ARN                              = aws_sns_topic.lease_locked.arn
+    BUDGET_NOTIFICATION_FROM_EMAIL                      = var.budget_notification_from_email
+    BUDGET_NOTIFICATION_BCC_EMAILS                      = join(",", var.budget_notification_bcc_emails)
+    BUDGET_NOTIFICATION_TEMPLATES_BUCKET                = local.budget_notification_templates_bucket
+    BUDGET_NOTIFICATION_TEMPLATE_HTML_KEY               = aws_s3_object.budget_notification_template_html.key
+    BUDGET_NOTIFICATION_TEMPLATE_TEXT_KEY               = aws_s3_object.budget_notification_template_text.key
+    BUDGET_NOTIFICATION_TEMPLATE_SUBJECT                = var.budget_notification_template_subject
+    BUDGET_NOTIFICATION_THRESHOLD_PERCENTILES           = join(",", var.budget_notification_threshold_percentiles)
+    PRINCIPAL_BUDGET_NOTIFICATION_TEMPLATE_HTML_KEY     = aws_s3_object.principal_budget_notification_template_html.key
+    PRINCIPAL_BUDGET_NOTIFICATION_TEMPLATE_TEXT_KEY     = aws_s3_object.principal_budget_notification_template_text.key
+    PRINCIPAL_BUDGET_NOTIFICATION_TEMPLATE_SUBJECT      = var.principal_budget_notification_template_subject
+    PRINCIPAL_BUDGET_NOTIFICATION_THRESHOLD_PERCENTILES = join(",", var.principal_budget_notification_threshold_percentiles)
+    PRINCIPAL_BUDGET_AMOUNT                             = var.principal_budget_amount
+    PRINCIPAL_BUDGET_PERIOD                             = var.principal_budget_period
+    PRINCIPAL_BUDGET_PERIOD_WEEK_START                  = var.principal_budget_period_week_start
+    PRINCIPAL_BUDGET_PERIOD_ROLLING_DAYS                = var.principal_budget_period_rolling_days
+    PRINCIPAL_BUDGET_TIME_ZONE                          = var.principal_budget_time_zone
+    USAGE_TTL                                           = var.usage_ttl
+    LEASE_FREEZE_PERIOD                                 = var.lease_freeze_period
+    LEASE_FROZEN_TEMPLATE_HTML_KEY                      = aws_s3_object.lease_frozen_template_html.key
+    LEASE_FROZEN_TEMPLATE_TEXT_KEY                      = aws_s3_object.lease_frozen_template_text.key
+    LEASE_FROZEN_TEMPLATE_SUBJECT                       = var.lease_frozen_template_subject
+    LEASE_IDLE_DAYS                                     = var.lease_idle_days
+    LEASE_IDLE_SPEND_FLOOR                              = var.lease_idle_spend_floor
+    LEASE_IDLE_WARNING_PERIOD                           = var.lease_idle_warning_period
+    LEASE_IDLE_TEMPLATE_HTML_KEY                        = aws_s3_object.lease_idle_template_html.key
+    LEASE_IDLE_TEMPLATE_TEXT_KEY                        = aws_s3_object.lease_idle_template_text.key
+    LEASE_IDLE_TEMPLATE_SUBJECT                         = var.lease_idle_template_subject
+    FORECAST_METHOD                                     = var.lease_forecast_method
+    FORECAST_MARGIN                                     = var.lease_forecast_margin
+    FORECAST_ACTION                                     = var.lease_forecast_action
+    FORECAST_NOTIFICATION_TEMPLATE_HTML_KEY             = aws_s3_object.forecast_notification_template_html.key
+    FORECAST_NOTIFICATION_TEMPLATE_TEXT_KEY             = aws_s3_object.forecast_notification_template_text.key
+    FORECAST_NOTIFICATION_TEMPLATE_SUBJECT              = var.forecast_notification_template_subject
+    EXPIRY_NOTIFICATION_THRESHOLD_HOURS                 = join(",", var.expiry_notification_threshold_hours)
+    EXPIRY_NOTIFICATION_TEMPLATE_HTML_KEY               = aws_s3_object.expiry_notification_template_html.key
+    EXPIRY_NOTIFICATION_TEMPLATE_TEXT_KEY               = aws_s3_object.expiry_notification_template_text.key
+    EXPIRY_NOTIFICATION_TEMPLATE_SUBJECT                = var.expiry_notification_template_subject
+    COST_CURRENCY                                       = var.cost_currency
+    COST_METRIC                                         = var.cost_metric
+    COST_EXCLUDED_RECORD_TYPES                          = join(",", var.cost_excluded_record_types)
+    CURRENCY_RATES                                      = join(",", [for currency, rate in var.currency_rates : "${currency}=${rate}"])
+    CURRENCY_RATES_BUCKET                               = local.budget_notification_templates_bucket
+    CURRENCY_RATES_KEY                                  = var.currency_rates_s3_key
+    CONSOLIDATED_BILLING                                = var.consolidated_billing
+    SPEND_SOURCE                                        = var.spend_source
+    CUR_BUCKET                                          = var.cur_bucket
+    CUR_PREFIX                                          = var.cur_prefix
+    CUR_REPORT_NAME                                     = var.cur_report_name
+    CUR_FORMAT                                          = var.cur_format
   }
 }
 
@@ -66,6 +110,64 @@ resource "aws_s3_object" "budget_notification_template_text" {
   key     = "budget_notification_templates/text.tmpl"
   content = var.budget_notification_template_text
 }
+resource "aws_s3_object" "principal_budget_notification_template_html" {
+  bucket  = local.budget_notification_templates_bucket
+  key     = "principal_budget_notification_templates/html.tmpl"
+  content = var.principal_budget_notification_template_html
+}
+resource "aws_s3_object" "principal_budget_notification_template_text" {
+  bucket  = local.budget_notification_templates_bucket
+  key     = "principal_budget_notification_templates/text.tmpl"
+  content = var.principal_budget_notification_template_text
+}
+
+// Upload lease expiry notification email templates to S3
+resource "aws_s3_object" "expiry_notification_template_html" {
+  bucket  = local.budget_notification_templates_bucket
+  key     = "expiry_notification_templates/html.tmpl"
+  content = var.expiry_notification_template_html
+}
+resource "aws_s3_object" "expiry_notification_template_text" {
+  bucket  = local.budget_notification_templates_bucket
+  key     = "expiry_notification_templates/text.tmpl"
+  content = var.expiry_notification_template_text
+}
+
+// Upload lease forecast notification email templates to S3
+resource "aws_s3_object" "forecast_notification_template_html" {
+  bucket  = local.budget_notification_templates_bucket
+  key     = "forecast_notification_templates/html.tmpl"
+  content = var.forecast_notification_template_html
+}
+resource "aws_s3_object" "forecast_notification_template_text" {
+  bucket  = local.budget_notification_templates_bucket
+  key     = "forecast_notification_templates/text.tmpl"
+  content = var.forecast_notification_template_text
+}
+
+// Upload lease frozen email templates to S3
+resource "aws_s3_object" "lease_frozen_template_html" {
+  bucket  = local.budget_notification_templates_bucket
+  key     = "lease_frozen_templates/html.tmpl"
+  content = var.lease_frozen_template_html
+}
+resource "aws_s3_object" "lease_frozen_template_text" {
+  bucket  = local.budget_notification_templates_bucket
+  key     = "lease_frozen_templates/text.tmpl"
+  content = var.lease_frozen_template_text
+}
+
+// Upload lease idle email templates to S3
+resource "aws_s3_object" "lease_idle_template_html" {
+  bucket  = local.budget_notification_templates_bucket
+  key     = "lease_idle_templates/html.tmpl"
+  content = var.lease_idle_template_html
+}
+resource "aws_s3_object" "lease_idle_template_text" {
+  bucket  = local.budget_notification_templates_bucket
+  key     = "lease_idle_templates/text.tmpl"
+  content = var.lease_idle_template_text
+}
 
 // Allow update_lease_status lambda to send emails with SES
 resource "aws_iam_role_policy" "check_buget_ses" {
@@ -14,6 +14,7 @@ module "update_principal_policy" {
     AWS_CURRENT_REGION             = var.aws_region
     ACCOUNT_DB                     = aws_dynamodb_table.accounts.id
     LEASE_DB                       = aws_dynamodb_table.leases.id
+    LEASE_PROFILE_DB               = aws_dynamodb_table.lease_profiles.id
     ARTIFACTS_BUCKET               = aws_s3_bucket.artifacts.id
     PRINCIPAL_ROLE_NAME            = local.principal_role_name
     PRINCIPAL_POLICY_NAME          = local.principal_policy_name
@@ -65,7 +66,10 @@ resource "aws_iam_role_policy" "update_principal_policy" {
         "Action": [
             "dynamodb:GetItem"
         ],
-        "Resource": "${aws_dynamodb_table.accounts.arn}"
+        "Resource": [
+            "${aws_dynamodb_table.accounts.arn}",
+            "${aws_dynamodb_table.lease_profiles.arn}"
+        ]
     },
     {
         "Effect": "Allow",
@@ -11,6 +11,9 @@ module "usage_lambda" {
     DEBUG              = "false"
     NAMESPACE          = var.namespace
     AWS_CURRENT_REGION = var.aws_region
-    USAGE_CACHE_DB     = aws_dynamodb_table.usage.id
+    USAGE_CACHE_DB     = aws_dynamodb_table.lease_usage.id
+    ACCOUNT_DB         = aws_dynamodb_table.accounts.id
+    LEASE_DB           = aws_dynamodb_table.leases.id
+    LEASE_HISTORY_DB   = aws_dynamodb_table.lease_history.id
   }
 }
@@ -111,6 +111,12 @@ variable "budget_notification_template_html" {
 {{if .IsOverBudget}}
 Lease for principal {{.Lease.PrincipalID}} in AWS Account {{.Lease.AccountID}}
 has exceeded its budget of $${{.Lease.BudgetAmount}}. Actual spend is $${{.ActualSpend}}
+{{if .ServiceCosts}}
+<br/>Top services by spend:
+<ul>
+{{range .ServiceCosts}}<li>{{.Service}}: $${{printf "%.2f" .Amount}}</li>
+{{end}}</ul>
+{{end}}
 {{else}}
 Lease for principal {{.Lease.PrincipalID}} in AWS Account {{.Lease.AccountID}}
 has exceeded the {{.ThresholdPercentile}}% threshold limit for its budget of $${{.Lease.BudgetAmount}}.
@@ -127,6 +133,10 @@ variable "budget_notification_template_text" {
 {{if .IsOverBudget}}
 Lease for principal {{.Lease.PrincipalID}} in AWS Account {{.Lease.AccountID}}
 has exceeded its budget of $${{.Lease.BudgetAmount}}. Actual spend is $${{.ActualSpend}}
+{{if .ServiceCosts}}
+Top services by spend:
+{{range .ServiceCosts}}- {{.Service}}: $${{printf "%.2f" .Amount}}
+{{end}}{{end}}
 {{else}}
 Lease for principal {{.Lease.PrincipalID}} in AWS Account {{.Lease.AccountID}}
 has exceeded the {{.ThresholdPercentile}}% threshold limit for its budget of $${{.Lease.BudgetAmount}}.
@@ -149,6 +159,54 @@ variable "budget_notification_threshold_percentiles" {
   default     = [75, 100]
 }
 
+variable "principal_budget_notification_threshold_percentiles" {
+  type        = list(number)
+  description = "Thresholds (percentiles of principal_budget_amount) at which principal budget notification emails will be sent to users."
+  default     = [75, 100]
+}
+
+variable "principal_budget_notification_template_html" {
+  type        = string
+  description = "HTML template for principal budget notification emails"
+  default     = <<TMPL
+<p>
+{{if .IsOverBudget}}
+Principal {{.Lease.PrincipalID}} has spent $${{.ActualSpend}}, exceeding their principal budget of $${{.PrincipalBudgetAmount}}
+for the budget period of {{.PeriodStart}} to {{.PeriodEnd}}.
+Their leases will be ended, and no new leases can be created for them until the next budget period.
+{{else}}
+Principal {{.Lease.PrincipalID}} has exceeded the {{.ThresholdPercentile}}% threshold limit of their principal budget of $${{.PrincipalBudgetAmount}}
+for the budget period of {{.PeriodStart}} to {{.PeriodEnd}}.
+Actual spend is $${{.ActualSpend}}
+{{end}}
+</p>
+TMPL
+}
+
+variable "principal_budget_notification_template_text" {
+  type        = string
+  description = "Text template for principal budget notification emails"
+  default     = <<TMPL
+{{if .IsOverBudget}}
+Principal {{.Lease.PrincipalID}} has spent $${{.ActualSpend}}, exceeding their principal budget of $${{.PrincipalBudgetAmount}}
+for the budget period of {{.PeriodStart}} to {{.PeriodEnd}}.
+Their leases will be ended, and no new leases can be created for them until the next budget period.
+{{else}}
+Principal {{.Lease.PrincipalID}} has exceeded the {{.ThresholdPercentile}}% threshold limit of their principal budget of $${{.PrincipalBudgetAmount}}
+for the budget period of {{.PeriodStart}} to {{.PeriodEnd}}.
+Actual spend is $${{.ActualSpend}}
+{{end}}
+TMPL
+}
+
+variable "principal_budget_notification_template_subject" {
+  type        = string
+  description = "Template for principal budget notification email subject"
+  default     = <<SUBJ
+Principal budget {{if .IsOverBudget}}exhausted{{else}}at {{.ThresholdPercentile}}%{{end}} [{{.Lease.PrincipalID}}]
+SUBJ
+}
+
 variable "principal_policy" {
   type        = string
   description = "Location of file with the policy to be attached to principal IAM users"
@@ -204,6 +262,330 @@ variable "max_lease_period" {
   default     = 604800
 }
 
+variable "max_lease_extensions" {
+  type        = number
+  description = "Maximum number of times a lease may be extended"
+  default     = 3
+}
+
+variable "max_active_leases" {
+  type        = number
+  description = "Maximum number of Active leases a principal may have at once"
+  default     = 1
+}
+
+variable "max_active_leases_by_group" {
+  type        = map(number)
+  description = "Overrides for max_active_leases, by the principal's Cognito group. The most generous matching group wins."
+  default     = {}
+}
+
+variable "auto_approve_budget_amount" {
+  type        = number
+  description = "Leases with a larger budget amount wait for an admin to approve them. 0 disables the approval workflow for budgets."
+  default     = 0
+}
+
+variable "auto_approve_lease_period" {
+  type        = number
+  description = "Leases longer than this (seconds) wait for an admin to approve them. 0 disables the approval workflow for lease periods."
+  default     = 0
+}
+
+variable "lease_approval_timeout" {
+  type        = number
+  description = "Seconds until a lease which nobody approved or rejected expires"
+  default     = 259200
+}
+
+variable "lease_approver_emails" {
+  type        = list(string)
+  description = "Email addresses notified of leases waiting for approval"
+  default     = []
+}
+
+variable "lease_approval_template_html" {
+  type        = string
+  description = "HTML template for the emails to approvers about leases waiting for approval"
+  default     = <<TMPL
+<p>
+Lease {{.Lease.ID}} for principal {{.Lease.PrincipalID}} in AWS Account {{.Lease.AccountID}}
+is waiting for approval.
+</p>
+<p>
+Budget: {{printf "%.2f" .BudgetAmount}} {{.Lease.BudgetCurrency}}<br/>
+Expires on: {{.ExpiresOn}}
+</p>
+<p>
+Approve it with POST /leases/{{.Lease.ID}}/approve, or reject it with POST /leases/{{.Lease.ID}}/reject.
+If nobody approves it by {{.ApproveBy}}, the lease will be rejected.
+</p>
+TMPL
+}
+
+variable "lease_approval_template_text" {
+  type        = string
+  description = "Text template for the emails to approvers about leases waiting for approval"
+  default     = <<TMPL
+Lease {{.Lease.ID}} for principal {{.Lease.PrincipalID}} in AWS Account {{.Lease.AccountID}}
+is waiting for approval.
+
+Budget: {{printf "%.2f" .BudgetAmount}} {{.Lease.BudgetCurrency}}
+Expires on: {{.ExpiresOn}}
+
+Approve it with POST /leases/{{.Lease.ID}}/approve, or reject it with POST /leases/{{.Lease.ID}}/reject.
+If nobody approves it by {{.ApproveBy}}, the lease will be rejected.
+TMPL
+}
+
+variable "lease_approval_template_subject" {
+  type        = string
+  description = "Template for the subject of the emails to approvers about leases waiting for approval"
+  default     = <<SUBJ
+Lease approval requested by {{.Lease.PrincipalID}} [{{.Lease.AccountID}}]
+SUBJ
+}
+
+variable "lease_request_fulfilled_template_html" {
+  type        = string
+  description = "HTML template for the emails to users whose lease request was fulfilled"
+  default     = <<TMPL
+<p>
+Your lease request {{.Request.ID}} has been fulfilled.
+</p>
+<p>
+Account {{.Lease.AccountID}} is now leased to {{.Lease.PrincipalID}}, with lease ID {{.Lease.ID}}.<br/>
+The lease expires on {{.ExpiresOn}}.
+</p>
+{{if .IsPending}}<p>
+The lease is waiting for an admin to approve it, and can't be used until then.
+</p>{{end}}
+TMPL
+}
+
+variable "lease_request_fulfilled_template_text" {
+  type        = string
+  description = "Text template for the emails to users whose lease request was fulfilled"
+  default     = <<TMPL
+Your lease request {{.Request.ID}} has been fulfilled.
+
+Account {{.Lease.AccountID}} is now leased to {{.Lease.PrincipalID}}, with lease ID {{.Lease.ID}}.
+The lease expires on {{.ExpiresOn}}.
+{{if .IsPending}}The lease is waiting for an admin to approve it, and can't be used until then.
+{{end}}
+TMPL
+}
+
+variable "lease_request_fulfilled_template_subject" {
+  type        = string
+  description = "Template for the subject of the emails to users whose lease request was fulfilled"
+  default     = <<SUBJ
+Lease request fulfilled for {{.Lease.PrincipalID}}
+SUBJ
+}
+
+variable "lease_freeze_period" {
+  type        = number
+  description = "Seconds an over budget lease is frozen, so the principal can export their data, before its account is reset. 0 resets the account right away"
+  default     = 0
+}
+
+variable "lease_frozen_template_html" {
+  type        = string
+  description = "HTML template for the emails telling users their lease is frozen"
+  default     = <<TMPL
+<p>
+Lease for principal {{.Lease.PrincipalID}} in AWS Account {{.Lease.AccountID}}
+{{if .IsForecast}}is forecast to spend $${{printf "%.2f" .ForecastSpend}}, exceeding{{else}}has exceeded{{end}}
+its budget of $${{.Lease.BudgetAmount}}, and has been frozen.
+</p>
+<p>
+You can still log in to read and export your data, but you can't create or change any resources.
+The account will be reset on {{.FrozenUntil}}, or as soon as you end the lease.
+</p>
+TMPL
+}
+
+variable "lease_frozen_template_text" {
+  type        = string
+  description = "Text template for the emails telling users their lease is frozen"
+  default     = <<TMPL
+Lease for principal {{.Lease.PrincipalID}} in AWS Account {{.Lease.AccountID}}
+{{if .IsForecast}}is forecast to spend $${{printf "%.2f" .ForecastSpend}}, exceeding{{else}}has exceeded{{end}}
+its budget of $${{.Lease.BudgetAmount}}, and has been frozen.
+
+You can still log in to read and export your data, but you can't create or change any resources.
+The account will be reset on {{.FrozenUntil}}, or as soon as you end the lease.
+TMPL
+}
+
+variable "lease_frozen_template_subject" {
+  type        = string
+  description = "Template for the subject of the emails telling users their lease is frozen"
+  default     = <<SUBJ
+Lease frozen until {{.FrozenUntil}} [{{.Lease.AccountID}}]
+SUBJ
+}
+
+variable "lease_idle_days" {
+  type        = number
+  description = "Days in a row a lease may spend less than lease_idle_spend_floor before the principal is warned it's idle. 0 disables idle lease detection"
+  default     = 0
+}
+
+variable "lease_idle_spend_floor" {
+  type        = number
+  description = "Daily spend below which a lease counts as idle"
+  default     = 1
+}
+
+variable "lease_idle_warning_period" {
+  type        = number
+  description = "Seconds between warning the principal of an idle lease, and ending the lease if it's still idle"
+  default     = 172800
+}
+
+variable "lease_idle_template_html" {
+  type        = string
+  description = "HTML template for the emails warning users their lease is idle"
+  default     = <<TMPL
+<p>
+Lease for principal {{.Lease.PrincipalID}} in AWS Account {{.Lease.AccountID}}
+has had next to no spend for the last {{.IdleDays}} days.
+</p>
+<p>
+If the account is still unused on {{.EndsOn}}, the lease will be ended and the account reset.
+End the lease yourself if you no longer need the account.
+</p>
+TMPL
+}
+
+variable "lease_idle_template_text" {
+  type        = string
+  description = "Text template for the emails warning users their lease is idle"
+  default     = <<TMPL
+Lease for principal {{.Lease.PrincipalID}} in AWS Account {{.Lease.AccountID}}
+has had next to no spend for the last {{.IdleDays}} days.
+
+If the account is still unused on {{.EndsOn}}, the lease will be ended and the account reset.
+End the lease yourself if you no longer need the account.
+TMPL
+}
+
+variable "lease_idle_template_subject" {
+  type        = string
+  description = "Template for the subject of the emails warning users their lease is idle"
+  default     = <<SUBJ
+Lease idle, ending on {{.EndsOn}} [{{.Lease.AccountID}}]
+SUBJ
+}
+
+variable "lease_forecast_method" {
+  type        = string
+  description = "How to forecast a lease's spend by the time it expires: LINEAR or COST_EXPLORER. An empty string disables spend forecasts"
+  default     = ""
+}
+
+variable "lease_forecast_margin" {
+  type        = number
+  description = "Percent a lease's forecast spend may go over its budget before lease_forecast_action is taken"
+  default     = 0
+}
+
+variable "lease_forecast_action" {
+  type        = string
+  description = "What to do with leases forecast to go over budget: NOTIFY the principal, or FREEZE the lease. An empty string only records the forecast"
+  default     = ""
+}
+
+variable "forecast_notification_template_html" {
+  type        = string
+  description = "HTML template for the emails warning users their lease is forecast to go over budget"
+  default     = <<TMPL
+<p>
+Lease for principal {{.Lease.PrincipalID}} in AWS Account {{.Lease.AccountID}}
+is forecast to spend {{printf "%.2f" .ForecastSpend}} {{.Lease.BudgetCurrency}} by the time it expires on {{.ExpiresOn}},
+over its budget of {{.Lease.BudgetAmount}} {{.Lease.BudgetCurrency}}.
+</p>
+<p>
+Clean up resources you no longer need, to keep the lease from being ended once it's over budget.
+</p>
+TMPL
+}
+
+variable "forecast_notification_template_text" {
+  type        = string
+  description = "Text template for the emails warning users their lease is forecast to go over budget"
+  default     = <<TMPL
+Lease for principal {{.Lease.PrincipalID}} in AWS Account {{.Lease.AccountID}}
+is forecast to spend {{printf "%.2f" .ForecastSpend}} {{.Lease.BudgetCurrency}} by the time it expires on {{.ExpiresOn}},
+over its budget of {{.Lease.BudgetAmount}} {{.Lease.BudgetCurrency}}.
+
+Clean up resources you no longer need, to keep the lease from being ended once it's over budget.
+TMPL
+}
+
+variable "forecast_notification_template_subject" {
+  type        = string
+  description = "Template for the subject of the emails warning users their lease is forecast to go over budget"
+  default     = <<SUBJ
+Lease forecast to go over budget [{{.Lease.AccountID}}]
+SUBJ
+}
+
+variable "expiry_notification_threshold_hours" {
+  type        = list(number)
+  description = "Hours before a lease expires at which expiry notification emails will be sent to users. An empty list disables expiry notifications"
+  default     = [72, 24, 1]
+}
+
+variable "expiry_notification_template_html" {
+  type        = string
+  description = "HTML template for lease expiry notification emails"
+  default     = <<TMPL
+<p>
+Lease for principal {{.Lease.PrincipalID}} in AWS Account {{.Lease.AccountID}}
+expires in {{.HoursRemaining}} hours, on {{.ExpiresOn}}.
+</p>
+<p>
+Once the lease expires, the account will be reset and any resources left in it deleted.
+Ask an admin to extend the lease if you still need the account.
+</p>
+TMPL
+}
+
+variable "expiry_notification_template_text" {
+  type        = string
+  description = "Text template for lease expiry notification emails"
+  default     = <<TMPL
+Lease for principal {{.Lease.PrincipalID}} in AWS Account {{.Lease.AccountID}}
+expires in {{.HoursRemaining}} hours, on {{.ExpiresOn}}.
+
+Once the lease expires, the account will be reset and any resources left in it deleted.
+Ask an admin to extend the lease if you still need the account.
+TMPL
+}
+
+variable "expiry_notification_template_subject" {
+  type        = string
+  description = "Template for lease expiry notification email subject"
+  default     = <<SUBJ
+Lease expires in {{.HoursRemaining}} hours [{{.Lease.AccountID}}]
+SUBJ
+}
+
+variable "account_selection_strategy" {
+  type        = string
+  description = "How to choose the account for a new lease: first-ready, least-recently-leased, affinity, random or metadata"
+  default     = "first-ready"
+}
+
+variable "account_selection_metadata_keys" {
+  type        = list(string)
+  description = "Metadata keys which must match between the lease and the account, when using the metadata account selection strategy"
+  default     = ["pool"]
+}
+
 variable "principal_budget_amount" {
   type        = number
   description = "User Principal's budget amount for given principal budget period"
@@ -212,10 +594,148 @@ variable "principal_budget_amount" {
 
 variable "principal_budget_period" {
   type        = string
-  description = "Principal budget period must be WEEKLY or MONTHLY"
+  description = "Principal budget period must be DAILY, WEEKLY, MONTHLY, QUARTERLY or ROLLING"
   default     = "WEEKLY"
 }
 
+variable "principal_budget_period_week_start" {
+  type        = string
+  description = "Day of the week WEEKLY principal budget periods start on, eg. SUNDAY"
+  default     = "SUNDAY"
+}
+
+variable "principal_budget_period_rolling_days" {
+  type        = number
+  description = "Number of days ROLLING principal budget periods look back over, including today"
+  default     = 30
+}
+
+variable "principal_budget_time_zone" {
+  type        = string
+  description = "IANA time zone principal budget periods start and end in, eg. America/Chicago"
+  default     = "UTC"
+}
+
+variable "cost_currency" {
+  type        = string
+  description = "Currency AWS Cost Explorer reports costs in. The principal budget amount is in this currency"
+  default     = "USD"
+}
+
+variable "cost_metric" {
+  type        = string
+  description = "Cost Explorer metric lease and principal spend is measured in: UnblendedCost, BlendedCost, AmortizedCost or NetUnblendedCost"
+  default     = "UnblendedCost"
+}
+
+variable "cost_excluded_record_types" {
+  type        = list(string)
+  description = "Cost Explorer record types left out of lease and principal spend, eg. [\"Credit\", \"Refund\", \"Tax\", \"Support\"]"
+  default     = []
+}
+
+variable "consolidated_billing" {
+  type        = bool
+  description = "Collect the usage of every leased account from the master account's consolidated billing, instead of calling Cost Explorer in each leased account"
+  default     = false
+}
+
+variable "collect_usage_schedule_expression" {
+  type        = string
+  description = "How often usage is collected from consolidated billing"
+  default     = "rate(6 hours)"
+}
+
+variable "spend_source" {
+  type        = string
+  description = "Where lease and principal spend is read from: COST_EXPLORER, or CUR to read the Cost and Usage Report delivered by the master account"
+  default     = "COST_EXPLORER"
+}
+
+variable "cur_bucket" {
+  type        = string
+  description = "S3 bucket the Cost and Usage Report is delivered to, when the spend_source is CUR"
+  default     = ""
+}
+
+variable "cur_prefix" {
+  type        = string
+  description = "S3 path prefix of the Cost and Usage Report"
+  default     = ""
+}
+
+variable "cur_report_name" {
+  type        = string
+  description = "Name of the Cost and Usage Report"
+  default     = ""
+}
+
+variable "cur_format" {
+  type        = string
+  description = "Format the Cost and Usage Report is delivered in: CSV or PARQUET"
+  default     = "CSV"
+}
+
+variable "usage_lookback_days" {
+  type        = number
+  description = "Days of usage, up to today, collected from consolidated billing or the Cost and Usage Report, as spend may be reported late"
+  default     = 1
+}
+
+variable "chargeback_export_enabled" {
+  type        = bool
+  description = "Export each month's usage, with lease metadata, to S3 for chargeback"
+  default     = false
+}
+
+variable "chargeback_export_schedule_expression" {
+  type        = string
+  description = "When the previous month's usage is exported for chargeback. Run it late enough for the month's usage to be collected"
+  default     = "cron(0 6 2 * ? *)"
+}
+
+variable "chargeback_bucket" {
+  type        = string
+  description = "S3 bucket chargeback reports are written to. Defaults to the DCE artifacts bucket"
+  default     = ""
+}
+
+variable "chargeback_prefix" {
+  type        = string
+  description = "S3 key prefix of chargeback reports. Each month's report is written under <prefix>/<YYYY-MM>/"
+  default     = "chargeback"
+}
+
+variable "chargeback_metadata_keys" {
+  type        = list(string)
+  description = "Lease metadata keys added as columns of chargeback reports, eg. [\"costCenter\"]"
+  default     = []
+}
+
+variable "chargeback_emails" {
+  type        = list(string)
+  description = "Chargeback reports are emailed to these addresses, as a CSV attachment"
+  default     = []
+}
+
+variable "supported_currencies" {
+  type        = list(string)
+  description = "Currencies lease budgets may use. The first one is the default for leases which don't specify a budget currency"
+  default     = ["USD"]
+}
+
+variable "currency_rates" {
+  type        = map(number)
+  description = "Exchange rates from the cost currency to each supported currency, eg. { EUR = 0.92 }"
+  default     = {}
+}
+
+variable "currency_rates_s3_key" {
+  type        = string
+  description = "Key of a JSON object of exchange rates in the artifacts bucket, eg. {\"EUR\": 0.92}. Overrides currency_rates when set"
+  default     = ""
+}
+
 variable "allowed_regions" {
   type = list(string)
   default = [
@@ -48,6 +48,20 @@ func (_m *Servicer) Delete(data *account.Account) error {
 	return r0
 }
 
+// FreezePrincipalAccess provides a mock function with given fields: data
+func (_m *Servicer) FreezePrincipalAccess(data *account.Account) error {
+	ret := _m.Called(data)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*account.Account) error); ok {
+		r0 = rf(data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Get provides a mock function with given fields: ID
 func (_m *Servicer) Get(ID string) (*account.Account, error) {
 	ret := _m.Called(ID)
@@ -145,6 +159,43 @@ func (_m *Servicer) Save(data *account.Account) error {
 	return r0
 }
 
+// TransitionStatus provides a mock function with given fields: ID, prevStatus, nextStatus
+func (_m *Servicer) TransitionStatus(ID string, prevStatus account.Status, nextStatus account.Status) (*account.Account, error) {
+	ret := _m.Called(ID, prevStatus, nextStatus)
+
+	var r0 *account.Account
+	if rf, ok := ret.Get(0).(func(string, account.Status, account.Status) *account.Account); ok {
+		r0 = rf(ID, prevStatus, nextStatus)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*account.Account)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, account.Status, account.Status) error); ok {
+		r1 = rf(ID, prevStatus, nextStatus)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UnfreezePrincipalAccess provides a mock function with given fields: data
+func (_m *Servicer) UnfreezePrincipalAccess(data *account.Account) error {
+	ret := _m.Called(data)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*account.Account) error); ok {
+		r0 = rf(data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ID, data
 func (_m *Servicer) Update(ID string, data *account.Account) (*account.Account, error) {
 	ret := _m.Called(ID, data)
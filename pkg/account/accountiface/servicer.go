@@ -14,6 +14,9 @@ type Servicer interface {
 	Save(data *account.Account) error
 	// Update the Account record in DynamoDB
 	Update(ID string, data *account.Account) (*account.Account, error)
+	// TransitionStatus moves the account from prevStatus to nextStatus.
+	// Returns a conflict error if the account is not in prevStatus.
+	TransitionStatus(ID string, prevStatus account.Status, nextStatus account.Status) (*account.Account, error)
 	// Delete finds a given account and deletes it if it is not of status `Leased`. Returns the account.
 	Delete(data *account.Account) error
 	// List Get a list of accounts based on Principal ID
@@ -26,4 +29,8 @@ type Servicer interface {
 	Reset(id string) (*account.Account, error)
 	// UpsertPrincipalAccess merges principal access to make sure its
 	UpsertPrincipalAccess(data *account.Account) error
+	// FreezePrincipalAccess limits the principal to reading and exporting data from the account
+	FreezePrincipalAccess(data *account.Account) error
+	// UnfreezePrincipalAccess lifts a freeze from the principal's access to the account
+	UnfreezePrincipalAccess(data *account.Account) error
 }
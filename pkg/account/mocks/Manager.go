@@ -25,6 +25,34 @@ func (_m *Manager) DeletePrincipalAccess(_a0 *account.Account) error {
 	return r0
 }
 
+// FreezePrincipalAccess provides a mock function with given fields: _a0
+func (_m *Manager) FreezePrincipalAccess(_a0 *account.Account) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*account.Account) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UnfreezePrincipalAccess provides a mock function with given fields: _a0
+func (_m *Manager) UnfreezePrincipalAccess(_a0 *account.Account) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*account.Account) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpsertPrincipalAccess provides a mock function with given fields: _a0
 func (_m *Manager) UpsertPrincipalAccess(_a0 *account.Account) error {
 	ret := _m.Called(_a0)
@@ -70,6 +70,29 @@ func (_m *ReaderWriterDeleter) List(query *account.Account) (*account.Accounts,
 	return r0, r1
 }
 
+// TransitionStatus provides a mock function with given fields: ID, prevStatus, nextStatus
+func (_m *ReaderWriterDeleter) TransitionStatus(ID string, prevStatus account.Status, nextStatus account.Status) (*account.Account, error) {
+	ret := _m.Called(ID, prevStatus, nextStatus)
+
+	var r0 *account.Account
+	if rf, ok := ret.Get(0).(func(string, account.Status, account.Status) *account.Account); ok {
+		r0 = rf(ID, prevStatus, nextStatus)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*account.Account)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, account.Status, account.Status) error); ok {
+		r1 = rf(ID, prevStatus, nextStatus)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Write provides a mock function with given fields: i, lastModifiedOn
 func (_m *ReaderWriterDeleter) Write(i *account.Account, lastModifiedOn *int64) error {
 	ret := _m.Called(i, lastModifiedOn)
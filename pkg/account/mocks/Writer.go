@@ -10,6 +10,29 @@ type Writer struct {
 	mock.Mock
 }
 
+// TransitionStatus provides a mock function with given fields: ID, prevStatus, nextStatus
+func (_m *Writer) TransitionStatus(ID string, prevStatus account.Status, nextStatus account.Status) (*account.Account, error) {
+	ret := _m.Called(ID, prevStatus, nextStatus)
+
+	var r0 *account.Account
+	if rf, ok := ret.Get(0).(func(string, account.Status, account.Status) *account.Account); ok {
+		r0 = rf(ID, prevStatus, nextStatus)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*account.Account)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, account.Status, account.Status) error); ok {
+		r1 = rf(ID, prevStatus, nextStatus)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Write provides a mock function with given fields: i, lastModifiedOn
 func (_m *Writer) Write(i *account.Account, lastModifiedOn *int64) error {
 	ret := _m.Called(i, lastModifiedOn)
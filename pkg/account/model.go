@@ -40,6 +40,7 @@ type Account struct {
 	Status              *Status                `json:"accountStatus,omitempty" dynamodbav:"AccountStatus,omitempty" schema:"status,omitempty"`                          // Status of the AWS Account
 	LastModifiedOn      *int64                 `json:"lastModifiedOn,omitempty" dynamodbav:"LastModifiedOn" schema:"lastModifiedOn,omitempty"`                          // Last Modified Epoch Timestamp
 	CreatedOn           *int64                 `json:"createdOn,omitempty"  dynamodbav:"CreatedOn,omitempty" schema:"createdOn,omitempty"`                              // Account CreatedOn
+	LastLeasedOn        *int64                 `json:"lastLeasedOn,omitempty" dynamodbav:"LastLeasedOn,omitempty" schema:"-"`                                           // When the account was last claimed for a lease, as Epoch
 	AdminRoleArn        *arn.ARN               `json:"adminRoleArn,omitempty"  dynamodbav:"AdminRoleArn" schema:"adminRoleArn,omitempty"`                               // Assumed by the master account, to manage this user account
 	PrincipalRoleArn    *arn.ARN               `json:"principalRoleArn,omitempty"  dynamodbav:"PrincipalRoleArn,omitempty" schema:"principalRoleArn,omitempty"`         // Assumed by principal users
 	PrincipalPolicyHash *string                `json:"principalPolicyHash,omitempty" dynamodbav:"PrincipalPolicyHash,omitempty" schema:"principalPolicyHash,omitempty"` // The the hash of the policy version deployed
@@ -47,6 +48,8 @@ type Account struct {
 	Limit               *int64                 `json:"-" dynamodbav:"-" schema:"limit,omitempty"`
 	NextID              *string                `json:"-" dynamodbav:"-" schema:"nextId,omitempty"`
 	PrincipalPolicyArn  *arn.ARN               `json:"-" dynamodbav:"-" schema:"-"`
+	PrincipalPolicyKey  *string                `json:"-" dynamodbav:"-" schema:"-"` // Principal policy template to render instead of the default one, eg. from a lease profile
+	AllowedRegions      *[]string              `json:"-" dynamodbav:"-" schema:"-"` // Regions to render into the principal policy instead of the default ones
 }
 
 // Validate the account data
@@ -79,6 +82,7 @@ func (a *Account) UnmarshalJSON(data []byte) error {
 	a.Status = alias.Status
 	a.LastModifiedOn = alias.LastModifiedOn
 	a.CreatedOn = alias.CreatedOn
+	a.LastLeasedOn = alias.LastLeasedOn
 	a.PrincipalRoleArn = alias.PrincipalRoleArn
 	a.AdminRoleArn = alias.AdminRoleArn
 	a.Metadata = alias.Metadata
@@ -106,6 +110,7 @@ func (a *Account) UnmarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) e
 	a.Status = alias.Status
 	a.LastModifiedOn = alias.LastModifiedOn
 	a.CreatedOn = alias.CreatedOn
+	a.LastLeasedOn = alias.LastLeasedOn
 	a.PrincipalRoleArn = alias.PrincipalRoleArn
 	a.AdminRoleArn = alias.AdminRoleArn
 	a.Metadata = alias.Metadata
@@ -13,6 +13,7 @@ import (
 // Writer put an item into the data store
 type Writer interface {
 	Write(i *Account, lastModifiedOn *int64) error
+	TransitionStatus(ID string, prevStatus Status, nextStatus Status) (*Account, error)
 }
 
 // Deleter Deletes an Account from the data store
@@ -61,6 +62,8 @@ type Manager interface {
 	ValidateAccess(role *arn.ARN) error
 	UpsertPrincipalAccess(account *Account) error
 	DeletePrincipalAccess(account *Account) error
+	FreezePrincipalAccess(account *Account) error
+	UnfreezePrincipalAccess(account *Account) error
 }
 
 // Service is a type corresponding to a Account table record
@@ -134,6 +137,12 @@ func (a *Service) Update(ID string, data *Account) (*Account, error) {
 	return account, nil
 }
 
+// TransitionStatus moves the account from prevStatus to nextStatus.
+// Returns a conflict error if the account is not in prevStatus.
+func (a *Service) TransitionStatus(ID string, prevStatus Status, nextStatus Status) (*Account, error) {
+	return a.dataSvc.TransitionStatus(ID, prevStatus, nextStatus)
+}
+
 // Create creates a new account using the data provided. Returns the account record
 func (a *Service) Create(data *Account) (*Account, error) {
 	// Validate the incoming record doesn't have unneeded fields
@@ -327,6 +336,33 @@ func (a *Service) UpsertPrincipalAccess(data *Account) error {
 	return nil
 }
 
+// FreezePrincipalAccess limits the principal to reading and exporting data from the account
+func (a *Service) FreezePrincipalAccess(data *Account) error {
+	err := validation.ValidateStruct(data,
+		validation.Field(&data.Status, validation.NotNil, validation.By(isAccountLeased)),
+		validation.Field(&data.AdminRoleArn, validation.NotNil),
+		validation.Field(&data.PrincipalRoleArn, validation.NotNil),
+	)
+	if err != nil {
+		return errors.NewConflict("account", *data.ID, err)
+	}
+
+	return a.managerSvc.FreezePrincipalAccess(data)
+}
+
+// UnfreezePrincipalAccess lifts a freeze from the principal's access to the account
+func (a *Service) UnfreezePrincipalAccess(data *Account) error {
+	err := validation.ValidateStruct(data,
+		validation.Field(&data.AdminRoleArn, validation.NotNil),
+		validation.Field(&data.PrincipalRoleArn, validation.NotNil),
+	)
+	if err != nil {
+		return errors.NewConflict("account", *data.ID, err)
+	}
+
+	return a.managerSvc.UnfreezePrincipalAccess(data)
+}
+
 // NewServiceInput Input for creating a new Service
 type NewServiceInput struct {
 	PrincipalRoleName string `env:"PRINCIPAL_ROLE_NAME" envDefault:"DCEPrincipal"`
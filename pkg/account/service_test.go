@@ -712,3 +712,169 @@ func TestUpsertPrincipalAccess(t *testing.T) {
 		})
 	}
 }
+
+func TestFreezePrincipalAccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      *account.Account
+		expErr     error
+		managerErr error
+	}{
+		{
+			name: "should freeze principal access to a leased account",
+			input: &account.Account{
+				ID:               ptrString("123456789012"),
+				AdminRoleArn:     arn.New("aws", "iam", "", "123456789012", "role/AdminRole"),
+				PrincipalRoleArn: arn.New("aws", "iam", "", "123456789012", "role/PrincipalRole"),
+				Status:           account.StatusLeased.StatusPtr(),
+			},
+		},
+		{
+			name: "should fail to freeze principal access to an account which isn't leased",
+			input: &account.Account{
+				ID:               ptrString("123456789012"),
+				AdminRoleArn:     arn.New("aws", "iam", "", "123456789012", "role/AdminRole"),
+				PrincipalRoleArn: arn.New("aws", "iam", "", "123456789012", "role/PrincipalRole"),
+				Status:           account.StatusReady.StatusPtr(),
+			},
+			expErr: errors.NewConflict("account", "123456789012", fmt.Errorf("accountStatus: must be leased.")),
+		},
+		{
+			name: "should return an error when the manager fails",
+			input: &account.Account{
+				ID:               ptrString("123456789012"),
+				AdminRoleArn:     arn.New("aws", "iam", "", "123456789012", "role/AdminRole"),
+				PrincipalRoleArn: arn.New("aws", "iam", "", "123456789012", "role/PrincipalRole"),
+				Status:           account.StatusLeased.StatusPtr(),
+			},
+			managerErr: errors.NewInternalServer("error", fmt.Errorf("failure")),
+			expErr:     errors.NewInternalServer("error", fmt.Errorf("failure")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mocksManager := &mocks.Manager{}
+			mocksManager.On("FreezePrincipalAccess", tt.input).Return(tt.managerErr)
+
+			accountSvc := account.NewService(
+				account.NewServiceInput{
+					ManagerSvc: mocksManager,
+				},
+			)
+
+			err := accountSvc.FreezePrincipalAccess(tt.input)
+			assert.Truef(t, errors.Is(err, tt.expErr), "actual error %q doesn't match expected error %q", err, tt.expErr)
+		})
+	}
+}
+
+func TestUnfreezePrincipalAccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      *account.Account
+		expErr     error
+		managerErr error
+	}{
+		{
+			name: "should unfreeze principal access to an account",
+			input: &account.Account{
+				ID:               ptrString("123456789012"),
+				AdminRoleArn:     arn.New("aws", "iam", "", "123456789012", "role/AdminRole"),
+				PrincipalRoleArn: arn.New("aws", "iam", "", "123456789012", "role/PrincipalRole"),
+				Status:           account.StatusLeased.StatusPtr(),
+			},
+		},
+		{
+			name: "should fail to unfreeze principal access to an account without a principal role",
+			input: &account.Account{
+				ID:           ptrString("123456789012"),
+				AdminRoleArn: arn.New("aws", "iam", "", "123456789012", "role/AdminRole"),
+				Status:       account.StatusLeased.StatusPtr(),
+			},
+			expErr: errors.NewConflict("account", "123456789012", fmt.Errorf("principalRoleArn: is required.")),
+		},
+		{
+			name: "should return an error when the manager fails",
+			input: &account.Account{
+				ID:               ptrString("123456789012"),
+				AdminRoleArn:     arn.New("aws", "iam", "", "123456789012", "role/AdminRole"),
+				PrincipalRoleArn: arn.New("aws", "iam", "", "123456789012", "role/PrincipalRole"),
+				Status:           account.StatusLeased.StatusPtr(),
+			},
+			managerErr: errors.NewInternalServer("error", fmt.Errorf("failure")),
+			expErr:     errors.NewInternalServer("error", fmt.Errorf("failure")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mocksManager := &mocks.Manager{}
+			mocksManager.On("UnfreezePrincipalAccess", tt.input).Return(tt.managerErr)
+
+			accountSvc := account.NewService(
+				account.NewServiceInput{
+					ManagerSvc: mocksManager,
+				},
+			)
+
+			err := accountSvc.UnfreezePrincipalAccess(tt.input)
+			assert.Truef(t, errors.Is(err, tt.expErr), "actual error %q doesn't match expected error %q", err, tt.expErr)
+		})
+	}
+}
+
+func TestTransitionStatus(t *testing.T) {
+
+	type response struct {
+		data *account.Account
+		err  error
+	}
+
+	tests := []struct {
+		name string
+		ret  response
+		exp  response
+	}{
+		{
+			name: "should transition the account status",
+			ret: response{
+				data: &account.Account{
+					ID:     ptrString("123456789012"),
+					Status: account.StatusLeased.StatusPtr(),
+				},
+			},
+			exp: response{
+				data: &account.Account{
+					ID:     ptrString("123456789012"),
+					Status: account.StatusLeased.StatusPtr(),
+				},
+			},
+		},
+		{
+			name: "should return conflict when the account is not in the previous status",
+			ret: response{
+				err: errors.NewConflict("account", "123456789012", fmt.Errorf("unable to update account: account status is not \"Ready\"")),
+			},
+			exp: response{
+				err: errors.NewConflict("account", "123456789012", fmt.Errorf("unable to update account: account status is not \"Ready\"")),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mocksRwd := &mocks.ReaderWriterDeleter{}
+
+			mocksRwd.On("TransitionStatus", "123456789012", account.StatusReady, account.StatusLeased).Return(tt.ret.data, tt.ret.err)
+
+			accountSvc := account.NewService(account.NewServiceInput{
+				DataSvc: mocksRwd,
+			})
+
+			result, err := accountSvc.TransitionStatus("123456789012", account.StatusReady, account.StatusLeased)
+			assert.True(t, errors.Is(err, tt.exp.err), "actual error %q doesn't match expected error %q", err, tt.exp.err)
+			assert.Equal(t, tt.exp.data, result)
+		})
+	}
+}
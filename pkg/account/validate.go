@@ -64,3 +64,11 @@ func isAccountNotLeased(value interface{}) error {
 	}
 	return nil
 }
+
+func isAccountLeased(value interface{}) error {
+	s, _ := value.(*Status)
+	if s.String() != StatusLeased.String() {
+		return errors.New("must be leased")
+	}
+	return nil
+}
@@ -13,6 +13,10 @@ type Servicer interface {
 	ValidateAccess(role *arn.ARN) error
 	// UpsertPrincipalAccess creates roles, policies and update them as needed
 	UpsertPrincipalAccess(account *account.Account) error
+	// FreezePrincipalAccess limits the principal role to reading and exporting data
+	FreezePrincipalAccess(account *account.Account) error
+	// UnfreezePrincipalAccess removes the freeze from the principal role, if it has one
+	UnfreezePrincipalAccess(account *account.Account) error
 	// DeletePrincipalAccess removes all the principal roles and policies
 	DeletePrincipalAccess(account *account.Account) error
 }
@@ -12,6 +12,90 @@ import (
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 )
 
+// freezePolicyName is the inline policy which freezes a principal role
+const freezePolicyName = "DCEPrincipalFreeze"
+
+// freezePolicy denies everything except reading and exporting data,
+// listing the read actions of each service a principal may have data in
+const freezePolicy = `{
+	"Version": "2012-10-17",
+	"Statement": [
+		{
+			"Sid": "DenyAllButReadAndExport",
+			"Effect": "Deny",
+			"NotAction": [
+				"acm:Describe*",
+				"acm:Get*",
+				"acm:List*",
+				"apigateway:GET",
+				"autoscaling:Describe*",
+				"cloudformation:Describe*",
+				"cloudformation:Get*",
+				"cloudformation:List*",
+				"cloudfront:Get*",
+				"cloudfront:List*",
+				"cloudtrail:Describe*",
+				"cloudtrail:Get*",
+				"cloudtrail:List*",
+				"cloudtrail:LookupEvents",
+				"cloudwatch:Describe*",
+				"cloudwatch:Get*",
+				"cloudwatch:List*",
+				"dynamodb:BatchGetItem",
+				"dynamodb:Describe*",
+				"dynamodb:ExportTableToPointInTime",
+				"dynamodb:Get*",
+				"dynamodb:List*",
+				"dynamodb:Query",
+				"dynamodb:Scan",
+				"ec2:CreateSnapshot",
+				"ec2:CreateSnapshots",
+				"ec2:Describe*",
+				"ec2:Get*",
+				"ecr:BatchGetImage",
+				"ecr:Describe*",
+				"ecr:Get*",
+				"ecr:List*",
+				"ecs:Describe*",
+				"ecs:List*",
+				"eks:Describe*",
+				"eks:List*",
+				"elasticloadbalancing:Describe*",
+				"iam:Get*",
+				"iam:List*",
+				"kms:Describe*",
+				"kms:Get*",
+				"kms:List*",
+				"lambda:Get*",
+				"lambda:List*",
+				"logs:Describe*",
+				"logs:FilterLogEvents",
+				"logs:Get*",
+				"logs:StartQuery",
+				"logs:StopQuery",
+				"rds:CreateDBSnapshot",
+				"rds:Describe*",
+				"rds:List*",
+				"s3:Get*",
+				"s3:List*",
+				"secretsmanager:Describe*",
+				"secretsmanager:GetSecretValue",
+				"secretsmanager:List*",
+				"sns:Get*",
+				"sns:List*",
+				"sqs:Get*",
+				"sqs:List*",
+				"sqs:ReceiveMessage",
+				"ssm:Describe*",
+				"ssm:Get*",
+				"ssm:List*",
+				"sts:GetCallerIdentity"
+			],
+			"Resource": "*"
+		}
+	]
+}`
+
 type principalService struct {
 	iamSvc   iamiface.IAMAPI
 	storager common.Storager
@@ -187,6 +271,39 @@ func (p *principalService) DetachRoleWithPolicy() error {
 	return nil
 }
 
+// PutFreezePolicy adds the freeze policy to the principal role
+func (p *principalService) PutFreezePolicy() error {
+
+	_, err := p.iamSvc.PutRolePolicy(&iam.PutRolePolicyInput{
+		PolicyName:     aws.String(freezePolicyName),
+		PolicyDocument: aws.String(freezePolicy),
+		RoleName:       p.account.PrincipalRoleArn.IAMResourceName(),
+	})
+	if err != nil {
+		return errors.NewInternalServer(
+			fmt.Sprintf("unexpected error adding freeze policy to role %q", p.account.PrincipalRoleArn.String()),
+			err)
+	}
+
+	return nil
+}
+
+// DeleteFreezePolicy removes the freeze policy from the principal role, if it has one
+func (p *principalService) DeleteFreezePolicy() error {
+
+	_, err := p.iamSvc.DeleteRolePolicy(&iam.DeleteRolePolicyInput{
+		PolicyName: aws.String(freezePolicyName),
+		RoleName:   p.account.PrincipalRoleArn.IAMResourceName(),
+	})
+	if err != nil && !isAWSNoSuchEntityError(err) {
+		return errors.NewInternalServer(
+			fmt.Sprintf("unexpected error removing freeze policy from role %q", p.account.PrincipalRoleArn.String()),
+			err)
+	}
+
+	return nil
+}
+
 func (p *principalService) buildPolicy() (*string, *string, error) {
 
 	type principalPolicyInput struct {
@@ -197,13 +314,23 @@ func (p *principalService) buildPolicy() (*string, *string, error) {
 		Regions              []string
 	}
 
-	policy, policyHash, err := p.storager.GetTemplateObject(p.config.S3BucketName, p.config.S3PolicyKey,
+	// A lease profile may use its own policy template and regions
+	policyKey := p.config.S3PolicyKey
+	if p.account.PrincipalPolicyKey != nil {
+		policyKey = *p.account.PrincipalPolicyKey
+	}
+	regions := p.config.AllowedRegions
+	if p.account.AllowedRegions != nil {
+		regions = *p.account.AllowedRegions
+	}
+
+	policy, policyHash, err := p.storager.GetTemplateObject(p.config.S3BucketName, policyKey,
 		principalPolicyInput{
 			PrincipalPolicyArn:   p.account.PrincipalPolicyArn.String(),
 			PrincipalRoleArn:     p.account.PrincipalRoleArn.String(),
 			PrincipalIAMDenyTags: p.config.PrincipalIAMDenyTags,
 			AdminRoleArn:         p.account.AdminRoleArn.String(),
-			Regions:              p.config.AllowedRegions,
+			Regions:              regions,
 		})
 	if err != nil {
 		return nil, nil, err
@@ -77,6 +77,26 @@ func TestPrincipalMergePolicyAccess(t *testing.T) {
 			},
 			listPolicyVersionsOutput: listPolicyVersionsOutput{},
 		},
+		{
+			name: "should create policy from the lease profile's template",
+			account: &account.Account{
+				ID:                 aws.String("123456789012"),
+				PrincipalRoleArn:   arn.New("aws", "iam", "", "123456789012", "role/DCEPrincipal"),
+				AdminRoleArn:       arn.New("aws", "iam", "", "123456789012", "role/AdminAccess"),
+				PrincipalPolicyArn: arn.New("aws", "iam", "", "123456789012", "policy/DCEPrincipalDefaultPolicy"),
+				PrincipalPolicyKey: aws.String("ml_large_policy.tmpl"),
+				AllowedRegions:     &[]string{"us-west-2"},
+			},
+			createPolicyOutput: createPolicyOutput{
+				output: &iam.CreatePolicyOutput{},
+				err:    nil,
+			},
+			attachRolePolicyOutput: attachRolePolicyOutput{
+				output: &iam.AttachRolePolicyOutput{},
+				err:    nil,
+			},
+			listPolicyVersionsOutput: listPolicyVersionsOutput{},
+		},
 		{
 			name: "should get duplicate errors and still work",
 			account: &account.Account{
@@ -117,9 +137,14 @@ func TestPrincipalMergePolicyAccess(t *testing.T) {
 			iamSvc.On("AttachRolePolicy", mock.AnythingOfType("*iam.AttachRolePolicyInput")).
 				Return(tt.attachRolePolicyOutput.output, tt.attachRolePolicyOutput.err)
 
+			policyKey := "DefaultPrincipalPolicyS3Key"
+			if tt.account.PrincipalPolicyKey != nil {
+				policyKey = *tt.account.PrincipalPolicyKey
+			}
+
 			storagerSvc := &commonMocks.Storager{}
 			storagerSvc.On(
-				"GetTemplateObject", "DefaultArtifactBucket", "DefaultPrincipalPolicyS3Key",
+				"GetTemplateObject", "DefaultArtifactBucket", policyKey,
 				mock.Anything).Return("", "123", nil)
 
 			clientSvc := &mocks.Clienter{}
@@ -87,6 +87,50 @@ func (s *Service) UpsertPrincipalAccess(account *account.Account) error {
 	return nil
 }
 
+// FreezePrincipalAccess limits the principal role to reading and exporting data
+func (s *Service) FreezePrincipalAccess(account *account.Account) error {
+	err := validation.ValidateStruct(account,
+		validation.Field(&account.AdminRoleArn, validation.NotNil),
+		validation.Field(&account.PrincipalRoleArn, validation.NotNil),
+	)
+	if err != nil {
+		return errors.NewValidation("account", err)
+	}
+
+	iamSvc := s.client.IAM(account.AdminRoleArn)
+
+	principalSvc := principalService{
+		iamSvc:   iamSvc,
+		storager: s.storager,
+		account:  account,
+		config:   s.config,
+	}
+
+	return principalSvc.PutFreezePolicy()
+}
+
+// UnfreezePrincipalAccess removes the freeze from the principal role, if it has one
+func (s *Service) UnfreezePrincipalAccess(account *account.Account) error {
+	err := validation.ValidateStruct(account,
+		validation.Field(&account.AdminRoleArn, validation.NotNil),
+		validation.Field(&account.PrincipalRoleArn, validation.NotNil),
+	)
+	if err != nil {
+		return errors.NewValidation("account", err)
+	}
+
+	iamSvc := s.client.IAM(account.AdminRoleArn)
+
+	principalSvc := principalService{
+		iamSvc:   iamSvc,
+		storager: s.storager,
+		account:  account,
+		config:   s.config,
+	}
+
+	return principalSvc.DeleteFreezePolicy()
+}
+
 // DeletePrincipalAccess removes all the principal roles and policies
 func (s *Service) DeletePrincipalAccess(account *account.Account) error {
 	err := validation.ValidateStruct(account,
@@ -115,6 +159,12 @@ func (s *Service) DeletePrincipalAccess(account *account.Account) error {
 		return err
 	}
 
+	// Roles can't be deleted while they have inline policies
+	err = principalSvc.DeleteFreezePolicy()
+	if err != nil {
+		return err
+	}
+
 	err = principalSvc.DeleteRole()
 	if err != nil {
 		return err
@@ -1,6 +1,7 @@
 package accountmanager
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -193,6 +194,8 @@ func TestUpsertPrincipalAccess(t *testing.T) {
 
 			err = amSvc.UpsertPrincipalAccess(tt.input)
 			assert.True(t, errors.Is(err, tt.exp), "actual error %+v doesn't match expected error %+v", err, tt.exp)
+			// Leases which are frozen stay frozen when their access is updated
+			iamSvc.AssertNotCalled(t, "DeleteRolePolicy", mock.Anything)
 		})
 	}
 }
@@ -462,6 +465,8 @@ func TestDeletePrincipalAccess(t *testing.T) {
 				Return(tt.deletePolicyOutput.output, tt.deletePolicyOutput.err)
 			iamSvc.On("DetachRolePolicy", mock.AnythingOfType("*iam.DetachRolePolicyInput")).
 				Return(tt.detachRolePolicyOutput.output, tt.detachRolePolicyOutput.err)
+			iamSvc.On("DeleteRolePolicy", mock.AnythingOfType("*iam.DeleteRolePolicyInput")).
+				Return(&iam.DeleteRolePolicyOutput{}, nil)
 
 			clientSvc := &mocks.Clienter{}
 			clientSvc.On("IAM", mock.Anything).Return(iamSvc)
@@ -481,3 +486,128 @@ func TestDeletePrincipalAccess(t *testing.T) {
 		})
 	}
 }
+
+func TestFreezePrincipalAccess(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		exp          error
+		input        *account.Account
+		putPolicyErr error
+	}{
+		{
+			name: "should add the freeze policy to the principal role",
+			input: &account.Account{
+				ID:               aws.String("123456789012"),
+				PrincipalRoleArn: arn.New("aws", "iam", "", "123456789012", "role/DCEPrincipal"),
+				AdminRoleArn:     arn.New("aws", "iam", "", "123456789012", "role/AdminAccess"),
+			},
+		},
+		{
+			name: "should fail when the freeze policy can't be added",
+			input: &account.Account{
+				ID:               aws.String("123456789012"),
+				PrincipalRoleArn: arn.New("aws", "iam", "", "123456789012", "role/DCEPrincipal"),
+				AdminRoleArn:     arn.New("aws", "iam", "", "123456789012", "role/AdminAccess"),
+			},
+			putPolicyErr: awserr.New(iam.ErrCodeLimitExceededException, "Limit Exceeded", nil),
+			exp:          errors.NewInternalServer("unexpected error adding freeze policy to role \"arn:aws:iam::123456789012:role/DCEPrincipal\"", awserr.New(iam.ErrCodeLimitExceededException, "Limit Exceeded", nil)),
+		},
+		{
+			name: "should fail without a principal role",
+			input: &account.Account{
+				ID:           aws.String("123456789012"),
+				AdminRoleArn: arn.New("aws", "iam", "", "123456789012", "role/AdminAccess"),
+			},
+			exp: errors.NewValidation("account", fmt.Errorf("principalRoleArn: is required.")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iamSvc := &awsMocks.IAM{}
+			iamSvc.On("PutRolePolicy", mock.MatchedBy(func(input *iam.PutRolePolicyInput) bool {
+				return *input.PolicyName == "DCEPrincipalFreeze" && *input.RoleName == "DCEPrincipal"
+			})).Return(&iam.PutRolePolicyOutput{}, tt.putPolicyErr)
+
+			clientSvc := &mocks.Clienter{}
+			clientSvc.On("IAM", mock.Anything).Return(iamSvc)
+
+			amSvc, err := NewService(NewServiceInput{
+				Session: session.Must(session.NewSession()),
+				Sts:     &awsMocks.STSAPI{},
+				Config:  testConfig,
+			})
+			amSvc.client = clientSvc
+
+			assert.Nil(t, err)
+
+			err = amSvc.FreezePrincipalAccess(tt.input)
+
+			assert.True(t, errors.Is(err, tt.exp), "actual error %+v doesn't match expected error %+v", err, tt.exp)
+		})
+	}
+}
+
+func TestUnfreezePrincipalAccess(t *testing.T) {
+
+	tests := []struct {
+		name            string
+		exp             error
+		input           *account.Account
+		deletePolicyErr error
+	}{
+		{
+			name: "should remove the freeze policy from the principal role",
+			input: &account.Account{
+				ID:               aws.String("123456789012"),
+				PrincipalRoleArn: arn.New("aws", "iam", "", "123456789012", "role/DCEPrincipal"),
+				AdminRoleArn:     arn.New("aws", "iam", "", "123456789012", "role/AdminAccess"),
+			},
+		},
+		{
+			name: "should pass when the principal role isn't frozen",
+			input: &account.Account{
+				ID:               aws.String("123456789012"),
+				PrincipalRoleArn: arn.New("aws", "iam", "", "123456789012", "role/DCEPrincipal"),
+				AdminRoleArn:     arn.New("aws", "iam", "", "123456789012", "role/AdminAccess"),
+			},
+			deletePolicyErr: awserr.New(iam.ErrCodeNoSuchEntityException, "No Such Entity", nil),
+		},
+		{
+			name: "should fail when the freeze policy can't be removed",
+			input: &account.Account{
+				ID:               aws.String("123456789012"),
+				PrincipalRoleArn: arn.New("aws", "iam", "", "123456789012", "role/DCEPrincipal"),
+				AdminRoleArn:     arn.New("aws", "iam", "", "123456789012", "role/AdminAccess"),
+			},
+			deletePolicyErr: awserr.New(iam.ErrCodeLimitExceededException, "Limit Exceeded", nil),
+			exp:             errors.NewInternalServer("unexpected error removing freeze policy from role \"arn:aws:iam::123456789012:role/DCEPrincipal\"", awserr.New(iam.ErrCodeLimitExceededException, "Limit Exceeded", nil)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iamSvc := &awsMocks.IAM{}
+			iamSvc.On("DeleteRolePolicy", mock.MatchedBy(func(input *iam.DeleteRolePolicyInput) bool {
+				return *input.PolicyName == "DCEPrincipalFreeze" && *input.RoleName == "DCEPrincipal"
+			})).Return(&iam.DeleteRolePolicyOutput{}, tt.deletePolicyErr)
+
+			clientSvc := &mocks.Clienter{}
+			clientSvc.On("IAM", mock.Anything).Return(iamSvc)
+
+			amSvc, err := NewService(NewServiceInput{
+				Session: session.Must(session.NewSession()),
+				Sts:     &awsMocks.STSAPI{},
+				Config:  testConfig,
+			})
+			amSvc.client = clientSvc
+
+			assert.Nil(t, err)
+
+			err = amSvc.UnfreezePrincipalAccess(tt.input)
+
+			assert.True(t, errors.Is(err, tt.exp), "actual error %+v doesn't match expected error %+v", err, tt.exp)
+		})
+	}
+}
@@ -38,4 +38,9 @@ type LeaseResponse struct {
 	LeaseStatusModifiedOn    int64                  `json:"leaseStatusModifiedOn"`
 	ExpiresOn                int64                  `json:"expiresOn"`
 	Metadata                 map[string]interface{} `json:"metadata"`
+	IdleWarnedOn             *int64                 `json:"idleWarnedOn,omitempty"`
+	ExpiryNotificationsSent  []float64              `json:"expiryNotificationsSent,omitempty"`
+	BudgetNotificationsSent  []float64              `json:"budgetNotificationsSent,omitempty"`
+	ForecastSpend            *float64               `json:"forecastSpend,omitempty"`
+	ForecastWarnedOn         *int64                 `json:"forecastWarnedOn,omitempty"`
 }
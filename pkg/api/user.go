@@ -30,6 +30,7 @@ const AdminGroupName = "Admin"
 type User struct {
 	Username string
 	Role     string
+	Groups   *[]string
 }
 
 // Authorize returns an error if the user is not authorized to act on the principalID
@@ -87,10 +88,16 @@ func (u *UserDetails) GetUser(reqCtx *events.APIGatewayProxyRequestContext) *Use
 	user := &User{
 		Role:     UserGroupName,
 		Username: *users.Users[0].Username,
+		Groups:   &[]string{},
 	}
 
 	for _, attribute := range users.Users[0].Attributes {
 		if *attribute.Name == "custom:roles" {
+			for _, group := range strings.Split(*attribute.Value, ",") {
+				if strings.TrimSpace(group) != "" {
+					*user.Groups = append(*user.Groups, strings.TrimSpace(group))
+				}
+			}
 			if u.isUserInAdminFromList(*attribute.Value) {
 				user.Role = AdminGroupName
 				return user
@@ -98,20 +105,23 @@ func (u *UserDetails) GetUser(reqCtx *events.APIGatewayProxyRequestContext) *Use
 		}
 	}
 
-	isAdmin, err := u.isUserInAdminGroup(user.Username)
+	groups, err := u.listUserGroups(user.Username)
 	if err != nil {
 		log.Printf("Got an error when quering groups for user: %s", err)
 		return user
 	}
-	if isAdmin {
-		user.Role = AdminGroupName
-		return user
+	*user.Groups = append(*user.Groups, groups...)
+	for _, group := range groups {
+		if group == AdminGroupName {
+			user.Role = AdminGroupName
+			return user
+		}
 	}
 
 	return user
 }
 
-func (u *UserDetails) isUserInAdminGroup(username string) (bool, error) {
+func (u *UserDetails) listUserGroups(username string) ([]string, error) {
 
 	groups, err := u.CognitoClient.AdminListGroupsForUser(&cognitoidentityprovider.AdminListGroupsForUserInput{
 		Username:   aws.String(username),
@@ -119,14 +129,13 @@ func (u *UserDetails) isUserInAdminGroup(username string) (bool, error) {
 	})
 	if err != nil {
 		log.Printf("Was not abile to query a users for its groups: %s", err)
-		return false, fmt.Errorf("Was not abile to query a users for its groups: %s", err)
+		return nil, fmt.Errorf("Was not abile to query a users for its groups: %s", err)
 	}
+	groupNames := []string{}
 	for _, group := range groups.Groups {
-		if *group.GroupName == AdminGroupName {
-			return true, nil
-		}
+		groupNames = append(groupNames, *group.GroupName)
 	}
-	return false, nil
+	return groupNames, nil
 }
 
 func (u *UserDetails) isUserInAdminFromList(groups string) bool {
@@ -70,6 +70,7 @@ func TestUser(t *testing.T) {
 		})
 		require.Equal(t, user.Username, "testuser")
 		require.Equal(t, user.Role, api.AdminGroupName)
+		require.Equal(t, user.Groups, &[]string{api.AdminGroupName})
 	})
 
 	t.Run("CognitoAuthInAdminsRoleAttributes, Output", func(t *testing.T) {
@@ -112,6 +113,7 @@ func TestUser(t *testing.T) {
 		})
 		require.Equal(t, user.Username, "testuser")
 		require.Equal(t, user.Role, api.AdminGroupName)
+		require.Equal(t, user.Groups, &[]string{"group1", "group2", "group3", api.AdminGroupName})
 	})
 
 	t.Run("LookForStringInCommaList, Output", func(t *testing.T) {
@@ -1,8 +1,11 @@
 package budget
 
 import (
+	"fmt"
 	"github.com/Optum/dce/pkg/awsiface"
+	"github.com/Optum/dce/pkg/common"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -15,18 +18,131 @@ import (
 //go:generate mockery -name Service
 type Service interface {
 	CalculateTotalSpend(startDate time.Time, endDate time.Time) (float64, error)
+	CalculateSpendByService(startDate time.Time, endDate time.Time) (map[string]float64, error)
+	CalculateSpendByAccount(startDate time.Time, endDate time.Time) (map[string]map[string]float64, error)
+	ForecastSpend(startDate time.Time, endDate time.Time) (float64, error)
 	SetCostExplorer(costExplorer awsiface.CostExplorerAPI)
+	SetAccounts(accountIDs []string)
+}
+
+// Cost Explorer metrics lease spend may be measured in
+const (
+	CostMetricUnblended    = "UnblendedCost"
+	CostMetricBlended      = "BlendedCost"
+	CostMetricAmortized    = "AmortizedCost"
+	CostMetricNetUnblended = "NetUnblendedCost"
+)
+
+// forecastMetrics maps cost metrics to their Cost Explorer forecast metric
+var forecastMetrics = map[string]string{
+	CostMetricUnblended:    costexplorer.MetricUnblendedCost,
+	CostMetricBlended:      costexplorer.MetricBlendedCost,
+	CostMetricAmortized:    costexplorer.MetricAmortizedCost,
+	CostMetricNetUnblended: costexplorer.MetricNetUnblendedCost,
 }
 
 // Define a concrete implementation of the Service interface
 type AWSBudgetService struct {
 	CostExplorer awsiface.CostExplorerAPI
+	// Cost Explorer metric spend is measured in. Defaults to UnblendedCost
+	Metric string
+	// Cost Explorer record types left out of spend, eg. Credit, Refund, Tax or Support
+	ExcludedRecordTypes []string
+}
+
+// NewAWSBudgetServiceFromEnv creates a budget service measuring spend with the
+// COST_METRIC, leaving out the COST_EXCLUDED_RECORD_TYPES
+func NewAWSBudgetServiceFromEnv() (*AWSBudgetService, error) {
+	metric, err := parseCostMetric(common.GetEnv("COST_METRIC", CostMetricUnblended))
+	if err != nil {
+		return nil, err
+	}
+
+	return &AWSBudgetService{
+		Metric:              metric,
+		ExcludedRecordTypes: parseRecordTypes(common.GetEnv("COST_EXCLUDED_RECORD_TYPES", "")),
+	}, nil
+}
+
+// Sources spend may be read from
+const (
+	SpendSourceCostExplorer = "COST_EXPLORER"
+	SpendSourceCUR          = "CUR"
+)
+
+// NewServiceFromEnv creates a budget service reading spend from the SPEND_SOURCE:
+// Cost Explorer by default, or the Cost and Usage Report in S3
+func NewServiceFromEnv(storage common.Storager) (Service, error) {
+	switch source := strings.ToUpper(common.GetEnv("SPEND_SOURCE", SpendSourceCostExplorer)); source {
+	case SpendSourceCostExplorer:
+		svc, err := NewAWSBudgetServiceFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		return svc, nil
+	case SpendSourceCUR:
+		svc, err := NewCURBudgetServiceFromEnv(storage)
+		if err != nil {
+			return nil, err
+		}
+		return svc, nil
+	default:
+		return nil, fmt.Errorf("unknown spend source %q, expected %s or %s", source, SpendSourceCostExplorer, SpendSourceCUR)
+	}
+}
+
+// parseRecordTypes parses a comma-separated list of record types, eg. "Credit,Refund"
+func parseRecordTypes(recordTypes string) []string {
+	parsed := []string{}
+	for _, recordType := range strings.Split(recordTypes, ",") {
+		if strings.TrimSpace(recordType) != "" {
+			parsed = append(parsed, strings.TrimSpace(recordType))
+		}
+	}
+	return parsed
+}
+
+func parseCostMetric(metric string) (string, error) {
+	for costMetric := range forecastMetrics {
+		if strings.EqualFold(costMetric, strings.TrimSpace(metric)) {
+			return costMetric, nil
+		}
+	}
+	return "", fmt.Errorf("unknown cost metric %q, expected one of %s, %s, %s or %s", metric,
+		CostMetricUnblended, CostMetricBlended, CostMetricAmortized, CostMetricNetUnblended)
+}
+
+// metric returns the Cost Explorer metric spend is measured in
+func (budgetSvc *AWSBudgetService) metric() string {
+	if budgetSvc.Metric == "" {
+		return CostMetricUnblended
+	}
+	return budgetSvc.Metric
+}
+
+// filter returns the Cost Explorer filter leaving out the excluded record types,
+// or nil if no record types are excluded
+func (budgetSvc *AWSBudgetService) filter() *costexplorer.Expression {
+	if len(budgetSvc.ExcludedRecordTypes) == 0 {
+		return nil
+	}
+	return &costexplorer.Expression{
+		Not: &costexplorer.Expression{
+			Dimensions: &costexplorer.DimensionValues{
+				Key:    aws.String(costexplorer.DimensionRecordType),
+				Values: aws.StringSlice(budgetSvc.ExcludedRecordTypes),
+			},
+		},
+	}
 }
 
 func (budgetSvc *AWSBudgetService) SetCostExplorer(costExplorer awsiface.CostExplorerAPI) {
 	budgetSvc.CostExplorer = costExplorer
 }
 
+// SetAccounts does nothing, as Cost Explorer groups spend by account itself
+func (budgetSvc *AWSBudgetService) SetAccounts(accountIDs []string) {}
+
 // Implement the CalculateTotalSpend method of the Service interface
 func (budgetSvc *AWSBudgetService) CalculateTotalSpend(startDate time.Time, endDate time.Time) (float64, error) {
 
@@ -40,13 +156,14 @@ func (budgetSvc *AWSBudgetService) CalculateTotalSpend(startDate time.Time, endD
 		End:   aws.String(endDate.UTC().Format(timeFormat)),
 	}
 
-	metrics := []*string{aws.String("UnblendedCost")}
+	metrics := []*string{aws.String(budgetSvc.metric())}
 	granularity := aws.String("DAILY")
 
 	getCostAndUsageInput := costexplorer.GetCostAndUsageInput{
 		Metrics:     metrics,
 		TimePeriod:  &timePeriod,
 		Granularity: granularity,
+		Filter:      budgetSvc.filter(),
 	}
 
 	output, err := budgetSvc.CostExplorer.GetCostAndUsage(&getCostAndUsageInput)
@@ -57,7 +174,7 @@ func (budgetSvc *AWSBudgetService) CalculateTotalSpend(startDate time.Time, endD
 	var totalCost float64
 
 	for _, result := range output.ResultsByTime {
-		cost, err := strconv.ParseFloat(*result.Total["UnblendedCost"].Amount, 64)
+		cost, err := strconv.ParseFloat(*result.Total[budgetSvc.metric()].Amount, 64)
 		if err != nil {
 			return 0, err
 		}
@@ -67,3 +184,135 @@ func (budgetSvc *AWSBudgetService) CalculateTotalSpend(startDate time.Time, endD
 	}
 	return totalCost, nil
 }
+
+// CalculateSpendByService returns the spend between the start date (inclusive)
+// and end date (exclusive), by AWS service name, eg. "Amazon SageMaker".
+func (budgetSvc *AWSBudgetService) CalculateSpendByService(startDate time.Time, endDate time.Time) (map[string]float64, error) {
+	timeFormat := "2006-01-02"
+	input := &costexplorer.GetCostAndUsageInput{
+		Metrics:     []*string{aws.String(budgetSvc.metric())},
+		Granularity: aws.String("DAILY"),
+		Filter:      budgetSvc.filter(),
+		TimePeriod: &costexplorer.DateInterval{
+			Start: aws.String(startDate.UTC().Format(timeFormat)),
+			End:   aws.String(endDate.UTC().Format(timeFormat)),
+		},
+		GroupBy: []*costexplorer.GroupDefinition{
+			{
+				Type: aws.String(costexplorer.GroupDefinitionTypeDimension),
+				Key:  aws.String(costexplorer.DimensionService),
+			},
+		},
+	}
+
+	serviceCosts := map[string]float64{}
+	for {
+		output, err := budgetSvc.CostExplorer.GetCostAndUsage(input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, result := range output.ResultsByTime {
+			for _, group := range result.Groups {
+				if len(group.Keys) == 0 || group.Metrics[budgetSvc.metric()] == nil {
+					continue
+				}
+				cost, err := strconv.ParseFloat(*group.Metrics[budgetSvc.metric()].Amount, 64)
+				if err != nil {
+					return nil, err
+				}
+				serviceCosts[*group.Keys[0]] += cost
+			}
+		}
+
+		// Grouped results are paged
+		if output.NextPageToken == nil {
+			break
+		}
+		input.NextPageToken = output.NextPageToken
+	}
+
+	return serviceCosts, nil
+}
+
+// CalculateSpendByAccount returns the spend between the start date (inclusive)
+// and end date (exclusive), by linked account ID, then by AWS service name.
+// It's meant for the consolidated billing (master) account, where one call covers every account.
+func (budgetSvc *AWSBudgetService) CalculateSpendByAccount(startDate time.Time, endDate time.Time) (map[string]map[string]float64, error) {
+	timeFormat := "2006-01-02"
+	input := &costexplorer.GetCostAndUsageInput{
+		Metrics:     []*string{aws.String(budgetSvc.metric())},
+		Granularity: aws.String("DAILY"),
+		Filter:      budgetSvc.filter(),
+		TimePeriod: &costexplorer.DateInterval{
+			Start: aws.String(startDate.UTC().Format(timeFormat)),
+			End:   aws.String(endDate.UTC().Format(timeFormat)),
+		},
+		GroupBy: []*costexplorer.GroupDefinition{
+			{
+				Type: aws.String(costexplorer.GroupDefinitionTypeDimension),
+				Key:  aws.String(costexplorer.DimensionLinkedAccount),
+			},
+			{
+				Type: aws.String(costexplorer.GroupDefinitionTypeDimension),
+				Key:  aws.String(costexplorer.DimensionService),
+			},
+		},
+	}
+
+	accountCosts := map[string]map[string]float64{}
+	for {
+		output, err := budgetSvc.CostExplorer.GetCostAndUsage(input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, result := range output.ResultsByTime {
+			for _, group := range result.Groups {
+				if len(group.Keys) < 2 || group.Metrics[budgetSvc.metric()] == nil {
+					continue
+				}
+				cost, err := strconv.ParseFloat(*group.Metrics[budgetSvc.metric()].Amount, 64)
+				if err != nil {
+					return nil, err
+				}
+				accountID, service := *group.Keys[0], *group.Keys[1]
+				if accountCosts[accountID] == nil {
+					accountCosts[accountID] = map[string]float64{}
+				}
+				accountCosts[accountID][service] += cost
+			}
+		}
+
+		// Grouped results are paged
+		if output.NextPageToken == nil {
+			break
+		}
+		input.NextPageToken = output.NextPageToken
+	}
+
+	return accountCosts, nil
+}
+
+// ForecastSpend returns the spend Cost Explorer forecasts between the start date (inclusive)
+// and end date (exclusive). The start date may not be in the past.
+func (budgetSvc *AWSBudgetService) ForecastSpend(startDate time.Time, endDate time.Time) (float64, error) {
+	timeFormat := "2006-01-02"
+	output, err := budgetSvc.CostExplorer.GetCostForecast(&costexplorer.GetCostForecastInput{
+		Metric:      aws.String(forecastMetrics[budgetSvc.metric()]),
+		Granularity: aws.String(costexplorer.GranularityDaily),
+		Filter:      budgetSvc.filter(),
+		TimePeriod: &costexplorer.DateInterval{
+			Start: aws.String(startDate.UTC().Format(timeFormat)),
+			End:   aws.String(endDate.UTC().Format(timeFormat)),
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if output.Total == nil || output.Total.Amount == nil {
+		return 0, nil
+	}
+	return strconv.ParseFloat(*output.Total.Amount, 64)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/costexplorer"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestCalculateTotalSpend(t *testing.T) {
@@ -51,3 +52,172 @@ func TestCalculateTotalSpend(t *testing.T) {
 	assert.Nil(t, err, "There should be no errors")
 	assert.Equal(t, cost, float64(150))
 }
+
+func TestCalculateSpendByService(t *testing.T) {
+	// Mock the CostExplorer SDK, with the groups split across two pages
+	costExplorer := &mocks.CostExplorerAPI{}
+	groupsResult := func(groups map[string]string) []*costexplorer.ResultByTime {
+		result := &costexplorer.ResultByTime{}
+		for service, amount := range groups {
+			result.Groups = append(result.Groups, &costexplorer.Group{
+				Keys: []*string{aws.String(service)},
+				Metrics: map[string]*costexplorer.MetricValue{
+					"UnblendedCost": {
+						Amount: aws.String(amount),
+						Unit:   aws.String("USD"),
+					},
+				},
+			})
+		}
+		return []*costexplorer.ResultByTime{result}
+	}
+	costExplorer.On("GetCostAndUsage", mock.MatchedBy(func(input *costexplorer.GetCostAndUsageInput) bool {
+		return input.NextPageToken == nil
+	})).Return(&costexplorer.GetCostAndUsageOutput{
+		ResultsByTime: groupsResult(map[string]string{"Amazon SageMaker": "100", "EC2 - Other": "20"}),
+		NextPageToken: aws.String("page-2"),
+	}, nil)
+	costExplorer.On("GetCostAndUsage", mock.MatchedBy(func(input *costexplorer.GetCostAndUsageInput) bool {
+		return input.NextPageToken != nil && *input.NextPageToken == "page-2"
+	})).Return(&costexplorer.GetCostAndUsageOutput{
+		ResultsByTime: groupsResult(map[string]string{"EC2 - Other": "5"}),
+	}, nil)
+
+	budgetSvc := AWSBudgetService{
+		CostExplorer: costExplorer,
+	}
+	serviceCosts, err := budgetSvc.CalculateSpendByService(
+		time.Unix(0, 0),
+		time.Unix(0, 0).Add(time.Hour*24),
+	)
+	assert.Nil(t, err, "There should be no errors")
+	assert.Equal(t, map[string]float64{"Amazon SageMaker": 100, "EC2 - Other": 25}, serviceCosts)
+	costExplorer.AssertNumberOfCalls(t, "GetCostAndUsage", 2)
+}
+
+func TestCalculateSpendByAccount(t *testing.T) {
+	// Mock the CostExplorer SDK, with a group per account and service
+	costExplorer := &mocks.CostExplorerAPI{}
+	group := func(accountID string, service string, amount string) *costexplorer.Group {
+		return &costexplorer.Group{
+			Keys: []*string{aws.String(accountID), aws.String(service)},
+			Metrics: map[string]*costexplorer.MetricValue{
+				"UnblendedCost": {
+					Amount: aws.String(amount),
+					Unit:   aws.String("USD"),
+				},
+			},
+		}
+	}
+	costExplorer.On("GetCostAndUsage", mock.MatchedBy(func(input *costexplorer.GetCostAndUsageInput) bool {
+		return len(input.GroupBy) == 2 && *input.GroupBy[0].Key == "LINKED_ACCOUNT" && *input.GroupBy[1].Key == "SERVICE"
+	})).Return(&costexplorer.GetCostAndUsageOutput{
+		ResultsByTime: []*costexplorer.ResultByTime{
+			{
+				Groups: []*costexplorer.Group{
+					group("123456789012", "Amazon SageMaker", "100"),
+					group("123456789012", "EC2 - Other", "20"),
+					group("210987654321", "Amazon S3", "0.5"),
+				},
+			},
+		},
+	}, nil)
+
+	budgetSvc := AWSBudgetService{
+		CostExplorer: costExplorer,
+	}
+	accountCosts, err := budgetSvc.CalculateSpendByAccount(
+		time.Unix(0, 0),
+		time.Unix(0, 0).Add(time.Hour*24),
+	)
+	assert.Nil(t, err, "There should be no errors")
+	assert.Equal(t, map[string]map[string]float64{
+		"123456789012": {"Amazon SageMaker": 100, "EC2 - Other": 20},
+		"210987654321": {"Amazon S3": 0.5},
+	}, accountCosts)
+}
+
+func TestForecastSpend(t *testing.T) {
+	// Mock the CostExplorer SDK
+	costExplorer := &mocks.CostExplorerAPI{}
+	costExplorer.On("GetCostForecast", &costexplorer.GetCostForecastInput{
+		Metric:      aws.String("UNBLENDED_COST"),
+		Granularity: aws.String("DAILY"),
+		TimePeriod: &costexplorer.DateInterval{
+			Start: aws.String("1970-01-02"),
+			End:   aws.String("1970-01-05"),
+		},
+	}).Return(&costexplorer.GetCostForecastOutput{
+		Total: &costexplorer.MetricValue{
+			Amount: aws.String("42.5"),
+			Unit:   aws.String("USD"),
+		},
+	}, nil)
+
+	budgetSvc := AWSBudgetService{
+		CostExplorer: costExplorer,
+	}
+	forecast, err := budgetSvc.ForecastSpend(
+		time.Unix(0, 0).AddDate(0, 0, 1),
+		time.Unix(0, 0).AddDate(0, 0, 4),
+	)
+	assert.Nil(t, err, "There should be no errors")
+	assert.Equal(t, 42.5, forecast)
+}
+
+func TestCalculateTotalSpendWithMetricAndFilter(t *testing.T) {
+	// Mock the CostExplorer SDK
+	costExplorer := &mocks.CostExplorerAPI{}
+	costExplorer.On("GetCostAndUsage", &costexplorer.GetCostAndUsageInput{
+		Metrics:     []*string{aws.String("AmortizedCost")},
+		Granularity: aws.String("DAILY"),
+		TimePeriod: &costexplorer.DateInterval{
+			Start: aws.String("1970-01-01"),
+			End:   aws.String("1970-01-02"),
+		},
+		Filter: &costexplorer.Expression{
+			Not: &costexplorer.Expression{
+				Dimensions: &costexplorer.DimensionValues{
+					Key:    aws.String("RECORD_TYPE"),
+					Values: aws.StringSlice([]string{"Credit", "Refund"}),
+				},
+			},
+		},
+	}).Return(&costexplorer.GetCostAndUsageOutput{
+		ResultsByTime: []*costexplorer.ResultByTime{
+			{
+				Total: map[string]*costexplorer.MetricValue{
+					"AmortizedCost": {
+						Amount: aws.String("75"),
+						Unit:   aws.String("USD"),
+					},
+				},
+			},
+		},
+	}, nil)
+
+	budgetSvc := AWSBudgetService{
+		CostExplorer:        costExplorer,
+		Metric:              CostMetricAmortized,
+		ExcludedRecordTypes: []string{"Credit", "Refund"},
+	}
+	cost, err := budgetSvc.CalculateTotalSpend(
+		time.Unix(0, 0),
+		time.Unix(0, 0).Add(time.Hour*24),
+	)
+	assert.Nil(t, err, "There should be no errors")
+	assert.Equal(t, float64(75), cost)
+}
+
+func TestNewAWSBudgetServiceFromEnv(t *testing.T) {
+	t.Setenv("COST_METRIC", "netunblendedcost")
+	t.Setenv("COST_EXCLUDED_RECORD_TYPES", "Credit, Tax,")
+	budgetSvc, err := NewAWSBudgetServiceFromEnv()
+	assert.Nil(t, err)
+	assert.Equal(t, CostMetricNetUnblended, budgetSvc.Metric)
+	assert.Equal(t, []string{"Credit", "Tax"}, budgetSvc.ExcludedRecordTypes)
+
+	t.Setenv("COST_METRIC", "ListCost")
+	_, err = NewAWSBudgetServiceFromEnv()
+	assert.NotNil(t, err)
+}
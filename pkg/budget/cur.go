@@ -0,0 +1,528 @@
+package budget
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Optum/dce/pkg/awsiface"
+	"github.com/Optum/dce/pkg/common"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/parquet-go/parquet-go"
+)
+
+// Formats the Cost and Usage Report may be delivered in
+const (
+	CURFormatCSV     = "CSV"
+	CURFormatParquet = "PARQUET"
+)
+
+// CUR columns spend is read from, named as in Parquet reports.
+// CSV report headers, eg. lineItem/UsageAccountId, are converted to these names.
+const (
+	curColumnAccountID    = "line_item_usage_account_id"
+	curColumnLineItemType = "line_item_line_item_type"
+	curColumnUsageStart   = "line_item_usage_start_date"
+	curColumnProductCode  = "line_item_product_code"
+	curColumnProductName  = "product_product_name"
+)
+
+// curCostColumns maps cost metrics to the CUR column they're read from.
+// Amortized costs are spread across several columns, so aren't supported.
+var curCostColumns = map[string]string{
+	CostMetricUnblended:    "line_item_unblended_cost",
+	CostMetricBlended:      "line_item_blended_cost",
+	CostMetricNetUnblended: "line_item_net_unblended_cost",
+}
+
+// julianUnixEpoch is the Julian day of the Unix epoch, which Parquet INT96 timestamps count days from
+const julianUnixEpoch = 2440588
+
+// curSpend is the spend of each day, by linked account and AWS service
+type curSpend map[time.Time]map[string]map[string]float64
+
+// CURBudgetService is a Service reading spend from the AWS Cost and Usage Report (CUR)
+// delivered to S3 by the consolidated billing account, rather than from Cost Explorer.
+// The report covers every linked account, so spend is for all of them, unless broken down by account,
+// or limited to the accounts set with SetAccounts.
+type CURBudgetService struct {
+	Storage common.Storager
+	// Bucket and Prefix the report is delivered to
+	Bucket string
+	Prefix string
+	// ReportName of the report
+	ReportName string
+	// Format of the report, CSV or PARQUET
+	Format string
+	// Cost metric spend is measured in. Defaults to UnblendedCost
+	Metric string
+	// Line item types left out of spend, eg. Credit, Refund or Tax
+	ExcludedRecordTypes []string
+	// Accounts line items are kept for. Every account's are kept when empty
+	Accounts map[string]bool
+
+	// spend of each billing period read so far, by the first day of the period
+	periods map[time.Time]curSpend
+}
+
+// NewCURBudgetServiceFromEnv creates a budget service reading spend from the
+// CUR_REPORT_NAME report in CUR_BUCKET and CUR_PREFIX, delivered in the CUR_FORMAT.
+// Spend is measured with the COST_METRIC, leaving out the COST_EXCLUDED_RECORD_TYPES
+func NewCURBudgetServiceFromEnv(storage common.Storager) (*CURBudgetService, error) {
+	metric, err := parseCostMetric(common.GetEnv("COST_METRIC", CostMetricUnblended))
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := curCostColumns[metric]; !ok {
+		return nil, fmt.Errorf("cost metric %s can't be read from the Cost and Usage Report", metric)
+	}
+
+	format := strings.ToUpper(strings.TrimSpace(common.GetEnv("CUR_FORMAT", CURFormatCSV)))
+	if format != CURFormatCSV && format != CURFormatParquet {
+		return nil, fmt.Errorf("unknown Cost and Usage Report format %q, expected %s or %s", format, CURFormatCSV, CURFormatParquet)
+	}
+
+	svc := &CURBudgetService{
+		Storage:             storage,
+		Bucket:              common.GetEnv("CUR_BUCKET", ""),
+		Prefix:              common.GetEnv("CUR_PREFIX", ""),
+		ReportName:          common.GetEnv("CUR_REPORT_NAME", ""),
+		Format:              format,
+		Metric:              metric,
+		ExcludedRecordTypes: parseRecordTypes(common.GetEnv("COST_EXCLUDED_RECORD_TYPES", "")),
+	}
+	if svc.Bucket == "" || svc.ReportName == "" {
+		return nil, fmt.Errorf("CUR_BUCKET and CUR_REPORT_NAME are required to read the Cost and Usage Report")
+	}
+	return svc, nil
+}
+
+// CalculateTotalSpend returns the spend of every linked account between the dates
+func (s *CURBudgetService) CalculateTotalSpend(startDate time.Time, endDate time.Time) (float64, error) {
+	accountCosts, err := s.CalculateSpendByAccount(startDate, endDate)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0.0
+	for _, serviceCosts := range accountCosts {
+		for _, cost := range serviceCosts {
+			total += cost
+		}
+	}
+	return total, nil
+}
+
+// CalculateSpendByService returns the spend of every linked account between the dates,
+// by AWS service
+func (s *CURBudgetService) CalculateSpendByService(startDate time.Time, endDate time.Time) (map[string]float64, error) {
+	accountCosts, err := s.CalculateSpendByAccount(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	costs := map[string]float64{}
+	for _, serviceCosts := range accountCosts {
+		for service, cost := range serviceCosts {
+			costs[service] += cost
+		}
+	}
+	return costs, nil
+}
+
+// CalculateSpendByAccount returns the spend between the dates,
+// by linked account ID and AWS service.
+// Like Cost Explorer, the end date is exclusive.
+func (s *CURBudgetService) CalculateSpendByAccount(startDate time.Time, endDate time.Time) (map[string]map[string]float64, error) {
+	startDay := truncateToDay(startDate)
+	costs := map[string]map[string]float64{}
+
+	for period := truncateToMonth(startDay); period.Before(endDate); period = period.AddDate(0, 1, 0) {
+		spend, err := s.periodSpend(period)
+		if err != nil {
+			return nil, err
+		}
+
+		for day, accountCosts := range spend {
+			if day.Before(startDay) || !day.Before(endDate) {
+				continue
+			}
+			for accountID, serviceCosts := range accountCosts {
+				if costs[accountID] == nil {
+					costs[accountID] = map[string]float64{}
+				}
+				for service, cost := range serviceCosts {
+					costs[accountID][service] += cost
+				}
+			}
+		}
+	}
+
+	return costs, nil
+}
+
+// ForecastSpend isn't supported, as the Cost and Usage Report only has past spend
+func (s *CURBudgetService) ForecastSpend(startDate time.Time, endDate time.Time) (float64, error) {
+	return 0, fmt.Errorf("the Cost and Usage Report can't forecast spend")
+}
+
+// SetCostExplorer does nothing, as spend is read from the Cost and Usage Report
+func (s *CURBudgetService) SetCostExplorer(costExplorer awsiface.CostExplorerAPI) {}
+
+// SetAccounts limits spend to the accounts, so only their line items are kept
+// while reading the report. Billing periods read so far are read again.
+func (s *CURBudgetService) SetAccounts(accountIDs []string) {
+	s.Accounts = map[string]bool{}
+	for _, accountID := range accountIDs {
+		s.Accounts[accountID] = true
+	}
+	s.periods = nil
+}
+
+// periodSpend returns the daily spend of the billing period starting on the date.
+// Each billing period is only read once.
+func (s *CURBudgetService) periodSpend(period time.Time) (curSpend, error) {
+	if spend, ok := s.periods[period]; ok {
+		return spend, nil
+	}
+
+	keys, err := s.reportKeys(period)
+	if err != nil {
+		return nil, err
+	}
+
+	spend := curSpend{}
+	for _, key := range keys {
+		log.Printf("Reading Cost and Usage Report s3://%s/%s", s.Bucket, key)
+		err = s.readReport(spend, key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if s.periods == nil {
+		s.periods = map[time.Time]curSpend{}
+	}
+	s.periods[period] = spend
+	return spend, nil
+}
+
+// readReport adds the line items of a report file to the spend,
+// reading CSV files as they're downloaded
+func (s *CURBudgetService) readReport(spend curSpend, key string) error {
+	body, err := s.Storage.GetObjectReader(s.Bucket, key)
+	if err != nil {
+		return fmt.Errorf("failed to read Cost and Usage Report s3://%s/%s: %w", s.Bucket, key, err)
+	}
+	defer body.Close()
+
+	if s.Format == CURFormatParquet {
+		err = s.readParquet(spend, body)
+	} else {
+		err = s.readCSV(spend, key, body)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse Cost and Usage Report s3://%s/%s: %w", s.Bucket, key, err)
+	}
+	return nil
+}
+
+// reportKeys returns the keys of the report files for the billing period.
+// CSV reports list the files of their latest version in a manifest,
+// while Parquet reports overwrite the files in a folder for each month.
+func (s *CURBudgetService) reportKeys(period time.Time) ([]string, error) {
+	if s.Format == CURFormatParquet {
+		prefix := path.Join(s.Prefix, s.ReportName, s.ReportName,
+			fmt.Sprintf("year=%d", period.Year()), fmt.Sprintf("month=%d", int(period.Month()))) + "/"
+		objects, err := s.Storage.ListObjects(s.Bucket, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Cost and Usage Report s3://%s/%s: %w", s.Bucket, prefix, err)
+		}
+
+		keys := []string{}
+		for _, key := range objects {
+			if strings.HasSuffix(key, ".parquet") {
+				keys = append(keys, key)
+			}
+		}
+		return keys, nil
+	}
+
+	billingPeriod := period.Format("20060102") + "-" + period.AddDate(0, 1, 0).Format("20060102")
+	manifestKey := path.Join(s.Prefix, s.ReportName, billingPeriod, s.ReportName+"-Manifest.json")
+	object, err := s.Storage.GetObject(s.Bucket, manifestKey)
+	if err != nil {
+		// AWS hasn't delivered a report for the billing period yet
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read Cost and Usage Report manifest s3://%s/%s: %w", s.Bucket, manifestKey, err)
+	}
+
+	manifest := struct {
+		ReportKeys []string `json:"reportKeys"`
+	}{}
+	err = json.Unmarshal([]byte(object), &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Cost and Usage Report manifest s3://%s/%s: %w", s.Bucket, manifestKey, err)
+	}
+	return manifest.ReportKeys, nil
+}
+
+// readCSV adds the line items of a CSV report file, which may be gzipped, to the spend
+func (s *CURBudgetService) readCSV(spend curSpend, key string, input io.Reader) error {
+	if strings.HasSuffix(key, ".gz") {
+		gzipReader, err := gzip.NewReader(input)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		input = gzipReader
+	}
+
+	reader := csv.NewReader(input)
+	header, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[curColumnName(name)] = i
+	}
+
+	column := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		accountID := column(record, curColumnAccountID)
+		if !s.keepsAccount(accountID) {
+			continue
+		}
+
+		usageStart, err := time.Parse(time.RFC3339, column(record, curColumnUsageStart))
+		if err != nil {
+			return err
+		}
+		cost := 0.0
+		if value := column(record, curCostColumns[s.metric()]); value != "" {
+			cost, err = strconv.ParseFloat(value, 64)
+			if err != nil {
+				return err
+			}
+		}
+
+		s.addLineItem(spend, curLineItem{
+			accountID:    accountID,
+			lineItemType: column(record, curColumnLineItemType),
+			usageStart:   usageStart,
+			productCode:  column(record, curColumnProductCode),
+			productName:  column(record, curColumnProductName),
+			cost:         cost,
+		})
+	}
+}
+
+// readParquet adds the line items of a Parquet report file to the spend.
+// Parquet files are read from their footer, so are downloaded before they're read.
+func (s *CURBudgetService) readParquet(spend curSpend, input io.Reader) error {
+	object, err := io.ReadAll(input)
+	if err != nil {
+		return err
+	}
+	file, err := parquet.OpenFile(bytes.NewReader(object), int64(len(object)))
+	if err != nil {
+		return err
+	}
+
+	columns := map[int]string{}
+	var usageStartColumn parquet.Node
+	for _, name := range []string{curColumnAccountID, curColumnLineItemType, curColumnUsageStart,
+		curColumnProductCode, curColumnProductName, curCostColumns[s.metric()]} {
+		leaf, ok := file.Schema().Lookup(name)
+		if !ok {
+			continue
+		}
+		columns[leaf.ColumnIndex] = name
+		if name == curColumnUsageStart {
+			usageStartColumn = leaf.Node
+		}
+	}
+
+	reader := parquet.NewReader(file)
+	defer reader.Close()
+	rows := make([]parquet.Row, 100)
+	for {
+		n, err := reader.ReadRows(rows)
+		for _, row := range rows[:n] {
+			item := curLineItem{}
+			var parseErr error
+			for _, value := range row {
+				if value.IsNull() {
+					continue
+				}
+				switch columns[value.Column()] {
+				case curColumnAccountID:
+					item.accountID = value.String()
+				case curColumnLineItemType:
+					item.lineItemType = value.String()
+				case curColumnProductCode:
+					item.productCode = value.String()
+				case curColumnProductName:
+					item.productName = value.String()
+				case curColumnUsageStart:
+					item.usageStart, parseErr = parquetTime(value, usageStartColumn)
+				case curCostColumns[s.metric()]:
+					item.cost, parseErr = parquetFloat(value)
+				}
+				if parseErr != nil {
+					return parseErr
+				}
+			}
+			s.addLineItem(spend, item)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// curLineItem is the part of a CUR line item spend is calculated from
+type curLineItem struct {
+	accountID    string
+	lineItemType string
+	usageStart   time.Time
+	productCode  string
+	productName  string
+	cost         float64
+}
+
+// addLineItem adds the line item's cost to the spend of its account, on the day its usage started
+func (s *CURBudgetService) addLineItem(spend curSpend, item curLineItem) {
+	if !s.keepsAccount(item.accountID) {
+		return
+	}
+	for _, recordType := range s.ExcludedRecordTypes {
+		if strings.EqualFold(recordType, item.lineItemType) {
+			return
+		}
+	}
+
+	service := item.productName
+	if service == "" {
+		service = item.productCode
+	}
+
+	day := truncateToDay(item.usageStart)
+	if spend[day] == nil {
+		spend[day] = map[string]map[string]float64{}
+	}
+	if spend[day][item.accountID] == nil {
+		spend[day][item.accountID] = map[string]float64{}
+	}
+	spend[day][item.accountID][service] += item.cost
+}
+
+// keepsAccount returns whether the account's line items are kept
+func (s *CURBudgetService) keepsAccount(accountID string) bool {
+	return len(s.Accounts) == 0 || s.Accounts[accountID]
+}
+
+func (s *CURBudgetService) metric() string {
+	if s.Metric == "" {
+		return CostMetricUnblended
+	}
+	return s.Metric
+}
+
+// curColumnName converts a CSV report header, eg. lineItem/UsageAccountId,
+// to its Parquet column name, eg. line_item_usage_account_id
+func curColumnName(header string) string {
+	name := strings.Builder{}
+	var prev rune
+	for _, r := range header {
+		switch {
+		case r == '/':
+			name.WriteRune('_')
+		case unicode.IsUpper(r):
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) {
+				name.WriteRune('_')
+			}
+			name.WriteRune(unicode.ToLower(r))
+		default:
+			name.WriteRune(r)
+		}
+		prev = r
+	}
+	return name.String()
+}
+
+// parquetTime reads a timestamp, which CUR writes as an INT96, an INT64 or a string
+func parquetTime(value parquet.Value, node parquet.Node) (time.Time, error) {
+	switch value.Kind() {
+	case parquet.Int96:
+		i := value.Int96()
+		nanos := int64(i[1])<<32 | int64(i[0])
+		return time.Unix((int64(i[2])-julianUnixEpoch)*86400, nanos).UTC(), nil
+	case parquet.Int64:
+		logicalType := node.Type().LogicalType()
+		if logicalType != nil && logicalType.Timestamp != nil {
+			switch {
+			case logicalType.Timestamp.Unit.Nanos != nil:
+				return time.Unix(0, value.Int64()).UTC(), nil
+			case logicalType.Timestamp.Unit.Micros != nil:
+				return time.UnixMicro(value.Int64()).UTC(), nil
+			}
+		}
+		return time.UnixMilli(value.Int64()).UTC(), nil
+	case parquet.ByteArray:
+		return time.Parse(time.RFC3339, value.String())
+	}
+	return time.Time{}, fmt.Errorf("unexpected %s value for %s", value.Kind(), curColumnUsageStart)
+}
+
+// parquetFloat reads a cost, which may be a DOUBLE, a FLOAT or a string
+func parquetFloat(value parquet.Value) (float64, error) {
+	switch value.Kind() {
+	case parquet.Double:
+		return value.Double(), nil
+	case parquet.Float:
+		return float64(value.Float()), nil
+	case parquet.ByteArray:
+		return strconv.ParseFloat(value.String(), 64)
+	}
+	return 0, fmt.Errorf("unexpected %s value for cost", value.Kind())
+}
+
+func truncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func truncateToMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
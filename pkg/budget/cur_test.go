@@ -0,0 +1,198 @@
+package budget
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	commonMocks "github.com/Optum/dce/pkg/common/mocks"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const curCSVReport = `identity/LineItemId,lineItem/UsageAccountId,lineItem/LineItemType,lineItem/UsageStartDate,lineItem/ProductCode,lineItem/UnblendedCost,product/ProductName
+1,123456789012,Usage,2024-05-14T10:00:00Z,AmazonEC2,10.5,Amazon Elastic Compute Cloud
+2,123456789012,Usage,2024-05-15T10:00:00Z,AmazonEC2,20,Amazon Elastic Compute Cloud
+3,123456789012,Usage,2024-05-15T11:00:00Z,AmazonS3,1.25,Amazon Simple Storage Service
+4,123456789012,Tax,2024-05-15T00:00:00Z,AmazonEC2,2,Amazon Elastic Compute Cloud
+5,210987654321,Usage,2024-05-15T09:00:00Z,AWSDataTransfer,3,
+`
+
+func gzipString(t *testing.T, content string) string {
+	buf := &bytes.Buffer{}
+	writer := gzip.NewWriter(buf)
+	_, err := writer.Write([]byte(content))
+	require.Nil(t, err)
+	require.Nil(t, writer.Close())
+	return buf.String()
+}
+
+// reportFile returns a report file's body, each time it's read
+func reportFile(content string) func(string, string) io.ReadCloser {
+	return func(string, string) io.ReadCloser {
+		return io.NopCloser(strings.NewReader(content))
+	}
+}
+
+func TestCURCalculateSpendByAccountCSV(t *testing.T) {
+	storage := &commonMocks.Storager{}
+	storage.On("GetObject", "cur-bucket", "cur/dce/20240501-20240601/dce-Manifest.json").
+		Return(`{"reportKeys": ["cur/dce/20240501-20240601/abc/dce-1.csv.gz"]}`, nil).Once()
+	storage.On("GetObjectReader", "cur-bucket", "cur/dce/20240501-20240601/abc/dce-1.csv.gz").
+		Return(reportFile(gzipString(t, curCSVReport)), nil).Once()
+
+	svc := &CURBudgetService{
+		Storage:             storage,
+		Bucket:              "cur-bucket",
+		Prefix:              "cur",
+		ReportName:          "dce",
+		Format:              CURFormatCSV,
+		ExcludedRecordTypes: []string{"tax"},
+	}
+
+	startDate := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+	costs, err := svc.CalculateSpendByAccount(startDate, startDate.AddDate(0, 0, 1))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]map[string]float64{
+		"123456789012": {"Amazon Elastic Compute Cloud": 20, "Amazon Simple Storage Service": 1.25},
+		"210987654321": {"AWSDataTransfer": 3},
+	}, costs)
+
+	// The billing period has already been read
+	total, err := svc.CalculateTotalSpend(startDate.AddDate(0, 0, -1), startDate.AddDate(0, 0, 1))
+	assert.Nil(t, err)
+	assert.Equal(t, 34.75, total)
+	storage.AssertExpectations(t)
+}
+
+func TestCURCalculateSpendByAccountSetAccounts(t *testing.T) {
+	storage := &commonMocks.Storager{}
+	storage.On("GetObject", "cur-bucket", "dce/20240501-20240601/dce-Manifest.json").
+		Return(`{"reportKeys": ["dce/20240501-20240601/abc/dce-1.csv"]}`, nil)
+	storage.On("GetObjectReader", "cur-bucket", "dce/20240501-20240601/abc/dce-1.csv").
+		Return(reportFile(curCSVReport), nil).Twice()
+
+	svc := &CURBudgetService{
+		Storage:    storage,
+		Bucket:     "cur-bucket",
+		ReportName: "dce",
+		Format:     CURFormatCSV,
+	}
+
+	startDate := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+	svc.SetAccounts([]string{"210987654321"})
+	costs, err := svc.CalculateSpendByAccount(startDate, startDate.AddDate(0, 0, 1))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]map[string]float64{
+		"210987654321": {"AWSDataTransfer": 3},
+	}, costs)
+
+	// The report is read again for the new accounts
+	svc.SetAccounts([]string{"123456789012"})
+	costs, err = svc.CalculateSpendByAccount(startDate, startDate.AddDate(0, 0, 1))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]map[string]float64{
+		"123456789012": {"Amazon Elastic Compute Cloud": 22, "Amazon Simple Storage Service": 1.25},
+	}, costs)
+	storage.AssertExpectations(t)
+}
+
+func TestCURCalculateSpendByServiceWithoutReport(t *testing.T) {
+	storage := &commonMocks.Storager{}
+	storage.On("GetObject", "cur-bucket", "dce/20240501-20240601/dce-Manifest.json").
+		Return("", awserr.New(s3.ErrCodeNoSuchKey, "not found", nil))
+	storage.On("GetObject", "cur-bucket", "dce/20240601-20240701/dce-Manifest.json").
+		Return("", errors.New("access denied"))
+
+	svc := &CURBudgetService{
+		Storage:    storage,
+		Bucket:     "cur-bucket",
+		ReportName: "dce",
+		Format:     CURFormatCSV,
+	}
+
+	costs, err := svc.CalculateSpendByService(time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC))
+	assert.Nil(t, err, "billing periods without a report have no spend")
+	assert.Equal(t, map[string]float64{}, costs)
+
+	_, err = svc.CalculateSpendByService(time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC), time.Date(2024, 6, 16, 0, 0, 0, 0, time.UTC))
+	assert.NotNil(t, err)
+}
+
+func TestCURCalculateSpendByAccountParquet(t *testing.T) {
+	type lineItem struct {
+		AccountID    string    `parquet:"line_item_usage_account_id"`
+		LineItemType string    `parquet:"line_item_line_item_type"`
+		UsageStart   time.Time `parquet:"line_item_usage_start_date,timestamp"`
+		ProductName  string    `parquet:"product_product_name"`
+		BlendedCost  float64   `parquet:"line_item_blended_cost"`
+	}
+	buf := &bytes.Buffer{}
+	err := parquet.Write(buf, []lineItem{
+		{"123456789012", "Usage", time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC), "Amazon SageMaker", 100},
+		{"123456789012", "Credit", time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC), "Amazon SageMaker", -50},
+		{"123456789012", "Usage", time.Date(2024, 5, 16, 10, 0, 0, 0, time.UTC), "Amazon SageMaker", 40},
+	})
+	require.Nil(t, err)
+
+	storage := &commonMocks.Storager{}
+	storage.On("ListObjects", "cur-bucket", "cur/dce/dce/year=2024/month=5/").
+		Return([]string{"cur/dce/dce/year=2024/month=5/dce-00001.snappy.parquet", "cur/dce/dce/year=2024/month=5/status"}, nil)
+	storage.On("GetObjectReader", "cur-bucket", "cur/dce/dce/year=2024/month=5/dce-00001.snappy.parquet").
+		Return(reportFile(buf.String()), nil)
+
+	svc := &CURBudgetService{
+		Storage:    storage,
+		Bucket:     "cur-bucket",
+		Prefix:     "cur",
+		ReportName: "dce",
+		Format:     CURFormatParquet,
+		Metric:     CostMetricBlended,
+	}
+
+	startDate := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+	costs, err := svc.CalculateSpendByAccount(startDate, startDate.AddDate(0, 0, 1))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]map[string]float64{
+		"123456789012": {"Amazon SageMaker": 50},
+	}, costs)
+}
+
+func TestCURColumnName(t *testing.T) {
+	assert.Equal(t, "line_item_usage_account_id", curColumnName("lineItem/UsageAccountId"))
+	assert.Equal(t, "line_item_net_unblended_cost", curColumnName("lineItem/NetUnblendedCost"))
+	assert.Equal(t, "product_product_name", curColumnName("product/ProductName"))
+	assert.Equal(t, "line_item_usage_start_date", curColumnName("line_item_usage_start_date"))
+}
+
+func TestNewServiceFromEnv(t *testing.T) {
+	svc, err := NewServiceFromEnv(&commonMocks.Storager{})
+	assert.Nil(t, err)
+	assert.IsType(t, &AWSBudgetService{}, svc)
+
+	t.Setenv("SPEND_SOURCE", "cur")
+	_, err = NewServiceFromEnv(&commonMocks.Storager{})
+	assert.NotNil(t, err, "the report's bucket and name are required")
+
+	t.Setenv("CUR_BUCKET", "cur-bucket")
+	t.Setenv("CUR_REPORT_NAME", "dce")
+	t.Setenv("CUR_FORMAT", "parquet")
+	svc, err = NewServiceFromEnv(&commonMocks.Storager{})
+	assert.Nil(t, err)
+	assert.Equal(t, CURFormatParquet, svc.(*CURBudgetService).Format)
+
+	t.Setenv("COST_METRIC", "AmortizedCost")
+	_, err = NewServiceFromEnv(&commonMocks.Storager{})
+	assert.NotNil(t, err, "amortized costs can't be read from the report")
+
+	t.Setenv("SPEND_SOURCE", "BILLING")
+	_, err = NewServiceFromEnv(&commonMocks.Storager{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,24 @@
+package budget
+
+import (
+	"time"
+)
+
+// LinearForecast projects the spend at the end date, assuming spending continues
+// at the same daily rate as between the start date and now.
+// At least a day is taken to have elapsed, so a new lease's first hours of spend
+// don't project to a huge daily rate.
+func LinearForecast(spend float64, startDate time.Time, now time.Time, endDate time.Time) float64 {
+	if !now.Before(endDate) {
+		return spend
+	}
+
+	elapsed := now.Sub(startDate)
+	if elapsed < 24*time.Hour {
+		elapsed = 24 * time.Hour
+	}
+	dailyRate := spend / elapsed.Hours() * 24
+	remainingDays := endDate.Sub(now).Hours() / 24
+
+	return spend + dailyRate*remainingDays
+}
@@ -0,0 +1,55 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinearForecast(t *testing.T) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		spend       float64
+		now         time.Time
+		end         time.Time
+		expForecast float64
+	}{
+		{
+			name:        "should project the daily rate to the end date",
+			spend:       40,
+			now:         start.AddDate(0, 0, 4),
+			end:         start.AddDate(0, 0, 10),
+			expForecast: 100,
+		},
+		{
+			name:        "should take at least a day to have elapsed",
+			spend:       10,
+			now:         start.Add(time.Hour),
+			end:         start.Add(time.Hour).AddDate(0, 0, 2),
+			expForecast: 30,
+		},
+		{
+			name:        "should not project past the end date",
+			spend:       40,
+			now:         start.AddDate(0, 0, 10),
+			end:         start.AddDate(0, 0, 7),
+			expForecast: 40,
+		},
+		{
+			name:        "should forecast nothing without spend",
+			spend:       0,
+			now:         start.AddDate(0, 0, 4),
+			end:         start.AddDate(0, 0, 10),
+			expForecast: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expForecast, LinearForecast(tt.spend, start, tt.now, tt.end), 0.0001)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package budget
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	// Lambda runtimes don't always ship the time zone database
+	_ "time/tzdata"
+
+	"github.com/Optum/dce/pkg/common"
+)
+
+// PeriodType is the kind of principal budget period
+type PeriodType string
+
+const (
+	// PeriodDaily resets the principal budget every day
+	PeriodDaily PeriodType = "DAILY"
+	// PeriodWeekly resets the principal budget every week, on the configured start day
+	PeriodWeekly PeriodType = "WEEKLY"
+	// PeriodMonthly resets the principal budget on the first of every month
+	PeriodMonthly PeriodType = "MONTHLY"
+	// PeriodQuarterly resets the principal budget on the first of January, April, July and October
+	PeriodQuarterly PeriodType = "QUARTERLY"
+	// PeriodRolling measures the principal budget over the last N days, including today
+	PeriodRolling PeriodType = "ROLLING"
+)
+
+// Period is the period across which a principal's spend is measured
+// against their principal budget
+type Period struct {
+	Type      PeriodType
+	WeekStart time.Weekday   // Day WEEKLY periods start on
+	Days      int            // Length of ROLLING periods, in days
+	Location  *time.Location // Time zone the period starts and ends in
+}
+
+// NewPeriodInput is the configuration of a principal budget period
+type NewPeriodInput struct {
+	Period      string // DAILY, WEEKLY, MONTHLY, QUARTERLY or ROLLING. Defaults to WEEKLY
+	WeekStart   string // Day WEEKLY periods start on, eg. SUNDAY. Defaults to SUNDAY
+	RollingDays int    // Length of ROLLING periods, in days
+	TimeZone    string // IANA time zone, eg. America/Chicago. Defaults to UTC
+}
+
+// NewPeriod creates a principal budget period from its configuration
+func NewPeriod(input NewPeriodInput) (*Period, error) {
+	period := &Period{
+		Type:      PeriodType(strings.ToUpper(strings.TrimSpace(input.Period))),
+		WeekStart: time.Sunday,
+		Days:      input.RollingDays,
+		Location:  time.UTC,
+	}
+	if period.Type == "" {
+		period.Type = PeriodWeekly
+	}
+
+	switch period.Type {
+	case PeriodDaily, PeriodWeekly, PeriodMonthly, PeriodQuarterly:
+	case PeriodRolling:
+		if period.Days < 1 {
+			return nil, fmt.Errorf("rolling budget periods must be at least 1 day long, got %d", period.Days)
+		}
+	default:
+		return nil, fmt.Errorf("unknown budget period %q, expected one of DAILY, WEEKLY, MONTHLY, QUARTERLY or ROLLING", input.Period)
+	}
+
+	if input.WeekStart != "" {
+		weekStart, err := parseWeekday(input.WeekStart)
+		if err != nil {
+			return nil, err
+		}
+		period.WeekStart = weekStart
+	}
+
+	if input.TimeZone != "" {
+		location, err := time.LoadLocation(input.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("unknown budget period time zone %q: %w", input.TimeZone, err)
+		}
+		period.Location = location
+	}
+
+	return period, nil
+}
+
+// NewPeriodFromEnv creates the principal budget period configured in the environment
+func NewPeriodFromEnv() (*Period, error) {
+	return NewPeriod(NewPeriodInput{
+		Period:      common.GetEnv("PRINCIPAL_BUDGET_PERIOD", string(PeriodWeekly)),
+		WeekStart:   common.GetEnv("PRINCIPAL_BUDGET_PERIOD_WEEK_START", "SUNDAY"),
+		RollingDays: common.GetEnvInt("PRINCIPAL_BUDGET_PERIOD_ROLLING_DAYS", 30),
+		TimeZone:    common.GetEnv("PRINCIPAL_BUDGET_TIME_ZONE", "UTC"),
+	})
+}
+
+// Start returns the beginning of the period t is in
+func (p *Period) Start(t time.Time) time.Time {
+	t = t.In(p.Location)
+	today := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, p.Location)
+
+	switch p.Type {
+	case PeriodDaily:
+		return today
+	case PeriodMonthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, p.Location)
+	case PeriodQuarterly:
+		quarterMonth := time.Month((int(t.Month())-1)/3*3 + 1)
+		return time.Date(t.Year(), quarterMonth, 1, 0, 0, 0, 0, p.Location)
+	case PeriodRolling:
+		return today.AddDate(0, 0, -(p.Days - 1))
+	default:
+		daysSinceStart := (int(t.Weekday()) - int(p.WeekStart) + 7) % 7
+		return today.AddDate(0, 0, -daysSinceStart)
+	}
+}
+
+// End returns the end of the period t is in, which is the beginning of the next period.
+// Rolling periods end at the end of the day.
+func (p *Period) End(t time.Time) time.Time {
+	start := p.Start(t)
+
+	switch p.Type {
+	case PeriodDaily:
+		return start.AddDate(0, 0, 1)
+	case PeriodMonthly:
+		return start.AddDate(0, 1, 0)
+	case PeriodQuarterly:
+		return start.AddDate(0, 3, 0)
+	case PeriodRolling:
+		return start.AddDate(0, 0, p.Days)
+	default:
+		return start.AddDate(0, 0, 7)
+	}
+}
+
+// String returns a description of the period, eg. "WEEKLY (SUNDAY, UTC)"
+func (p *Period) String() string {
+	switch p.Type {
+	case PeriodWeekly:
+		return fmt.Sprintf("%s (%s, %s)", p.Type, strings.ToUpper(p.WeekStart.String()), p.Location)
+	case PeriodRolling:
+		return fmt.Sprintf("%s (%d days, %s)", p.Type, p.Days, p.Location)
+	default:
+		return fmt.Sprintf("%s (%s)", p.Type, p.Location)
+	}
+}
+
+func parseWeekday(day string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(d.String(), strings.TrimSpace(day)) {
+			return d, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("unknown budget period week start %q, expected a day of the week", day)
+}
@@ -0,0 +1,140 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriod(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.Nil(t, err)
+
+	// Wednesday
+	wednesday := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	// Monday in UTC, but still Sunday evening in Chicago
+	mondayUTC := time.Date(2024, 5, 13, 3, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		input    NewPeriodInput
+		now      time.Time
+		expStart time.Time
+		expEnd   time.Time
+	}{
+		{
+			name:     "should default to weekly periods starting on Sunday",
+			input:    NewPeriodInput{},
+			now:      wednesday,
+			expStart: time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC),
+			expEnd:   time.Date(2024, 5, 19, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "should ignore the case of the period",
+			input:    NewPeriodInput{Period: "Weekly"},
+			now:      wednesday,
+			expStart: time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC),
+			expEnd:   time.Date(2024, 5, 19, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "should start weekly periods on the week start",
+			input:    NewPeriodInput{Period: "WEEKLY", WeekStart: "monday"},
+			now:      wednesday,
+			expStart: time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC),
+			expEnd:   time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "should start weekly periods today, when today is the week start",
+			input:    NewPeriodInput{Period: "WEEKLY", WeekStart: "WEDNESDAY"},
+			now:      wednesday,
+			expStart: time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC),
+			expEnd:   time.Date(2024, 5, 22, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "should start daily periods today",
+			input:    NewPeriodInput{Period: "DAILY"},
+			now:      wednesday,
+			expStart: time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC),
+			expEnd:   time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "should start monthly periods on the first of the month",
+			input:    NewPeriodInput{Period: "MONTHLY"},
+			now:      wednesday,
+			expStart: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			expEnd:   time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "should start quarterly periods on the first of the quarter",
+			input:    NewPeriodInput{Period: "QUARTERLY"},
+			now:      wednesday,
+			expStart: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			expEnd:   time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "should cover the last N days, including today, for rolling periods",
+			input:    NewPeriodInput{Period: "ROLLING", RollingDays: 30},
+			now:      wednesday,
+			expStart: time.Date(2024, 4, 16, 0, 0, 0, 0, time.UTC),
+			expEnd:   time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "should start daily periods in the organization's time zone",
+			input:    NewPeriodInput{Period: "DAILY", TimeZone: "America/Chicago"},
+			now:      mondayUTC,
+			expStart: time.Date(2024, 5, 12, 0, 0, 0, 0, chicago),
+			expEnd:   time.Date(2024, 5, 13, 0, 0, 0, 0, chicago),
+		},
+		{
+			name:     "should start weekly periods in the organization's time zone",
+			input:    NewPeriodInput{Period: "WEEKLY", WeekStart: "MONDAY", TimeZone: "America/Chicago"},
+			now:      mondayUTC,
+			expStart: time.Date(2024, 5, 6, 0, 0, 0, 0, chicago),
+			expEnd:   time.Date(2024, 5, 13, 0, 0, 0, 0, chicago),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			period, err := NewPeriod(tt.input)
+			require.Nil(t, err)
+
+			assert.True(t, tt.expStart.Equal(period.Start(tt.now)), "expected start %s, got %s", tt.expStart, period.Start(tt.now))
+			assert.True(t, tt.expEnd.Equal(period.End(tt.now)), "expected end %s, got %s", tt.expEnd, period.End(tt.now))
+		})
+	}
+}
+
+func TestNewPeriodErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input NewPeriodInput
+	}{
+		{
+			name:  "should fail on unknown periods",
+			input: NewPeriodInput{Period: "FORTNIGHTLY"},
+		},
+		{
+			name:  "should fail on unknown week starts",
+			input: NewPeriodInput{Period: "WEEKLY", WeekStart: "FUNDAY"},
+		},
+		{
+			name:  "should fail on unknown time zones",
+			input: NewPeriodInput{Period: "MONTHLY", TimeZone: "Mars/Olympus_Mons"},
+		},
+		{
+			name:  "should fail on empty rolling periods",
+			input: NewPeriodInput{Period: "ROLLING", RollingDays: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			period, err := NewPeriod(tt.input)
+			assert.Nil(t, period)
+			assert.NotNil(t, err)
+		})
+	}
+}
@@ -2,7 +2,11 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	io "io"
+
+	mock "github.com/stretchr/testify/mock"
+)
 
 // Storager is an autogenerated mock type for the Storager type
 type Storager struct {
@@ -44,6 +48,29 @@ func (_m *Storager) GetObject(bucket string, key string) (string, error) {
 	return r0, r1
 }
 
+// GetObjectReader provides a mock function with given fields: bucket, key
+func (_m *Storager) GetObjectReader(bucket string, key string) (io.ReadCloser, error) {
+	ret := _m.Called(bucket, key)
+
+	var r0 io.ReadCloser
+	if rf, ok := ret.Get(0).(func(string, string) io.ReadCloser); ok {
+		r0 = rf(bucket, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(bucket, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetTemplateObject provides a mock function with given fields: bucket, key, input
 func (_m *Storager) GetTemplateObject(bucket string, key string, input interface{}) (string, string, error) {
 	ret := _m.Called(bucket, key, input)
@@ -71,3 +98,40 @@ func (_m *Storager) GetTemplateObject(bucket string, key string, input interface
 
 	return r0, r1, r2
 }
+
+// ListObjects provides a mock function with given fields: bucket, prefix
+func (_m *Storager) ListObjects(bucket string, prefix string) ([]string, error) {
+	ret := _m.Called(bucket, prefix)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string, string) []string); ok {
+		r0 = rf(bucket, prefix)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(bucket, prefix)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PutObject provides a mock function with given fields: bucket, key, body
+func (_m *Storager) PutObject(bucket string, key string, body string) error {
+	ret := _m.Called(bucket, key, body)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(bucket, key, body)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
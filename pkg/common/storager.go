@@ -3,6 +3,7 @@ package common
 import (
 	"bytes"
 	"html/template"
+	"io"
 	"log"
 	"os"
 	"strings"
@@ -16,8 +17,11 @@ import (
 // based on the provided S3 Object Input
 type Storager interface {
 	GetObject(bucket string, key string) (string, error)
+	GetObjectReader(bucket string, key string) (io.ReadCloser, error)
 	GetTemplateObject(bucket string, key string, input interface{}) (string, string, error)
 	Download(bukcet string, key string, filepath string) error
+	ListObjects(bucket string, prefix string) ([]string, error)
+	PutObject(bucket string, key string, body string) error
 }
 
 // S3 implements the Storage interface using AWS S3 Client
@@ -50,6 +54,20 @@ func (stor S3) GetObject(bucket string, key string) (string, error) {
 	return object, nil
 }
 
+// GetObjectReader returns the body of an existing object from S3, to be read
+// as it's downloaded rather than all at once. The caller closes the body.
+func (stor S3) GetObjectReader(bucket string, key string) (io.ReadCloser, error) {
+	getInput := s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	}
+	getOutput, err := stor.Client.GetObject(&getInput)
+	if err != nil {
+		return nil, err
+	}
+	return getOutput.Body, nil
+}
+
 // GetObjectWithETag returns a string output based on the results of the retrieval
 // of an existing object from S3
 func (stor S3) GetObjectWithETag(bucket string, key string) (string, string, error) {
@@ -124,3 +142,36 @@ func (stor S3) Download(bucket string, key string, filepath string) error {
 	_, err = stor.Manager.Download(file, getInput)
 	return err
 }
+
+// ListObjects returns the keys of every S3 Object in the Bucket
+// starting with the prefix provided
+func (stor S3) ListObjects(bucket string, prefix string) ([]string, error) {
+	keys := []string{}
+	listInput := &s3.ListObjectsV2Input{
+		Bucket: &bucket,
+		Prefix: &prefix,
+	}
+	err := stor.Client.ListObjectsV2Pages(listInput,
+		func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+			for _, object := range page.Contents {
+				keys = append(keys, *object.Key)
+			}
+			return true
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// PutObject creates or replaces an S3 Object in the Bucket with the body provided
+func (stor S3) PutObject(bucket string, key string, body string) error {
+	putInput := &s3.PutObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+		Body:   strings.NewReader(body),
+	}
+	_, err := stor.Client.PutObject(putInput)
+	return err
+}
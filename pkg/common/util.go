@@ -68,6 +68,24 @@ func GetEnvInt(env string, defaultValue int) int {
 	return intVal
 }
 
+// GetEnvFloat returns an environment that is required to be a float64.
+// The defaultValue is returned if the variable does not exist.
+func GetEnvFloat(env string, defaultValue float64) float64 {
+	val, ok := os.LookupEnv(env)
+
+	if !ok {
+		return defaultValue
+	}
+
+	floatVal, err := strconv.ParseFloat(val, 64)
+
+	if err != nil {
+		return defaultValue
+	}
+
+	return floatVal
+}
+
 // RequireEnvStringSlice - Requires the given environment variable to contain a slice of string
 func RequireEnvStringSlice(env string, sep string) []string {
 	val := RequireEnv(env)
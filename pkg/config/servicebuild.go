@@ -16,8 +16,10 @@ import (
 	"github.com/Optum/dce/pkg/accountmanager"
 	"github.com/Optum/dce/pkg/accountmanager/accountmanageriface"
 	"github.com/Optum/dce/pkg/common"
+	"github.com/Optum/dce/pkg/currency"
 	"github.com/Optum/dce/pkg/data"
 	"github.com/Optum/dce/pkg/data/dataiface"
+	"github.com/Optum/dce/pkg/email"
 	"github.com/Optum/dce/pkg/event"
 	"github.com/Optum/dce/pkg/event/eventiface"
 	"github.com/Optum/dce/pkg/lease"
@@ -30,6 +32,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
 	"github.com/aws/aws-sdk-go/service/sns/snsiface"
 	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	"github.com/aws/aws-sdk-go/service/ssm"
@@ -42,6 +45,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/ses"
 	"github.com/aws/aws-sdk-go/service/sns"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/aws/aws-sdk-go/service/sts"
@@ -140,6 +144,19 @@ func (bldr *ServiceBuilder) WithLambda() *ServiceBuilder {
 	return bldr
 }
 
+// WithSES tells the builder to add an AWS SES service to the `DefaultConfigurater`
+func (bldr *ServiceBuilder) WithSES() *ServiceBuilder {
+	bldr.handlers = append(bldr.handlers, bldr.createSES)
+	return bldr
+}
+
+// WithEmailService tells the builder to add the Email service to the `ConfigurationBuilder`
+func (bldr *ServiceBuilder) WithEmailService() *ServiceBuilder {
+	bldr.WithSES()
+	bldr.handlers = append(bldr.handlers, bldr.createEmailService)
+	return bldr
+}
+
 // WithStorageService tells the builder to add the DCE DAO (DBer) service to the `ConfigurationBuilder`
 func (bldr *ServiceBuilder) WithStorageService() *ServiceBuilder {
 	bldr.WithS3()
@@ -161,6 +178,36 @@ func (bldr *ServiceBuilder) WithLeaseDataService() *ServiceBuilder {
 	return bldr
 }
 
+// WithLeaseRequestDataService tells the builder to add the Data service to the `ConfigurationBuilder`
+func (bldr *ServiceBuilder) WithLeaseRequestDataService() *ServiceBuilder {
+	bldr.WithDynamoDB()
+	bldr.handlers = append(bldr.handlers, bldr.createLeaseRequestDataService)
+	return bldr
+}
+
+// WithLeaseProfileDataService tells the builder to add the Data service to the `ConfigurationBuilder`
+func (bldr *ServiceBuilder) WithLeaseProfileDataService() *ServiceBuilder {
+	bldr.WithDynamoDB()
+	bldr.handlers = append(bldr.handlers, bldr.createLeaseProfileDataService)
+	return bldr
+}
+
+// LeaseProfileData returns the lease profile Data service for you
+func (bldr *ServiceBuilder) LeaseProfileData() dataiface.LeaseProfileData {
+	var profileData dataiface.LeaseProfileData
+	if err := bldr.Config.GetService(&profileData); err != nil {
+		panic(err)
+	}
+	return profileData
+}
+
+// WithLeaseHistoryDataService tells the builder to add the Data service to the `ConfigurationBuilder`
+func (bldr *ServiceBuilder) WithLeaseHistoryDataService() *ServiceBuilder {
+	bldr.WithDynamoDB()
+	bldr.handlers = append(bldr.handlers, bldr.createLeaseHistoryDataService)
+	return bldr
+}
+
 // WithAccountManagerService tells the builder to add the Data service to the `ConfigurationBuilder`
 func (bldr *ServiceBuilder) WithAccountManagerService() *ServiceBuilder {
 	bldr.WithSTS().WithStorageService()
@@ -197,7 +244,7 @@ func (bldr *ServiceBuilder) AccountService() accountiface.Servicer {
 
 // WithLeaseService tells the builder to add the Account service to the `ConfigurationBuilder`
 func (bldr *ServiceBuilder) WithLeaseService() *ServiceBuilder {
-	bldr.WithLeaseDataService().WithEventService().WithAccountService()
+	bldr.WithLeaseDataService().WithLeaseRequestDataService().WithLeaseProfileDataService().WithLeaseHistoryDataService().WithEventService().WithAccountService().WithEmailService().WithStorageService()
 	bldr.handlers = append(bldr.handlers, bldr.createLeaseService)
 	return bldr
 }
@@ -438,6 +485,39 @@ func (bldr *ServiceBuilder) createLambda(config ConfigurationServiceBuilder) err
 	return nil
 }
 
+func (bldr *ServiceBuilder) createSES(config ConfigurationServiceBuilder) error {
+	// Don't add the service twice
+	var api sesiface.SESAPI
+	err := bldr.Config.GetService(&api)
+	if err == nil {
+		log.Printf("Already added SES service")
+		return nil
+	}
+
+	sesSvc := ses.New(bldr.awsSession)
+	config.WithService(sesSvc)
+	return nil
+}
+
+func (bldr *ServiceBuilder) createEmailService(config ConfigurationServiceBuilder) error {
+	// Don't add the service twice
+	var api email.Service
+	err := bldr.Config.GetService(&api)
+	if err == nil {
+		log.Printf("Already added Email service")
+		return nil
+	}
+
+	var sesSvc sesiface.SESAPI
+	err = bldr.Config.GetService(&sesSvc)
+	if err != nil {
+		return err
+	}
+
+	config.WithService(&email.SESEmailService{SES: sesSvc})
+	return nil
+}
+
 func (bldr *ServiceBuilder) createStorageService(config ConfigurationServiceBuilder) error {
 	// Don't add the service twice
 	var api common.Storager
@@ -670,6 +750,99 @@ func (bldr *ServiceBuilder) createLeaseDataService(config ConfigurationServiceBu
 	return nil
 }
 
+func (bldr *ServiceBuilder) createLeaseRequestDataService(config ConfigurationServiceBuilder) error {
+	// Don't add the service twice
+	var api dataiface.LeaseRequestData
+	err := bldr.Config.GetService(&api)
+	if err == nil {
+		log.Printf("Already added Lease Request Data service")
+		return nil
+	}
+
+	var dynamodbSvc dynamodbiface.DynamoDBAPI
+	err = bldr.Config.GetService(&dynamodbSvc)
+
+	if err != nil {
+		return err
+	}
+
+	dataSvcImpl := &data.LeaseRequest{}
+
+	err = bldr.Config.Unmarshal(dataSvcImpl)
+	if err != nil {
+		return err
+	}
+
+	dataSvcImpl.DynamoDB = dynamodbSvc
+
+	config.WithService(dataSvcImpl)
+	return nil
+}
+
+func (bldr *ServiceBuilder) createLeaseProfileDataService(config ConfigurationServiceBuilder) error {
+	// Don't add the service twice
+	var api dataiface.LeaseProfileData
+	err := bldr.Config.GetService(&api)
+	if err == nil {
+		log.Printf("Already added Lease Profile Data service")
+		return nil
+	}
+
+	var dynamodbSvc dynamodbiface.DynamoDBAPI
+	err = bldr.Config.GetService(&dynamodbSvc)
+
+	if err != nil {
+		return err
+	}
+
+	dataSvcImpl := &data.LeaseProfile{}
+
+	err = bldr.Config.Unmarshal(dataSvcImpl)
+	if err != nil {
+		return err
+	}
+
+	dataSvcImpl.DynamoDB = dynamodbSvc
+
+	config.WithService(dataSvcImpl)
+	return nil
+}
+
+func (bldr *ServiceBuilder) createLeaseHistoryDataService(config ConfigurationServiceBuilder) error {
+	// Don't add the service twice
+	var api dataiface.LeaseHistoryData
+	err := bldr.Config.GetService(&api)
+	if err == nil {
+		log.Printf("Already added Lease History Data service")
+		return nil
+	}
+
+	var dynamodbSvc dynamodbiface.DynamoDBAPI
+	err = bldr.Config.GetService(&dynamodbSvc)
+
+	if err != nil {
+		return err
+	}
+
+	dataSvcImpl := &data.LeaseHistory{}
+
+	err = bldr.Config.Unmarshal(dataSvcImpl)
+	if err != nil {
+		return err
+	}
+
+	// Lease history is optional, don't add the service without a table
+	if dataSvcImpl.TableName == "" {
+		log.Printf("No Lease History table configured, lease changes won't be recorded")
+		return nil
+	}
+
+	dataSvcImpl.DynamoDB = dynamodbSvc
+
+	config.WithService(dataSvcImpl)
+	return nil
+}
+
 func (bldr *ServiceBuilder) createLeaseService(config ConfigurationServiceBuilder) error {
 	// Don't add the service twice
 	var api leaseiface.Servicer
@@ -685,6 +858,22 @@ func (bldr *ServiceBuilder) createLeaseService(config ConfigurationServiceBuilde
 		return err
 	}
 
+	var requestSvc dataiface.LeaseRequestData
+	err = bldr.Config.GetService(&requestSvc)
+	if err != nil {
+		return err
+	}
+
+	var profileSvc dataiface.LeaseProfileData
+	err = bldr.Config.GetService(&profileSvc)
+	if err != nil {
+		return err
+	}
+
+	// The history service isn't added without a table, so it may be missing
+	var historySvc dataiface.LeaseHistoryData
+	_ = bldr.Config.GetService(&historySvc)
+
 	var eventSvc eventiface.Servicer
 	err = bldr.Config.GetService(&eventSvc)
 	if err != nil {
@@ -697,14 +886,35 @@ func (bldr *ServiceBuilder) createLeaseService(config ConfigurationServiceBuilde
 		return err
 	}
 
+	var emailSvc email.Service
+	err = bldr.Config.GetService(&emailSvc)
+	if err != nil {
+		return err
+	}
+
+	var storageSvc common.Storager
+	err = bldr.Config.GetService(&storageSvc)
+	if err != nil {
+		return err
+	}
+
 	leaseSvcInput := lease.NewServiceInput{}
 	if err := bldr.Config.Unmarshal(&leaseSvcInput); err != nil {
 		log.Printf("Could not load configuration: %s", err.Error())
 		return err
 	}
 	leaseSvcInput.DataSvc = dataSvc
+	leaseSvcInput.RequestSvc = requestSvc
+	leaseSvcInput.ProfileSvc = profileSvc
+	leaseSvcInput.HistorySvc = historySvc
 	leaseSvcInput.EventSvc = eventSvc
 	leaseSvcInput.AccountSvc = accountSvc
+	leaseSvcInput.EmailSvc = emailSvc
+	leaseSvcInput.TemplateSvc = storageSvc
+	leaseSvcInput.CurrencyConverter, err = currency.NewConverterFromEnv(storageSvc)
+	if err != nil {
+		return err
+	}
 	leaseSvc := lease.NewService(
 		leaseSvcInput,
 	)
@@ -0,0 +1,144 @@
+package currency
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Optum/dce/pkg/common"
+)
+
+// RateProvider provides exchange rates, as the amount of each currency
+// worth one unit of the base currency
+type RateProvider interface {
+	Rates() (map[string]float64, error)
+}
+
+// StaticRates is a fixed table of exchange rates, eg. from configuration
+type StaticRates map[string]float64
+
+// Rates returns the exchange rate table
+func (r StaticRates) Rates() (map[string]float64, error) {
+	return r, nil
+}
+
+// ParseStaticRates parses an exchange rate table formatted as "EUR=0.92,GBP=0.79"
+func ParseStaticRates(table string) (StaticRates, error) {
+	rates := StaticRates{}
+	for _, entry := range strings.Split(table, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid exchange rate %q, expected \"CURRENCY=rate\"", entry)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate %q, expected a positive number", entry)
+		}
+		rates[strings.ToUpper(strings.TrimSpace(parts[0]))] = rate
+	}
+	return rates, nil
+}
+
+// S3Rates reads the exchange rate table from a JSON object in S3, eg. {"EUR": 0.92, "GBP": 0.79}.
+// The table is only read once.
+type S3Rates struct {
+	S3     common.Storager
+	Bucket string
+	Key    string
+
+	once  sync.Once
+	rates StaticRates
+	err   error
+}
+
+// Rates returns the exchange rate table
+func (r *S3Rates) Rates() (map[string]float64, error) {
+	r.once.Do(func() {
+		var object string
+		object, r.err = r.S3.GetObject(r.Bucket, r.Key)
+		if r.err != nil {
+			r.err = fmt.Errorf("failed to read exchange rates from s3://%s/%s: %w", r.Bucket, r.Key, r.err)
+			return
+		}
+		r.err = json.Unmarshal([]byte(object), &r.rates)
+		if r.err != nil {
+			r.err = fmt.Errorf("failed to parse exchange rates from s3://%s/%s: %w", r.Bucket, r.Key, r.err)
+		}
+	})
+	return r.rates, r.err
+}
+
+// Converter converts amounts between currencies
+type Converter struct {
+	BaseCurrency string // Currency the rates are relative to, ie. the Cost Explorer currency
+	Provider     RateProvider
+}
+
+// NewConverterFromEnv creates a converter from the COST_CURRENCY and CURRENCY_RATES environment variables.
+// If CURRENCY_RATES_BUCKET and CURRENCY_RATES_KEY are set, the rates are read from that S3 object instead.
+func NewConverterFromEnv(s3Svc common.Storager) (*Converter, error) {
+	converter := &Converter{
+		BaseCurrency: common.GetEnv("COST_CURRENCY", "USD"),
+	}
+
+	bucket := common.GetEnv("CURRENCY_RATES_BUCKET", "")
+	key := common.GetEnv("CURRENCY_RATES_KEY", "")
+	if bucket != "" && key != "" {
+		converter.Provider = &S3Rates{S3: s3Svc, Bucket: bucket, Key: key}
+		return converter, nil
+	}
+
+	rates, err := ParseStaticRates(common.GetEnv("CURRENCY_RATES", ""))
+	if err != nil {
+		return nil, err
+	}
+	converter.Provider = rates
+	return converter, nil
+}
+
+// Convert converts the amount from one currency to another.
+// An empty currency is taken to be the base currency.
+func (c *Converter) Convert(amount float64, from string, to string) (float64, error) {
+	from = c.normalize(from)
+	to = c.normalize(to)
+	if from == to {
+		return amount, nil
+	}
+
+	fromRate, err := c.rate(from)
+	if err != nil {
+		return 0, err
+	}
+	toRate, err := c.rate(to)
+	if err != nil {
+		return 0, err
+	}
+	return amount / fromRate * toRate, nil
+}
+
+func (c *Converter) normalize(currency string) string {
+	if currency == "" {
+		return strings.ToUpper(c.BaseCurrency)
+	}
+	return strings.ToUpper(currency)
+}
+
+func (c *Converter) rate(currency string) (float64, error) {
+	if currency == strings.ToUpper(c.BaseCurrency) {
+		return 1, nil
+	}
+	rates, err := c.Provider.Rates()
+	if err != nil {
+		return 0, err
+	}
+	rate, ok := rates[currency]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("no exchange rate from %s to %s", c.BaseCurrency, currency)
+	}
+	return rate, nil
+}
@@ -0,0 +1,114 @@
+package currency
+
+import (
+	"errors"
+	"testing"
+
+	commonMocks "github.com/Optum/dce/pkg/common/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvert(t *testing.T) {
+	converter := &Converter{
+		BaseCurrency: "USD",
+		Provider:     StaticRates{"EUR": 0.5, "GBP": 0.25},
+	}
+
+	tests := []struct {
+		name      string
+		amount    float64
+		from      string
+		to        string
+		expAmount float64
+		expErr    bool
+	}{
+		{
+			name:      "should convert from the base currency",
+			amount:    100,
+			from:      "USD",
+			to:        "EUR",
+			expAmount: 50,
+		},
+		{
+			name:      "should convert to the base currency",
+			amount:    100,
+			from:      "EUR",
+			to:        "USD",
+			expAmount: 200,
+		},
+		{
+			name:      "should convert between other currencies",
+			amount:    100,
+			from:      "EUR",
+			to:        "GBP",
+			expAmount: 50,
+		},
+		{
+			name:      "should treat an empty currency as the base currency",
+			amount:    100,
+			from:      "",
+			to:        "eur",
+			expAmount: 50,
+		},
+		{
+			name:      "should not convert within the same currency, even without a rate",
+			amount:    100,
+			from:      "JPY",
+			to:        "JPY",
+			expAmount: 100,
+		},
+		{
+			name:   "should fail without an exchange rate",
+			amount: 100,
+			from:   "USD",
+			to:     "JPY",
+			expErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, err := converter.Convert(tt.amount, tt.from, tt.to)
+			if tt.expErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.InDelta(t, tt.expAmount, amount, 0.0001)
+		})
+	}
+}
+
+func TestParseStaticRates(t *testing.T) {
+	rates, err := ParseStaticRates("eur=0.92, GBP = 0.79,")
+	assert.Nil(t, err)
+	assert.Equal(t, StaticRates{"EUR": 0.92, "GBP": 0.79}, rates)
+
+	rates, err = ParseStaticRates("")
+	assert.Nil(t, err)
+	assert.Equal(t, StaticRates{}, rates)
+
+	_, err = ParseStaticRates("EUR")
+	assert.NotNil(t, err)
+
+	_, err = ParseStaticRates("EUR=-1")
+	assert.NotNil(t, err)
+}
+
+func TestS3Rates(t *testing.T) {
+	s3Svc := &commonMocks.Storager{}
+	s3Svc.On("GetObject", "rates-bucket", "rates.json").Return(`{"EUR": 0.92}`, nil).Once()
+
+	rates := &S3Rates{S3: s3Svc, Bucket: "rates-bucket", Key: "rates.json"}
+	for i := 0; i < 2; i++ {
+		table, err := rates.Rates()
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]float64{"EUR": 0.92}, table)
+	}
+	s3Svc.AssertNumberOfCalls(t, "GetObject", 1)
+
+	failing := &commonMocks.Storager{}
+	failing.On("GetObject", "rates-bucket", "rates.json").Return("", errors.New("access denied"))
+	_, err := (&S3Rates{S3: failing, Bucket: "rates-bucket", Key: "rates.json"}).Rates()
+	assert.NotNil(t, err)
+}
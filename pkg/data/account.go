@@ -2,6 +2,7 @@ package data
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/Optum/dce/pkg/account"
 	"github.com/Optum/dce/pkg/errors"
@@ -79,6 +80,69 @@ func (a *Account) Write(account *account.Account, prevLastModifiedOn *int64) err
 	return nil
 }
 
+// TransitionStatus updates the status of the Account record in DynamoDB,
+// but only if the account is currently in the prevStatus.
+// This allows callers to claim an account without racing other writers.
+func (a *Account) TransitionStatus(ID string, prevStatus account.Status, nextStatus account.Status) (*account.Account, error) {
+
+	now := time.Now().Unix()
+	condExpr := expression.Name("AccountStatus").Equal(expression.Value(prevStatus.String()))
+	updateExpr := expression.
+		Set(expression.Name("AccountStatus"), expression.Value(nextStatus.String())).
+		Set(expression.Name("LastModifiedOn"), expression.Value(now))
+	// Remember when the account was claimed, for the least-recently-leased account selection
+	if nextStatus == account.StatusLeased {
+		updateExpr = updateExpr.Set(expression.Name("LastLeasedOn"), expression.Value(now))
+	}
+	expr, err := expression.NewBuilder().WithCondition(condExpr).WithUpdate(updateExpr).Build()
+	if err != nil {
+		return nil, errors.NewInternalServer("error building query", err)
+	}
+
+	res, err := a.DynamoDB.UpdateItem(
+		&dynamodb.UpdateItemInput{
+			TableName: aws.String(a.TableName),
+			Key: map[string]*dynamodb.AttributeValue{
+				"Id": {
+					S: aws.String(ID),
+				},
+			},
+			ConditionExpression:       expr.Condition(),
+			UpdateExpression:          expr.Update(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			// Return the updated record
+			ReturnValues: aws.String("ALL_NEW"),
+		},
+	)
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		if awsErr.Code() == "ConditionalCheckFailedException" {
+			return nil, errors.NewConflict(
+				"account",
+				ID,
+				fmt.Errorf("unable to update account: account status is not %q", prevStatus))
+		}
+	}
+	if err != nil {
+		return nil, errors.NewInternalServer(
+			fmt.Sprintf("update status failed for account %q", ID),
+			err,
+		)
+	}
+
+	updated := account.Account{}
+	err = dynamodbattribute.UnmarshalMap(res.Attributes, &updated)
+	if err != nil {
+		return nil, errors.NewInternalServer(
+			fmt.Sprintf("failure unmarshaling account with id %q", ID),
+			err,
+		)
+	}
+
+	return &updated, nil
+}
+
 // Delete the Account record in DynamoDB
 func (a *Account) Delete(account *account.Account) error {
 
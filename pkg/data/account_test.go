@@ -261,3 +261,89 @@ func TestUpdate(t *testing.T) {
 	}
 
 }
+
+func TestTransitionStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		prevStatus  account.Status
+		nextStatus  account.Status
+		dynamoRes   *dynamodb.UpdateItemOutput
+		dynamoErr   error
+		expAccount  *account.Account
+		expectedErr error
+	}{
+		{
+			name:       "transition",
+			prevStatus: account.StatusReady,
+			nextStatus: account.StatusLeased,
+			dynamoRes: &dynamodb.UpdateItemOutput{
+				Attributes: map[string]*dynamodb.AttributeValue{
+					"Id": {
+						S: aws.String("123456789012"),
+					},
+					"AccountStatus": {
+						S: aws.String("Leased"),
+					},
+				},
+			},
+			expAccount: &account.Account{
+				ID:     ptrString("123456789012"),
+				Status: account.StatusLeased.StatusPtr(),
+			},
+		},
+		{
+			name:       "conditional failure",
+			prevStatus: account.StatusReady,
+			nextStatus: account.StatusLeased,
+			dynamoErr:  awserr.New("ConditionalCheckFailedException", "Message", fmt.Errorf("Bad")),
+			expectedErr: errors.NewConflict(
+				"account",
+				"123456789012",
+				fmt.Errorf("unable to update account: account status is not \"Ready\"")),
+		},
+		{
+			name:        "other dynamo error",
+			prevStatus:  account.StatusLeased,
+			nextStatus:  account.StatusReady,
+			dynamoErr:   gErrors.New("failure"),
+			expectedErr: errors.NewInternalServer("update status failed for account \"123456789012\"", gErrors.New("failure")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDynamo := awsmocks.DynamoDBAPI{}
+
+			mockDynamo.On("UpdateItem", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+				// Claimed accounts record when they were last leased
+				setsLastLeasedOn := false
+				for _, name := range input.ExpressionAttributeNames {
+					if *name == "LastLeasedOn" {
+						setsLastLeasedOn = true
+					}
+				}
+				return (*input.TableName == "Accounts" &&
+					*input.Key["Id"].S == "123456789012" &&
+					*input.ConditionExpression == "#0 = :0" &&
+					*input.ExpressionAttributeValues[":0"].S == tt.prevStatus.String() &&
+					*input.ExpressionAttributeValues[":1"].S == tt.nextStatus.String() &&
+					setsLastLeasedOn == (tt.nextStatus == account.StatusLeased))
+			})).Return(
+				tt.dynamoRes, tt.dynamoErr,
+			)
+			accountData := &Account{
+				DynamoDB:  &mockDynamo,
+				TableName: "Accounts",
+			}
+
+			acct, err := accountData.TransitionStatus("123456789012", tt.prevStatus, tt.nextStatus)
+			assert.Truef(t, errors.Is(err, tt.expectedErr), "actual error %q doesn't match expected error %q", err, tt.expectedErr)
+			if tt.expAccount != nil {
+				assert.Equal(t, tt.expAccount.ID, acct.ID)
+				assert.Equal(t, tt.expAccount.Status, acct.Status)
+			} else {
+				assert.Nil(t, acct)
+			}
+		})
+	}
+}
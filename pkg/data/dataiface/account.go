@@ -13,6 +13,9 @@ type AccountData interface {
 	// be inserted or updated
 	// prevLastModifiedOn parameter is the original lastModifiedOn
 	Write(account *account.Account, prevLastModifiedOn *int64) error
+	// TransitionStatus updates the status of the Account record in DynamoDB,
+	// but only if the account is currently in the prevStatus.
+	TransitionStatus(ID string, prevStatus account.Status, nextStatus account.Status) (*account.Account, error)
 	// Delete the Account record in DynamoDB
 	Delete(account *account.Account) error
 	// Get the Account record by ID
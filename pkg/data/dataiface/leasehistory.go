@@ -0,0 +1,17 @@
+//
+
+package dataiface
+
+import (
+	"github.com/Optum/dce/pkg/lease"
+)
+
+// LeaseHistoryData makes working with the Lease History Data Layer easier
+type LeaseHistoryData interface {
+
+	// List gets the Lease History records of a lease
+	List(leaseID string) (*lease.Histories, error)
+
+	// Write the Lease History record in DynamoDB
+	Write(history *lease.History) error
+}
@@ -0,0 +1,23 @@
+//
+
+package dataiface
+
+import (
+	"github.com/Optum/dce/pkg/lease"
+)
+
+// LeaseProfileData makes working with the Lease Profile Data Layer easier
+type LeaseProfileData interface {
+
+	// Get the Lease Profile record by name
+	Get(name string) (*lease.Profile, error)
+
+	// List Get a list of lease profiles
+	List(query *lease.Profile) (*lease.Profiles, error)
+
+	// Write the Lease Profile record in DynamoDB
+	// This is an upsert operation in which the record will either
+	// be inserted or updated
+	// prevLastModifiedOn parameter is the original lastModifiedOn
+	Write(profile *lease.Profile, prevLastModifiedOn *int64) error
+}
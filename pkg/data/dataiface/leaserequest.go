@@ -0,0 +1,23 @@
+//
+
+package dataiface
+
+import (
+	"github.com/Optum/dce/pkg/lease"
+)
+
+// LeaseRequestData makes working with the Lease Request Data Layer easier
+type LeaseRequestData interface {
+
+	// Get the Lease Request record by ID
+	Get(requestID string) (*lease.Request, error)
+
+	// List Get a list of lease requests
+	List(query *lease.Request) (*lease.Requests, error)
+
+	// Write the Lease Request record in DynamoDB
+	// This is an upsert operation in which the record will either
+	// be inserted or updated
+	// prevLastModifiedOn parameter is the original lastModifiedOn
+	Write(request *lease.Request, prevLastModifiedOn *int64) error
+}
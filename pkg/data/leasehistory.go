@@ -0,0 +1,87 @@
+package data
+
+import (
+	"fmt"
+
+	"github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// LeaseHistory - Data Layer Struct
+type LeaseHistory struct {
+	DynamoDB       dynamodbiface.DynamoDBAPI
+	TableName      string `env:"LEASE_HISTORY_DB"`
+	ConsistentRead bool   `env:"USE_CONSISTENT_READS" envDefault:"false"`
+}
+
+// Write the Lease History record in DynamoDB
+// History records are never updated, so this is an insert operation
+func (a *LeaseHistory) Write(history *lease.History) error {
+
+	putMap, _ := dynamodbattribute.Marshal(history)
+	input := &dynamodb.PutItemInput{
+		TableName:    aws.String(a.TableName),
+		Item:         putMap.M,
+		ReturnValues: aws.String("NONE"),
+	}
+	err := putItem(input, a.DynamoDB)
+	if err != nil {
+		return errors.NewInternalServer(
+			fmt.Sprintf("write failed for lease history of lease %q", *history.LeaseID),
+			err,
+		)
+	}
+
+	return nil
+}
+
+// List gets the Lease History records of a lease
+func (a *LeaseHistory) List(leaseID string) (*lease.Histories, error) {
+
+	keyCondition := expression.Key("LeaseId").Equal(expression.Value(leaseID))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, errors.NewInternalServer("unable to build query", err)
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(a.TableName),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ConsistentRead:            aws.Bool(a.ConsistentRead),
+	}
+
+	histories := lease.Histories{}
+	for {
+		res, err := query(input, a.DynamoDB)
+		if err != nil {
+			return nil, errors.NewInternalServer(
+				fmt.Sprintf("get lease history failed for lease %q", leaseID),
+				err,
+			)
+		}
+
+		page := lease.Histories{}
+		err = dynamodbattribute.UnmarshalListOfMaps(res.Items, &page)
+		if err != nil {
+			return nil, errors.NewInternalServer(
+				fmt.Sprintf("failure unmarshaling lease history of lease %q", leaseID),
+				err,
+			)
+		}
+		histories = append(histories, page...)
+
+		if len(res.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = res.LastEvaluatedKey
+	}
+
+	return &histories, nil
+}
@@ -0,0 +1,83 @@
+package data
+
+import (
+	gErrors "errors"
+	"testing"
+
+	awsmocks "github.com/Optum/dce/pkg/awsiface/mocks"
+	"github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListLeaseHistory(t *testing.T) {
+	tests := []struct {
+		name         string
+		leaseID      string
+		dynamoErr    error
+		dynamoOutput *dynamodb.QueryOutput
+		expectedErr  error
+		expected     *lease.Histories
+	}{
+		{
+			name:    "should return the lease history",
+			leaseID: "abc123",
+			dynamoOutput: &dynamodb.QueryOutput{
+				Items: []map[string]*dynamodb.AttributeValue{
+					{
+						"LeaseId": {
+							S: aws.String("abc123"),
+						},
+						"Id": {
+							S: aws.String("1"),
+						},
+						"Actor": {
+							S: aws.String("user1"),
+						},
+						"LeaseStatus": {
+							S: aws.String("Active"),
+						},
+					},
+				},
+			},
+			expected: &lease.Histories{
+				lease.History{
+					LeaseID: ptrString("abc123"),
+					ID:      ptrString("1"),
+					Actor:   ptrString("user1"),
+					Status:  lease.StatusActive.StatusPtr(),
+				},
+			},
+		},
+		{
+			name:        "should return nil when dynamodb err",
+			leaseID:     "abc123",
+			dynamoErr:   gErrors.New("failure"),
+			expectedErr: errors.NewInternalServer("get lease history failed for lease \"abc123\"", gErrors.New("failure")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDynamo := awsmocks.DynamoDBAPI{}
+
+			mockDynamo.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+				return *input.TableName == "LeaseHistory" &&
+					*input.KeyConditionExpression == "#0 = :0"
+			})).Return(
+				tt.dynamoOutput, tt.dynamoErr,
+			)
+			historyData := &LeaseHistory{
+				DynamoDB:  &mockDynamo,
+				TableName: "LeaseHistory",
+			}
+
+			histories, err := historyData.List(tt.leaseID)
+			assert.Equal(t, tt.expected, histories)
+			assert.True(t, errors.Is(err, tt.expectedErr))
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package data
+
+import (
+	"fmt"
+
+	"github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// LeaseProfile - Data Layer Struct
+type LeaseProfile struct {
+	DynamoDB       dynamodbiface.DynamoDBAPI
+	TableName      string `env:"LEASE_PROFILE_DB"`
+	ConsistentRead bool   `env:"USE_CONSISTENT_READS" envDefault:"false"`
+	Limit          int64  `env:"LIMIT" envDefault:"25"`
+}
+
+// Write the Lease Profile record in DynamoDB
+// This is an upsert operation in which the record will either
+// be inserted or updated
+// prevLastModifiedOn parameter is the original lastModifiedOn
+func (a *LeaseProfile) Write(profile *lease.Profile, prevLastModifiedOn *int64) error {
+
+	var expr expression.Expression
+	var err error
+	// lastModifiedOn is nil on a create
+	if prevLastModifiedOn != nil {
+		modExpr := expression.Name("LastModifiedOn").Equal(expression.Value(prevLastModifiedOn))
+		expr, err = expression.NewBuilder().WithCondition(modExpr).Build()
+	} else {
+		modExpr := expression.Name("LastModifiedOn").AttributeNotExists()
+		expr, err = expression.NewBuilder().WithCondition(modExpr).Build()
+	}
+	if err != nil {
+		return errors.NewInternalServer("error building query", err)
+	}
+
+	putMap, _ := dynamodbattribute.Marshal(profile)
+	input := &dynamodb.PutItemInput{
+		TableName:                 aws.String(a.TableName),
+		Item:                      putMap.M,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ReturnValues:              aws.String("NONE"),
+	}
+	err = putItem(input, a.DynamoDB)
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		if awsErr.Code() == "ConditionalCheckFailedException" {
+			return errors.NewConflict(
+				"lease profile",
+				*profile.Name,
+				fmt.Errorf("unable to update lease profile: lease profile has been modified since request was made"))
+		}
+	}
+	if err != nil {
+		return errors.NewInternalServer(
+			fmt.Sprintf("update failed for lease profile %q", *profile.Name),
+			err,
+		)
+	}
+
+	return nil
+}
+
+// Get gets the Lease Profile record by name
+func (a *LeaseProfile) Get(name string) (*lease.Profile, error) {
+
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(a.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Name": {
+				S: aws.String(name),
+			},
+		},
+		ConsistentRead: aws.Bool(a.ConsistentRead),
+	}
+
+	res, err := getItem(input, a.DynamoDB)
+	if err != nil {
+		return nil, errors.NewInternalServer(
+			fmt.Sprintf("get lease profile failed for name %q", name),
+			err,
+		)
+	}
+
+	if len(res.Item) == 0 {
+		return nil, errors.NewNotFound("lease profile", name)
+	}
+
+	profile := lease.Profile{}
+	err = dynamodbattribute.UnmarshalMap(res.Item, &profile)
+	if err != nil {
+		return nil, errors.NewInternalServer(
+			fmt.Sprintf("failure unmarshaling lease profile with name %q", name),
+			err,
+		)
+	}
+
+	return &profile, nil
+}
+
+// List Get a list of lease profiles
+func (a *LeaseProfile) List(query *lease.Profile) (*lease.Profiles, error) {
+
+	if query.Limit == nil {
+		query.Limit = &a.Limit
+	}
+
+	scanInput := &dynamodb.ScanInput{
+		TableName:      aws.String(a.TableName),
+		ConsistentRead: aws.Bool(a.ConsistentRead),
+		Limit:          query.Limit,
+	}
+	_, filters := getFiltersFromStruct(query, nil)
+	if filters != nil {
+		expr, err := expression.NewBuilder().WithFilter(*filters).Build()
+		if err != nil {
+			return nil, errors.NewInternalServer("unable to build query", err)
+		}
+		scanInput.FilterExpression = expr.Filter()
+		scanInput.ExpressionAttributeNames = expr.Names()
+		scanInput.ExpressionAttributeValues = expr.Values()
+	}
+
+	res, err := a.DynamoDB.Scan(scanInput)
+	if err != nil {
+		return nil, errors.NewInternalServer("error getting lease profiles", err)
+	}
+
+	profiles := &lease.Profiles{}
+	err = dynamodbattribute.UnmarshalListOfMaps(res.Items, profiles)
+	if err != nil {
+		return nil, errors.NewInternalServer("failed unmarshal of lease profiles", err)
+	}
+
+	return profiles, nil
+}
@@ -0,0 +1,92 @@
+package data
+
+import (
+	gErrors "errors"
+	"testing"
+
+	awsmocks "github.com/Optum/dce/pkg/awsiface/mocks"
+	"github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetLeaseProfile(t *testing.T) {
+	tests := []struct {
+		name            string
+		profileName     string
+		dynamoErr       error
+		dynamoOutput    *dynamodb.GetItemOutput
+		expectedErr     error
+		expectedProfile *lease.Profile
+	}{
+		{
+			name:        "should return a lease profile object",
+			profileName: "sandbox-small",
+			expectedProfile: &lease.Profile{
+				Name:                 ptrString("sandbox-small"),
+				MaxBudgetAmount:      aws.Float64(100),
+				AllowedRegions:       &[]string{"us-east-1"},
+				PrincipalPolicyS3Key: ptrString("fixtures/policies/sandbox_small_policy.tmpl"),
+			},
+			dynamoOutput: &dynamodb.GetItemOutput{
+				Item: map[string]*dynamodb.AttributeValue{
+					"Name": {
+						S: aws.String("sandbox-small"),
+					},
+					"MaxBudgetAmount": {
+						N: aws.String("100"),
+					},
+					"AllowedRegions": {
+						L: []*dynamodb.AttributeValue{
+							{S: aws.String("us-east-1")},
+						},
+					},
+					"PrincipalPolicyS3Key": {
+						S: aws.String("fixtures/policies/sandbox_small_policy.tmpl"),
+					},
+				},
+			},
+		},
+		{
+			name:        "should return nil object when not found",
+			profileName: "sandbox-small",
+			dynamoOutput: &dynamodb.GetItemOutput{
+				Item: map[string]*dynamodb.AttributeValue{},
+			},
+			expectedErr: errors.NewNotFound("lease profile", "sandbox-small"),
+		},
+		{
+			name:        "should return nil when dynamodb err",
+			profileName: "sandbox-small",
+			dynamoErr:   gErrors.New("failure"),
+			dynamoOutput: &dynamodb.GetItemOutput{
+				Item: map[string]*dynamodb.AttributeValue{},
+			},
+			expectedErr: errors.NewInternalServer("get lease profile failed for name \"sandbox-small\"", gErrors.New("failure")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDynamo := awsmocks.DynamoDBAPI{}
+
+			mockDynamo.On("GetItem", mock.MatchedBy(func(input *dynamodb.GetItemInput) bool {
+				return (*input.TableName == "LeaseProfiles" &&
+					*input.Key["Name"].S == tt.profileName)
+			})).Return(
+				tt.dynamoOutput, tt.dynamoErr,
+			)
+			profileData := &LeaseProfile{
+				DynamoDB:  &mockDynamo,
+				TableName: "LeaseProfiles",
+			}
+
+			profile, err := profileData.Get(tt.profileName)
+			assert.Equal(t, tt.expectedProfile, profile)
+			assert.True(t, errors.Is(err, tt.expectedErr))
+		})
+	}
+}
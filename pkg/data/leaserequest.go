@@ -0,0 +1,215 @@
+package data
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// LeaseRequest - Data Layer Struct
+type LeaseRequest struct {
+	DynamoDB       dynamodbiface.DynamoDBAPI
+	TableName      string `env:"LEASE_REQUEST_DB"`
+	ConsistentRead bool   `env:"USE_CONSISTENT_READS" envDefault:"false"`
+	Limit          int64  `env:"LIMIT" envDefault:"25"`
+}
+
+// Write the Lease Request record in DynamoDB
+// This is an upsert operation in which the record will either
+// be inserted or updated
+// prevLastModifiedOn parameter is the original lastModifiedOn
+func (a *LeaseRequest) Write(request *lease.Request, prevLastModifiedOn *int64) error {
+
+	var expr expression.Expression
+	var err error
+	// lastModifiedOn is nil on a create
+	if prevLastModifiedOn != nil {
+		modExpr := expression.Name("LastModifiedOn").Equal(expression.Value(prevLastModifiedOn))
+		expr, err = expression.NewBuilder().WithCondition(modExpr).Build()
+	} else {
+		modExpr := expression.Name("LastModifiedOn").AttributeNotExists()
+		expr, err = expression.NewBuilder().WithCondition(modExpr).Build()
+	}
+	if err != nil {
+		return errors.NewInternalServer("error building query", err)
+	}
+
+	putMap, _ := dynamodbattribute.Marshal(request)
+	input := &dynamodb.PutItemInput{
+		TableName:                 aws.String(a.TableName),
+		Item:                      putMap.M,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ReturnValues:              aws.String("NONE"),
+	}
+	err = putItem(input, a.DynamoDB)
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		if awsErr.Code() == "ConditionalCheckFailedException" {
+			return errors.NewConflict(
+				"lease request",
+				*request.ID,
+				fmt.Errorf("unable to update lease request: lease request has been modified since request was made"))
+		}
+	}
+	if err != nil {
+		return errors.NewInternalServer(
+			fmt.Sprintf("update failed for lease request %q", *request.ID),
+			err,
+		)
+	}
+
+	return nil
+}
+
+// Get gets the Lease Request record by ID
+func (a *LeaseRequest) Get(requestID string) (*lease.Request, error) {
+
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(a.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Id": {
+				S: aws.String(requestID),
+			},
+		},
+		ConsistentRead: aws.Bool(a.ConsistentRead),
+	}
+
+	res, err := getItem(input, a.DynamoDB)
+	if err != nil {
+		return nil, errors.NewInternalServer(
+			fmt.Sprintf("get lease request failed for id %q", requestID),
+			err,
+		)
+	}
+
+	if len(res.Item) == 0 {
+		return nil, errors.NewNotFound("lease request", requestID)
+	}
+
+	request := lease.Request{}
+	err = dynamodbattribute.UnmarshalMap(res.Item, &request)
+	if err != nil {
+		return nil, errors.NewInternalServer(
+			fmt.Sprintf("failure unmarshaling lease request with id %q", requestID),
+			err,
+		)
+	}
+
+	return &request, nil
+}
+
+// List Get a list of lease requests
+// When querying by status the requests are returned oldest first
+func (a *LeaseRequest) List(query *lease.Request) (*lease.Requests, error) {
+
+	if query.Limit == nil {
+		query.Limit = &a.Limit
+	}
+
+	var items []map[string]*dynamodb.AttributeValue
+	var lastEvaluatedKey map[string]*dynamodb.AttributeValue
+	if query.Status != nil {
+		keyName := "RequestStatus"
+		keyCondition, filters := getFiltersFromStruct(query, &keyName)
+		bldr := expression.NewBuilder().WithKeyCondition(*keyCondition)
+		if filters != nil {
+			bldr = bldr.WithFilter(*filters)
+		}
+		expr, err := bldr.Build()
+		if err != nil {
+			return nil, errors.NewInternalServer("unable to build query", err)
+		}
+
+		queryInput := &dynamodb.QueryInput{
+			TableName:                 aws.String(a.TableName),
+			IndexName:                 aws.String("RequestStatusCreatedOn"),
+			KeyConditionExpression:    expr.KeyCondition(),
+			FilterExpression:          expr.Filter(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			ScanIndexForward:          aws.Bool(true),
+			Limit:                     query.Limit,
+		}
+		if query.NextID != nil && query.NextCreatedOn != nil {
+			queryInput.SetExclusiveStartKey(map[string]*dynamodb.AttributeValue{
+				"Id": {
+					S: query.NextID,
+				},
+				"RequestStatus": {
+					S: aws.String(query.Status.String()),
+				},
+				"CreatedOn": {
+					N: aws.String(strconv.FormatInt(*query.NextCreatedOn, 10)),
+				},
+			})
+		}
+
+		res, err := a.DynamoDB.Query(queryInput)
+		if err != nil {
+			return nil, errors.NewInternalServer("failed to query lease requests", err)
+		}
+		items = res.Items
+		lastEvaluatedKey = res.LastEvaluatedKey
+	} else {
+		scanInput := &dynamodb.ScanInput{
+			TableName:      aws.String(a.TableName),
+			ConsistentRead: aws.Bool(a.ConsistentRead),
+			Limit:          query.Limit,
+		}
+		if query.NextID != nil {
+			scanInput.SetExclusiveStartKey(map[string]*dynamodb.AttributeValue{
+				"Id": {
+					S: query.NextID,
+				},
+			})
+		}
+		_, filters := getFiltersFromStruct(query, nil)
+		if filters != nil {
+			expr, err := expression.NewBuilder().WithFilter(*filters).Build()
+			if err != nil {
+				return nil, errors.NewInternalServer("unable to build query", err)
+			}
+			scanInput.FilterExpression = expr.Filter()
+			scanInput.ExpressionAttributeNames = expr.Names()
+			scanInput.ExpressionAttributeValues = expr.Values()
+		}
+
+		res, err := a.DynamoDB.Scan(scanInput)
+		if err != nil {
+			return nil, errors.NewInternalServer("error getting lease requests", err)
+		}
+		items = res.Items
+		lastEvaluatedKey = res.LastEvaluatedKey
+	}
+
+	query.NextID = nil
+	query.NextCreatedOn = nil
+	if v, ok := lastEvaluatedKey["Id"]; ok {
+		query.NextID = v.S
+	}
+	if v, ok := lastEvaluatedKey["CreatedOn"]; ok && v.N != nil {
+		createdOn, err := strconv.ParseInt(*v.N, 10, 64)
+		if err != nil {
+			return nil, errors.NewInternalServer("invalid lease request start key", err)
+		}
+		query.NextCreatedOn = &createdOn
+	}
+
+	requests := &lease.Requests{}
+	err := dynamodbattribute.UnmarshalListOfMaps(items, requests)
+	if err != nil {
+		return nil, errors.NewInternalServer("failed unmarshal of lease requests", err)
+	}
+
+	return requests, nil
+}
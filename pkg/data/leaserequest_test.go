@@ -0,0 +1,161 @@
+package data
+
+import (
+	gErrors "errors"
+	"fmt"
+	"testing"
+
+	awsmocks "github.com/Optum/dce/pkg/awsiface/mocks"
+	"github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetLeaseRequest(t *testing.T) {
+	tests := []struct {
+		name            string
+		requestID       string
+		dynamoErr       error
+		dynamoOutput    *dynamodb.GetItemOutput
+		expectedErr     error
+		expectedRequest *lease.Request
+	}{
+		{
+			name:      "should return a lease request object",
+			requestID: "abc123",
+			expectedRequest: &lease.Request{
+				ID:          ptrString("abc123"),
+				PrincipalID: ptrString("User1"),
+				Status:      lease.RequestStatusPending.RequestStatusPtr(),
+			},
+			dynamoOutput: &dynamodb.GetItemOutput{
+				Item: map[string]*dynamodb.AttributeValue{
+					"Id": {
+						S: aws.String("abc123"),
+					},
+					"PrincipalId": {
+						S: aws.String("User1"),
+					},
+					"RequestStatus": {
+						S: aws.String("Pending"),
+					},
+				},
+			},
+		},
+		{
+			name:      "should return nil object when not found",
+			requestID: "abc123",
+			dynamoOutput: &dynamodb.GetItemOutput{
+				Item: map[string]*dynamodb.AttributeValue{},
+			},
+			expectedErr: errors.NewNotFound("lease request", "abc123"),
+		},
+		{
+			name:      "should return nil when dynamodb err",
+			requestID: "abc123",
+			dynamoErr: gErrors.New("failure"),
+			dynamoOutput: &dynamodb.GetItemOutput{
+				Item: map[string]*dynamodb.AttributeValue{},
+			},
+			expectedErr: errors.NewInternalServer("get lease request failed for id \"abc123\"", gErrors.New("failure")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDynamo := awsmocks.DynamoDBAPI{}
+
+			mockDynamo.On("GetItem", mock.MatchedBy(func(input *dynamodb.GetItemInput) bool {
+				return (*input.TableName == "LeaseRequests" &&
+					*input.Key["Id"].S == tt.requestID)
+			})).Return(
+				tt.dynamoOutput, tt.dynamoErr,
+			)
+			requestData := &LeaseRequest{
+				DynamoDB:  &mockDynamo,
+				TableName: "LeaseRequests",
+			}
+
+			request, err := requestData.Get(tt.requestID)
+			assert.Equal(t, tt.expectedRequest, request)
+			assert.True(t, errors.Is(err, tt.expectedErr))
+		})
+	}
+}
+
+func TestLeaseRequestWrite(t *testing.T) {
+	tests := []struct {
+		name              string
+		request           *lease.Request
+		dynamoErr         error
+		expectedErr       error
+		oldLastModifiedOn *int64
+	}{
+		{
+			name: "create",
+			request: &lease.Request{
+				ID:             ptrString("abc123"),
+				PrincipalID:    ptrString("User1"),
+				Status:         lease.RequestStatusPending.RequestStatusPtr(),
+				LastModifiedOn: ptrInt64(1573592058),
+			},
+		},
+		{
+			name: "conditional failure",
+			request: &lease.Request{
+				ID:             ptrString("abc123"),
+				PrincipalID:    ptrString("User1"),
+				Status:         lease.RequestStatusFulfilled.RequestStatusPtr(),
+				LastModifiedOn: ptrInt64(1573592058),
+			},
+			oldLastModifiedOn: ptrInt64(1573592057),
+			dynamoErr:         awserr.New("ConditionalCheckFailedException", "Message", fmt.Errorf("Bad")),
+			expectedErr: errors.NewConflict(
+				"lease request",
+				"abc123",
+				fmt.Errorf("unable to update lease request: lease request has been modified since request was made")),
+		},
+		{
+			name: "other dynamo error",
+			request: &lease.Request{
+				ID:             ptrString("abc123"),
+				PrincipalID:    ptrString("User1"),
+				Status:         lease.RequestStatusFulfilled.RequestStatusPtr(),
+				LastModifiedOn: ptrInt64(1573592058),
+			},
+			oldLastModifiedOn: ptrInt64(1573592057),
+			dynamoErr:         gErrors.New("failure"),
+			expectedErr:       errors.NewInternalServer("update failed for lease request \"abc123\"", gErrors.New("failure")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDynamo := awsmocks.DynamoDBAPI{}
+
+			mockDynamo.On("PutItem", mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+				if tt.oldLastModifiedOn == nil {
+					return (*input.TableName == "LeaseRequests" &&
+						*input.Item["Id"].S == *tt.request.ID &&
+						*input.ConditionExpression == "attribute_not_exists (#0)")
+				}
+				return (*input.TableName == "LeaseRequests" &&
+					*input.Item["Id"].S == *tt.request.ID &&
+					*input.Item["RequestStatus"].S == tt.request.Status.String())
+			})).Return(
+				&dynamodb.PutItemOutput{}, tt.dynamoErr,
+			)
+			requestData := &LeaseRequest{
+				DynamoDB:  &mockDynamo,
+				TableName: "LeaseRequests",
+			}
+
+			err := requestData.Write(tt.request, tt.oldLastModifiedOn)
+			assert.Truef(t, errors.Is(err, tt.expectedErr), "actual error %q doesn't match expected error %q", err, tt.expectedErr)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package data
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/Optum/dce/pkg/errors"
@@ -31,7 +32,6 @@ func (a *Lease) queryLeases(query *lease.Lease, keyName string, index string) (*
 
 	queryInput := &dynamodb.QueryInput{
 		TableName:                 aws.String(a.TableName),
-		IndexName:                 aws.String(index),
 		KeyConditionExpression:    expr.KeyCondition(),
 		ConsistentRead:            aws.Bool(a.ConsistentRead),
 		FilterExpression:          expr.Filter(),
@@ -40,17 +40,14 @@ func (a *Lease) queryLeases(query *lease.Lease, keyName string, index string) (*
 		ScanIndexForward:          aws.Bool(false),
 	}
 
+	// An empty index queries the table by its own keys
+	if index != "" {
+		queryInput.SetIndexName(index)
+	}
+
 	queryInput.SetLimit(*query.Limit)
 	if query.NextAccountID != nil && query.NextPrincipalID != nil {
-		// Should be more dynamic
-		queryInput.SetExclusiveStartKey(map[string]*dynamodb.AttributeValue{
-			"AccountId": &dynamodb.AttributeValue{
-				S: query.NextAccountID,
-			},
-			"PrincipalId": &dynamodb.AttributeValue{
-				S: query.NextPrincipalID,
-			},
-		})
+		queryInput.SetExclusiveStartKey(leaseStartKey(query, index))
 	}
 
 	res, err = a.DynamoDB.Query(queryInput)
@@ -67,6 +64,36 @@ func (a *Lease) queryLeases(query *lease.Lease, keyName string, index string) (*
 	}, nil
 }
 
+// leaseStartKey returns the key to resume a query from.
+// Index queries need the index keys of the last lease read, as well as the table keys.
+func leaseStartKey(query *lease.Lease, index string) map[string]*dynamodb.AttributeValue {
+	startKey := map[string]*dynamodb.AttributeValue{
+		"AccountId": {
+			S: query.NextAccountID,
+		},
+		"PrincipalId": {
+			S: query.NextPrincipalID,
+		},
+	}
+	switch index {
+	case "LeaseId":
+		startKey["Id"] = &dynamodb.AttributeValue{
+			S: query.ID,
+		}
+	case "LeaseStatus":
+		startKey["LeaseStatus"] = &dynamodb.AttributeValue{
+			S: aws.String(query.Status.String()),
+		}
+	case "PrincipalIdLastModifiedOn":
+		if query.NextLastModifiedOn != nil {
+			startKey["LastModifiedOn"] = &dynamodb.AttributeValue{
+				N: aws.String(strconv.FormatInt(*query.NextLastModifiedOn, 10)),
+			}
+		}
+	}
+	return startKey
+}
+
 // scanLeases for doing a scan against dynamodb
 func (a *Lease) scanLeases(query *lease.Lease) (*queryScanOutput, error) {
 	var expr expression.Expression
@@ -128,6 +155,8 @@ func (a *Lease) List(query *lease.Lease) (*lease.Leases, error) {
 		outputs, err = a.queryLeases(query, "Id", "LeaseId")
 	} else if query.PrincipalID != nil {
 		outputs, err = a.queryLeases(query, "PrincipalId", "PrincipalIdLastModifiedOn")
+	} else if query.AccountID != nil {
+		outputs, err = a.queryLeases(query, "AccountId", "")
 	} else if query.Status != nil {
 		outputs, err = a.queryLeases(query, "LeaseStatus", "LeaseStatus")
 	} else {
@@ -139,6 +168,7 @@ func (a *Lease) List(query *lease.Lease) (*lease.Leases, error) {
 
 	query.NextAccountID = nil
 	query.NextPrincipalID = nil
+	query.NextLastModifiedOn = nil
 	for k, v := range outputs.lastEvaluatedKey {
 		if strings.Contains(k, "Account") {
 			query.NextAccountID = v.S
@@ -146,6 +176,13 @@ func (a *Lease) List(query *lease.Lease) (*lease.Leases, error) {
 		if strings.Contains(k, "Principal") {
 			query.NextPrincipalID = v.S
 		}
+		if k == "LastModifiedOn" && v.N != nil {
+			lastModifiedOn, err := strconv.ParseInt(*v.N, 10, 64)
+			if err != nil {
+				return nil, errors.NewInternalServer("invalid lease start key", err)
+			}
+			query.NextLastModifiedOn = &lastModifiedOn
+		}
 	}
 
 	leases := &lease.Leases{}
@@ -10,6 +10,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestGetLeasesScan(t *testing.T) {
@@ -62,44 +63,6 @@ func TestGetLeasesScan(t *testing.T) {
 				},
 			},
 		},
-		{
-			name: "scan get all leases with accountId",
-			query: &lease.Lease{
-				AccountID: ptrString("1"),
-			},
-			sInput: &dynamodb.ScanInput{
-				ConsistentRead:   aws.Bool(false),
-				TableName:        aws.String("Leases"),
-				FilterExpression: aws.String("#0 = :0"),
-				ExpressionAttributeNames: map[string]*string{
-					"#0": aws.String("AccountId"),
-				},
-				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-					":0": {
-						S: aws.String("1"),
-					},
-				},
-				Limit: ptrInt64(25),
-			},
-			sOutputRec: &dynamodb.ScanOutput{
-				Items: []map[string]*dynamodb.AttributeValue{
-					map[string]*dynamodb.AttributeValue{
-						"AccountId": {
-							S: aws.String("1"),
-						},
-						"PrincipalId": {
-							S: aws.String("User1"),
-						},
-					},
-				},
-			},
-			expLeases: &lease.Leases{
-				{
-					AccountID:   ptrString("1"),
-					PrincipalID: ptrString("User1"),
-				},
-			},
-		},
 		{
 			name:  "scan failure with internal server error",
 			query: &lease.Lease{},
@@ -232,6 +195,45 @@ func TestGetLeasesQuery(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "query leases by account id",
+			query: &lease.Lease{
+				AccountID: ptrString("1"),
+			},
+			qInput: &dynamodb.QueryInput{
+				ConsistentRead: aws.Bool(false),
+				TableName:      aws.String("Leases"),
+				ExpressionAttributeNames: map[string]*string{
+					"#0": aws.String("AccountId"),
+				},
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":0": {
+						S: aws.String("1"),
+					},
+				},
+				KeyConditionExpression: aws.String("#0 = :0"),
+				ScanIndexForward:       aws.Bool(false),
+				Limit:                  ptrInt64(25),
+			},
+			qOutputRec: &dynamodb.QueryOutput{
+				Items: []map[string]*dynamodb.AttributeValue{
+					map[string]*dynamodb.AttributeValue{
+						"AccountId": {
+							S: aws.String("1"),
+						},
+						"PrincipalId": {
+							S: aws.String("User1"),
+						},
+					},
+				},
+			},
+			expLeases: &lease.Leases{
+				{
+					AccountID:   ptrString("1"),
+					PrincipalID: ptrString("User1"),
+				},
+			},
+		},
 		{
 			name: "query internal error",
 			query: &lease.Lease{
@@ -287,3 +289,41 @@ func TestGetLeasesQuery(t *testing.T) {
 	}
 
 }
+
+func TestGetLeasesQueryNextPage(t *testing.T) {
+	mockDynamo := awsmocks.DynamoDBAPI{}
+	leaseData := &Lease{
+		DynamoDB:  &mockDynamo,
+		TableName: "Leases",
+		Limit:     1,
+	}
+	query := &lease.Lease{
+		PrincipalID: aws.String("User1"),
+	}
+	item := map[string]*dynamodb.AttributeValue{
+		"AccountId":      {S: aws.String("1")},
+		"PrincipalId":    {S: aws.String("User1")},
+		"LastModifiedOn": {N: aws.String("1590000000")},
+	}
+
+	mockDynamo.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		return input.ExclusiveStartKey == nil
+	})).Return(&dynamodb.QueryOutput{
+		Items:            []map[string]*dynamodb.AttributeValue{item},
+		LastEvaluatedKey: item,
+	}, nil).Once()
+	mockDynamo.On("Query", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		return assert.ObjectsAreEqual(item, input.ExclusiveStartKey)
+	})).Return(&dynamodb.QueryOutput{}, nil).Once()
+
+	_, err := leaseData.List(query)
+	assert.Nil(t, err)
+	assert.Equal(t, aws.Int64(1590000000), query.NextLastModifiedOn)
+
+	leases, err := leaseData.List(query)
+	assert.Nil(t, err)
+	assert.Empty(t, *leases)
+	assert.Nil(t, query.NextAccountID)
+	assert.Nil(t, query.NextPrincipalID)
+	mockDynamo.AssertExpectations(t)
+}
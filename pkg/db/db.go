@@ -35,6 +35,8 @@ type DB struct {
     AccountTableName string
     // Name of the Lease table
     LeaseTableName string
+    // Name of the LeaseHistory table, lease transitions aren't recorded if it's empty
+    LeaseHistoryTableName string
     // Default expiry time, in days, of the lease
     DefaultLeaseLengthInDays int
     // Use Consistent Reads when scanning or querying when possible.
@@ -485,12 +487,66 @@ func (db *DB) TransitionLeaseStatus(accountID string, principalID string, prevSt
         return nil, err
     }
 
-    return unmarshalLease(result.Attributes)
+    lease, err := unmarshalLease(result.Attributes)
+    if err != nil {
+        return nil, err
+    }
+
+    // The lease is already updated, so don't fail on a history error
+    err = db.putLeaseHistory(lease, prevStatus)
+    if err != nil {
+        log.Printf("Failed to record history for lease %s: %s", lease.ID, err)
+    }
+
+    return lease, nil
+}
+
+// putLeaseHistory records a lease status transition in the LeaseHistory table
+func (db *DB) putLeaseHistory(lease *Lease, prevStatus LeaseStatus) error {
+    if db.LeaseHistoryTableName == "" {
+        return nil
+    }
+
+    _, err := db.Client.PutItem(
+        &dynamodb.PutItemInput{
+            TableName: aws.String(db.LeaseHistoryTableName),
+            Item: map[string]*dynamodb.AttributeValue{
+                "LeaseId": {
+                    S: aws.String(lease.ID),
+                },
+                "Id": {
+                    S: aws.String(guuid.New().String()),
+                },
+                "CreatedOn": {
+                    N: aws.String(strconv.FormatInt(time.Now().Unix(), 10)),
+                },
+                "Actor": {
+                    S: aws.String("system"),
+                },
+                "PrevLeaseStatus": {
+                    S: aws.String(string(prevStatus)),
+                },
+                "LeaseStatus": {
+                    S: aws.String(string(lease.LeaseStatus)),
+                },
+                "LeaseStatusReason": {
+                    S: aws.String(string(lease.LeaseStatusReason)),
+                },
+            },
+        },
+    )
+    return err
 }
 
 // TransitionAccountStatus updates account status for a given accountID and
 // returns the updated record on success
 func (db *DB) TransitionAccountStatus(accountID string, prevStatus AccountStatus, nextStatus AccountStatus) (*Account, error) {
+    updateExpression := "set AccountStatus=:nextStatus, " +
+        "LastModifiedOn=:lastModifiedOn"
+    // Remember when the account was claimed, for the least-recently-leased account selection
+    if nextStatus == Leased {
+        updateExpression += ", LastLeasedOn=:lastModifiedOn"
+    }
     result, err := db.Client.UpdateItem(
         &dynamodb.UpdateItemInput{
             // Query in Lease Table
@@ -502,8 +558,7 @@ func (db *DB) TransitionAccountStatus(accountID string, prevStatus AccountStatus
                 },
             },
             // Set Status=nextStatus ("READY")
-            UpdateExpression: aws.String("set AccountStatus=:nextStatus, " +
-                "LastModifiedOn=:lastModifiedOn"),
+            UpdateExpression: aws.String(updateExpression),
             ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
                 ":prevStatus": {
                     S: aws.String(string(prevStatus)),
@@ -822,13 +877,15 @@ Requires env vars for:
 - AWS_CURRENT_REGION
 - ACCOUNT_DB
 - LEASE_DB
+
+and optionally LEASE_HISTORY_DB
 */
 func NewFromEnv() (*DB, error) {
     awsSession, err := session.NewSession()
     if err != nil {
         return nil, err
     }
-    return New(
+    db := New(
         dynamodb.New(
             awsSession,
             aws.NewConfig().WithRegion(common.RequireEnv("AWS_CURRENT_REGION")),
@@ -836,7 +893,9 @@ func NewFromEnv() (*DB, error) {
         common.RequireEnv("ACCOUNT_DB"),
         common.RequireEnv("LEASE_DB"),
         common.GetEnvInt("DEFAULT_LEASE_LENGTH_IN_DAYS", 7),
-    ), nil
+    )
+    db.LeaseHistoryTableName = common.GetEnv("LEASE_HISTORY_DB", "")
+    return db, nil
 }
 
 type buildUpdateExpressInput struct {
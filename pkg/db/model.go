@@ -20,19 +20,24 @@ type Account struct {
 // Lease is a type corresponding to a Lease
 // table record
 type Lease struct {
-	AccountID                string                 `json:"AccountId"`                // AWS Account ID
-	PrincipalID              string                 `json:"PrincipalId"`              // Azure User Principal ID
-	ID                       string                 `json:"Id"`                       // Lease ID
-	LeaseStatus              LeaseStatus            `json:"LeaseStatus"`              // Status of the Lease
-	LeaseStatusReason        LeaseStatusReason      `json:"LeaseStatusReason"`        // Reason for the status of the lease
-	CreatedOn                int64                  `json:"CreatedOn"`                // Created Epoch Timestamp
-	LastModifiedOn           int64                  `json:"LastModifiedOn"`           // Last Modified Epoch Timestamp
-	BudgetAmount             float64                `json:"BudgetAmount"`             // Budget Amount allocated for this lease
-	BudgetCurrency           string                 `json:"BudgetCurrency"`           // Budget currency
-	BudgetNotificationEmails []string               `json:"BudgetNotificationEmails"` // Budget notification emails
-	LeaseStatusModifiedOn    int64                  `json:"LeaseStatusModifiedOn"`    // Last Modified Epoch Timestamp
-	ExpiresOn                int64                  `json:"ExpiresOn"`                // Lease expiration time as Epoch
-	Metadata                 map[string]interface{} `json:"Metadata"`                 // Arbitrary key-value metadata to store with lease object
+	AccountID                string                 `json:"AccountId"`                         // AWS Account ID
+	PrincipalID              string                 `json:"PrincipalId"`                       // Azure User Principal ID
+	ID                       string                 `json:"Id"`                                // Lease ID
+	LeaseStatus              LeaseStatus            `json:"LeaseStatus"`                       // Status of the Lease
+	LeaseStatusReason        LeaseStatusReason      `json:"LeaseStatusReason"`                 // Reason for the status of the lease
+	CreatedOn                int64                  `json:"CreatedOn"`                         // Created Epoch Timestamp
+	LastModifiedOn           int64                  `json:"LastModifiedOn"`                    // Last Modified Epoch Timestamp
+	BudgetAmount             float64                `json:"BudgetAmount"`                      // Budget Amount allocated for this lease
+	BudgetCurrency           string                 `json:"BudgetCurrency"`                    // Budget currency
+	BudgetNotificationEmails []string               `json:"BudgetNotificationEmails"`          // Budget notification emails
+	LeaseStatusModifiedOn    int64                  `json:"LeaseStatusModifiedOn"`             // Last Modified Epoch Timestamp
+	ExpiresOn                int64                  `json:"ExpiresOn"`                         // Lease expiration time as Epoch
+	Metadata                 map[string]interface{} `json:"Metadata"`                          // Arbitrary key-value metadata to store with lease object
+	IdleWarnedOn             *int64                 `json:"IdleWarnedOn,omitempty"`            // When the principal was warned the lease is idle, as Epoch
+	ExpiryNotificationsSent  []float64              `json:"ExpiryNotificationsSent,omitempty"` // Hours-before-expiry thresholds the principal was already notified of
+	BudgetNotificationsSent  []float64              `json:"BudgetNotificationsSent,omitempty"` // Lease budget threshold percentiles the principal was already notified of
+	ForecastSpend            *float64               `json:"ForecastSpend,omitempty"`           // Spend forecast by the time the lease expires, in the budget currency
+	ForecastWarnedOn         *int64                 `json:"ForecastWarnedOn,omitempty"`        // When the principal was warned the lease is forecast to go over budget, as Epoch
 }
 
 // Timestamp is a timestamp type for epoch format
@@ -83,6 +88,8 @@ const (
 	Active LeaseStatus = "Active"
 	// Inactive status
 	Inactive LeaseStatus = "Inactive"
+	// Frozen status, the lease is over budget and the principal can only read and export data
+	Frozen LeaseStatus = "Frozen"
 )
 
 // ParseLeaseStatus - parses the string into an account status.
@@ -92,6 +99,8 @@ func ParseLeaseStatus(status string) (LeaseStatus, error) {
 		return Active, nil
 	case "inactive":
 		return Inactive, nil
+	case "frozen":
+		return Frozen, nil
 	}
 	return EmptyLeaseStatus, fmt.Errorf("Cannot parse value %s", status)
 }
@@ -116,4 +125,6 @@ const (
 	// AccountOrphaned means that the health of the account was compromised.  The account has been orphaned
 	// which means the leases are also made Inactive
 	AccountOrphaned LeaseStatusReason = "AccountOrphaned"
+	// LeaseIdle means the lease spent next to nothing for too long, and was reclaimed.
+	LeaseIdle LeaseStatusReason = "Idle"
 )
@@ -75,7 +75,10 @@ func (svc *SESEmailService) SendEmail(input *SendEmailInput) error {
 func (svc *SESEmailService) SendRawEmailWithAttachment(input *SendEmailWithAttachmentInput) error {
 	msg := gomail.NewMessage()
 	msg.SetHeader("From", input.FromAddress)
-	msg.SetHeader("To", input.ToAddresses[0])
+	msg.SetHeader("To", input.ToAddresses...)
+	if len(input.CCAddresses) > 0 {
+		msg.SetHeader("Cc", input.CCAddresses...)
+	}
 	msg.SetHeader("Subject", input.Subject)
 	msg.SetBody("text/html", input.BodyHTML)
 	msg.Attach(input.AttachmentFileName)
@@ -88,9 +91,16 @@ func (svc *SESEmailService) SendRawEmailWithAttachment(input *SendEmailWithAttac
 
 	message := ses.RawMessage{Data: emailRaw.Bytes()}
 	emailInput := &ses.SendRawEmailInput{
-		FromArn:    aws.String(input.FromArn),
 		RawMessage: &message,
 	}
+	if input.FromArn != "" {
+		emailInput.FromArn = aws.String(input.FromArn)
+	}
+	// BCC addresses aren't in the message headers, so are only sent to as destinations
+	if len(input.BCCAddresses) > 0 {
+		destinations := append(append(append([]string{}, input.ToAddresses...), input.CCAddresses...), input.BCCAddresses...)
+		emailInput.Destinations = aws.StringSlice(destinations)
+	}
 
 	_, err = svc.SES.SendRawEmail(emailInput)
 
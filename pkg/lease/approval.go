@@ -0,0 +1,210 @@
+package lease
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Optum/dce/pkg/email"
+	"github.com/Optum/dce/pkg/errors"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// Approve activates a lease which is pending approval. Returns the approved lease.
+func (a *Service) Approve(ID string, reviewedBy string, comment *string) (*Lease, error) {
+	existing, err := a.getPendingLease(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	approved := *existing
+	approved.Status = StatusActive.StatusPtr()
+	approved.StatusReason = StatusReasonActive.StatusReasonPtr()
+	approved.ReviewedBy = &reviewedBy
+	approved.Actor = &reviewedBy
+	approved.ReviewComment = comment
+	err = a.Save(&approved)
+	if err != nil {
+		return nil, err
+	}
+
+	// The lease wasn't announced when it was created, so announce it now
+	err = a.eventSvc.LeaseCreate(&approved)
+	if err != nil {
+		return nil, err
+	}
+
+	return &approved, nil
+}
+
+// Reject ends a lease which is pending approval, and returns its account to the pool.
+// Returns the rejected lease.
+func (a *Service) Reject(ID string, reviewedBy string, comment *string) (*Lease, error) {
+	existing, err := a.getPendingLease(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rejected := *existing
+	rejected.ReviewedBy = &reviewedBy
+	rejected.Actor = &reviewedBy
+	rejected.ReviewComment = comment
+	err = a.endPendingLease(&rejected, StatusReasonRejected)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rejected, nil
+}
+
+// ExpirePendingApprovals ends the leases which nobody approved or rejected
+// within the approval timeout. Returns the expired leases.
+func (a *Service) ExpirePendingApprovals() (*Leases, error) {
+	expiresBefore := time.Now().Unix() - a.approvalTimeout
+
+	pending := Leases{}
+	err := a.ListPages(&Lease{
+		Status: StatusPending.StatusPtr(),
+	}, func(leases *Leases) bool {
+		if leases != nil {
+			pending = append(pending, *leases...)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	expired := Leases{}
+	var errs []error
+	for i := range pending {
+		l := pending[i]
+		if l.StatusModifiedOn == nil || *l.StatusModifiedOn > expiresBefore {
+			continue
+		}
+		err = a.endPendingLease(&l, StatusReasonApprovalExpired)
+		if err != nil {
+			log.Printf("Failed to expire pending lease %s: %s", *l.ID, err)
+			errs = append(errs, err)
+			continue
+		}
+		expired = append(expired, l)
+	}
+	if len(errs) > 0 {
+		return &expired, errors.NewMultiError("error when expiring pending leases", errs)
+	}
+
+	return &expired, nil
+}
+
+// getPendingLease returns the lease, or a conflict error if it isn't pending approval
+func (a *Service) getPendingLease(ID string) (*Lease, error) {
+	existing, err := a.dataSvc.Get(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validation.ValidateStruct(existing,
+		validation.Field(&existing.Status, validation.NotNil, validation.By(isLeasePending)),
+		validation.Field(&existing.AccountID, validateAccountID...),
+	)
+	if err != nil {
+		return nil, errors.NewConflict("lease", ID, err)
+	}
+	return existing, nil
+}
+
+// endPendingLease marks a pending lease Inactive, and resets the account it was holding
+func (a *Service) endPendingLease(data *Lease, reason StatusReason) error {
+	data.Status = StatusInactive.StatusPtr()
+	data.StatusReason = reason.StatusReasonPtr()
+	err := a.Save(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.accountSvc.Reset(*data.AccountID)
+	return err
+}
+
+// requiresApproval returns true if the lease is over the auto-approve budget amount or lease period.
+// A limit of 0 means leases never need approval for it. The auto-approve budget amount is in the cost currency.
+func (a *Service) requiresApproval(data *Lease) (bool, error) {
+	if a.autoApproveBudgetAmount > 0 {
+		budgetAmount, err := a.toCostCurrency(*data.BudgetAmount, data.BudgetCurrency)
+		if err != nil {
+			return false, err
+		}
+		if budgetAmount > a.autoApproveBudgetAmount {
+			return true, nil
+		}
+	}
+	if a.autoApproveLeasePeriod > 0 && *data.ExpiresOn > time.Now().Unix()+a.autoApproveLeasePeriod {
+		return true, nil
+	}
+	return false, nil
+}
+
+// notifyApprovers emails the approvers about a lease which is waiting for their approval,
+// rendering the lease approval templates from S3
+func (a *Service) notifyApprovers(data *Lease) error {
+	if a.emailSvc == nil || len(a.approverEmails) == 0 {
+		log.Printf("No lease approvers configured, skipping notification for lease %s", *data.ID)
+		return nil
+	}
+	if a.approvalFromEmail == "" {
+		return fmt.Errorf("no from email address configured for lease approval emails")
+	}
+	if a.templateSvc == nil {
+		return fmt.Errorf("no template storage configured for lease approval emails")
+	}
+
+	templateData := struct {
+		Lease        Lease
+		BudgetAmount float64
+		ExpiresOn    string
+		ApproveBy    string
+	}{
+		Lease:        *data,
+		BudgetAmount: *data.BudgetAmount,
+		ExpiresOn:    time.Unix(*data.ExpiresOn, 0).UTC().Format(time.RFC1123),
+		ApproveBy:    time.Unix(*data.StatusModifiedOn+a.approvalTimeout, 0).UTC().Format(time.RFC1123),
+	}
+
+	bodyHTML, _, err := a.templateSvc.GetTemplateObject(a.templatesBucket, a.approvalTemplateHTMLKey, templateData)
+	if err != nil {
+		return fmt.Errorf("failed to render lease approval template at s3://%s/%s: %w", a.templatesBucket, a.approvalTemplateHTMLKey, err)
+	}
+	bodyText, _, err := a.templateSvc.GetTemplateObject(a.templatesBucket, a.approvalTemplateTextKey, templateData)
+	if err != nil {
+		return fmt.Errorf("failed to render lease approval template at s3://%s/%s: %w", a.templatesBucket, a.approvalTemplateTextKey, err)
+	}
+	subject, err := renderTemplate("approvalSubject", a.approvalTemplateSubject, templateData)
+	if err != nil {
+		return err
+	}
+
+	return a.emailSvc.SendEmail(&email.SendEmailInput{
+		FromAddress: a.approvalFromEmail,
+		ToAddresses: a.approverEmails,
+		Subject:     subject,
+		BodyText:    bodyText,
+		BodyHTML:    bodyHTML,
+	})
+}
+
+// renderTemplate renders a template string, such as an email subject
+func renderTemplate(id string, templateStr string, data interface{}) (string, error) {
+	tmpl, err := template.New(id).Parse(templateStr)
+	if err != nil {
+		return "", err
+	}
+
+	buf := &bytes.Buffer{}
+	err = tmpl.Execute(buf, data)
+
+	return strings.TrimSpace(buf.String()), err
+}
@@ -0,0 +1,250 @@
+package lease_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Optum/dce/pkg/account"
+	commonMocks "github.com/Optum/dce/pkg/common/mocks"
+	"github.com/Optum/dce/pkg/email"
+	emailMocks "github.com/Optum/dce/pkg/email/mocks"
+	"github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/lease/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestApprove(t *testing.T) {
+
+	type response struct {
+		data *lease.Lease
+		err  error
+	}
+
+	createdOn := time.Now().Unix()
+	tests := []struct {
+		name        string
+		getResponse *lease.Lease
+		exp         response
+	}{
+		{
+			name: "should approve a pending lease",
+			getResponse: &lease.Lease{
+				ID:           ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+				PrincipalID:  ptrString("User1"),
+				AccountID:    ptrString("123456789012"),
+				Status:       lease.StatusPending.StatusPtr(),
+				StatusReason: lease.StatusReasonPendingApproval.StatusReasonPtr(),
+				CreatedOn:    &createdOn,
+			},
+			exp: response{
+				data: &lease.Lease{
+					ID:            ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+					PrincipalID:   ptrString("User1"),
+					AccountID:     ptrString("123456789012"),
+					Status:        lease.StatusActive.StatusPtr(),
+					StatusReason:  lease.StatusReasonActive.StatusReasonPtr(),
+					ReviewedBy:    ptrString("admin1"),
+					ReviewComment: ptrString("approved by finance"),
+					Actor:         ptrString("admin1"),
+				},
+			},
+		},
+		{
+			name: "should fail to approve an active lease",
+			getResponse: &lease.Lease{
+				ID:          ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+				PrincipalID: ptrString("User1"),
+				AccountID:   ptrString("123456789012"),
+				Status:      lease.StatusActive.StatusPtr(),
+			},
+			exp: response{
+				err: errors.NewConflict("lease", "6d666a28-4f2c-43af-8c94-1b715ca079ae", fmt.Errorf("leaseStatus: must be pending lease.")),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mocksRwd := &mocks.ReaderWriter{}
+			mocksEventer := &mocks.Eventer{}
+
+			mocksRwd.On("Get", "6d666a28-4f2c-43af-8c94-1b715ca079ae").Return(tt.getResponse, nil)
+			mocksRwd.On("Write", mock.AnythingOfType("*lease.Lease"), mock.AnythingOfType("*int64")).Return(nil)
+			mocksEventer.On("LeaseCreate", mock.AnythingOfType("*lease.Lease")).Return(nil)
+
+			leaseSvc := lease.NewService(lease.NewServiceInput{
+				DataSvc:  mocksRwd,
+				EventSvc: mocksEventer,
+			})
+
+			result, err := leaseSvc.Approve("6d666a28-4f2c-43af-8c94-1b715ca079ae", "admin1", ptrString("approved by finance"))
+
+			assert.Truef(t, errors.Is(err, tt.exp.err), "actual error %q doesn't match expected error %q", err, tt.exp.err)
+			if result != nil {
+				result.CreatedOn = nil
+				result.LastModifiedOn = nil
+				result.StatusModifiedOn = nil
+				mocksEventer.AssertCalled(t, "LeaseCreate", mock.AnythingOfType("*lease.Lease"))
+			}
+			assert.Equal(t, tt.exp.data, result)
+		})
+	}
+}
+
+func TestReject(t *testing.T) {
+	mocksRwd := &mocks.ReaderWriter{}
+	mocksAccountSvc := &mocks.AccountServicer{}
+
+	lastModifiedOn := time.Now().Unix()
+	mocksRwd.On("Get", "6d666a28-4f2c-43af-8c94-1b715ca079ae").Return(&lease.Lease{
+		ID:             ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+		PrincipalID:    ptrString("User1"),
+		AccountID:      ptrString("123456789012"),
+		Status:         lease.StatusPending.StatusPtr(),
+		StatusReason:   lease.StatusReasonPendingApproval.StatusReasonPtr(),
+		CreatedOn:      &lastModifiedOn,
+		LastModifiedOn: &lastModifiedOn,
+	}, nil)
+	mocksRwd.On("Write", mock.AnythingOfType("*lease.Lease"), &lastModifiedOn).Return(nil)
+	mocksAccountSvc.On("Reset", "123456789012").Return(&account.Account{}, nil)
+
+	leaseSvc := lease.NewService(lease.NewServiceInput{
+		DataSvc:    mocksRwd,
+		AccountSvc: mocksAccountSvc,
+	})
+
+	result, err := leaseSvc.Reject("6d666a28-4f2c-43af-8c94-1b715ca079ae", "admin1", ptrString("too expensive"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, lease.StatusInactive, *result.Status)
+	assert.Equal(t, lease.StatusReasonRejected, *result.StatusReason)
+	assert.Equal(t, "admin1", *result.ReviewedBy)
+	assert.Equal(t, "too expensive", *result.ReviewComment)
+	mocksAccountSvc.AssertCalled(t, "Reset", "123456789012")
+}
+
+func TestExpirePendingApprovals(t *testing.T) {
+	mocksRwd := &mocks.ReaderWriter{}
+	mocksAccountSvc := &mocks.AccountServicer{}
+
+	pendingSinceLastWeek := time.Now().AddDate(0, 0, -7).Unix()
+	pendingSinceNow := time.Now().Unix()
+	mocksRwd.On("List", mock.AnythingOfType("*lease.Lease")).Return(&lease.Leases{
+		lease.Lease{
+			ID:               ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+			PrincipalID:      ptrString("User1"),
+			AccountID:        ptrString("123456789012"),
+			Status:           lease.StatusPending.StatusPtr(),
+			CreatedOn:        &pendingSinceLastWeek,
+			LastModifiedOn:   &pendingSinceLastWeek,
+			StatusModifiedOn: &pendingSinceLastWeek,
+		},
+		lease.Lease{
+			ID:               ptrString("1e5a1c56-5b2e-4b6c-9f33-6a0c3b8e9d21"),
+			PrincipalID:      ptrString("User2"),
+			AccountID:        ptrString("210987654321"),
+			Status:           lease.StatusPending.StatusPtr(),
+			LastModifiedOn:   &pendingSinceNow,
+			StatusModifiedOn: &pendingSinceNow,
+		},
+	}, nil)
+	mocksRwd.On("Write", mock.AnythingOfType("*lease.Lease"), mock.AnythingOfType("*int64")).Return(nil)
+	mocksAccountSvc.On("Reset", "123456789012").Return(&account.Account{}, nil)
+
+	leaseSvc := lease.NewService(lease.NewServiceInput{
+		DataSvc:         mocksRwd,
+		AccountSvc:      mocksAccountSvc,
+		ApprovalTimeout: 259200,
+	})
+
+	expired, err := leaseSvc.ExpirePendingApprovals()
+
+	assert.Nil(t, err)
+	assert.Len(t, *expired, 1)
+	assert.Equal(t, "6d666a28-4f2c-43af-8c94-1b715ca079ae", *(*expired)[0].ID)
+	assert.Equal(t, lease.StatusReasonApprovalExpired, *(*expired)[0].StatusReason)
+	mocksAccountSvc.AssertNumberOfCalls(t, "Reset", 1)
+}
+
+func TestCreateNotifiesApprovers(t *testing.T) {
+
+	tests := []struct {
+		name                   string
+		approvalFromEmail      string
+		budgetNotificationFrom string
+		expFromAddress         string
+		expEmails              int
+	}{
+		{
+			name:              "should send from the lease approval address",
+			approvalFromEmail: "approvals@example.com",
+			expFromAddress:    "approvals@example.com",
+			expEmails:         1,
+		},
+		{
+			name:                   "should default to the budget notification address",
+			budgetNotificationFrom: "dce@example.com",
+			expFromAddress:         "dce@example.com",
+			expEmails:              1,
+		},
+		{
+			name: "should not send without a from address",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mocksRwd := &mocks.ReaderWriter{}
+			mocksEmailSvc := &emailMocks.Service{}
+			mocksTemplateSvc := &commonMocks.Storager{}
+
+			mocksRwd.On("List", mock.AnythingOfType("*lease.Lease")).Return(nil, nil)
+			mocksRwd.On("Write", mock.AnythingOfType("*lease.Lease"), mock.Anything).Return(nil)
+			mocksTemplateSvc.On("GetTemplateObject", "dce-artifacts", "lease_approval_templates/html.tmpl", mock.Anything).
+				Return("<p>Approve lease</p>", "", nil)
+			mocksTemplateSvc.On("GetTemplateObject", "dce-artifacts", "lease_approval_templates/text.tmpl", mock.Anything).
+				Return("Approve lease", "", nil)
+			mocksEmailSvc.On("SendEmail", mock.MatchedBy(func(input *email.SendEmailInput) bool {
+				return input.FromAddress == tt.expFromAddress &&
+					input.Subject == "Lease approval requested by User1" &&
+					input.BodyHTML == "<p>Approve lease</p>" &&
+					input.BodyText == "Approve lease" &&
+					assert.ObjectsAreEqual([]string{"finance@example.com"}, input.ToAddresses)
+			})).Return(nil)
+
+			leaseSvc := lease.NewService(lease.NewServiceInput{
+				DataSvc:                  mocksRwd,
+				EmailSvc:                 mocksEmailSvc,
+				TemplateSvc:              mocksTemplateSvc,
+				DefaultLeaseLengthInDays: 7,
+				PrincipalBudgetAmount:    1000.00,
+				MaxLeaseBudgetAmount:     1000.00,
+				MaxLeasePeriod:           704800,
+				AutoApproveLeasePeriod:   86400,
+				ApprovalTimeout:          259200,
+				ApproverEmails:           []string{"finance@example.com"},
+				ApprovalFromEmail:        tt.approvalFromEmail,
+				BudgetNotificationFrom:   tt.budgetNotificationFrom,
+				TemplatesBucket:          "dce-artifacts",
+				ApprovalTemplateHTMLKey:  "lease_approval_templates/html.tmpl",
+				ApprovalTemplateTextKey:  "lease_approval_templates/text.tmpl",
+				ApprovalTemplateSubject:  "Lease approval requested by {{.Lease.PrincipalID}}",
+			})
+
+			result, err := leaseSvc.Create(&lease.Lease{
+				PrincipalID:    ptrString("User1"),
+				AccountID:      ptrString("123456789012"),
+				BudgetAmount:   ptrFloat(100.00),
+				BudgetCurrency: ptrString("USD"),
+			}, 0)
+
+			// The lease is created, even when the approvers can't be notified
+			assert.Nil(t, err)
+			assert.Equal(t, lease.StatusPending, *result.Status)
+			mocksEmailSvc.AssertNumberOfCalls(t, "SendEmail", tt.expEmails)
+		})
+	}
+}
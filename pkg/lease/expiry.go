@@ -0,0 +1,9 @@
+package lease
+
+// MarkExpiryNotified records that the principal was notified their lease
+// expires within each of the thresholds, in hours. Returns the updated lease.
+func (a *Service) MarkExpiryNotified(ID string, thresholds []float64) (*Lease, error) {
+	return a.writeActiveLease(ID, func(updated *Lease) {
+		updated.ExpiryNotificationsSent = mergeThresholds(updated.ExpiryNotificationsSent, thresholds)
+	})
+}
@@ -0,0 +1,79 @@
+package lease_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/lease/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMarkExpiryNotified(t *testing.T) {
+
+	lastModifiedOn := time.Now().AddDate(0, 0, -1).Unix()
+	tests := []struct {
+		name        string
+		getResponse *lease.Lease
+		thresholds  []float64
+		expSent     []float64
+		expErr      error
+	}{
+		{
+			name: "should record the thresholds notified",
+			getResponse: &lease.Lease{
+				ID:             ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+				Status:         lease.StatusActive.StatusPtr(),
+				LastModifiedOn: &lastModifiedOn,
+			},
+			thresholds: []float64{1, 24},
+			expSent:    []float64{24, 1},
+		},
+		{
+			name: "should keep the thresholds already notified",
+			getResponse: &lease.Lease{
+				ID:                      ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+				Status:                  lease.StatusActive.StatusPtr(),
+				LastModifiedOn:          &lastModifiedOn,
+				ExpiryNotificationsSent: &[]float64{72, 24},
+			},
+			thresholds: []float64{24, 1},
+			expSent:    []float64{72, 24, 1},
+		},
+		{
+			name: "should fail to mark an inactive lease",
+			getResponse: &lease.Lease{
+				ID:             ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+				Status:         lease.StatusInactive.StatusPtr(),
+				LastModifiedOn: &lastModifiedOn,
+			},
+			thresholds: []float64{24},
+			expErr:     errors.NewConflict("lease", "6d666a28-4f2c-43af-8c94-1b715ca079ae", fmt.Errorf("leaseStatus: must be active lease.")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mocksRwd := &mocks.ReaderWriter{}
+
+			mocksRwd.On("Get", "6d666a28-4f2c-43af-8c94-1b715ca079ae").Return(tt.getResponse, nil)
+			mocksRwd.On("Write", mock.AnythingOfType("*lease.Lease"), &lastModifiedOn).Return(nil)
+
+			leaseSvc := lease.NewService(lease.NewServiceInput{
+				DataSvc: mocksRwd,
+			})
+
+			result, err := leaseSvc.MarkExpiryNotified("6d666a28-4f2c-43af-8c94-1b715ca079ae", tt.thresholds)
+
+			assert.Truef(t, errors.Is(err, tt.expErr), "actual error %q doesn't match expected error %q", err, tt.expErr)
+			if tt.expErr == nil {
+				assert.Equal(t, tt.expSent, *result.ExpiryNotificationsSent)
+				assert.Equal(t, lease.StatusActive, *result.Status)
+				mocksRwd.AssertNumberOfCalls(t, "Write", 1)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package lease
+
+import "time"
+
+// SetForecast records the spend forecast by the time the lease expires.
+// Returns the updated lease.
+func (a *Service) SetForecast(ID string, forecastSpend float64) (*Lease, error) {
+	return a.writeActiveLease(ID, func(updated *Lease) {
+		updated.ForecastSpend = &forecastSpend
+	})
+}
+
+// WarnForecast records that the principal was warned their lease is forecast
+// to go over budget. Returns the updated lease.
+func (a *Service) WarnForecast(ID string) (*Lease, error) {
+	now := time.Now().Unix()
+	return a.writeActiveLease(ID, func(updated *Lease) {
+		updated.ForecastWarnedOn = &now
+	})
+}
@@ -0,0 +1,103 @@
+package lease
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Optum/dce/pkg/errors"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// Freeze limits the principal of an over budget lease to reading and exporting data
+// until frozenUntil, when the lease's account will be reset. Returns the frozen lease.
+func (a *Service) Freeze(ID string, frozenUntil int64) (*Lease, error) {
+	existing, err := a.dataSvc.Get(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validation.ValidateStruct(existing,
+		validation.Field(&existing.Status, validation.NotNil, validation.By(isLeaseActive)),
+		validation.Field(&existing.AccountID, validateAccountID...),
+	)
+	if err != nil {
+		return nil, errors.NewConflict("lease", ID, err)
+	}
+
+	acct, err := a.accountSvc.Get(*existing.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	err = a.accountSvc.FreezePrincipalAccess(acct)
+	if err != nil {
+		return nil, err
+	}
+
+	frozen := *existing
+	frozen.Status = StatusFrozen.StatusPtr()
+	frozen.StatusReason = StatusReasonOverBudget.StatusReasonPtr()
+	frozen.FrozenUntil = &frozenUntil
+	err = a.Save(&frozen)
+	if err != nil {
+		return nil, err
+	}
+
+	return &frozen, nil
+}
+
+// ReclaimFrozenLeases ends the frozen leases which are past their frozenUntil date,
+// and resets their accounts. Returns the reclaimed leases.
+func (a *Service) ReclaimFrozenLeases() (*Leases, error) {
+	now := time.Now().Unix()
+
+	frozen := Leases{}
+	err := a.ListPages(&Lease{
+		Status: StatusFrozen.StatusPtr(),
+	}, func(leases *Leases) bool {
+		if leases != nil {
+			frozen = append(frozen, *leases...)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reclaimed := Leases{}
+	var errs []error
+	for i := range frozen {
+		l := frozen[i]
+		if l.FrozenUntil != nil && *l.FrozenUntil > now {
+			continue
+		}
+		err = a.reclaimFrozenLease(&l)
+		if err != nil {
+			log.Printf("Failed to reclaim frozen lease %s: %s", *l.ID, err)
+			errs = append(errs, err)
+			continue
+		}
+		reclaimed = append(reclaimed, l)
+	}
+	if len(errs) > 0 {
+		return &reclaimed, errors.NewMultiError("error when reclaiming frozen leases", errs)
+	}
+
+	return &reclaimed, nil
+}
+
+// reclaimFrozenLease marks a frozen lease Inactive, and resets its account
+func (a *Service) reclaimFrozenLease(data *Lease) error {
+	data.Status = StatusInactive.StatusPtr()
+	err := a.Save(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.accountSvc.Reset(*data.AccountID)
+	if err != nil {
+		return errors.NewInternalServer(fmt.Sprintf("failed to reset account %s", *data.AccountID), err)
+	}
+
+	return a.eventSvc.LeaseEnd(data)
+}
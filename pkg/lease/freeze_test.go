@@ -0,0 +1,158 @@
+package lease_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Optum/dce/pkg/account"
+	"github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/lease/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFreeze(t *testing.T) {
+
+	type response struct {
+		data *lease.Lease
+		err  error
+	}
+
+	createdOn := time.Now().Unix()
+	frozenUntil := time.Now().AddDate(0, 0, 3).Unix()
+	tests := []struct {
+		name        string
+		getResponse *lease.Lease
+		freezeErr   error
+		exp         response
+	}{
+		{
+			name: "should freeze an active lease",
+			getResponse: &lease.Lease{
+				ID:           ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+				PrincipalID:  ptrString("User1"),
+				AccountID:    ptrString("123456789012"),
+				Status:       lease.StatusActive.StatusPtr(),
+				StatusReason: lease.StatusReasonActive.StatusReasonPtr(),
+				CreatedOn:    &createdOn,
+			},
+			exp: response{
+				data: &lease.Lease{
+					ID:           ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+					PrincipalID:  ptrString("User1"),
+					AccountID:    ptrString("123456789012"),
+					Status:       lease.StatusFrozen.StatusPtr(),
+					StatusReason: lease.StatusReasonOverBudget.StatusReasonPtr(),
+					FrozenUntil:  &frozenUntil,
+				},
+			},
+		},
+		{
+			name: "should fail to freeze an inactive lease",
+			getResponse: &lease.Lease{
+				ID:          ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+				PrincipalID: ptrString("User1"),
+				AccountID:   ptrString("123456789012"),
+				Status:      lease.StatusInactive.StatusPtr(),
+			},
+			exp: response{
+				err: errors.NewConflict("lease", "6d666a28-4f2c-43af-8c94-1b715ca079ae", fmt.Errorf("leaseStatus: must be active lease.")),
+			},
+		},
+		{
+			name: "should fail when the principal access can't be frozen",
+			getResponse: &lease.Lease{
+				ID:          ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+				PrincipalID: ptrString("User1"),
+				AccountID:   ptrString("123456789012"),
+				Status:      lease.StatusActive.StatusPtr(),
+				CreatedOn:   &createdOn,
+			},
+			freezeErr: errors.NewInternalServer("failure", fmt.Errorf("original failure")),
+			exp: response{
+				err: errors.NewInternalServer("failure", fmt.Errorf("original failure")),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mocksRwd := &mocks.ReaderWriter{}
+			mocksAccountSvc := &mocks.AccountServicer{}
+
+			acct := &account.Account{ID: ptrString("123456789012")}
+			mocksRwd.On("Get", "6d666a28-4f2c-43af-8c94-1b715ca079ae").Return(tt.getResponse, nil)
+			mocksRwd.On("Write", mock.AnythingOfType("*lease.Lease"), mock.AnythingOfType("*int64")).Return(nil)
+			mocksAccountSvc.On("Get", "123456789012").Return(acct, nil)
+			mocksAccountSvc.On("FreezePrincipalAccess", acct).Return(tt.freezeErr)
+
+			leaseSvc := lease.NewService(lease.NewServiceInput{
+				DataSvc:    mocksRwd,
+				AccountSvc: mocksAccountSvc,
+			})
+
+			result, err := leaseSvc.Freeze("6d666a28-4f2c-43af-8c94-1b715ca079ae", frozenUntil)
+
+			assert.Truef(t, errors.Is(err, tt.exp.err), "actual error %q doesn't match expected error %q", err, tt.exp.err)
+			if result != nil {
+				result.CreatedOn = nil
+				result.LastModifiedOn = nil
+				result.StatusModifiedOn = nil
+			}
+			assert.Equal(t, tt.exp.data, result)
+		})
+	}
+}
+
+func TestReclaimFrozenLeases(t *testing.T) {
+	mocksRwd := &mocks.ReaderWriter{}
+	mocksAccountSvc := &mocks.AccountServicer{}
+	mocksEventer := &mocks.Eventer{}
+
+	frozenSinceLastWeek := time.Now().AddDate(0, 0, -7).Unix()
+	frozenUntilYesterday := time.Now().AddDate(0, 0, -1).Unix()
+	frozenUntilTomorrow := time.Now().AddDate(0, 0, 1).Unix()
+	mocksRwd.On("List", mock.AnythingOfType("*lease.Lease")).Return(&lease.Leases{
+		lease.Lease{
+			ID:             ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+			PrincipalID:    ptrString("User1"),
+			AccountID:      ptrString("123456789012"),
+			Status:         lease.StatusFrozen.StatusPtr(),
+			StatusReason:   lease.StatusReasonOverBudget.StatusReasonPtr(),
+			CreatedOn:      &frozenSinceLastWeek,
+			LastModifiedOn: &frozenSinceLastWeek,
+			FrozenUntil:    &frozenUntilYesterday,
+		},
+		lease.Lease{
+			ID:             ptrString("1e5a1c56-5b2e-4b6c-9f33-6a0c3b8e9d21"),
+			PrincipalID:    ptrString("User2"),
+			AccountID:      ptrString("210987654321"),
+			Status:         lease.StatusFrozen.StatusPtr(),
+			StatusReason:   lease.StatusReasonOverBudget.StatusReasonPtr(),
+			CreatedOn:      &frozenSinceLastWeek,
+			LastModifiedOn: &frozenSinceLastWeek,
+			FrozenUntil:    &frozenUntilTomorrow,
+		},
+	}, nil)
+	mocksRwd.On("Write", mock.AnythingOfType("*lease.Lease"), mock.AnythingOfType("*int64")).Return(nil)
+	mocksAccountSvc.On("Reset", "123456789012").Return(&account.Account{}, nil)
+	mocksEventer.On("LeaseEnd", mock.AnythingOfType("*lease.Lease")).Return(nil)
+
+	leaseSvc := lease.NewService(lease.NewServiceInput{
+		DataSvc:    mocksRwd,
+		AccountSvc: mocksAccountSvc,
+		EventSvc:   mocksEventer,
+	})
+
+	reclaimed, err := leaseSvc.ReclaimFrozenLeases()
+
+	assert.Nil(t, err)
+	assert.Len(t, *reclaimed, 1)
+	assert.Equal(t, "6d666a28-4f2c-43af-8c94-1b715ca079ae", *(*reclaimed)[0].ID)
+	assert.Equal(t, lease.StatusInactive, *(*reclaimed)[0].Status)
+	assert.Equal(t, lease.StatusReasonOverBudget, *(*reclaimed)[0].StatusReason)
+	mocksAccountSvc.AssertNumberOfCalls(t, "Reset", 1)
+	mocksEventer.AssertNumberOfCalls(t, "LeaseEnd", 1)
+}
@@ -0,0 +1,97 @@
+package lease
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ActorSystem is the actor recorded for the changes DCE makes to leases on its own,
+// eg. expiring them or resetting them when they're over budget
+const ActorSystem = "system"
+
+// History is a type corresponding to a LeaseHistory table record.
+// Each record is one change to a lease, with the values before and after the change.
+type History struct {
+	LeaseID          *string       `json:"leaseId,omitempty" dynamodbav:"LeaseId"`                                       // Lease ID
+	ID               *string       `json:"id,omitempty" dynamodbav:"Id"`                                                 // History record ID
+	CreatedOn        *int64        `json:"createdOn,omitempty" dynamodbav:"CreatedOn,omitempty"`                         // When the change was made, as Epoch
+	Actor            *string       `json:"actor,omitempty" dynamodbav:"Actor,omitempty"`                                 // User who made the change, or "system"
+	PrevStatus       *Status       `json:"prevLeaseStatus,omitempty" dynamodbav:"PrevLeaseStatus,omitempty"`             // Status before the change
+	Status           *Status       `json:"leaseStatus,omitempty" dynamodbav:"LeaseStatus,omitempty"`                     // Status after the change
+	PrevStatusReason *StatusReason `json:"prevLeaseStatusReason,omitempty" dynamodbav:"PrevLeaseStatusReason,omitempty"` // Status reason before the change
+	StatusReason     *StatusReason `json:"leaseStatusReason,omitempty" dynamodbav:"LeaseStatusReason,omitempty"`         // Status reason after the change
+	PrevExpiresOn    *int64        `json:"prevExpiresOn,omitempty" dynamodbav:"PrevExpiresOn,omitempty"`                 // Expiration before the change
+	ExpiresOn        *int64        `json:"expiresOn,omitempty" dynamodbav:"ExpiresOn,omitempty"`                         // Expiration after the change
+	PrevBudgetAmount *float64      `json:"prevBudgetAmount,omitempty" dynamodbav:"PrevBudgetAmount,omitempty"`           // Budget amount before the change
+	BudgetAmount     *float64      `json:"budgetAmount,omitempty" dynamodbav:"BudgetAmount,omitempty"`                   // Budget amount after the change
+}
+
+// Histories is a list of type History
+type Histories []History
+
+// NewHistory creates the history record for a change to a lease.
+// prev is nil when the lease was just created.
+func NewHistory(prev *Lease, next *Lease) *History {
+	now := time.Now().Unix()
+	historyID := uuid.New().String()
+	actor := ActorSystem
+	if next.Actor != nil {
+		actor = *next.Actor
+	}
+
+	history := &History{
+		LeaseID:      next.ID,
+		ID:           &historyID,
+		CreatedOn:    &now,
+		Actor:        &actor,
+		Status:       next.Status,
+		StatusReason: next.StatusReason,
+		ExpiresOn:    next.ExpiresOn,
+		BudgetAmount: next.BudgetAmount,
+	}
+	if prev != nil {
+		history.PrevStatus = prev.Status
+		history.PrevStatusReason = prev.StatusReason
+		history.PrevExpiresOn = prev.ExpiresOn
+		history.PrevBudgetAmount = prev.BudgetAmount
+	}
+	return history
+}
+
+// ListHistory returns the changes made to a lease, oldest first
+func (a *Service) ListHistory(ID string) (*Histories, error) {
+	_, err := a.dataSvc.Get(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lease history is optional, without it there's nothing to list
+	if a.historySvc == nil {
+		return &Histories{}, nil
+	}
+
+	histories, err := a.historySvc.List(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(*histories, func(i, j int) bool {
+		return *(*histories)[i].CreatedOn < *(*histories)[j].CreatedOn
+	})
+	return histories, nil
+}
+
+// recordHistory writes a change to a lease into the lease history.
+// The lease itself has already been written, so failures are only logged.
+func (a *Service) recordHistory(prev *Lease, next *Lease) {
+	if a.historySvc == nil {
+		return
+	}
+	err := a.historySvc.Write(NewHistory(prev, next))
+	if err != nil {
+		log.Printf("Failed to record history for lease %s: %s", *next.ID, err)
+	}
+}
@@ -0,0 +1,125 @@
+package lease_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Optum/dce/pkg/account"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/lease/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSaveRecordsHistory(t *testing.T) {
+	lastModifiedOn := time.Now().Unix()
+	stored := &lease.Lease{
+		ID:             ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+		PrincipalID:    ptrString("User1"),
+		AccountID:      ptrString("123456789012"),
+		Status:         lease.StatusActive.StatusPtr(),
+		StatusReason:   lease.StatusReasonActive.StatusReasonPtr(),
+		BudgetAmount:   ptrFloat(100.00),
+		ExpiresOn:      &lastModifiedOn,
+		CreatedOn:      &lastModifiedOn,
+		LastModifiedOn: &lastModifiedOn,
+	}
+
+	mocksRwd := &mocks.ReaderWriter{}
+	mocksHistory := &mocks.HistoryReaderWriter{}
+	mocksRwd.On("Get", "6d666a28-4f2c-43af-8c94-1b715ca079ae").Return(stored, nil)
+	mocksRwd.On("Write", mock.AnythingOfType("*lease.Lease"), &lastModifiedOn).Return(nil)
+	mocksHistory.On("Write", mock.MatchedBy(func(input *lease.History) bool {
+		return *input.LeaseID == "6d666a28-4f2c-43af-8c94-1b715ca079ae" &&
+			*input.Actor == lease.ActorSystem &&
+			*input.PrevStatus == lease.StatusActive &&
+			*input.Status == lease.StatusInactive &&
+			*input.PrevStatusReason == lease.StatusReasonActive &&
+			*input.StatusReason == lease.StatusReasonExpired &&
+			*input.PrevBudgetAmount == 100.00
+	})).Return(nil)
+
+	leaseSvc := lease.NewService(lease.NewServiceInput{
+		DataSvc:    mocksRwd,
+		HistorySvc: mocksHistory,
+	})
+
+	expired := *stored
+	expired.Status = lease.StatusInactive.StatusPtr()
+	expired.StatusReason = lease.StatusReasonExpired.StatusReasonPtr()
+	err := leaseSvc.Save(&expired)
+
+	assert.Nil(t, err)
+	mocksHistory.AssertNumberOfCalls(t, "Write", 1)
+}
+
+func TestDeleteRecordsHistory(t *testing.T) {
+	mocksRwd := &mocks.ReaderWriter{}
+	mocksHistory := &mocks.HistoryReaderWriter{}
+	mocksAccountSvc := &mocks.AccountServicer{}
+	mocksEvents := &mocks.Eventer{}
+
+	mocksRwd.On("Get", "6d666a28-4f2c-43af-8c94-1b715ca079ae").Return(&lease.Lease{
+		ID:           ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+		PrincipalID:  ptrString("User1"),
+		AccountID:    ptrString("123456789012"),
+		Status:       lease.StatusActive.StatusPtr(),
+		StatusReason: lease.StatusReasonActive.StatusReasonPtr(),
+	}, nil)
+	mocksRwd.On("Write", mock.AnythingOfType("*lease.Lease"), mock.Anything).Return(nil)
+	mocksHistory.On("Write", mock.MatchedBy(func(input *lease.History) bool {
+		return *input.Actor == "User1" &&
+			*input.PrevStatus == lease.StatusActive &&
+			*input.Status == lease.StatusInactive &&
+			*input.StatusReason == lease.StatusReasonDestroyed
+	})).Return(nil)
+	mocksAccountSvc.On("Reset", "123456789012").Return(&account.Account{}, nil)
+	mocksEvents.On("LeaseEnd", mock.AnythingOfType("*lease.Lease")).Return(nil)
+
+	leaseSvc := lease.NewService(lease.NewServiceInput{
+		DataSvc:    mocksRwd,
+		HistorySvc: mocksHistory,
+		AccountSvc: mocksAccountSvc,
+		EventSvc:   mocksEvents,
+	})
+
+	_, err := leaseSvc.Delete("6d666a28-4f2c-43af-8c94-1b715ca079ae", "User1")
+
+	assert.Nil(t, err)
+	mocksHistory.AssertNumberOfCalls(t, "Write", 1)
+}
+
+func TestListHistory(t *testing.T) {
+	firstChange := int64(1561149393)
+	secondChange := int64(1561149400)
+	mocksRwd := &mocks.ReaderWriter{}
+	mocksHistory := &mocks.HistoryReaderWriter{}
+
+	mocksRwd.On("Get", "6d666a28-4f2c-43af-8c94-1b715ca079ae").Return(&lease.Lease{
+		ID: ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+	}, nil)
+	mocksHistory.On("List", "6d666a28-4f2c-43af-8c94-1b715ca079ae").Return(&lease.Histories{
+		lease.History{
+			ID:        ptrString("b"),
+			CreatedOn: &secondChange,
+			Status:    lease.StatusInactive.StatusPtr(),
+		},
+		lease.History{
+			ID:        ptrString("a"),
+			CreatedOn: &firstChange,
+			Status:    lease.StatusActive.StatusPtr(),
+		},
+	}, nil)
+
+	leaseSvc := lease.NewService(lease.NewServiceInput{
+		DataSvc:    mocksRwd,
+		HistorySvc: mocksHistory,
+	})
+
+	result, err := leaseSvc.ListHistory("6d666a28-4f2c-43af-8c94-1b715ca079ae")
+
+	assert.Nil(t, err)
+	assert.Len(t, *result, 2)
+	assert.Equal(t, "a", *(*result)[0].ID)
+	assert.Equal(t, "b", *(*result)[1].ID)
+}
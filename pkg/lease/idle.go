@@ -0,0 +1,25 @@
+package lease
+
+import (
+	"time"
+)
+
+// WarnIdle records that the principal was warned their lease is idle,
+// and will be ended unless they use it. Returns the updated lease.
+func (a *Service) WarnIdle(ID string) (*Lease, error) {
+	now := time.Now().Unix()
+	return a.writeIdleWarnedOn(ID, &now)
+}
+
+// ClearIdleWarning forgets the idle warning of a lease which is in use again.
+// Returns the updated lease.
+func (a *Service) ClearIdleWarning(ID string) (*Lease, error) {
+	return a.writeIdleWarnedOn(ID, nil)
+}
+
+// writeIdleWarnedOn sets the idleWarnedOn date of an active lease
+func (a *Service) writeIdleWarnedOn(ID string, idleWarnedOn *int64) (*Lease, error) {
+	return a.writeActiveLease(ID, func(updated *Lease) {
+		updated.IdleWarnedOn = idleWarnedOn
+	})
+}
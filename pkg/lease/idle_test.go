@@ -0,0 +1,86 @@
+package lease_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/lease/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWarnIdle(t *testing.T) {
+
+	lastModifiedOn := time.Now().AddDate(0, 0, -1).Unix()
+	tests := []struct {
+		name        string
+		getResponse *lease.Lease
+		expErr      error
+	}{
+		{
+			name: "should warn an active lease",
+			getResponse: &lease.Lease{
+				ID:             ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+				Status:         lease.StatusActive.StatusPtr(),
+				LastModifiedOn: &lastModifiedOn,
+			},
+		},
+		{
+			name: "should fail to warn an inactive lease",
+			getResponse: &lease.Lease{
+				ID:             ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+				Status:         lease.StatusInactive.StatusPtr(),
+				LastModifiedOn: &lastModifiedOn,
+			},
+			expErr: errors.NewConflict("lease", "6d666a28-4f2c-43af-8c94-1b715ca079ae", fmt.Errorf("leaseStatus: must be active lease.")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mocksRwd := &mocks.ReaderWriter{}
+
+			mocksRwd.On("Get", "6d666a28-4f2c-43af-8c94-1b715ca079ae").Return(tt.getResponse, nil)
+			mocksRwd.On("Write", mock.AnythingOfType("*lease.Lease"), &lastModifiedOn).Return(nil)
+
+			leaseSvc := lease.NewService(lease.NewServiceInput{
+				DataSvc: mocksRwd,
+			})
+
+			result, err := leaseSvc.WarnIdle("6d666a28-4f2c-43af-8c94-1b715ca079ae")
+
+			assert.Truef(t, errors.Is(err, tt.expErr), "actual error %q doesn't match expected error %q", err, tt.expErr)
+			if tt.expErr == nil {
+				assert.NotNil(t, result.IdleWarnedOn)
+				assert.Equal(t, lease.StatusActive, *result.Status)
+				mocksRwd.AssertNumberOfCalls(t, "Write", 1)
+			}
+		})
+	}
+}
+
+func TestClearIdleWarning(t *testing.T) {
+	lastModifiedOn := time.Now().AddDate(0, 0, -1).Unix()
+	idleWarnedOn := lastModifiedOn
+
+	mocksRwd := &mocks.ReaderWriter{}
+	mocksRwd.On("Get", "6d666a28-4f2c-43af-8c94-1b715ca079ae").Return(&lease.Lease{
+		ID:             ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+		Status:         lease.StatusActive.StatusPtr(),
+		LastModifiedOn: &lastModifiedOn,
+		IdleWarnedOn:   &idleWarnedOn,
+	}, nil)
+	mocksRwd.On("Write", mock.AnythingOfType("*lease.Lease"), &lastModifiedOn).Return(nil)
+
+	leaseSvc := lease.NewService(lease.NewServiceInput{
+		DataSvc: mocksRwd,
+	})
+
+	result, err := leaseSvc.ClearIdleWarning("6d666a28-4f2c-43af-8c94-1b715ca079ae")
+
+	assert.Nil(t, err)
+	assert.Nil(t, result.IdleWarnedOn)
+}
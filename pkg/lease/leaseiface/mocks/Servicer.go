@@ -2,6 +2,7 @@
 
 package mocks
 
+import account "github.com/Optum/dce/pkg/account"
 import lease "github.com/Optum/dce/pkg/lease"
 
 import mock "github.com/stretchr/testify/mock"
@@ -11,6 +12,52 @@ type Servicer struct {
 	mock.Mock
 }
 
+// Approve provides a mock function with given fields: ID, reviewedBy, comment
+func (_m *Servicer) Approve(ID string, reviewedBy string, comment *string) (*lease.Lease, error) {
+	ret := _m.Called(ID, reviewedBy, comment)
+
+	var r0 *lease.Lease
+	if rf, ok := ret.Get(0).(func(string, string, *string) *lease.Lease); ok {
+		r0 = rf(ID, reviewedBy, comment)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lease.Lease)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, *string) error); ok {
+		r1 = rf(ID, reviewedBy, comment)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ClearIdleWarning provides a mock function with given fields: ID
+func (_m *Servicer) ClearIdleWarning(ID string) (*lease.Lease, error) {
+	ret := _m.Called(ID)
+
+	var r0 *lease.Lease
+	if rf, ok := ret.Get(0).(func(string) *lease.Lease); ok {
+		r0 = rf(ID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lease.Lease)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(ID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: data, principalSpentAmount
 func (_m *Servicer) Create(data *lease.Lease, principalSpentAmount float64) (*lease.Lease, error) {
 	ret := _m.Called(data, principalSpentAmount)
@@ -34,13 +81,36 @@ func (_m *Servicer) Create(data *lease.Lease, principalSpentAmount float64) (*le
 	return r0, r1
 }
 
-// Delete provides a mock function with given fields: ID
-func (_m *Servicer) Delete(ID string) (*lease.Lease, error) {
-	ret := _m.Called(ID)
+// CreateRequest provides a mock function with given fields: data, principalSpentAmount
+func (_m *Servicer) CreateRequest(data *lease.Lease, principalSpentAmount float64) (*lease.Request, error) {
+	ret := _m.Called(data, principalSpentAmount)
+
+	var r0 *lease.Request
+	if rf, ok := ret.Get(0).(func(*lease.Lease, float64) *lease.Request); ok {
+		r0 = rf(data, principalSpentAmount)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lease.Request)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*lease.Lease, float64) error); ok {
+		r1 = rf(data, principalSpentAmount)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ID, actor
+func (_m *Servicer) Delete(ID string, actor string) (*lease.Lease, error) {
+	ret := _m.Called(ID, actor)
 
 	var r0 *lease.Lease
-	if rf, ok := ret.Get(0).(func(string) *lease.Lease); ok {
-		r0 = rf(ID)
+	if rf, ok := ret.Get(0).(func(string, string) *lease.Lease); ok {
+		r0 = rf(ID, actor)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*lease.Lease)
@@ -48,8 +118,54 @@ func (_m *Servicer) Delete(ID string) (*lease.Lease, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(ID)
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(ID, actor)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExpirePendingApprovals provides a mock function with given fields:
+func (_m *Servicer) ExpirePendingApprovals() (*lease.Leases, error) {
+	ret := _m.Called()
+
+	var r0 *lease.Leases
+	if rf, ok := ret.Get(0).(func() *lease.Leases); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lease.Leases)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Freeze provides a mock function with given fields: ID, frozenUntil
+func (_m *Servicer) Freeze(ID string, frozenUntil int64) (*lease.Lease, error) {
+	ret := _m.Called(ID, frozenUntil)
+
+	var r0 *lease.Lease
+	if rf, ok := ret.Get(0).(func(string, int64) *lease.Lease); ok {
+		r0 = rf(ID, frozenUntil)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lease.Lease)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, int64) error); ok {
+		r1 = rf(ID, frozenUntil)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -57,6 +173,38 @@ func (_m *Servicer) Delete(ID string) (*lease.Lease, error) {
 	return r0, r1
 }
 
+// FulfillRequest provides a mock function with given fields: accountID, principalSpend
+func (_m *Servicer) FulfillRequest(accountID string, principalSpend func(string) (float64, error)) (*lease.Lease, *lease.Request, error) {
+	ret := _m.Called(accountID, principalSpend)
+
+	var r0 *lease.Lease
+	if rf, ok := ret.Get(0).(func(string, func(string) (float64, error)) *lease.Lease); ok {
+		r0 = rf(accountID, principalSpend)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lease.Lease)
+		}
+	}
+
+	var r1 *lease.Request
+	if rf, ok := ret.Get(1).(func(string, func(string) (float64, error)) *lease.Request); ok {
+		r1 = rf(accountID, principalSpend)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*lease.Request)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, func(string) (float64, error)) error); ok {
+		r2 = rf(accountID, principalSpend)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // Get provides a mock function with given fields: ID
 func (_m *Servicer) Get(ID string) (*lease.Lease, error) {
 	ret := _m.Called(ID)
@@ -103,6 +251,52 @@ func (_m *Servicer) GetByAccountIDAndPrincipalID(accountID string, principalID s
 	return r0, r1
 }
 
+// GetProfile provides a mock function with given fields: name
+func (_m *Servicer) GetProfile(name string) (*lease.Profile, error) {
+	ret := _m.Called(name)
+
+	var r0 *lease.Profile
+	if rf, ok := ret.Get(0).(func(string) *lease.Profile); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lease.Profile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRequest provides a mock function with given fields: ID
+func (_m *Servicer) GetRequest(ID string) (*lease.Request, error) {
+	ret := _m.Called(ID)
+
+	var r0 *lease.Request
+	if rf, ok := ret.Get(0).(func(string) *lease.Request); ok {
+		r0 = rf(ID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lease.Request)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(ID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // List provides a mock function with given fields: query
 func (_m *Servicer) List(query *lease.Lease) (*lease.Leases, error) {
 	ret := _m.Called(query)
@@ -126,6 +320,29 @@ func (_m *Servicer) List(query *lease.Lease) (*lease.Leases, error) {
 	return r0, r1
 }
 
+// ListHistory provides a mock function with given fields: ID
+func (_m *Servicer) ListHistory(ID string) (*lease.Histories, error) {
+	ret := _m.Called(ID)
+
+	var r0 *lease.Histories
+	if rf, ok := ret.Get(0).(func(string) *lease.Histories); ok {
+		r0 = rf(ID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lease.Histories)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(ID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListPages provides a mock function with given fields: query, fn
 func (_m *Servicer) ListPages(query *lease.Lease, fn func(*lease.Leases) bool) error {
 	ret := _m.Called(query, fn)
@@ -139,3 +356,256 @@ func (_m *Servicer) ListPages(query *lease.Lease, fn func(*lease.Leases) bool) e
 
 	return r0
 }
+
+// ListProfiles provides a mock function with given fields: query
+func (_m *Servicer) ListProfiles(query *lease.Profile) (*lease.Profiles, error) {
+	ret := _m.Called(query)
+
+	var r0 *lease.Profiles
+	if rf, ok := ret.Get(0).(func(*lease.Profile) *lease.Profiles); ok {
+		r0 = rf(query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lease.Profiles)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*lease.Profile) error); ok {
+		r1 = rf(query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListRequests provides a mock function with given fields: query
+func (_m *Servicer) ListRequests(query *lease.Request) (*lease.Requests, error) {
+	ret := _m.Called(query)
+
+	var r0 *lease.Requests
+	if rf, ok := ret.Get(0).(func(*lease.Request) *lease.Requests); ok {
+		r0 = rf(query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lease.Requests)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*lease.Request) error); ok {
+		r1 = rf(query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkBudgetNotified provides a mock function with given fields: ID, thresholds
+func (_m *Servicer) MarkBudgetNotified(ID string, thresholds []float64) (*lease.Lease, error) {
+	ret := _m.Called(ID, thresholds)
+
+	var r0 *lease.Lease
+	if rf, ok := ret.Get(0).(func(string, []float64) *lease.Lease); ok {
+		r0 = rf(ID, thresholds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lease.Lease)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []float64) error); ok {
+		r1 = rf(ID, thresholds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkExpiryNotified provides a mock function with given fields: ID, thresholds
+func (_m *Servicer) MarkExpiryNotified(ID string, thresholds []float64) (*lease.Lease, error) {
+	ret := _m.Called(ID, thresholds)
+
+	var r0 *lease.Lease
+	if rf, ok := ret.Get(0).(func(string, []float64) *lease.Lease); ok {
+		r0 = rf(ID, thresholds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lease.Lease)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []float64) error); ok {
+		r1 = rf(ID, thresholds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReclaimFrozenLeases provides a mock function with given fields:
+func (_m *Servicer) ReclaimFrozenLeases() (*lease.Leases, error) {
+	ret := _m.Called()
+
+	var r0 *lease.Leases
+	if rf, ok := ret.Get(0).(func() *lease.Leases); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lease.Leases)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reject provides a mock function with given fields: ID, reviewedBy, comment
+func (_m *Servicer) Reject(ID string, reviewedBy string, comment *string) (*lease.Lease, error) {
+	ret := _m.Called(ID, reviewedBy, comment)
+
+	var r0 *lease.Lease
+	if rf, ok := ret.Get(0).(func(string, string, *string) *lease.Lease); ok {
+		r0 = rf(ID, reviewedBy, comment)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lease.Lease)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, *string) error); ok {
+		r1 = rf(ID, reviewedBy, comment)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SelectAccounts provides a mock function with given fields: data, accounts
+func (_m *Servicer) SelectAccounts(data *lease.Lease, accounts *account.Accounts) (*account.Accounts, error) {
+	ret := _m.Called(data, accounts)
+
+	var r0 *account.Accounts
+	if rf, ok := ret.Get(0).(func(*lease.Lease, *account.Accounts) *account.Accounts); ok {
+		r0 = rf(data, accounts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*account.Accounts)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*lease.Lease, *account.Accounts) error); ok {
+		r1 = rf(data, accounts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetForecast provides a mock function with given fields: ID, forecastSpend
+func (_m *Servicer) SetForecast(ID string, forecastSpend float64) (*lease.Lease, error) {
+	ret := _m.Called(ID, forecastSpend)
+
+	var r0 *lease.Lease
+	if rf, ok := ret.Get(0).(func(string, float64) *lease.Lease); ok {
+		r0 = rf(ID, forecastSpend)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lease.Lease)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, float64) error); ok {
+		r1 = rf(ID, forecastSpend)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ID, data, principalSpentAmount
+func (_m *Servicer) Update(ID string, data *lease.Lease, principalSpentAmount float64) (*lease.Lease, error) {
+	ret := _m.Called(ID, data, principalSpentAmount)
+
+	var r0 *lease.Lease
+	if rf, ok := ret.Get(0).(func(string, *lease.Lease, float64) *lease.Lease); ok {
+		r0 = rf(ID, data, principalSpentAmount)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lease.Lease)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, *lease.Lease, float64) error); ok {
+		r1 = rf(ID, data, principalSpentAmount)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WarnForecast provides a mock function with given fields: ID
+func (_m *Servicer) WarnForecast(ID string) (*lease.Lease, error) {
+	ret := _m.Called(ID)
+
+	var r0 *lease.Lease
+	if rf, ok := ret.Get(0).(func(string) *lease.Lease); ok {
+		r0 = rf(ID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lease.Lease)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(ID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WarnIdle provides a mock function with given fields: ID
+func (_m *Servicer) WarnIdle(ID string) (*lease.Lease, error) {
+	ret := _m.Called(ID)
+
+	var r0 *lease.Lease
+	if rf, ok := ret.Get(0).(func(string) *lease.Lease); ok {
+		r0 = rf(ID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lease.Lease)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(ID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
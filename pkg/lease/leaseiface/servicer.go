@@ -3,6 +3,7 @@
 package leaseiface
 
 import (
+	"github.com/Optum/dce/pkg/account"
 	"github.com/Optum/dce/pkg/lease"
 )
 
@@ -17,12 +18,72 @@ type Servicer interface {
 	// Save writes the record to the dataSvc
 	Create(data *lease.Lease, principalSpentAmount float64) (*lease.Lease, error)
 
+	// Update extends the Lease expiresOn and/or budgetAmount
+	Update(ID string, data *lease.Lease, principalSpentAmount float64) (*lease.Lease, error)
+
 	// Update the Lease record to status Inactive in DynamoDB
-	Delete(ID string) (*lease.Lease, error)
+	Delete(ID string, actor string) (*lease.Lease, error)
 
 	// List Get a list of lease based on Lease ID
 	List(query *lease.Lease) (*lease.Leases, error)
 
+	// ListHistory returns the changes made to a lease, oldest first
+	ListHistory(ID string) (*lease.Histories, error)
+
 	// ListPages runs a function on each page in a list
 	ListPages(query *lease.Lease, fn func(*lease.Leases) bool) error
+
+	// CreateRequest queues a lease request until an account is available
+	CreateRequest(data *lease.Lease, principalSpentAmount float64) (*lease.Request, error)
+
+	// GetRequest returns a lease request from ID
+	GetRequest(ID string) (*lease.Request, error)
+
+	// ListRequests Get a list of lease requests
+	ListRequests(query *lease.Request) (*lease.Requests, error)
+
+	// GetProfile returns a lease profile from its name
+	GetProfile(name string) (*lease.Profile, error)
+
+	// ListProfiles Get a list of lease profiles
+	ListProfiles(query *lease.Profile) (*lease.Profiles, error)
+
+	// FulfillRequest leases the account to the oldest pending lease request
+	FulfillRequest(accountID string, principalSpend func(principalID string) (float64, error)) (*lease.Lease, *lease.Request, error)
+
+	// Approve activates a lease which is pending approval
+	Approve(ID string, reviewedBy string, comment *string) (*lease.Lease, error)
+
+	// Reject ends a lease which is pending approval
+	Reject(ID string, reviewedBy string, comment *string) (*lease.Lease, error)
+
+	// ExpirePendingApprovals ends the leases nobody approved or rejected in time
+	ExpirePendingApprovals() (*lease.Leases, error)
+
+	// Freeze limits the principal of an over budget lease to reading and exporting data until frozenUntil
+	Freeze(ID string, frozenUntil int64) (*lease.Lease, error)
+
+	// ReclaimFrozenLeases ends the frozen leases which are past their frozenUntil date
+	ReclaimFrozenLeases() (*lease.Leases, error)
+
+	// WarnIdle records that the principal was warned their lease is idle
+	WarnIdle(ID string) (*lease.Lease, error)
+
+	// ClearIdleWarning forgets the idle warning of a lease which is in use again
+	ClearIdleWarning(ID string) (*lease.Lease, error)
+
+	// MarkExpiryNotified records that the principal was notified their lease expires within the thresholds, in hours
+	MarkExpiryNotified(ID string, thresholds []float64) (*lease.Lease, error)
+
+	// MarkBudgetNotified records that the principal was notified their lease spend crossed the lease budget threshold percentiles
+	MarkBudgetNotified(ID string, thresholds []float64) (*lease.Lease, error)
+
+	// SetForecast records the spend forecast by the time the lease expires
+	SetForecast(ID string, forecastSpend float64) (*lease.Lease, error)
+
+	// WarnForecast records that the principal was warned their lease is forecast to go over budget
+	WarnForecast(ID string) (*lease.Lease, error)
+
+	// SelectAccounts orders the Ready accounts for a new lease
+	SelectAccounts(data *lease.Lease, accounts *account.Accounts) (*account.Accounts, error)
 }
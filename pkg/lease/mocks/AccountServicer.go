@@ -11,6 +11,43 @@ type AccountServicer struct {
 	mock.Mock
 }
 
+// FreezePrincipalAccess provides a mock function with given fields: data
+func (_m *AccountServicer) FreezePrincipalAccess(data *account.Account) error {
+	ret := _m.Called(data)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*account.Account) error); ok {
+		r0 = rf(data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Get provides a mock function with given fields: ID
+func (_m *AccountServicer) Get(ID string) (*account.Account, error) {
+	ret := _m.Called(ID)
+
+	var r0 *account.Account
+	if rf, ok := ret.Get(0).(func(string) *account.Account); ok {
+		r0 = rf(ID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*account.Account)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(ID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Reset provides a mock function with given fields: id
 func (_m *AccountServicer) Reset(id string) (*account.Account, error) {
 	ret := _m.Called(id)
@@ -24,10 +24,23 @@ type Lease struct {
 	BudgetNotificationEmails *[]string              `json:"budgetNotificationEmails,omitempty" dynamodbav:"BudgetNotificationEmails,omitempty" schema:"budgetNotificationEmails,omitempty"` // Budget notification emails
 	StatusModifiedOn         *int64                 `json:"leaseStatusModifiedOn,omitempty" dynamodbav:"LeaseStatusModifiedOn,omitempty" schema:"leaseStatusModifiedOn,omitempty"`          // Last Modified Epoch Timestamp
 	ExpiresOn                *int64                 `json:"expiresOn,omitempty" dynamodbav:"ExpiresOn,omitempty" schema:"expiresOn,omitempty"`                                              // Lease expiration time as Epoch
+	ExtensionCount           *int64                 `json:"extensionCount,omitempty" dynamodbav:"ExtensionCount,omitempty" schema:"-"`                                                      // Number of times the lease has been extended
 	Metadata                 map[string]interface{} `json:"metadata,omitempty"  dynamodbav:"Metadata,omitempty" schema:"-"`
+	Profile                  *string                `json:"profile,omitempty" dynamodbav:"Profile,omitempty" schema:"profile,omitempty"`                 // Name of the lease profile the lease was created with
+	ReviewedBy               *string                `json:"reviewedBy,omitempty" dynamodbav:"ReviewedBy,omitempty" schema:"-"`                           // Admin who approved or rejected the lease
+	ReviewComment            *string                `json:"reviewComment,omitempty" dynamodbav:"ReviewComment,omitempty" schema:"-"`                     // Comment left by the admin who approved or rejected the lease
+	FrozenUntil              *int64                 `json:"frozenUntil,omitempty" dynamodbav:"FrozenUntil,omitempty" schema:"-"`                         // When a frozen lease's account will be reset, as Epoch
+	IdleWarnedOn             *int64                 `json:"idleWarnedOn,omitempty" dynamodbav:"IdleWarnedOn,omitempty" schema:"-"`                       // When the principal was warned the lease is idle, as Epoch
+	ExpiryNotificationsSent  *[]float64             `json:"expiryNotificationsSent,omitempty" dynamodbav:"ExpiryNotificationsSent,omitempty" schema:"-"` // Hours-before-expiry thresholds the principal was already notified of
+	BudgetNotificationsSent  *[]float64             `json:"budgetNotificationsSent,omitempty" dynamodbav:"BudgetNotificationsSent,omitempty" schema:"-"` // Lease budget threshold percentiles the principal was already notified of
+	ForecastSpend            *float64               `json:"forecastSpend,omitempty" dynamodbav:"ForecastSpend,omitempty" schema:"-"`                     // Spend forecast by the time the lease expires, in the budget currency
+	ForecastWarnedOn         *int64                 `json:"forecastWarnedOn,omitempty" dynamodbav:"ForecastWarnedOn,omitempty" schema:"-"`               // When the principal was warned the lease is forecast to go over budget, as Epoch
 	Limit                    *int64                 `json:"-" dynamodbav:"-" schema:"limit,omitempty"`
 	NextAccountID            *string                `json:"-" dynamodbav:"-" schema:"nextAccountId,omitempty"`
 	NextPrincipalID          *string                `json:"-" dynamodbav:"-" schema:"nextPrincipalId,omitempty"`
+	NextLastModifiedOn       *int64                 `json:"-" dynamodbav:"-" schema:"nextLastModifiedOn,omitempty"`
+	PrincipalGroups          []string               `json:"-" dynamodbav:"-" schema:"-"` // Groups of the principal, used to find their max active leases
+	Actor                    *string                `json:"-" dynamodbav:"-" schema:"-"` // User making the change, recorded in the lease history
 }
 
 // Validate the lease data
@@ -49,6 +62,25 @@ func (l *Lease) Validate() error {
 // Leases is a list of type Lease
 type Leases []Lease
 
+// committedBudgetAmount returns the total budget amount of the leases in the cost currency,
+// leaving out the lease with the excluded ID. Each budget is converted from its own budget currency.
+func (l *Leases) committedBudgetAmount(excludeID *string, toCostCurrency func(amount float64, budgetCurrency *string) (float64, error)) (float64, error) {
+	committed := 0.0
+	for _, lease := range *l {
+		if excludeID != nil && lease.ID != nil && *lease.ID == *excludeID {
+			continue
+		}
+		if lease.BudgetAmount != nil {
+			amount, err := toCostCurrency(*lease.BudgetAmount, lease.BudgetCurrency)
+			if err != nil {
+				return 0, err
+			}
+			committed += amount
+		}
+	}
+	return committed, nil
+}
+
 // Status is a lease status type
 type Status string
 
@@ -59,6 +91,10 @@ const (
 	StatusActive Status = "Active"
 	// StatusInactive status
 	StatusInactive Status = "Inactive"
+	// StatusPending status, the lease is waiting for an admin to approve it
+	StatusPending Status = "Pending"
+	// StatusFrozen status, the lease is over budget and the principal can only read and export data
+	StatusFrozen Status = "Frozen"
 )
 
 // String returns the string value of Status
@@ -85,6 +121,10 @@ func ParseStatus(status string) (Status, error) {
 		return StatusActive, nil
 	case "inactive":
 		return StatusInactive, nil
+	case "pending":
+		return StatusPending, nil
+	case "frozen":
+		return StatusFrozen, nil
 	}
 	return StatusEmpty, fmt.Errorf("Cannot parse value %s", status)
 }
@@ -109,6 +149,17 @@ const (
 	// StatusReasonAccountOrphaned means that the health of the account was compromised.  The account has been orphaned
 	// which means the leases are also made Inactive
 	StatusReasonAccountOrphaned StatusReason = "LeaseAccountOrphaned"
+	// StatusReasonPendingApproval means the lease is over the auto-approve limits, and is waiting for an admin to approve it.
+	StatusReasonPendingApproval StatusReason = "PendingApproval"
+	// StatusReasonRejected means an admin rejected the lease instead of approving it.
+	StatusReasonRejected StatusReason = "Rejected"
+	// StatusReasonApprovalExpired means nobody approved or rejected the lease before the approval timeout.
+	StatusReasonApprovalExpired StatusReason = "ApprovalExpired"
+	// StatusReasonIdle means the lease spent next to nothing for too long, and was reclaimed.
+	StatusReasonIdle StatusReason = "Idle"
+	// StatusReasonPrincipalBudgetExhausted means the principal already spent their principal budget for the current
+	// budget period, and can't be leased new accounts until the next one.
+	StatusReasonPrincipalBudgetExhausted StatusReason = "PrincipalBudgetExhausted"
 )
 
 // StatusReasonPtr returns a pointer to the string value of StatusReason
@@ -126,6 +177,7 @@ type NewLeaseInput struct {
 	BudgetNotificationEmails []string
 	Metadata                 map[string]interface{}
 	ExpiresOn                int64
+	Profile                  *string
 }
 
 // NewLease creates a new instance of lease
@@ -142,5 +194,6 @@ func NewLease(input NewLeaseInput) *Lease {
 		Status:                   StatusActive.StatusPtr(),
 		StatusReason:             StatusReasonActive.StatusReasonPtr(),
 		ExpiresOn:                &input.ExpiresOn,
+		Profile:                  input.Profile,
 	}
 }
@@ -0,0 +1,38 @@
+package lease
+
+import (
+	"sort"
+)
+
+// MarkBudgetNotified records that the principal was notified their lease spend crossed
+// each of the lease budget threshold percentiles. Returns the updated lease.
+func (a *Service) MarkBudgetNotified(ID string, thresholds []float64) (*Lease, error) {
+	return a.writeActiveLease(ID, func(updated *Lease) {
+		updated.BudgetNotificationsSent = mergeThresholds(updated.BudgetNotificationsSent, thresholds)
+	})
+}
+
+// mergeThresholds adds the thresholds which were notified to the ones sent before.
+// Returns them highest first.
+func mergeThresholds(sent *[]float64, thresholds []float64) *[]float64 {
+	merged := []float64{}
+	if sent != nil {
+		merged = append(merged, *sent...)
+	}
+	for _, threshold := range thresholds {
+		if !containsFloat(merged, threshold) {
+			merged = append(merged, threshold)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(merged)))
+	return &merged
+}
+
+func containsFloat(values []float64, value float64) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package lease_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/lease/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMarkBudgetNotified(t *testing.T) {
+	lastModifiedOn := time.Now().AddDate(0, 0, -1).Unix()
+
+	mocksRwd := &mocks.ReaderWriter{}
+	mocksRwd.On("Get", "6d666a28-4f2c-43af-8c94-1b715ca079ae").Return(&lease.Lease{
+		ID:                      ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+		Status:                  lease.StatusActive.StatusPtr(),
+		LastModifiedOn:          &lastModifiedOn,
+		BudgetNotificationsSent: &[]float64{50},
+	}, nil)
+	mocksRwd.On("Write", mock.AnythingOfType("*lease.Lease"), &lastModifiedOn).Return(nil)
+
+	leaseSvc := lease.NewService(lease.NewServiceInput{
+		DataSvc: mocksRwd,
+	})
+
+	result, err := leaseSvc.MarkBudgetNotified("6d666a28-4f2c-43af-8c94-1b715ca079ae", []float64{50, 75})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{75, 50}, *result.BudgetNotificationsSent)
+	mocksRwd.AssertNumberOfCalls(t, "Write", 1)
+}
@@ -0,0 +1,52 @@
+package lease
+
+import (
+	"time"
+
+	"github.com/Optum/dce/pkg/errors"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// Profile is a type corresponding to a LeaseProfile table record.
+// A lease profile is a named template which bundles the budget, duration,
+// regions and principal policy for the leases created with it.
+type Profile struct {
+	Name                 *string   `json:"name,omitempty" dynamodbav:"Name" schema:"name,omitempty"`                              // Name of the profile, eg. "sandbox-small"
+	DefaultBudgetAmount  *float64  `json:"defaultBudgetAmount,omitempty" dynamodbav:"DefaultBudgetAmount,omitempty" schema:"-"`   // Budget amount for leases which don't request one
+	MaxBudgetAmount      *float64  `json:"maxBudgetAmount,omitempty" dynamodbav:"MaxBudgetAmount,omitempty" schema:"-"`           // Max budget amount a lease may request
+	MaxLeasePeriod       *int64    `json:"maxLeasePeriod,omitempty" dynamodbav:"MaxLeasePeriod,omitempty" schema:"-"`             // Max duration of a lease, in seconds
+	AllowedRegions       *[]string `json:"allowedRegions,omitempty" dynamodbav:"AllowedRegions,omitempty" schema:"-"`             // Regions the principal may use
+	PrincipalPolicyS3Key *string   `json:"principalPolicyS3Key,omitempty" dynamodbav:"PrincipalPolicyS3Key,omitempty" schema:"-"` // Key of the principal policy template in the artifacts bucket
+	CreatedOn            *int64    `json:"createdOn,omitempty" dynamodbav:"CreatedOn,omitempty" schema:"-"`                       // Created Epoch Timestamp
+	LastModifiedOn       *int64    `json:"lastModifiedOn,omitempty" dynamodbav:"LastModifiedOn,omitempty" schema:"-"`             // Last Modified Epoch Timestamp
+	Limit                *int64    `json:"-" dynamodbav:"-" schema:"limit,omitempty"`
+}
+
+// Validate the lease profile data
+func (p *Profile) Validate() error {
+	err := validation.ValidateStruct(p,
+		validation.Field(&p.Name, validation.NotNil.Error("must be a string")),
+		validation.Field(&p.LastModifiedOn, validateInt64...),
+		validation.Field(&p.CreatedOn, validateInt64...),
+	)
+	if err != nil {
+		return errors.NewValidation("lease profile", err)
+	}
+	return nil
+}
+
+// Profiles is a list of type Profile
+type Profiles []Profile
+
+// defaultExpiresOn returns the expiry of a lease created with the profile,
+// when the lease doesn't request one
+func (p *Profile) defaultExpiresOn(defaultLeaseLengthInDays int) int64 {
+	leaseExpires := time.Now().AddDate(0, 0, defaultLeaseLengthInDays).Unix()
+	if p != nil && p.MaxLeasePeriod != nil {
+		maxLeaseExpires := time.Now().Add(time.Second * time.Duration(*p.MaxLeasePeriod)).Unix()
+		if maxLeaseExpires < leaseExpires {
+			return maxLeaseExpires
+		}
+	}
+	return leaseExpires
+}
@@ -0,0 +1,121 @@
+package lease
+
+import (
+	"time"
+
+	"github.com/Optum/dce/pkg/errors"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/google/uuid"
+)
+
+// Request is a type corresponding to a LeaseRequest table record.
+// A lease request is queued when a lease is requested while there are
+// no Ready accounts, and is fulfilled once an account becomes Ready.
+type Request struct {
+	ID                       *string                `json:"id,omitempty" dynamodbav:"Id" schema:"id,omitempty"`                                            // Lease Request ID
+	PrincipalID              *string                `json:"principalId,omitempty" dynamodbav:"PrincipalId" schema:"principalId,omitempty"`                 // Azure User Principal ID
+	Status                   *RequestStatus         `json:"requestStatus,omitempty" dynamodbav:"RequestStatus,omitempty" schema:"status,omitempty"`        // Status of the Lease Request
+	StatusReason             *string                `json:"requestStatusReason,omitempty" dynamodbav:"RequestStatusReason,omitempty" schema:"-"`           // Reason for the status of the lease request
+	LeaseID                  *string                `json:"leaseId,omitempty" dynamodbav:"LeaseId,omitempty" schema:"-"`                                   // ID of the lease created to fulfill the request
+	AccountID                *string                `json:"accountId,omitempty" dynamodbav:"AccountId,omitempty" schema:"-"`                               // AWS Account ID leased to fulfill the request
+	CreatedOn                *int64                 `json:"createdOn,omitempty" dynamodbav:"CreatedOn,omitempty" schema:"-"`                               // Created Epoch Timestamp
+	LastModifiedOn           *int64                 `json:"lastModifiedOn,omitempty" dynamodbav:"LastModifiedOn,omitempty" schema:"-"`                     // Last Modified Epoch Timestamp
+	BudgetAmount             *float64               `json:"budgetAmount,omitempty" dynamodbav:"BudgetAmount,omitempty" schema:"-"`                         // Budget Amount requested for the lease
+	BudgetCurrency           *string                `json:"budgetCurrency,omitempty" dynamodbav:"BudgetCurrency,omitempty" schema:"-"`                     // Budget currency
+	BudgetNotificationEmails *[]string              `json:"budgetNotificationEmails,omitempty" dynamodbav:"BudgetNotificationEmails,omitempty" schema:"-"` // Budget notification emails
+	ExpiresOn                *int64                 `json:"expiresOn,omitempty" dynamodbav:"ExpiresOn,omitempty" schema:"-"`                               // Requested lease expiration time as Epoch
+	Metadata                 map[string]interface{} `json:"metadata,omitempty"  dynamodbav:"Metadata,omitempty" schema:"-"`
+	PrincipalGroups          []string               `json:"-" dynamodbav:"PrincipalGroups,omitempty" schema:"-"`         // Groups of the principal, used to find their max active leases
+	Profile                  *string                `json:"profile,omitempty" dynamodbav:"Profile,omitempty" schema:"-"` // Name of the lease profile requested
+	Limit                    *int64                 `json:"-" dynamodbav:"-" schema:"limit,omitempty"`
+	NextID                   *string                `json:"-" dynamodbav:"-" schema:"nextId,omitempty"`
+	NextCreatedOn            *int64                 `json:"-" dynamodbav:"-" schema:"nextCreatedOn,omitempty"`
+}
+
+// Validate the lease request data
+func (r *Request) Validate() error {
+	err := validation.ValidateStruct(r,
+		validation.Field(&r.ID, validateID...),
+		validation.Field(&r.PrincipalID, validatePrincipalID...),
+		validation.Field(&r.Status, validation.NotNil.Error("must be a valid lease request status")),
+		validation.Field(&r.LastModifiedOn, validateInt64...),
+		validation.Field(&r.CreatedOn, validateInt64...),
+	)
+	if err != nil {
+		return errors.NewValidation("lease request", err)
+	}
+	return nil
+}
+
+// Lease returns the lease which fulfills the request with the provided account
+func (r *Request) Lease(accountID string) *Lease {
+	return &Lease{
+		AccountID:                &accountID,
+		PrincipalID:              r.PrincipalID,
+		BudgetAmount:             r.BudgetAmount,
+		BudgetCurrency:           r.BudgetCurrency,
+		BudgetNotificationEmails: r.BudgetNotificationEmails,
+		ExpiresOn:                r.ExpiresOn,
+		Metadata:                 r.Metadata,
+		PrincipalGroups:          r.PrincipalGroups,
+		Profile:                  r.Profile,
+	}
+}
+
+// Requests is a list of type Request
+type Requests []Request
+
+// RequestStatus is a lease request status type
+type RequestStatus string
+
+const (
+	// RequestStatusPending means the request is waiting for an account to become available
+	RequestStatusPending RequestStatus = "Pending"
+	// RequestStatusFulfilled means a lease has been created for the request
+	RequestStatusFulfilled RequestStatus = "Fulfilled"
+	// RequestStatusFailed means the request could no longer be fulfilled when an account became available
+	RequestStatusFailed RequestStatus = "Failed"
+)
+
+// String returns the string value of RequestStatus
+func (c RequestStatus) String() string {
+	return string(c)
+}
+
+// RequestStatusPtr returns a pointer to the string value of RequestStatus
+func (c RequestStatus) RequestStatusPtr() *RequestStatus {
+	v := c
+	return &v
+}
+
+// NewRequestInput contains all the data for creating a new lease Request
+type NewRequestInput struct {
+	PrincipalID              string
+	BudgetAmount             *float64
+	BudgetCurrency           *string
+	BudgetNotificationEmails *[]string
+	Metadata                 map[string]interface{}
+	ExpiresOn                *int64
+	PrincipalGroups          []string
+	Profile                  *string
+}
+
+// NewRequest creates a new pending lease request
+func NewRequest(input NewRequestInput) *Request {
+	newID := uuid.New().String()
+	now := time.Now().Unix()
+	return &Request{
+		ID:                       &newID,
+		PrincipalID:              &input.PrincipalID,
+		Status:                   RequestStatusPending.RequestStatusPtr(),
+		BudgetAmount:             input.BudgetAmount,
+		BudgetCurrency:           input.BudgetCurrency,
+		BudgetNotificationEmails: input.BudgetNotificationEmails,
+		Metadata:                 input.Metadata,
+		ExpiresOn:                input.ExpiresOn,
+		PrincipalGroups:          input.PrincipalGroups,
+		Profile:                  input.Profile,
+		CreatedOn:                &now,
+		LastModifiedOn:           &now,
+	}
+}
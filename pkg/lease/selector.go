@@ -0,0 +1,158 @@
+package lease
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/Optum/dce/pkg/account"
+)
+
+// AccountSelector orders the Ready accounts a new lease can be created on.
+// The most preferred account is returned first, and accounts which
+// can't be used for the lease are left out.
+type AccountSelector interface {
+	Select(data *Lease, accounts *account.Accounts) (*account.Accounts, error)
+}
+
+// AccountSelectionStrategy is the name of a built-in AccountSelector
+type AccountSelectionStrategy string
+
+const (
+	// AccountSelectionFirstReady uses the accounts in the order they're listed
+	AccountSelectionFirstReady AccountSelectionStrategy = "first-ready"
+	// AccountSelectionLeastRecentlyLeased prefers the accounts which have gone the longest without a lease
+	AccountSelectionLeastRecentlyLeased AccountSelectionStrategy = "least-recently-leased"
+	// AccountSelectionAffinity prefers the accounts the principal leased most recently
+	AccountSelectionAffinity AccountSelectionStrategy = "affinity"
+	// AccountSelectionRandom shuffles the accounts
+	AccountSelectionRandom AccountSelectionStrategy = "random"
+	// AccountSelectionMetadata only uses the accounts whose metadata matches the lease metadata
+	AccountSelectionMetadata AccountSelectionStrategy = "metadata"
+)
+
+// String returns the string value of AccountSelectionStrategy
+func (c AccountSelectionStrategy) String() string {
+	return string(c)
+}
+
+// NewAccountSelectorInput contains all the data for creating an AccountSelector
+type NewAccountSelectorInput struct {
+	Strategy     AccountSelectionStrategy
+	DataSvc      MultipleReader
+	MetadataKeys []string
+}
+
+// NewAccountSelector creates the AccountSelector for the strategy
+func NewAccountSelector(input NewAccountSelectorInput) (AccountSelector, error) {
+	switch input.Strategy {
+	case "", AccountSelectionFirstReady:
+		return &firstReadySelector{}, nil
+	case AccountSelectionLeastRecentlyLeased:
+		return &leastRecentlyLeasedSelector{}, nil
+	case AccountSelectionAffinity:
+		return &affinitySelector{dataSvc: input.DataSvc}, nil
+	case AccountSelectionRandom:
+		return &randomSelector{}, nil
+	case AccountSelectionMetadata:
+		return &metadataSelector{keys: input.MetadataKeys}, nil
+	}
+	return nil, fmt.Errorf("unknown account selection strategy %q", input.Strategy)
+}
+
+type firstReadySelector struct{}
+
+// Select returns the accounts unchanged
+func (s *firstReadySelector) Select(data *Lease, accounts *account.Accounts) (*account.Accounts, error) {
+	return accounts, nil
+}
+
+type leastRecentlyLeasedSelector struct{}
+
+// Select orders the accounts by when they were last leased, oldest first.
+// Accounts which have never been leased come before all others.
+func (s *leastRecentlyLeasedSelector) Select(data *Lease, accounts *account.Accounts) (*account.Accounts, error) {
+	lastLeasedOn := func(acct account.Account) int64 {
+		if acct.LastLeasedOn == nil {
+			return 0
+		}
+		return *acct.LastLeasedOn
+	}
+
+	selected := append(account.Accounts{}, *accounts...)
+	sort.SliceStable(selected, func(i, j int) bool {
+		return lastLeasedOn(selected[i]) < lastLeasedOn(selected[j])
+	})
+	return &selected, nil
+}
+
+type affinitySelector struct {
+	dataSvc MultipleReader
+}
+
+// Select moves the accounts the principal has leased before to the front,
+// most recently leased first. The other accounts keep their order.
+func (s *affinitySelector) Select(data *Lease, accounts *account.Accounts) (*account.Accounts, error) {
+	lastLeasedOn := map[string]int64{}
+	err := listPages(s.dataSvc, &Lease{
+		PrincipalID: data.PrincipalID,
+	}, func(leases *Leases) bool {
+		for _, l := range *leases {
+			if l.AccountID != nil && l.LastModifiedOn != nil && *l.LastModifiedOn > lastLeasedOn[*l.AccountID] {
+				lastLeasedOn[*l.AccountID] = *l.LastModifiedOn
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	selected := append(account.Accounts{}, *accounts...)
+	sort.SliceStable(selected, func(i, j int) bool {
+		return lastLeasedOn[*selected[i].ID] > lastLeasedOn[*selected[j].ID]
+	})
+	return &selected, nil
+}
+
+type randomSelector struct{}
+
+// Select returns the accounts in a random order
+func (s *randomSelector) Select(data *Lease, accounts *account.Accounts) (*account.Accounts, error) {
+	selected := append(account.Accounts{}, *accounts...)
+	rand.Shuffle(len(selected), func(i, j int) {
+		selected[i], selected[j] = selected[j], selected[i]
+	})
+	return &selected, nil
+}
+
+type metadataSelector struct {
+	keys []string
+}
+
+// Select returns the accounts whose metadata has the same values
+// as the lease metadata, for each of the configured keys the lease sets.
+// A lease which sets none of the keys can use any account.
+func (s *metadataSelector) Select(data *Lease, accounts *account.Accounts) (*account.Accounts, error) {
+	selected := account.Accounts{}
+	for _, acct := range *accounts {
+		if s.matches(data.Metadata, acct.Metadata) {
+			selected = append(selected, acct)
+		}
+	}
+	return &selected, nil
+}
+
+func (s *metadataSelector) matches(leaseMetadata map[string]interface{}, accountMetadata map[string]interface{}) bool {
+	for _, key := range s.keys {
+		want, ok := leaseMetadata[key]
+		if !ok {
+			continue
+		}
+		got, ok := accountMetadata[key]
+		if !ok || fmt.Sprint(got) != fmt.Sprint(want) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,176 @@
+package lease_test
+
+import (
+	"testing"
+
+	"github.com/Optum/dce/pkg/account"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/lease/mocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func accountIDs(accounts *account.Accounts) []string {
+	ids := []string{}
+	for _, acct := range *accounts {
+		ids = append(ids, *acct.ID)
+	}
+	return ids
+}
+
+func TestAccountSelector(t *testing.T) {
+
+	readyAccounts := &account.Accounts{
+		{
+			ID:           ptrString("111111111111"),
+			Metadata:     map[string]interface{}{"pool": "default"},
+			LastLeasedOn: aws.Int64(300),
+		},
+		{
+			ID:           ptrString("222222222222"),
+			Metadata:     map[string]interface{}{"pool": "networking"},
+			LastLeasedOn: aws.Int64(200),
+		},
+		{
+			ID: ptrString("333333333333"),
+		},
+	}
+
+	tests := []struct {
+		name          string
+		strategy      lease.AccountSelectionStrategy
+		lease         *lease.Lease
+		principal     *lease.Leases
+		principalNext *lease.Leases
+		expIDs        []string
+		expErr        bool
+	}{
+		{
+			name:     "should keep the order with first-ready",
+			strategy: lease.AccountSelectionFirstReady,
+			lease:    &lease.Lease{PrincipalID: ptrString("User1")},
+			expIDs:   []string{"111111111111", "222222222222", "333333333333"},
+		},
+		{
+			name:     "should keep the order with no strategy",
+			strategy: "",
+			lease:    &lease.Lease{PrincipalID: ptrString("User1")},
+			expIDs:   []string{"111111111111", "222222222222", "333333333333"},
+		},
+		{
+			name:     "should put never leased accounts first with least-recently-leased",
+			strategy: lease.AccountSelectionLeastRecentlyLeased,
+			lease:    &lease.Lease{PrincipalID: ptrString("User1")},
+			expIDs:   []string{"333333333333", "222222222222", "111111111111"},
+		},
+		{
+			name:     "should put the principal's most recent accounts first with affinity",
+			strategy: lease.AccountSelectionAffinity,
+			lease:    &lease.Lease{PrincipalID: ptrString("User1")},
+			principal: &lease.Leases{
+				{AccountID: ptrString("333333333333"), LastModifiedOn: aws.Int64(300)},
+				{AccountID: ptrString("222222222222"), LastModifiedOn: aws.Int64(100)},
+				{AccountID: ptrString("999999999999"), LastModifiedOn: aws.Int64(500)},
+			},
+			expIDs: []string{"333333333333", "222222222222", "111111111111"},
+		},
+		{
+			name:     "should read all of the principal's leases with affinity",
+			strategy: lease.AccountSelectionAffinity,
+			lease:    &lease.Lease{PrincipalID: ptrString("User1")},
+			principal: &lease.Leases{
+				{AccountID: ptrString("222222222222"), LastModifiedOn: aws.Int64(100)},
+			},
+			principalNext: &lease.Leases{
+				{AccountID: ptrString("333333333333"), LastModifiedOn: aws.Int64(300)},
+			},
+			expIDs: []string{"333333333333", "222222222222", "111111111111"},
+		},
+		{
+			name:     "should only return matching accounts with metadata",
+			strategy: lease.AccountSelectionMetadata,
+			lease: &lease.Lease{
+				PrincipalID: ptrString("User1"),
+				Metadata:    map[string]interface{}{"pool": "networking", "team": "blue"},
+			},
+			expIDs: []string{"222222222222"},
+		},
+		{
+			name:     "should return all accounts with metadata when the lease has no matching keys",
+			strategy: lease.AccountSelectionMetadata,
+			lease: &lease.Lease{
+				PrincipalID: ptrString("User1"),
+				Metadata:    map[string]interface{}{"team": "blue"},
+			},
+			expIDs: []string{"111111111111", "222222222222", "333333333333"},
+		},
+		{
+			name:     "should fail on an unknown strategy",
+			strategy: "fastest",
+			lease:    &lease.Lease{PrincipalID: ptrString("User1")},
+			expErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mocksRead := &mocks.MultipleReader{}
+			if tt.principalNext != nil {
+				mocksRead.On("List", mock.MatchedBy(func(q *lease.Lease) bool {
+					return q.NextPrincipalID == nil
+				})).Run(func(args mock.Arguments) {
+					q := args.Get(0).(*lease.Lease)
+					q.NextAccountID = ptrString("222222222222")
+					q.NextPrincipalID = ptrString("User1")
+				}).Return(tt.principal, nil).Once()
+				mocksRead.On("List", mock.MatchedBy(func(q *lease.Lease) bool {
+					return q.NextPrincipalID != nil
+				})).Run(func(args mock.Arguments) {
+					q := args.Get(0).(*lease.Lease)
+					q.NextAccountID = nil
+					q.NextPrincipalID = nil
+				}).Return(tt.principalNext, nil).Once()
+			} else if tt.principal != nil {
+				mocksRead.On("List", mock.MatchedBy(func(q *lease.Lease) bool {
+					return q.PrincipalID != nil && *q.PrincipalID == "User1"
+				})).Return(tt.principal, nil)
+			}
+
+			selector, err := lease.NewAccountSelector(lease.NewAccountSelectorInput{
+				Strategy:     tt.strategy,
+				DataSvc:      mocksRead,
+				MetadataKeys: []string{"pool"},
+			})
+			if tt.expErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+
+			selected, err := selector.Select(tt.lease, readyAccounts)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expIDs, accountIDs(selected))
+			mocksRead.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRandomAccountSelector(t *testing.T) {
+	readyAccounts := &account.Accounts{
+		{ID: ptrString("111111111111")},
+		{ID: ptrString("222222222222")},
+		{ID: ptrString("333333333333")},
+	}
+
+	selector, err := lease.NewAccountSelector(lease.NewAccountSelectorInput{
+		Strategy: lease.AccountSelectionRandom,
+	})
+	assert.Nil(t, err)
+
+	selected, err := selector.Select(&lease.Lease{}, readyAccounts)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, accountIDs(readyAccounts), accountIDs(selected))
+	// The input isn't reordered in place
+	assert.Equal(t, []string{"111111111111", "222222222222", "333333333333"}, accountIDs(readyAccounts))
+}
@@ -2,9 +2,14 @@ package lease
 
 import (
 	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Optum/dce/pkg/account"
+	"github.com/Optum/dce/pkg/email"
 	"github.com/Optum/dce/pkg/errors"
 	validation "github.com/go-ozzo/ozzo-validation"
 )
@@ -37,6 +42,25 @@ type ReaderWriter interface {
 	Writer
 }
 
+// RequestReaderWriter reads and writes lease requests in the data store
+type RequestReaderWriter interface {
+	Get(requestID string) (*Request, error)
+	List(query *Request) (*Requests, error)
+	Write(input *Request, lastModifiedOn *int64) error
+}
+
+// ProfileReader reads lease profiles from the data store
+type ProfileReader interface {
+	Get(name string) (*Profile, error)
+	List(query *Profile) (*Profiles, error)
+}
+
+// HistoryReaderWriter reads and writes lease history records in the data store
+type HistoryReaderWriter interface {
+	List(leaseID string) (*Histories, error)
+	Write(input *History) error
+}
+
 // Eventer for publishing events
 type Eventer interface {
 	LeaseCreate(account *Lease) error
@@ -50,18 +74,54 @@ type Eventer interface {
 type AccountServicer interface {
 	// EndLease indicates that the provided account is no longer leased.
 	Reset(id string) (*account.Account, error)
+	// Get returns an account from ID
+	Get(ID string) (*account.Account, error)
+	// FreezePrincipalAccess limits the principal to reading and exporting data from the account
+	FreezePrincipalAccess(data *account.Account) error
+}
+
+// TemplateStorager renders the email templates stored in S3
+type TemplateStorager interface {
+	GetTemplateObject(bucket string, key string, input interface{}) (string, string, error)
+}
+
+// CurrencyConverter converts amounts between currencies.
+// An empty currency is the cost currency.
+type CurrencyConverter interface {
+	Convert(amount float64, from string, to string) (float64, error)
 }
 
 // Service is a type corresponding to a Lease table record
 type Service struct {
 	dataSvc                  ReaderWriter
+	requestSvc               RequestReaderWriter
+	profileSvc               ProfileReader
+	historySvc               HistoryReaderWriter
 	eventSvc                 Eventer
 	accountSvc               AccountServicer
+	emailSvc                 email.Service
+	templateSvc              TemplateStorager
+	currencyConverter        CurrencyConverter
 	defaultLeaseLengthInDays int
 	principalBudgetAmount    float64
 	principalBudgetPeriod    string
 	maxLeaseBudgetAmount     float64
 	maxLeasePeriod           int64
+	maxLeaseExtensions       int64
+	accountSelection         AccountSelectionStrategy
+	accountSelectionKeys     []string
+	maxActiveLeases          int64
+	maxActiveLeasesByGroup   map[string]int64
+	autoApproveBudgetAmount  float64
+	autoApproveLeasePeriod   int64
+	approvalTimeout          int64
+	approverEmails           []string
+	approvalFromEmail        string
+	templatesBucket          string
+	approvalTemplateHTMLKey  string
+	approvalTemplateTextKey  string
+	approvalTemplateSubject  string
+	supportedCurrencies      []string
 }
 
 // Weekly
@@ -89,8 +149,14 @@ func (a *Service) GetByAccountIDAndPrincipalID(accountID string, principalID str
 	return new, err
 }
 
-// Save writes the record to the dataSvc
+// Save writes the record to the dataSvc, and records the change in the lease history
 func (a *Service) Save(data *Lease) error {
+	// Look up the stored lease, so the history shows what changed
+	var prev *Lease
+	if a.historySvc != nil && data.LastModifiedOn != nil && data.ID != nil {
+		prev, _ = a.dataSvc.Get(*data.ID)
+	}
+
 	var lastModifiedOn *int64
 	now := time.Now().Unix()
 	if data.LastModifiedOn == nil {
@@ -112,11 +178,13 @@ func (a *Service) Save(data *Lease) error {
 	if err != nil {
 		return err
 	}
+	a.recordHistory(prev, data)
 	return nil
 }
 
-// Delete finds a given lease and checks if it's active and then updates it to status `Inactive`. Returns the lease.
-func (a *Service) Delete(ID string) (*Lease, error) {
+// Delete finds a given lease and checks if it's active or frozen and then updates it to status `Inactive`.
+// actor is the user ending the lease, recorded in the lease history. Returns the lease.
+func (a *Service) Delete(ID string, actor string) (*Lease, error) {
 
 	data, err := a.dataSvc.Get(ID)
 	if err != nil {
@@ -124,19 +192,22 @@ func (a *Service) Delete(ID string) (*Lease, error) {
 	}
 
 	err = validation.ValidateStruct(data,
-		validation.Field(&data.Status, validation.NotNil, validation.By(isLeaseActive)),
+		validation.Field(&data.Status, validation.NotNil, validation.By(isLeaseActiveOrFrozen)),
 		validation.Field(&data.AccountID, validateAccountID...),
 	)
 	if err != nil {
 		return nil, errors.NewConflict("lease", *data.ID, err)
 	}
 
+	prev := *data
 	data.Status = StatusInactive.StatusPtr()
 	data.StatusReason = StatusReasonDestroyed.StatusReasonPtr()
+	data.Actor = &actor
 	err = a.dataSvc.Write(data, data.LastModifiedOn)
 	if err != nil {
 		return nil, err
 	}
+	a.recordHistory(&prev, data)
 
 	_, err = a.accountSvc.Reset(*data.AccountID)
 	if err != nil {
@@ -151,6 +222,155 @@ func (a *Service) Delete(ID string) (*Lease, error) {
 	return data, nil
 }
 
+// Update extends an active lease by moving its expiresOn date and/or raising its budget amount.
+// Returns the updated lease.
+func (a *Service) Update(ID string, data *Lease, principalSpentAmount float64) (*Lease, error) {
+
+	// Only the expiresOn and budgetAmount fields may be updated
+	err := validation.ValidateStruct(data,
+		validation.Field(&data.ID, validation.NilOrNotEmpty, validation.In(ID)),
+		validation.Field(&data.AccountID, validation.By(isNil)),
+		validation.Field(&data.PrincipalID, validation.By(isNil)),
+		validation.Field(&data.Status, validation.By(isNil)),
+		validation.Field(&data.StatusReason, validation.By(isNil)),
+		validation.Field(&data.CreatedOn, validation.By(isNil)),
+		validation.Field(&data.LastModifiedOn, validation.By(isNil)),
+		validation.Field(&data.StatusModifiedOn, validation.By(isNil)),
+		validation.Field(&data.ExtensionCount, validation.By(isNil)),
+		validation.Field(&data.BudgetCurrency, validation.By(isNil)),
+		validation.Field(&data.BudgetNotificationEmails, validation.By(isNil)),
+		validation.Field(&data.Profile, validation.By(isNil)),
+		validation.Field(&data.ReviewedBy, validation.By(isNil)),
+		validation.Field(&data.ReviewComment, validation.By(isNil)),
+		validation.Field(&data.FrozenUntil, validation.By(isNil)),
+		validation.Field(&data.IdleWarnedOn, validation.By(isNil)),
+		validation.Field(&data.ExpiryNotificationsSent, validation.By(isNil)),
+		validation.Field(&data.BudgetNotificationsSent, validation.By(isNil)),
+		validation.Field(&data.ForecastSpend, validation.By(isNil)),
+		validation.Field(&data.ForecastWarnedOn, validation.By(isNil)),
+	)
+	if err != nil {
+		return nil, errors.NewValidation("lease", err)
+	}
+	if data.ExpiresOn == nil && data.BudgetAmount == nil {
+		return nil, errors.NewBadRequest("invalid request parameters: expiresOn or budgetAmount must be provided")
+	}
+
+	existing, err := a.dataSvc.Get(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validation.ValidateStruct(existing,
+		validation.Field(&existing.Status, validation.NotNil, validation.By(isLeaseActive)),
+	)
+	if err != nil {
+		return nil, errors.NewConflict("lease", ID, err)
+	}
+
+	extensionCount := int64(0)
+	if existing.ExtensionCount != nil {
+		extensionCount = *existing.ExtensionCount
+	}
+	if extensionCount >= a.maxLeaseExtensions {
+		return nil, errors.NewConflict("lease", ID,
+			fmt.Errorf("lease has already been extended %d times, which is the max lease extensions of %d", extensionCount, a.maxLeaseExtensions))
+	}
+
+	profile, err := a.getProfile(existing.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only a budget increase needs the budgets of the principal's other active leases
+	committedBudgetAmount := 0.0
+	if data.BudgetAmount != nil {
+		activeLeases, err := a.listActiveLeases(*existing.PrincipalID)
+		if err != nil {
+			return nil, err
+		}
+		committedBudgetAmount, err = activeLeases.committedBudgetAmount(existing.ID, a.toCostCurrency)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = validation.ValidateStruct(data,
+		validation.Field(&data.ExpiresOn, validation.By(isExpiresOnValid(a)), validation.By(isExpiresOnWithinProfile(profile)), validation.By(isExpiresOnExtended(existing.ExpiresOn))),
+		validation.Field(&data.BudgetAmount, validation.By(isBudgetAmountValid(a, *existing.PrincipalID, existing.BudgetCurrency, principalSpentAmount, committedBudgetAmount)), validation.By(isBudgetAmountWithinProfile(a, profile, existing.BudgetCurrency)), validation.By(isBudgetAmountIncreased(existing.BudgetAmount))),
+	)
+	if err != nil {
+		return nil, errors.NewValidation("lease", err)
+	}
+
+	updated := *existing
+	if data.ExpiresOn != nil {
+		updated.ExpiresOn = data.ExpiresOn
+		// The principal should be notified again as the new expiresOn date nears
+		updated.ExpiryNotificationsSent = nil
+		// The forecast was for the old expiresOn date
+		updated.ForecastSpend = nil
+		updated.ForecastWarnedOn = nil
+	}
+	if data.BudgetAmount != nil {
+		updated.BudgetAmount = data.BudgetAmount
+		// The thresholds notified were percentiles of the old budget
+		updated.BudgetNotificationsSent = nil
+		updated.ForecastWarnedOn = nil
+	}
+	extensionCount++
+	updated.ExtensionCount = &extensionCount
+	updated.Actor = data.Actor
+
+	// Don't use Save here, the status (and with it the budget period) isn't changing
+	now := time.Now().Unix()
+	updated.LastModifiedOn = &now
+	err = updated.Validate()
+	if err != nil {
+		return nil, err
+	}
+	err = a.dataSvc.Write(&updated, existing.LastModifiedOn)
+	if err != nil {
+		return nil, err
+	}
+	a.recordHistory(existing, &updated)
+
+	err = a.eventSvc.LeaseUpdate(existing, &updated)
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// writeActiveLease applies the update to an active lease, and writes it
+// without touching its status. Used for bookkeeping done on behalf of the system.
+func (a *Service) writeActiveLease(ID string, update func(*Lease)) (*Lease, error) {
+	existing, err := a.dataSvc.Get(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validation.ValidateStruct(existing,
+		validation.Field(&existing.Status, validation.NotNil, validation.By(isLeaseActive)),
+	)
+	if err != nil {
+		return nil, errors.NewConflict("lease", ID, err)
+	}
+
+	// Don't use Save here, the status (and with it the budget period) isn't changing
+	updated := *existing
+	update(&updated)
+	now := time.Now().Unix()
+	updated.LastModifiedOn = &now
+	err = a.dataSvc.Write(&updated, existing.LastModifiedOn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
 // List Get a list of leases based on Principal ID
 func (a *Service) List(query *Lease) (*Leases, error) {
 	err := validation.ValidateStruct(query,
@@ -169,12 +389,18 @@ func (a *Service) List(query *Lease) (*Leases, error) {
 	return leases, nil
 }
 
-// Create creates a new lease using the data provided. Returns the lease record
+// Create creates a new lease using the data provided. Returns the lease record.
+// If the lease was saved but a later step failed, the saved lease is returned along with the error.
 func (a *Service) Create(data *Lease, principalSpentAmount float64) (*Lease, error) {
 
+	profile, err := a.getProfile(data.Profile)
+	if err != nil {
+		return nil, err
+	}
+
 	// Set default expiresOn
 	if data.ExpiresOn == nil {
-		leaseExpires := time.Now().AddDate(0, 0, a.defaultLeaseLengthInDays).Unix()
+		leaseExpires := profile.defaultExpiresOn(a.defaultLeaseLengthInDays)
 		data.ExpiresOn = &leaseExpires
 	}
 
@@ -186,11 +412,17 @@ func (a *Service) Create(data *Lease, principalSpentAmount float64) (*Lease, err
 	// Set default budget amount
 	if data.BudgetAmount == nil {
 		data.BudgetAmount = &a.maxLeaseBudgetAmount
+		if profile != nil && profile.DefaultBudgetAmount != nil {
+			data.BudgetAmount = profile.DefaultBudgetAmount
+		}
 	}
 
 	// Set default budget currency
 	if data.BudgetCurrency == nil {
 		currency := ""
+		if len(a.supportedCurrencies) > 0 {
+			currency = a.supportedCurrencies[0]
+		}
 		data.BudgetCurrency = &currency
 	}
 
@@ -201,38 +433,48 @@ func (a *Service) Create(data *Lease, principalSpentAmount float64) (*Lease, err
 	}
 
 	// Validate the incoming record doesn't have unneeded fields
-	err := validation.ValidateStruct(data,
+	err = validation.ValidateStruct(data,
 		validation.Field(&data.AccountID, validateAccountID...),
 		validation.Field(&data.PrincipalID, validatePrincipalID...),
 		validation.Field(&data.ID, validation.By(isNil)),
 		validation.Field(&data.Status, validation.By(isNil)),
 		validation.Field(&data.StatusReason, validation.By(isNil)),
-		validation.Field(&data.ExpiresOn, validation.NotNil, validation.By(isExpiresOnValid(a))),
+		validation.Field(&data.ReviewedBy, validation.By(isNil)),
+		validation.Field(&data.ReviewComment, validation.By(isNil)),
+		validation.Field(&data.FrozenUntil, validation.By(isNil)),
+		validation.Field(&data.IdleWarnedOn, validation.By(isNil)),
+		validation.Field(&data.ExpiryNotificationsSent, validation.By(isNil)),
+		validation.Field(&data.BudgetNotificationsSent, validation.By(isNil)),
+		validation.Field(&data.ForecastSpend, validation.By(isNil)),
+		validation.Field(&data.ForecastWarnedOn, validation.By(isNil)),
+		validation.Field(&data.BudgetCurrency, validation.By(isBudgetCurrencySupported(a.supportedCurrencies))),
+		validation.Field(&data.ExpiresOn, validation.NotNil, validation.By(isExpiresOnValid(a)), validation.By(isExpiresOnWithinProfile(profile))),
 	)
 	if err != nil {
 		return nil, errors.NewValidation("lease", err)
 	}
 
-	err = validation.ValidateStruct(data,
-		validation.Field(&data.BudgetAmount, validation.By(isBudgetAmountValid(a, *data.PrincipalID, principalSpentAmount))),
-	)
+	activeLeases, err := a.listActiveLeases(*data.PrincipalID)
 	if err != nil {
-		return nil, errors.NewValidation("lease", err)
+		return nil, err
 	}
 
-	// Check if principal already has an active lease
-	query := &Lease{
-		PrincipalID: data.PrincipalID,
-		Status:      StatusActive.StatusPtr(),
+	committedBudgetAmount, err := activeLeases.committedBudgetAmount(nil, a.toCostCurrency)
+	if err != nil {
+		return nil, err
 	}
 
-	existingLeases, err := a.List(query)
+	err = validation.ValidateStruct(data,
+		validation.Field(&data.BudgetAmount, validation.By(isBudgetAmountValid(a, *data.PrincipalID, data.BudgetCurrency, principalSpentAmount, committedBudgetAmount)), validation.By(isBudgetAmountWithinProfile(a, profile, data.BudgetCurrency))),
+	)
 	if err != nil {
-		return nil, errors.NewInternalServer("lease", err)
+		return nil, errors.NewValidation("lease", err)
 	}
-	if existingLeases != nil && len(*existingLeases) > 0 {
-		message := fmt.Sprintf("with principal %s and account %s", *data.PrincipalID, *data.AccountID)
-		return nil, errors.NewAlreadyExists("lease", message)
+
+	// Check if principal already has the max number of active leases
+	err = a.checkMaxActiveLeases(*data.PrincipalID, data.PrincipalGroups, activeLeases)
+	if err != nil {
+		return nil, err
 	}
 
 	newLeaseRecord := NewLease(NewLeaseInput{
@@ -242,7 +484,9 @@ func (a *Service) Create(data *Lease, principalSpentAmount float64) (*Lease, err
 		BudgetCurrency:           *data.BudgetCurrency,
 		BudgetNotificationEmails: *data.BudgetNotificationEmails,
 		ExpiresOn:                *data.ExpiresOn,
+		Profile:                  data.Profile,
 	})
+	newLeaseRecord.Actor = data.Actor
 
 	if data.LastModifiedOn != nil {
 		newLeaseRecord.LastModifiedOn = data.LastModifiedOn
@@ -254,6 +498,26 @@ func (a *Service) Create(data *Lease, principalSpentAmount float64) (*Lease, err
 		return nil, err
 	}
 
+	// Large leases wait for an admin to approve them, holding on to their account
+	requiresApproval, err := a.requiresApproval(newLeaseRecord)
+	if err != nil {
+		return nil, err
+	}
+	if requiresApproval {
+		newLeaseRecord.Status = StatusPending.StatusPtr()
+		newLeaseRecord.StatusReason = StatusReasonPendingApproval.StatusReasonPtr()
+		err = a.Save(newLeaseRecord)
+		if err != nil {
+			return nil, err
+		}
+		err = a.notifyApprovers(newLeaseRecord)
+		if err != nil {
+			// The lease is already created, so don't fail on a notification error
+			log.Printf("Failed to notify approvers of lease %s: %s", *newLeaseRecord.ID, err)
+		}
+		return newLeaseRecord, nil
+	}
+
 	err = a.Save(newLeaseRecord)
 	if err != nil {
 		return nil, err
@@ -261,24 +525,291 @@ func (a *Service) Create(data *Lease, principalSpentAmount float64) (*Lease, err
 
 	err = a.eventSvc.LeaseCreate(newLeaseRecord)
 	if err != nil {
-		return nil, err
+		return newLeaseRecord, err
 	}
 
 	return newLeaseRecord, nil
 }
 
+// CreateRequest queues a request for a lease, to be fulfilled once an account
+// becomes available. Returns the pending lease request.
+func (a *Service) CreateRequest(data *Lease, principalSpentAmount float64) (*Request, error) {
+
+	profile, err := a.getProfile(data.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate the incoming record doesn't have unneeded fields
+	err = validation.ValidateStruct(data,
+		validation.Field(&data.PrincipalID, validatePrincipalID...),
+		validation.Field(&data.AccountID, validation.By(isNil)),
+		validation.Field(&data.ID, validation.By(isNil)),
+		validation.Field(&data.Status, validation.By(isNil)),
+		validation.Field(&data.StatusReason, validation.By(isNil)),
+		validation.Field(&data.BudgetCurrency, validation.By(isBudgetCurrencySupported(a.supportedCurrencies))),
+		validation.Field(&data.ExpiresOn, validation.By(isExpiresOnValid(a)), validation.By(isExpiresOnWithinProfile(profile))),
+	)
+	if err != nil {
+		return nil, errors.NewValidation("lease", err)
+	}
+
+	activeLeases, err := a.listActiveLeases(*data.PrincipalID)
+	if err != nil {
+		return nil, err
+	}
+
+	committedBudgetAmount, err := activeLeases.committedBudgetAmount(nil, a.toCostCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validation.ValidateStruct(data,
+		validation.Field(&data.BudgetAmount, validation.By(isBudgetAmountValid(a, *data.PrincipalID, data.BudgetCurrency, principalSpentAmount, committedBudgetAmount)), validation.By(isBudgetAmountWithinProfile(a, profile, data.BudgetCurrency))),
+	)
+	if err != nil {
+		return nil, errors.NewValidation("lease", err)
+	}
+
+	// Check if principal already has the max number of active leases
+	err = a.checkMaxActiveLeases(*data.PrincipalID, data.PrincipalGroups, activeLeases)
+	if err != nil {
+		return nil, err
+	}
+
+	request := NewRequest(NewRequestInput{
+		PrincipalID:              *data.PrincipalID,
+		BudgetAmount:             data.BudgetAmount,
+		BudgetCurrency:           data.BudgetCurrency,
+		BudgetNotificationEmails: data.BudgetNotificationEmails,
+		Metadata:                 data.Metadata,
+		ExpiresOn:                data.ExpiresOn,
+		PrincipalGroups:          data.PrincipalGroups,
+		Profile:                  data.Profile,
+	})
+
+	err = request.Validate()
+	if err != nil {
+		return nil, err
+	}
+	err = a.requestSvc.Write(request, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// GetProfile returns a lease profile from its name
+func (a *Service) GetProfile(name string) (*Profile, error) {
+	return a.profileSvc.Get(name)
+}
+
+// ListProfiles Get a list of lease profiles
+func (a *Service) ListProfiles(query *Profile) (*Profiles, error) {
+	return a.profileSvc.List(query)
+}
+
+// getProfile returns the named lease profile, or nil when no profile was requested
+func (a *Service) getProfile(name *string) (*Profile, error) {
+	if name == nil {
+		return nil, nil
+	}
+	profile, err := a.profileSvc.Get(*name)
+	if err != nil {
+		if errors.HTTPCodeForError(err) == http.StatusNotFound {
+			return nil, errors.NewValidation("lease", validation.Errors{
+				"profile": fmt.Errorf("lease profile %q does not exist", *name),
+			})
+		}
+		return nil, err
+	}
+	return profile, nil
+}
+
+// GetRequest returns a lease request from ID
+func (a *Service) GetRequest(ID string) (*Request, error) {
+	return a.requestSvc.Get(ID)
+}
+
+// ListRequests Get a list of lease requests
+func (a *Service) ListRequests(query *Request) (*Requests, error) {
+	err := validation.ValidateStruct(query,
+		validation.Field(&query.ID, validation.By(isNil)),
+	)
+	if err != nil {
+		return nil, errors.NewValidation("lease request", err)
+	}
+
+	return a.requestSvc.List(query)
+}
+
+// FulfillRequest leases the account to the oldest pending lease request
+// the account can be used for, as chosen by the account selection strategy.
+// Requests which can no longer be fulfilled (eg. their expiresOn has passed)
+// are marked Failed and skipped. Returns a nil lease if no request was fulfilled,
+// and the created lease along with the error if a step after creating it failed.
+func (a *Service) FulfillRequest(accountID string, principalSpend func(principalID string) (float64, error)) (*Lease, *Request, error) {
+
+	acct, err := a.accountSvc.Get(accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	selector, err := a.accountSelector()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := &Request{
+		Status: RequestStatusPending.RequestStatusPtr(),
+	}
+	for {
+		requests, err := a.requestSvc.List(query)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for i := range *requests {
+			newLease, request, err := a.fulfillRequest((*requests)[i], acct, selector, principalSpend)
+			if err != nil || newLease != nil {
+				return newLease, request, err
+			}
+		}
+
+		if query.NextID == nil {
+			return nil, nil, nil
+		}
+	}
+}
+
+// fulfillRequest leases the account to the pending lease request.
+// Returns a nil lease if the request can't be fulfilled with the account.
+func (a *Service) fulfillRequest(request Request, acct *account.Account, selector AccountSelector, principalSpend func(principalID string) (float64, error)) (*Lease, *Request, error) {
+	accountID := *acct.ID
+
+	// Skip requests the account can't be used for, eg. requests for another pool
+	selected, err := selector.Select(request.Lease(accountID), &account.Accounts{*acct})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(*selected) == 0 {
+		return nil, nil, nil
+	}
+
+	spent, err := principalSpend(*request.PrincipalID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The principal can't be leased new accounts until their next budget period
+	if spent > a.principalBudgetAmount {
+		log.Printf("Failed lease request %s: principal %s has already spent %.2f of their %.2f principal budget",
+			*request.ID, *request.PrincipalID, spent, a.principalBudgetAmount)
+		reason := string(StatusReasonPrincipalBudgetExhausted)
+		request.Status = RequestStatusFailed.RequestStatusPtr()
+		request.StatusReason = &reason
+		err = a.saveRequest(&request)
+		if err != nil && errors.HTTPCodeForError(err) != http.StatusConflict {
+			return nil, nil, err
+		}
+		return nil, nil, nil
+	}
+
+	// Claim the request first, so it can't be fulfilled twice
+	request.Status = RequestStatusFulfilled.RequestStatusPtr()
+	request.AccountID = &accountID
+	err = a.saveRequest(&request)
+	if err != nil {
+		if errors.HTTPCodeForError(err) == http.StatusConflict {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	newLease := request.Lease(accountID)
+	// Re-use the inactive lease record for this principal and account, if there is one
+	existing, err := a.dataSvc.GetByAccountIDAndPrincipalID(accountID, *request.PrincipalID)
+	if err != nil && errors.HTTPCodeForError(err) != http.StatusNotFound {
+		return nil, nil, a.releaseRequest(&request, err)
+	}
+	if existing != nil {
+		newLease.LastModifiedOn = existing.LastModifiedOn
+		newLease.CreatedOn = existing.CreatedOn
+	}
+
+	leaseCreated, err := a.Create(newLease, spent)
+	if err != nil && leaseCreated != nil {
+		// The lease exists, so the request stays fulfilled by it
+		request.LeaseID = leaseCreated.ID
+		saveErr := a.saveRequest(&request)
+		if saveErr != nil {
+			log.Printf("Failed to save lease %s on lease request %s: %s", *leaseCreated.ID, *request.ID, saveErr)
+		}
+		return leaseCreated, &request, err
+	}
+	if err != nil {
+		if errors.HTTPCodeForError(err) >= http.StatusInternalServerError {
+			return nil, nil, a.releaseRequest(&request, err)
+		}
+		// The request is no longer valid, fail it and move on to the next one
+		log.Printf("Failed lease request %s: %s", *request.ID, err)
+		reason := err.Error()
+		request.Status = RequestStatusFailed.RequestStatusPtr()
+		request.StatusReason = &reason
+		request.AccountID = nil
+		err = a.saveRequest(&request)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, nil
+	}
+
+	request.LeaseID = leaseCreated.ID
+	err = a.saveRequest(&request)
+	if err != nil {
+		return leaseCreated, &request, err
+	}
+
+	return leaseCreated, &request, nil
+}
+
+// releaseRequest puts a claimed lease request back in the Pending status
+// and returns the error which prevented it from being fulfilled
+func (a *Service) releaseRequest(request *Request, cause error) error {
+	request.Status = RequestStatusPending.RequestStatusPtr()
+	request.AccountID = nil
+	err := a.saveRequest(request)
+	if err != nil {
+		log.Printf("Failed to release lease request %s: %s", *request.ID, err)
+	}
+	return cause
+}
+
+// saveRequest writes the lease request, failing if it was modified since it was read
+func (a *Service) saveRequest(request *Request) error {
+	prevLastModifiedOn := request.LastModifiedOn
+	now := time.Now().Unix()
+	request.LastModifiedOn = &now
+	return a.requestSvc.Write(request, prevLastModifiedOn)
+}
+
 // ListPages runs a function on each page in a list
 func (a *Service) ListPages(query *Lease, fn func(*Leases) bool) error {
+	return listPages(a.dataSvc, query, fn)
+}
+
+// listPages runs a function on each page of leases read from the data store
+func listPages(dataSvc MultipleReader, query *Lease, fn func(*Leases) bool) error {
 
 	for {
-		records, err := a.dataSvc.List(query)
+		records, err := dataSvc.List(query)
 		if err != nil {
 			return err
 		}
 		if !fn(records) {
 			break
 		}
-		if query.PrincipalID == nil {
+		if query.NextPrincipalID == nil {
 			break
 		}
 	}
@@ -286,28 +817,176 @@ func (a *Service) ListPages(query *Lease, fn func(*Leases) bool) error {
 	return nil
 }
 
+// MaxActiveLeases returns the max number of active leases for a principal in the groups.
+// The most generous group override wins, otherwise the default applies.
+func (a *Service) MaxActiveLeases(groups []string) int64 {
+	maxActiveLeases := int64(0)
+	for _, group := range groups {
+		if groupMax, ok := a.maxActiveLeasesByGroup[group]; ok && groupMax > maxActiveLeases {
+			maxActiveLeases = groupMax
+		}
+	}
+	if maxActiveLeases > 0 {
+		return maxActiveLeases
+	}
+	if a.maxActiveLeases > 0 {
+		return a.maxActiveLeases
+	}
+	return 1
+}
+
+// toCostCurrency converts a lease budget amount from its budget currency to the cost currency,
+// which the max lease budget, principal budget and profile budgets are in
+func (a *Service) toCostCurrency(amount float64, budgetCurrency *string) (float64, error) {
+	if a.currencyConverter == nil || budgetCurrency == nil {
+		return amount, nil
+	}
+	return a.currencyConverter.Convert(amount, *budgetCurrency, "")
+}
+
+// listActiveLeases returns all of the principal's active leases,
+// including the leases which are pending approval or frozen
+func (a *Service) listActiveLeases(principalID string) (*Leases, error) {
+	activeLeases := Leases{}
+	for _, status := range []Status{StatusActive, StatusPending, StatusFrozen} {
+		err := a.ListPages(&Lease{
+			PrincipalID: &principalID,
+			Status:      status.StatusPtr(),
+		}, func(leases *Leases) bool {
+			if leases != nil {
+				activeLeases = append(activeLeases, *leases...)
+			}
+			return true
+		})
+		if err != nil {
+			return nil, errors.NewInternalServer("lease", err)
+		}
+	}
+	return &activeLeases, nil
+}
+
+// checkMaxActiveLeases returns a conflict error if the principal
+// can't have any more active leases
+func (a *Service) checkMaxActiveLeases(principalID string, groups []string, activeLeases *Leases) error {
+	maxActiveLeases := a.MaxActiveLeases(groups)
+	if int64(len(*activeLeases)) >= maxActiveLeases {
+		return errors.NewConflict(
+			"lease",
+			principalID,
+			fmt.Errorf("principal already has %d active leases, which is the max active leases of %d", len(*activeLeases), maxActiveLeases),
+		)
+	}
+	return nil
+}
+
+// SelectAccounts orders the Ready accounts for a new lease,
+// using the configured account selection strategy
+func (a *Service) SelectAccounts(data *Lease, accounts *account.Accounts) (*account.Accounts, error) {
+	selector, err := a.accountSelector()
+	if err != nil {
+		return nil, err
+	}
+	return selector.Select(data, accounts)
+}
+
+// accountSelector returns the AccountSelector for the configured account selection strategy
+func (a *Service) accountSelector() (AccountSelector, error) {
+	selector, err := NewAccountSelector(NewAccountSelectorInput{
+		Strategy:     a.accountSelection,
+		DataSvc:      a.dataSvc,
+		MetadataKeys: a.accountSelectionKeys,
+	})
+	if err != nil {
+		return nil, errors.NewInternalServer("invalid account selection configuration", err)
+	}
+	return selector, nil
+}
+
 // NewServiceInput Input for creating a new Service
 type NewServiceInput struct {
 	DataSvc                  ReaderWriter
+	RequestSvc               RequestReaderWriter
+	ProfileSvc               ProfileReader
+	HistorySvc               HistoryReaderWriter
 	EventSvc                 Eventer
 	AccountSvc               AccountServicer
-	DefaultLeaseLengthInDays int     `env:"DEFAULT_LEASE_LENGTH_IN_DAYS" envDefault:"7"`
-	PrincipalBudgetAmount    float64 `env:"PRINCIPAL_BUDGET_AMOUNT" envDefault:"1000.00"`
-	PrincipalBudgetPeriod    string  `env:"PRINCIPAL_BUDGET_PERIOD" envDefault:"Weekly"`
-	MaxLeaseBudgetAmount     float64 `env:"MAX_LEASE_BUDGET_AMOUNT" envDefault:"1000.00"`
-	MaxLeasePeriod           int64   `env:"MAX_LEASE_PERIOD" envDefault:"704800"`
+	EmailSvc                 email.Service
+	TemplateSvc              TemplateStorager
+	CurrencyConverter        CurrencyConverter
+	DefaultLeaseLengthInDays int      `env:"DEFAULT_LEASE_LENGTH_IN_DAYS" envDefault:"7"`
+	PrincipalBudgetAmount    float64  `env:"PRINCIPAL_BUDGET_AMOUNT" envDefault:"1000.00"`
+	PrincipalBudgetPeriod    string   `env:"PRINCIPAL_BUDGET_PERIOD" envDefault:"Weekly"`
+	MaxLeaseBudgetAmount     float64  `env:"MAX_LEASE_BUDGET_AMOUNT" envDefault:"1000.00"`
+	MaxLeasePeriod           int64    `env:"MAX_LEASE_PERIOD" envDefault:"704800"`
+	MaxLeaseExtensions       int64    `env:"MAX_LEASE_EXTENSIONS" envDefault:"3"`
+	AccountSelectionStrategy string   `env:"ACCOUNT_SELECTION_STRATEGY" envDefault:"first-ready"`
+	AccountSelectionKeys     []string `env:"ACCOUNT_SELECTION_METADATA_KEYS" envDefault:"pool"`
+	MaxActiveLeases          int64    `env:"MAX_ACTIVE_LEASES" envDefault:"1"`
+	MaxActiveLeasesByGroup   []string `env:"MAX_ACTIVE_LEASES_BY_GROUP"`                 // Overrides for MaxActiveLeases, as "group:max" entries
+	AutoApproveBudgetAmount  float64  `env:"AUTO_APPROVE_BUDGET_AMOUNT" envDefault:"0"`  // Leases with a larger budget need approval, 0 to disable
+	AutoApproveLeasePeriod   int64    `env:"AUTO_APPROVE_LEASE_PERIOD" envDefault:"0"`   // Leases longer than this (seconds) need approval, 0 to disable
+	ApprovalTimeout          int64    `env:"LEASE_APPROVAL_TIMEOUT" envDefault:"259200"` // Seconds until a lease nobody approved expires
+	ApproverEmails           []string `env:"LEASE_APPROVER_EMAILS"`                      // Addresses notified of leases waiting for approval
+	ApprovalFromEmail        string   `env:"LEASE_APPROVAL_FROM_EMAIL"`                  // Sender of the lease approval emails, defaults to the budget notification sender
+	BudgetNotificationFrom   string   `env:"BUDGET_NOTIFICATION_FROM_EMAIL"`
+	TemplatesBucket          string   `env:"BUDGET_NOTIFICATION_TEMPLATES_BUCKET"` // Bucket of the lease approval templates
+	ApprovalTemplateHTMLKey  string   `env:"LEASE_APPROVAL_TEMPLATE_HTML_KEY"`
+	ApprovalTemplateTextKey  string   `env:"LEASE_APPROVAL_TEMPLATE_TEXT_KEY"`
+	ApprovalTemplateSubject  string   `env:"LEASE_APPROVAL_TEMPLATE_SUBJECT" envDefault:"Lease approval requested by {{.Lease.PrincipalID}}"`
+	SupportedCurrencies      []string `env:"SUPPORTED_CURRENCIES" envDefault:"USD"` // Budget currencies leases may use. The first one is the default.
 }
 
 // NewService creates a new instance of the Service
 func NewService(input NewServiceInput) *Service {
+	maxActiveLeasesByGroup := map[string]int64{}
+	for _, override := range input.MaxActiveLeasesByGroup {
+		parts := strings.SplitN(override, ":", 2)
+		if len(parts) != 2 {
+			log.Printf("Ignoring max active leases override %q, expected \"group:max\"", override)
+			continue
+		}
+		groupMax, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+		if err != nil {
+			log.Printf("Ignoring max active leases override %q: %s", override, err)
+			continue
+		}
+		maxActiveLeasesByGroup[strings.TrimSpace(parts[0])] = groupMax
+	}
+
+	approvalFromEmail := input.ApprovalFromEmail
+	if approvalFromEmail == "" {
+		approvalFromEmail = input.BudgetNotificationFrom
+	}
+
 	return &Service{
 		dataSvc:                  input.DataSvc,
+		requestSvc:               input.RequestSvc,
+		profileSvc:               input.ProfileSvc,
+		historySvc:               input.HistorySvc,
 		eventSvc:                 input.EventSvc,
 		accountSvc:               input.AccountSvc,
+		emailSvc:                 input.EmailSvc,
+		templateSvc:              input.TemplateSvc,
+		currencyConverter:        input.CurrencyConverter,
 		defaultLeaseLengthInDays: input.DefaultLeaseLengthInDays,
 		principalBudgetAmount:    input.PrincipalBudgetAmount,
 		principalBudgetPeriod:    input.PrincipalBudgetPeriod,
 		maxLeaseBudgetAmount:     input.MaxLeaseBudgetAmount,
 		maxLeasePeriod:           input.MaxLeasePeriod,
+		maxLeaseExtensions:       input.MaxLeaseExtensions,
+		accountSelection:         AccountSelectionStrategy(input.AccountSelectionStrategy),
+		accountSelectionKeys:     input.AccountSelectionKeys,
+		maxActiveLeases:          input.MaxActiveLeases,
+		maxActiveLeasesByGroup:   maxActiveLeasesByGroup,
+		autoApproveBudgetAmount:  input.AutoApproveBudgetAmount,
+		autoApproveLeasePeriod:   input.AutoApproveLeasePeriod,
+		approvalTimeout:          input.ApprovalTimeout,
+		approverEmails:           input.ApproverEmails,
+		approvalFromEmail:        approvalFromEmail,
+		templatesBucket:          input.TemplatesBucket,
+		approvalTemplateHTMLKey:  input.ApprovalTemplateHTMLKey,
+		approvalTemplateTextKey:  input.ApprovalTemplateTextKey,
+		approvalTemplateSubject:  input.ApprovalTemplateSubject,
+		supportedCurrencies:      input.SupportedCurrencies,
 	}
 }
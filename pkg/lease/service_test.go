@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Optum/dce/pkg/account"
+	"github.com/Optum/dce/pkg/currency"
 	"github.com/Optum/dce/pkg/errors"
 	"github.com/Optum/dce/pkg/lease"
 	"github.com/Optum/dce/pkg/lease/mocks"
@@ -176,6 +178,17 @@ func TestDelete(t *testing.T) {
 			},
 			returnErr: nil,
 		},
+		{
+			name: "should delete a frozen lease",
+			ID:   "70c2d96d-7938-4ec9-917d-476f2b09cc04",
+			expLease: &lease.Lease{
+				ID:           ptrString("70c2d96d-7938-4ec9-917d-476f2b09cc04"),
+				AccountID:    ptrString("123456789012"),
+				Status:       lease.StatusFrozen.StatusPtr(),
+				StatusReason: lease.StatusReasonOverBudget.StatusReasonPtr(),
+			},
+			returnErr: nil,
+		},
 		{
 			name:      "should error when delete fails",
 			ID:        "70c2d96d-7938-4ec9-917d-476f2b09cc04",
@@ -204,7 +217,7 @@ func TestDelete(t *testing.T) {
 					AccountSvc: mocksAccountSvc,
 				},
 			)
-			actualLease, err := leaseSvc.Delete(tt.ID)
+			actualLease, err := leaseSvc.Delete(tt.ID, "admin1")
 			assert.True(t, errors.Is(err, tt.expErr), "actual error %q doesn't match expected error %q", err, tt.expErr)
 			assert.Equal(t, tt.expLease, actualLease)
 
@@ -424,13 +437,17 @@ func TestCreate(t *testing.T) {
 	timeNow := time.Now().Unix()
 
 	tests := []struct {
-		name                 string
-		req                  *lease.Lease
-		exp                  response
-		getResponse          *lease.Leases
-		writeErr             error
-		leaseCreateErr       error
-		principalSpentAmount float64
+		name                   string
+		req                    *lease.Lease
+		exp                    response
+		getResponse            *lease.Leases
+		writeErr               error
+		leaseCreateErr         error
+		principalSpentAmount   float64
+		maxActiveLeasesByGroup []string
+		profile                *lease.Profile
+		profileErr             error
+		autoApproveBudget      float64
 	}{
 		{
 			name: "should create",
@@ -516,6 +533,22 @@ func TestCreate(t *testing.T) {
 			},
 			principalSpentAmount: 2000.0,
 		},
+		{
+			name: "should fail on unsupported budget currency",
+			req: &lease.Lease{
+				PrincipalID:              ptrString("User1"),
+				AccountID:                ptrString("123456789012"),
+				BudgetAmount:             ptrFloat(200.00),
+				BudgetCurrency:           ptrString("JPY"),
+				BudgetNotificationEmails: ptrArrayString([]string{"test1@test.com", "test2@test.com"}),
+				Metadata:                 map[string]interface{}{},
+			},
+			exp: response{
+				data: nil,
+				err:  errors.NewValidation("lease", fmt.Errorf("budgetCurrency: Requested lease has a budget currency of \"JPY\", which is not one of the supported currencies: USD, EUR.")),
+			},
+			principalSpentAmount: 0.0,
+		},
 		{
 			name: "should fail on lease expires yesterday",
 			req: &lease.Lease{
@@ -655,7 +688,7 @@ func TestCreate(t *testing.T) {
 			},
 			exp: response{
 				data: nil,
-				err:  errors.NewAlreadyExists("lease", "with principal User1 and account 123456789012"),
+				err:  errors.NewConflict("lease", "User1", fmt.Errorf("principal already has 1 active leases, which is the max active leases of 1")),
 			},
 			getResponse: &lease.Leases{
 				lease.Lease{
@@ -670,6 +703,289 @@ func TestCreate(t *testing.T) {
 			},
 			principalSpentAmount: 0.0,
 		},
+		{
+			name: "should create when the principal's group allows another active lease",
+			req: &lease.Lease{
+				PrincipalID:              ptrString("User1"),
+				AccountID:                ptrString("123456789012"),
+				BudgetAmount:             ptrFloat(200.00),
+				BudgetCurrency:           ptrString("USD"),
+				BudgetNotificationEmails: ptrArrayString([]string{"test1@test.com"}),
+				Metadata:                 map[string]interface{}{},
+				PrincipalGroups:          []string{"NetworkTeam"},
+			},
+			exp: response{
+				data: &lease.Lease{
+					ID:                       ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+					PrincipalID:              ptrString("User1"),
+					AccountID:                ptrString("123456789012"),
+					Status:                   lease.StatusActive.StatusPtr(),
+					StatusReason:             lease.StatusReasonActive.StatusReasonPtr(),
+					BudgetAmount:             ptrFloat(200.00),
+					BudgetCurrency:           ptrString("USD"),
+					BudgetNotificationEmails: ptrArrayString([]string{"test1@test.com"}),
+					CreatedOn:                &timeNow,
+					LastModifiedOn:           &timeNow,
+					StatusModifiedOn:         &timeNow,
+					ExpiresOn:                &leaseExpiresAfterAWeek,
+				},
+			},
+			getResponse: &lease.Leases{
+				lease.Lease{
+					PrincipalID: ptrString("User1"),
+					AccountID:   ptrString("210987654321"),
+					Status:      lease.StatusActive.StatusPtr(),
+				},
+			},
+			principalSpentAmount:   0.0,
+			maxActiveLeasesByGroup: []string{"NetworkTeam:3"},
+		},
+		{
+			name: "should fail when the principal's active leases would go over the principal budget",
+			req: &lease.Lease{
+				PrincipalID:     ptrString("User1"),
+				AccountID:       ptrString("123456789012"),
+				BudgetAmount:    ptrFloat(200.00),
+				BudgetCurrency:  ptrString("USD"),
+				Metadata:        map[string]interface{}{},
+				PrincipalGroups: []string{"NetworkTeam"},
+			},
+			exp: response{
+				err: errors.NewValidation("lease", fmt.Errorf("budgetAmount: Requested lease has a budget amount of 200.00, which with the 900.00 budget of principal User1's other active leases is greater than their 1000.00 principal budget.")),
+			},
+			getResponse: &lease.Leases{
+				lease.Lease{
+					PrincipalID:  ptrString("User1"),
+					AccountID:    ptrString("210987654321"),
+					Status:       lease.StatusActive.StatusPtr(),
+					BudgetAmount: ptrFloat(900.00),
+				},
+			},
+			principalSpentAmount:   0.0,
+			maxActiveLeasesByGroup: []string{"NetworkTeam:3"},
+		},
+		{
+			name: "should fail when the principal's group is at its max active leases",
+			req: &lease.Lease{
+				PrincipalID:     ptrString("User1"),
+				AccountID:       ptrString("123456789012"),
+				BudgetAmount:    ptrFloat(200.00),
+				BudgetCurrency:  ptrString("USD"),
+				Metadata:        map[string]interface{}{},
+				PrincipalGroups: []string{"NetworkTeam"},
+			},
+			exp: response{
+				err: errors.NewConflict("lease", "User1", fmt.Errorf("principal already has 2 active leases, which is the max active leases of 2")),
+			},
+			getResponse: &lease.Leases{
+				lease.Lease{
+					PrincipalID: ptrString("User1"),
+					AccountID:   ptrString("210987654321"),
+					Status:      lease.StatusActive.StatusPtr(),
+				},
+				lease.Lease{
+					PrincipalID: ptrString("User1"),
+					AccountID:   ptrString("111111111111"),
+					Status:      lease.StatusActive.StatusPtr(),
+				},
+			},
+			principalSpentAmount:   0.0,
+			maxActiveLeasesByGroup: []string{"NetworkTeam:2"},
+		},
+		{
+			name: "should create with the profile's default budget",
+			req: &lease.Lease{
+				PrincipalID:              ptrString("User1"),
+				AccountID:                ptrString("123456789012"),
+				BudgetCurrency:           ptrString("USD"),
+				BudgetNotificationEmails: ptrArrayString([]string{"test1@test.com", "test2@test.com"}),
+				Metadata:                 map[string]interface{}{},
+				Profile:                  ptrString("sandbox-small"),
+			},
+			exp: response{
+				data: &lease.Lease{
+					ID:                       ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+					PrincipalID:              ptrString("User1"),
+					AccountID:                ptrString("123456789012"),
+					Status:                   lease.StatusActive.StatusPtr(),
+					StatusReason:             lease.StatusReasonActive.StatusReasonPtr(),
+					BudgetAmount:             ptrFloat(50.00),
+					BudgetCurrency:           ptrString("USD"),
+					BudgetNotificationEmails: ptrArrayString([]string{"test1@test.com", "test2@test.com"}),
+					CreatedOn:                &timeNow,
+					LastModifiedOn:           &timeNow,
+					StatusModifiedOn:         &timeNow,
+					ExpiresOn:                &leaseExpiresAfterAWeek,
+					Profile:                  ptrString("sandbox-small"),
+				},
+			},
+			profile: &lease.Profile{
+				Name:                ptrString("sandbox-small"),
+				DefaultBudgetAmount: ptrFloat(50.00),
+				MaxBudgetAmount:     ptrFloat(100.00),
+			},
+		},
+		{
+			name: "should fail on budget amount greater than the profile's max budget amount",
+			req: &lease.Lease{
+				PrincipalID:              ptrString("User1"),
+				AccountID:                ptrString("123456789012"),
+				BudgetAmount:             ptrFloat(200.00),
+				BudgetCurrency:           ptrString("USD"),
+				BudgetNotificationEmails: ptrArrayString([]string{"test1@test.com", "test2@test.com"}),
+				Metadata:                 map[string]interface{}{},
+				Profile:                  ptrString("sandbox-small"),
+			},
+			exp: response{
+				err: errors.NewValidation("lease", fmt.Errorf("budgetAmount: Requested lease has a budget amount of 200.000000, which is greater than the max budget amount of 100.000000 for profile sandbox-small.")),
+			},
+			profile: &lease.Profile{
+				Name:            ptrString("sandbox-small"),
+				MaxBudgetAmount: ptrFloat(100.00),
+			},
+		},
+		{
+			name: "should convert the budgets of the principal's active leases to the cost currency",
+			req: &lease.Lease{
+				PrincipalID:    ptrString("User1"),
+				AccountID:      ptrString("123456789012"),
+				BudgetAmount:   ptrFloat(300.00),
+				BudgetCurrency: ptrString("USD"),
+				Metadata:       map[string]interface{}{},
+			},
+			exp: response{
+				err: errors.NewValidation("lease", fmt.Errorf("budgetAmount: Requested lease has a budget amount of 300.00, which with the 800.00 budget of principal User1's other active leases is greater than their 1000.00 principal budget.")),
+			},
+			getResponse: &lease.Leases{
+				lease.Lease{
+					PrincipalID:    ptrString("User1"),
+					AccountID:      ptrString("210987654321"),
+					Status:         lease.StatusActive.StatusPtr(),
+					BudgetAmount:   ptrFloat(400.00),
+					BudgetCurrency: ptrString("EUR"),
+				},
+			},
+		},
+		{
+			name: "should convert the budget amount to the cost currency before checking the profile's max budget amount",
+			req: &lease.Lease{
+				PrincipalID:              ptrString("User1"),
+				AccountID:                ptrString("123456789012"),
+				BudgetAmount:             ptrFloat(100.00),
+				BudgetCurrency:           ptrString("EUR"),
+				BudgetNotificationEmails: ptrArrayString([]string{"test1@test.com", "test2@test.com"}),
+				Metadata:                 map[string]interface{}{},
+				Profile:                  ptrString("sandbox-small"),
+			},
+			exp: response{
+				err: errors.NewValidation("lease", fmt.Errorf("budgetAmount: Requested lease has a budget amount of 200.000000, which is greater than the max budget amount of 150.000000 for profile sandbox-small.")),
+			},
+			profile: &lease.Profile{
+				Name:            ptrString("sandbox-small"),
+				MaxBudgetAmount: ptrFloat(150.00),
+			},
+		},
+		{
+			name: "should fail when the profile does not exist",
+			req: &lease.Lease{
+				PrincipalID:              ptrString("User1"),
+				AccountID:                ptrString("123456789012"),
+				BudgetAmount:             ptrFloat(200.00),
+				BudgetCurrency:           ptrString("USD"),
+				BudgetNotificationEmails: ptrArrayString([]string{"test1@test.com", "test2@test.com"}),
+				Metadata:                 map[string]interface{}{},
+				Profile:                  ptrString("sandbox-small"),
+			},
+			exp: response{
+				err: errors.NewValidation("lease", fmt.Errorf("profile: lease profile \"sandbox-small\" does not exist.")),
+			},
+			profileErr: errors.NewNotFound("lease profile", "sandbox-small"),
+		},
+		{
+			name: "should create a pending lease when over the auto-approve budget amount",
+			req: &lease.Lease{
+				PrincipalID:              ptrString("User1"),
+				AccountID:                ptrString("123456789012"),
+				BudgetAmount:             ptrFloat(200.00),
+				BudgetCurrency:           ptrString("USD"),
+				BudgetNotificationEmails: ptrArrayString([]string{"test1@test.com", "test2@test.com"}),
+				Metadata:                 map[string]interface{}{},
+			},
+			exp: response{
+				data: &lease.Lease{
+					ID:                       ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+					PrincipalID:              ptrString("User1"),
+					AccountID:                ptrString("123456789012"),
+					Status:                   lease.StatusPending.StatusPtr(),
+					StatusReason:             lease.StatusReasonPendingApproval.StatusReasonPtr(),
+					BudgetAmount:             ptrFloat(200.00),
+					BudgetCurrency:           ptrString("USD"),
+					BudgetNotificationEmails: ptrArrayString([]string{"test1@test.com", "test2@test.com"}),
+					CreatedOn:                &timeNow,
+					LastModifiedOn:           &timeNow,
+					StatusModifiedOn:         &timeNow,
+					ExpiresOn:                &leaseExpiresAfterAWeek,
+				},
+			},
+			autoApproveBudget: 100.00,
+		},
+		{
+			name: "should create a pending lease when over the auto-approve budget amount in the cost currency",
+			req: &lease.Lease{
+				PrincipalID:              ptrString("User1"),
+				AccountID:                ptrString("123456789012"),
+				BudgetAmount:             ptrFloat(150.00),
+				BudgetCurrency:           ptrString("EUR"),
+				BudgetNotificationEmails: ptrArrayString([]string{"test1@test.com", "test2@test.com"}),
+				Metadata:                 map[string]interface{}{},
+			},
+			exp: response{
+				data: &lease.Lease{
+					ID:                       ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+					PrincipalID:              ptrString("User1"),
+					AccountID:                ptrString("123456789012"),
+					Status:                   lease.StatusPending.StatusPtr(),
+					StatusReason:             lease.StatusReasonPendingApproval.StatusReasonPtr(),
+					BudgetAmount:             ptrFloat(150.00),
+					BudgetCurrency:           ptrString("EUR"),
+					BudgetNotificationEmails: ptrArrayString([]string{"test1@test.com", "test2@test.com"}),
+					CreatedOn:                &timeNow,
+					LastModifiedOn:           &timeNow,
+					StatusModifiedOn:         &timeNow,
+					ExpiresOn:                &leaseExpiresAfterAWeek,
+				},
+			},
+			autoApproveBudget: 200.00,
+		},
+		{
+			name: "should return the saved lease when its create event fails",
+			req: &lease.Lease{
+				PrincipalID:              ptrString("User1"),
+				AccountID:                ptrString("123456789012"),
+				BudgetAmount:             ptrFloat(200.00),
+				BudgetCurrency:           ptrString("USD"),
+				BudgetNotificationEmails: ptrArrayString([]string{"test1@test.com", "test2@test.com"}),
+				Metadata:                 map[string]interface{}{},
+			},
+			exp: response{
+				data: &lease.Lease{
+					ID:                       ptrString("6d666a28-4f2c-43af-8c94-1b715ca079ae"),
+					PrincipalID:              ptrString("User1"),
+					AccountID:                ptrString("123456789012"),
+					Status:                   lease.StatusActive.StatusPtr(),
+					StatusReason:             lease.StatusReasonActive.StatusReasonPtr(),
+					BudgetAmount:             ptrFloat(200.00),
+					BudgetCurrency:           ptrString("USD"),
+					BudgetNotificationEmails: ptrArrayString([]string{"test1@test.com", "test2@test.com"}),
+					CreatedOn:                &timeNow,
+					LastModifiedOn:           &timeNow,
+					StatusModifiedOn:         &timeNow,
+					ExpiresOn:                &leaseExpiresAfterAWeek,
+				},
+				err: errors.NewInternalServer("failure", nil),
+			},
+			leaseCreateErr: errors.NewInternalServer("failure", nil),
+		},
 	}
 
 	for _, tt := range tests {
@@ -679,21 +995,33 @@ func TestCreate(t *testing.T) {
 			mocksEventer := &mocks.Eventer{}
 
 			mocksAccountSvc := &mocks.AccountServicer{}
+			mocksProfileSvc := &mocks.ProfileReader{}
+			mocksProfileSvc.On("Get", "sandbox-small").Return(tt.profile, tt.profileErr)
 
-			mocksRwd.On("List", mock.AnythingOfType("*lease.Lease")).Return(tt.getResponse, nil)
+			mocksRwd.On("List", mock.MatchedBy(func(query *lease.Lease) bool {
+				return *query.Status == lease.StatusActive
+			})).Return(tt.getResponse, nil)
+			mocksRwd.On("List", mock.MatchedBy(func(query *lease.Lease) bool {
+				return *query.Status != lease.StatusActive
+			})).Return(nil, nil)
 			mocksRwd.On("Write", mock.AnythingOfType("*lease.Lease"), mock.AnythingOfType("*int64")).Return(tt.writeErr)
-			mocksEventer.On("LeaseCreate", mock.AnythingOfType("*lease.Lease")).Return(nil)
+			mocksEventer.On("LeaseCreate", mock.AnythingOfType("*lease.Lease")).Return(tt.leaseCreateErr)
 
 			leaseSvc := lease.NewService(
 				lease.NewServiceInput{
 					DataSvc:                  mocksRwd,
 					EventSvc:                 mocksEventer,
 					AccountSvc:               mocksAccountSvc,
+					ProfileSvc:               mocksProfileSvc,
 					DefaultLeaseLengthInDays: 7,
 					PrincipalBudgetAmount:    1000.00,
 					PrincipalBudgetPeriod:    "Weekly",
 					MaxLeaseBudgetAmount:     1000.00,
 					MaxLeasePeriod:           704800,
+					MaxActiveLeasesByGroup:   tt.maxActiveLeasesByGroup,
+					AutoApproveBudgetAmount:  tt.autoApproveBudget,
+					SupportedCurrencies:      []string{"USD", "EUR"},
+					CurrencyConverter:        &currency.Converter{BaseCurrency: "USD", Provider: currency.StaticRates{"EUR": 0.5}},
 				},
 			)
 
@@ -707,3 +1035,596 @@ func TestCreate(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdate(t *testing.T) {
+
+	type response struct {
+		data *lease.Lease
+		err  error
+	}
+
+	now := time.Now().Unix()
+	expiresOn := time.Now().AddDate(0, 0, 2).Unix()
+	extendedExpiresOn := time.Now().AddDate(0, 0, 4).Unix()
+	pastMaxExpiresOn := time.Now().AddDate(0, 0, 30).Unix()
+
+	existingLease := func() *lease.Lease {
+		return &lease.Lease{
+			ID:               ptrString("70c2d96d-7938-4ec9-917d-476f2b09cc04"),
+			AccountID:        ptrString("123456789012"),
+			PrincipalID:      ptrString("User1"),
+			Status:           lease.StatusActive.StatusPtr(),
+			StatusReason:     lease.StatusReasonActive.StatusReasonPtr(),
+			BudgetAmount:     ptrFloat(200.00),
+			ExpiresOn:        &expiresOn,
+			CreatedOn:        &now,
+			LastModifiedOn:   &now,
+			StatusModifiedOn: &now,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		req           *lease.Lease
+		getResponse   *lease.Lease
+		activeLeases  *lease.Leases
+		writeErr      error
+		maxExtensions int64
+		exp           response
+	}{
+		{
+			name: "should extend expires on and budget amount",
+			req: &lease.Lease{
+				ExpiresOn:    &extendedExpiresOn,
+				BudgetAmount: ptrFloat(300.00),
+			},
+			getResponse:   existingLease(),
+			maxExtensions: 3,
+			exp: response{
+				data: &lease.Lease{
+					ID:               ptrString("70c2d96d-7938-4ec9-917d-476f2b09cc04"),
+					AccountID:        ptrString("123456789012"),
+					PrincipalID:      ptrString("User1"),
+					Status:           lease.StatusActive.StatusPtr(),
+					StatusReason:     lease.StatusReasonActive.StatusReasonPtr(),
+					BudgetAmount:     ptrFloat(300.00),
+					ExpiresOn:        &extendedExpiresOn,
+					ExtensionCount:   aws.Int64(1),
+					CreatedOn:        &now,
+					StatusModifiedOn: &now,
+				},
+			},
+		},
+		{
+			name: "should forget the notifications sent for the old budget amount and expires on",
+			req: &lease.Lease{
+				ExpiresOn:    &extendedExpiresOn,
+				BudgetAmount: ptrFloat(300.00),
+			},
+			getResponse: func() *lease.Lease {
+				l := existingLease()
+				l.BudgetNotificationsSent = &[]float64{75}
+				l.ExpiryNotificationsSent = &[]float64{72}
+				return l
+			}(),
+			maxExtensions: 3,
+			exp: response{
+				data: &lease.Lease{
+					ID:               ptrString("70c2d96d-7938-4ec9-917d-476f2b09cc04"),
+					AccountID:        ptrString("123456789012"),
+					PrincipalID:      ptrString("User1"),
+					Status:           lease.StatusActive.StatusPtr(),
+					StatusReason:     lease.StatusReasonActive.StatusReasonPtr(),
+					BudgetAmount:     ptrFloat(300.00),
+					ExpiresOn:        &extendedExpiresOn,
+					ExtensionCount:   aws.Int64(1),
+					CreatedOn:        &now,
+					StatusModifiedOn: &now,
+				},
+			},
+		},
+		{
+			name: "should fail when the principal's active leases would go over the principal budget",
+			req: &lease.Lease{
+				BudgetAmount: ptrFloat(500.00),
+			},
+			getResponse: existingLease(),
+			activeLeases: &lease.Leases{
+				*existingLease(),
+				{
+					ID:           ptrString("other-lease"),
+					AccountID:    ptrString("234567890123"),
+					PrincipalID:  ptrString("User1"),
+					Status:       lease.StatusActive.StatusPtr(),
+					BudgetAmount: ptrFloat(600.00),
+				},
+			},
+			maxExtensions: 3,
+			exp: response{
+				err: errors.NewValidation("lease", fmt.Errorf("budgetAmount: Requested lease has a budget amount of 500.00, which with the 600.00 budget of principal User1's other active leases is greater than their 1000.00 principal budget.")),
+			},
+		},
+		{
+			name: "should fail when max extensions reached",
+			req: &lease.Lease{
+				ExpiresOn: &extendedExpiresOn,
+			},
+			getResponse: func() *lease.Lease {
+				l := existingLease()
+				l.ExtensionCount = aws.Int64(3)
+				return l
+			}(),
+			maxExtensions: 3,
+			exp: response{
+				err: errors.NewConflict("lease", "70c2d96d-7938-4ec9-917d-476f2b09cc04", fmt.Errorf("lease has already been extended 3 times, which is the max lease extensions of 3")),
+			},
+		},
+		{
+			name: "should fail when lease is inactive",
+			req: &lease.Lease{
+				ExpiresOn: &extendedExpiresOn,
+			},
+			getResponse: func() *lease.Lease {
+				l := existingLease()
+				l.Status = lease.StatusInactive.StatusPtr()
+				return l
+			}(),
+			maxExtensions: 3,
+			exp: response{
+				err: errors.NewConflict("lease", "70c2d96d-7938-4ec9-917d-476f2b09cc04", fmt.Errorf("leaseStatus: must be active lease.")),
+			},
+		},
+		{
+			name: "should fail when budget amount is decreased",
+			req: &lease.Lease{
+				BudgetAmount: ptrFloat(100.00),
+			},
+			getResponse:   existingLease(),
+			maxExtensions: 3,
+			exp: response{
+				err: errors.NewValidation("lease", fmt.Errorf("budgetAmount: Requested lease has a budget amount of 100.000000, which is less than the current budget amount of 200.000000.")),
+			},
+		},
+		{
+			name: "should fail when expires on is past max lease period",
+			req: &lease.Lease{
+				ExpiresOn: &pastMaxExpiresOn,
+			},
+			getResponse:   existingLease(),
+			maxExtensions: 3,
+			exp: response{
+				err: errors.NewValidation("lease", fmt.Errorf("expiresOn: Requested lease has a budget expires on of %d, which is greater than max lease period of 704800.", pastMaxExpiresOn)),
+			},
+		},
+		{
+			name: "should fail when updating fields other than expires on and budget amount",
+			req: &lease.Lease{
+				PrincipalID: ptrString("User2"),
+				ExpiresOn:   &extendedExpiresOn,
+			},
+			getResponse:   existingLease(),
+			maxExtensions: 3,
+			exp: response{
+				err: errors.NewValidation("lease", fmt.Errorf("principalId: must be empty.")),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mocksRwd := &mocks.ReaderWriter{}
+			mocksEventer := &mocks.Eventer{}
+
+			mocksRwd.On("Get", "70c2d96d-7938-4ec9-917d-476f2b09cc04").Return(tt.getResponse, nil)
+			activeLeases := &lease.Leases{}
+			if tt.activeLeases != nil {
+				activeLeases = tt.activeLeases
+			}
+			mocksRwd.On("List", mock.MatchedBy(func(q *lease.Lease) bool {
+				return *q.Status == lease.StatusActive
+			})).Return(activeLeases, nil)
+			mocksRwd.On("List", mock.AnythingOfType("*lease.Lease")).Return(&lease.Leases{}, nil)
+			mocksRwd.On("Write", mock.AnythingOfType("*lease.Lease"), &now).Return(tt.writeErr)
+			mocksEventer.On("LeaseUpdate", mock.AnythingOfType("*lease.Lease"), mock.AnythingOfType("*lease.Lease")).Return(nil)
+
+			leaseSvc := lease.NewService(
+				lease.NewServiceInput{
+					DataSvc:               mocksRwd,
+					EventSvc:              mocksEventer,
+					PrincipalBudgetAmount: 1000.00,
+					MaxLeaseBudgetAmount:  1000.00,
+					MaxLeasePeriod:        704800,
+					MaxLeaseExtensions:    tt.maxExtensions,
+				},
+			)
+
+			before := time.Now().Unix()
+			result, err := leaseSvc.Update("70c2d96d-7938-4ec9-917d-476f2b09cc04", tt.req, 0.0)
+			after := time.Now().Unix()
+
+			assert.Truef(t, errors.Is(err, tt.exp.err), "actual error %q doesn't match expected error %q", err, tt.exp.err)
+			if result != nil {
+				// Update stamps the time it saved the lease
+				assert.True(t, *result.LastModifiedOn >= before && *result.LastModifiedOn <= after,
+					"LastModifiedOn %d isn't between %d and %d", *result.LastModifiedOn, before, after)
+				tt.exp.data.LastModifiedOn = result.LastModifiedOn
+			}
+			assert.Equal(t, tt.exp.data, result)
+			if tt.exp.err == nil {
+				mocksEventer.AssertCalled(t, "LeaseUpdate", tt.getResponse, result)
+			}
+		})
+	}
+}
+
+func TestCreateRequest(t *testing.T) {
+
+	type response struct {
+		data *lease.Request
+		err  error
+	}
+
+	leaseExpiresYesterday := time.Now().AddDate(0, 0, -1).Unix()
+
+	tests := []struct {
+		name                 string
+		req                  *lease.Lease
+		exp                  response
+		getResponse          *lease.Leases
+		writeErr             error
+		principalSpentAmount float64
+	}{
+		{
+			name: "should queue a pending request",
+			req: &lease.Lease{
+				PrincipalID:              ptrString("User1"),
+				BudgetAmount:             ptrFloat(200.00),
+				BudgetCurrency:           ptrString("USD"),
+				BudgetNotificationEmails: ptrArrayString([]string{"test1@test.com"}),
+			},
+			exp: response{
+				data: &lease.Request{
+					PrincipalID:              ptrString("User1"),
+					Status:                   lease.RequestStatusPending.RequestStatusPtr(),
+					BudgetAmount:             ptrFloat(200.00),
+					BudgetCurrency:           ptrString("USD"),
+					BudgetNotificationEmails: ptrArrayString([]string{"test1@test.com"}),
+				},
+			},
+		},
+		{
+			name: "should fail on accountId provided",
+			req: &lease.Lease{
+				PrincipalID: ptrString("User1"),
+				AccountID:   ptrString("123456789012"),
+			},
+			exp: response{
+				err: errors.NewValidation("lease", fmt.Errorf("accountId: must be empty.")),
+			},
+		},
+		{
+			name: "should fail on expiresOn in the past",
+			req: &lease.Lease{
+				PrincipalID: ptrString("User1"),
+				ExpiresOn:   &leaseExpiresYesterday,
+			},
+			exp: response{
+				err: errors.NewValidation("lease", fmt.Errorf("expiresOn: Requested lease has a desired expiry date less than today: %d.", leaseExpiresYesterday)),
+			},
+		},
+		{
+			name: "should fail when principal has an active lease",
+			req: &lease.Lease{
+				PrincipalID: ptrString("User1"),
+			},
+			getResponse: &lease.Leases{
+				lease.Lease{
+					PrincipalID: ptrString("User1"),
+					AccountID:   ptrString("123456789012"),
+					Status:      lease.StatusActive.StatusPtr(),
+				},
+			},
+			exp: response{
+				err: errors.NewConflict("lease", "User1", fmt.Errorf("principal already has 1 active leases, which is the max active leases of 1")),
+			},
+		},
+		{
+			name: "should fail on write error",
+			req: &lease.Lease{
+				PrincipalID: ptrString("User1"),
+			},
+			writeErr: errors.NewInternalServer("failure", nil),
+			exp: response{
+				err: errors.NewInternalServer("failure", nil),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mocksRwd := &mocks.ReaderWriter{}
+			mocksRequestRwd := &mocks.RequestReaderWriter{}
+
+			mocksRwd.On("List", mock.MatchedBy(func(query *lease.Lease) bool {
+				return *query.Status == lease.StatusActive
+			})).Return(tt.getResponse, nil)
+			mocksRwd.On("List", mock.MatchedBy(func(query *lease.Lease) bool {
+				return *query.Status != lease.StatusActive
+			})).Return(nil, nil)
+			mocksRequestRwd.On("Write", mock.AnythingOfType("*lease.Request"), mock.AnythingOfType("*int64")).Return(tt.writeErr)
+
+			leaseSvc := lease.NewService(
+				lease.NewServiceInput{
+					DataSvc:               mocksRwd,
+					RequestSvc:            mocksRequestRwd,
+					PrincipalBudgetAmount: 1000.00,
+					MaxLeaseBudgetAmount:  1000.00,
+					MaxLeasePeriod:        704800,
+				},
+			)
+
+			result, err := leaseSvc.CreateRequest(tt.req, tt.principalSpentAmount)
+
+			assert.Truef(t, errors.Is(err, tt.exp.err), "actual error %q doesn't match expected error %q", err, tt.exp.err)
+			if result != nil {
+				assert.NotNil(t, result.ID)
+				assert.NotNil(t, result.CreatedOn)
+				result.ID = nil
+				result.CreatedOn = nil
+				result.LastModifiedOn = nil
+			}
+			assert.Equal(t, tt.exp.data, result)
+		})
+	}
+}
+
+func TestFulfillRequest(t *testing.T) {
+
+	leaseExpiresYesterday := time.Now().AddDate(0, 0, -1).Unix()
+	timeNow := time.Now().Unix()
+
+	tests := []struct {
+		name            string
+		requests        *lease.Requests
+		nextRequests    *lease.Requests
+		accountMetadata map[string]interface{}
+		strategy        string
+		principalSpend  map[string]float64
+		expLease        bool
+		expRequestState []lease.RequestStatus
+		createErr       error
+		leaseCreateErr  error
+		expErr          error
+	}{
+		{
+			name:     "should do nothing when there are no pending requests",
+			requests: &lease.Requests{},
+		},
+		{
+			name: "should fulfill the oldest request",
+			requests: &lease.Requests{
+				lease.Request{
+					ID:             ptrString("request-1"),
+					PrincipalID:    ptrString("User1"),
+					Status:         lease.RequestStatusPending.RequestStatusPtr(),
+					LastModifiedOn: &timeNow,
+				},
+				lease.Request{
+					ID:             ptrString("request-2"),
+					PrincipalID:    ptrString("User2"),
+					Status:         lease.RequestStatusPending.RequestStatusPtr(),
+					LastModifiedOn: &timeNow,
+				},
+			},
+			expLease:        true,
+			expRequestState: []lease.RequestStatus{lease.RequestStatusFulfilled, lease.RequestStatusFulfilled},
+		},
+		{
+			name: "should fail expired requests and fulfill the next one",
+			requests: &lease.Requests{
+				lease.Request{
+					ID:             ptrString("request-1"),
+					PrincipalID:    ptrString("User1"),
+					Status:         lease.RequestStatusPending.RequestStatusPtr(),
+					ExpiresOn:      &leaseExpiresYesterday,
+					LastModifiedOn: &timeNow,
+				},
+				lease.Request{
+					ID:             ptrString("request-2"),
+					PrincipalID:    ptrString("User2"),
+					Status:         lease.RequestStatusPending.RequestStatusPtr(),
+					LastModifiedOn: &timeNow,
+				},
+			},
+			expLease: true,
+			expRequestState: []lease.RequestStatus{
+				lease.RequestStatusFulfilled, lease.RequestStatusFailed,
+				lease.RequestStatusFulfilled, lease.RequestStatusFulfilled,
+			},
+		},
+		{
+			name: "should fail requests of principals over their principal budget",
+			requests: &lease.Requests{
+				lease.Request{
+					ID:             ptrString("request-1"),
+					PrincipalID:    ptrString("User1"),
+					Status:         lease.RequestStatusPending.RequestStatusPtr(),
+					LastModifiedOn: &timeNow,
+				},
+				lease.Request{
+					ID:             ptrString("request-2"),
+					PrincipalID:    ptrString("User2"),
+					Status:         lease.RequestStatusPending.RequestStatusPtr(),
+					LastModifiedOn: &timeNow,
+				},
+			},
+			principalSpend: map[string]float64{"User1": 1500.00},
+			expLease:       true,
+			expRequestState: []lease.RequestStatus{
+				lease.RequestStatusFailed,
+				lease.RequestStatusFulfilled, lease.RequestStatusFulfilled,
+			},
+		},
+		{
+			name:            "should skip requests for another pool",
+			accountMetadata: map[string]interface{}{"pool": "gpu"},
+			strategy:        "metadata",
+			requests: &lease.Requests{
+				lease.Request{
+					ID:             ptrString("request-1"),
+					PrincipalID:    ptrString("User1"),
+					Status:         lease.RequestStatusPending.RequestStatusPtr(),
+					Metadata:       map[string]interface{}{"pool": "default"},
+					LastModifiedOn: &timeNow,
+				},
+				lease.Request{
+					ID:             ptrString("request-2"),
+					PrincipalID:    ptrString("User2"),
+					Status:         lease.RequestStatusPending.RequestStatusPtr(),
+					Metadata:       map[string]interface{}{"pool": "gpu"},
+					LastModifiedOn: &timeNow,
+				},
+			},
+			expLease:        true,
+			expRequestState: []lease.RequestStatus{lease.RequestStatusFulfilled, lease.RequestStatusFulfilled},
+		},
+		{
+			name:            "should page through the pending requests",
+			accountMetadata: map[string]interface{}{"pool": "gpu"},
+			strategy:        "metadata",
+			requests: &lease.Requests{
+				lease.Request{
+					ID:             ptrString("request-1"),
+					PrincipalID:    ptrString("User1"),
+					Status:         lease.RequestStatusPending.RequestStatusPtr(),
+					Metadata:       map[string]interface{}{"pool": "default"},
+					LastModifiedOn: &timeNow,
+				},
+			},
+			nextRequests: &lease.Requests{
+				lease.Request{
+					ID:             ptrString("request-2"),
+					PrincipalID:    ptrString("User2"),
+					Status:         lease.RequestStatusPending.RequestStatusPtr(),
+					Metadata:       map[string]interface{}{"pool": "gpu"},
+					LastModifiedOn: &timeNow,
+				},
+			},
+			expLease:        true,
+			expRequestState: []lease.RequestStatus{lease.RequestStatusFulfilled, lease.RequestStatusFulfilled},
+		},
+		{
+			name:            "should do nothing when no request can use the account",
+			accountMetadata: map[string]interface{}{"pool": "gpu"},
+			strategy:        "metadata",
+			requests: &lease.Requests{
+				lease.Request{
+					ID:             ptrString("request-1"),
+					PrincipalID:    ptrString("User1"),
+					Status:         lease.RequestStatusPending.RequestStatusPtr(),
+					Metadata:       map[string]interface{}{"pool": "default"},
+					LastModifiedOn: &timeNow,
+				},
+			},
+			expRequestState: []lease.RequestStatus{},
+		},
+		{
+			name: "should release the request when the lease can't be saved",
+			requests: &lease.Requests{
+				lease.Request{
+					ID:             ptrString("request-1"),
+					PrincipalID:    ptrString("User1"),
+					Status:         lease.RequestStatusPending.RequestStatusPtr(),
+					LastModifiedOn: &timeNow,
+				},
+			},
+			createErr:       errors.NewInternalServer("failure", nil),
+			expErr:          errors.NewInternalServer("failure", nil),
+			expRequestState: []lease.RequestStatus{lease.RequestStatusFulfilled, lease.RequestStatusPending},
+		}, {
+			name: "should keep the request fulfilled when the lease is saved but its create event fails",
+			requests: &lease.Requests{
+				lease.Request{
+					ID:             ptrString("request-1"),
+					PrincipalID:    ptrString("User1"),
+					Status:         lease.RequestStatusPending.RequestStatusPtr(),
+					LastModifiedOn: &timeNow,
+				},
+			},
+			leaseCreateErr:  errors.NewInternalServer("failure", nil),
+			expErr:          errors.NewInternalServer("failure", nil),
+			expLease:        true,
+			expRequestState: []lease.RequestStatus{lease.RequestStatusFulfilled, lease.RequestStatusFulfilled},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mocksRwd := &mocks.ReaderWriter{}
+			mocksRequestRwd := &mocks.RequestReaderWriter{}
+			mocksEventer := &mocks.Eventer{}
+
+			mocksRwd.On("List", mock.AnythingOfType("*lease.Lease")).Return(nil, nil)
+			mocksRwd.On("GetByAccountIDAndPrincipalID", "123456789012", mock.Anything).
+				Return(nil, errors.NewNotFound("lease", "123456789012"))
+			mocksRwd.On("Write", mock.AnythingOfType("*lease.Lease"), mock.Anything).Return(tt.createErr)
+			mocksEventer.On("LeaseCreate", mock.AnythingOfType("*lease.Lease")).Return(tt.leaseCreateErr)
+
+			requestStates := []lease.RequestStatus{}
+			mocksAccountSvc := &mocks.AccountServicer{}
+			mocksAccountSvc.On("Get", "123456789012").Return(&account.Account{
+				ID:       ptrString("123456789012"),
+				Metadata: tt.accountMetadata,
+			}, nil)
+
+			if tt.nextRequests != nil {
+				mocksRequestRwd.On("List", mock.MatchedBy(func(query *lease.Request) bool {
+					return query.NextID == nil
+				})).Run(func(args mock.Arguments) {
+					args.Get(0).(*lease.Request).NextID = ptrString("request-1")
+				}).Return(tt.requests, nil).Once()
+				mocksRequestRwd.On("List", mock.AnythingOfType("*lease.Request")).Run(func(args mock.Arguments) {
+					args.Get(0).(*lease.Request).NextID = nil
+				}).Return(tt.nextRequests, nil).Once()
+			} else {
+				mocksRequestRwd.On("List", mock.AnythingOfType("*lease.Request")).Return(tt.requests, nil)
+			}
+			mocksRequestRwd.On("Write", mock.AnythingOfType("*lease.Request"), mock.AnythingOfType("*int64")).
+				Run(func(args mock.Arguments) {
+					requestStates = append(requestStates, *args.Get(0).(*lease.Request).Status)
+				}).
+				Return(nil)
+
+			leaseSvc := lease.NewService(
+				lease.NewServiceInput{
+					DataSvc:                  mocksRwd,
+					RequestSvc:               mocksRequestRwd,
+					EventSvc:                 mocksEventer,
+					AccountSvc:               mocksAccountSvc,
+					AccountSelectionStrategy: tt.strategy,
+					AccountSelectionKeys:     []string{"pool"},
+					DefaultLeaseLengthInDays: 7,
+					PrincipalBudgetAmount:    1000.00,
+					MaxLeaseBudgetAmount:     1000.00,
+					MaxLeasePeriod:           704800,
+				},
+			)
+
+			result, request, err := leaseSvc.FulfillRequest("123456789012", func(principalID string) (float64, error) {
+				return tt.principalSpend[principalID], nil
+			})
+
+			assert.Truef(t, errors.Is(err, tt.expErr), "actual error %q doesn't match expected error %q", err, tt.expErr)
+			assert.Equal(t, tt.expLease, result != nil)
+			if result != nil {
+				assert.Equal(t, "123456789012", *result.AccountID)
+				assert.Equal(t, result.ID, request.LeaseID)
+			}
+			if tt.expRequestState != nil {
+				assert.Equal(t, tt.expRequestState, requestStates)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"reflect"
 	"regexp"
+	"strings"
 
 	"fmt"
 	validation "github.com/go-ozzo/ozzo-validation"
@@ -52,6 +53,22 @@ func isLeaseActive(value interface{}) error {
 	return nil
 }
 
+func isLeaseActiveOrFrozen(value interface{}) error {
+	s, _ := value.(*Status)
+	if s.String() != StatusActive.String() && s.String() != StatusFrozen.String() {
+		return errors.New("must be active or frozen lease")
+	}
+	return nil
+}
+
+func isLeasePending(value interface{}) error {
+	s, _ := value.(*Status)
+	if s.String() != StatusPending.String() {
+		return errors.New("must be pending lease")
+	}
+	return nil
+}
+
 func isExpiresOnValid(a *Service) validation.RuleFunc {
 
 	return func(value interface{}) error {
@@ -75,10 +92,16 @@ func isExpiresOnValid(a *Service) validation.RuleFunc {
 	}
 }
 
-func isBudgetAmountValid(a *Service, principalId string, principalSpentAmount float64) validation.RuleFunc {
+// isBudgetAmountValid checks the lease budget against the max lease budget, and the principal's
+// spend and the budgets of all of their active leases against the principal budget.
+// The lease budget is converted from its budget currency to the cost currency those are in.
+func isBudgetAmountValid(a *Service, principalId string, budgetCurrency *string, principalSpentAmount float64, committedBudgetAmount float64) validation.RuleFunc {
 	return func(value interface{}) error {
 		if !reflect.ValueOf(value).IsNil() {
-			b, _ := value.(*float64)
+			b, err := a.budgetAmountInCostCurrency(value, budgetCurrency)
+			if err != nil {
+				return err
+			}
 
 			// Validate requested lease budget amount is less than MAX_LEASE_BUDGET_AMOUNT
 			if *b > a.maxLeaseBudgetAmount {
@@ -93,6 +116,96 @@ func isBudgetAmountValid(a *Service, principalId string, principalSpentAmount fl
 				)
 			}
 
+			// Validate the budgets of the principal's active leases, with this one, are within PRINCIPAL_BUDGET_AMOUNT
+			if committedBudgetAmount+*b > a.principalBudgetAmount {
+				return fmt.Errorf(
+					"Requested lease has a budget amount of %.2f, which with the %.2f budget of principal %s's other active leases is greater than their %.2f principal budget",
+					*b, committedBudgetAmount, principalId, a.principalBudgetAmount,
+				)
+			}
+
+		}
+		return nil
+	}
+}
+
+// budgetAmountInCostCurrency converts the budget amount being validated to the cost currency
+func (a *Service) budgetAmountInCostCurrency(value interface{}, budgetCurrency *string) (*float64, error) {
+	b, _ := value.(*float64)
+	amount, err := a.toCostCurrency(*b, budgetCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to convert the requested lease budget amount to the cost currency: %s", err)
+	}
+	return &amount, nil
+}
+
+// isBudgetCurrencySupported checks the lease budget currency is one of the supported currencies.
+// Any currency is allowed when no supported currencies are configured.
+func isBudgetCurrencySupported(currencies []string) validation.RuleFunc {
+	return func(value interface{}) error {
+		if len(currencies) == 0 || reflect.ValueOf(value).IsNil() {
+			return nil
+		}
+		c, _ := value.(*string)
+		for _, supported := range currencies {
+			if *c == supported {
+				return nil
+			}
+		}
+		return fmt.Errorf("Requested lease has a budget currency of %q, which is not one of the supported currencies: %s", *c, strings.Join(currencies, ", "))
+	}
+}
+
+func isExpiresOnExtended(current *int64) validation.RuleFunc {
+	return func(value interface{}) error {
+		if !reflect.ValueOf(value).IsNil() && current != nil {
+			e, _ := value.(*int64)
+			if *e <= *current {
+				return fmt.Errorf("Requested lease has an expires on of %d, which is not after the current expires on of %d", *e, *current)
+			}
+		}
+		return nil
+	}
+}
+
+func isBudgetAmountIncreased(current *float64) validation.RuleFunc {
+	return func(value interface{}) error {
+		if !reflect.ValueOf(value).IsNil() && current != nil {
+			b, _ := value.(*float64)
+			if *b < *current {
+				return fmt.Errorf("Requested lease has a budget amount of %f, which is less than the current budget amount of %f", math.Round(*b), math.Round(*current))
+			}
+		}
+		return nil
+	}
+}
+
+// isBudgetAmountWithinProfile checks the lease budget, converted to the cost currency, against the profile's max budget
+func isBudgetAmountWithinProfile(a *Service, p *Profile, budgetCurrency *string) validation.RuleFunc {
+	return func(value interface{}) error {
+		if p == nil || p.MaxBudgetAmount == nil || reflect.ValueOf(value).IsNil() {
+			return nil
+		}
+		b, err := a.budgetAmountInCostCurrency(value, budgetCurrency)
+		if err != nil {
+			return err
+		}
+		if *b > *p.MaxBudgetAmount {
+			return fmt.Errorf("Requested lease has a budget amount of %f, which is greater than the max budget amount of %f for profile %s", *b, *p.MaxBudgetAmount, *p.Name)
+		}
+		return nil
+	}
+}
+
+func isExpiresOnWithinProfile(p *Profile) validation.RuleFunc {
+	return func(value interface{}) error {
+		if p == nil || p.MaxLeasePeriod == nil || reflect.ValueOf(value).IsNil() {
+			return nil
+		}
+		e, _ := value.(*int64)
+		maxLeaseExpiresOn := time.Now().Add(time.Second * time.Duration(*p.MaxLeasePeriod))
+		if *e > maxLeaseExpiresOn.Unix() {
+			return fmt.Errorf("Requested lease has a budget expires on of %d, which is greater than the max lease period of %d for profile %s", *e, *p.MaxLeasePeriod, *p.Name)
 		}
 		return nil
 	}
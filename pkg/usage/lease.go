@@ -0,0 +1,123 @@
+package usage
+
+import (
+	"log"
+	"time"
+
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// LeaseIndexName is the Usage table index keyed by LeaseId and StartDate
+const LeaseIndexName = "LeaseIdStartDate"
+
+// LeaseSpend is a lease's daily usage, and its total
+type LeaseSpend struct {
+	LeaseID               string   `json:"leaseId"`
+	PrincipalID           string   `json:"principalId"`
+	AccountID             string   `json:"accountId"`
+	StartDate             int64    `json:"startDate"`                       // Start date of the first day, Epoch Timestamp
+	EndDate               int64    `json:"endDate"`                         // End date of the last day, Epoch Timestamp
+	CostAmount            float64  `json:"costAmount"`                      // Total cost amount of the lease's usage
+	CostCurrency          string   `json:"costCurrency,omitempty"`          // Cost currency
+	ConvertedCostAmount   *float64 `json:"convertedCostAmount,omitempty"`   // Total cost amount in the lease's budget currency
+	ConvertedCostCurrency string   `json:"convertedCostCurrency,omitempty"` // Lease's budget currency
+	Usage                 []*Usage `json:"usage"`                           // Daily usage, by start date
+}
+
+// NewLeaseSpend totals the lease's daily usage records, from the start date to the end date.
+// The total in the lease's budget currency is only given when every record was converted to it.
+func NewLeaseSpend(l *lease.Lease, records []*Usage, startDate time.Time, endDate time.Time) *LeaseSpend {
+	spend := &LeaseSpend{
+		StartDate: time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, time.UTC).Unix(),
+		EndDate:   time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 23, 59, 59, 0, time.UTC).Unix(),
+		Usage:     []*Usage{},
+	}
+	if l.ID != nil {
+		spend.LeaseID = *l.ID
+	}
+	if l.PrincipalID != nil {
+		spend.PrincipalID = *l.PrincipalID
+	}
+	if l.AccountID != nil {
+		spend.AccountID = *l.AccountID
+	}
+
+	convertedCostAmount := 0.0
+	converted := len(records) > 0
+	for _, u := range records {
+		spend.Usage = append(spend.Usage, u)
+		if u.CostAmount != nil {
+			spend.CostAmount += *u.CostAmount
+		}
+		if u.CostCurrency != nil {
+			spend.CostCurrency = *u.CostCurrency
+		}
+
+		if u.ConvertedCostAmount == nil || u.ConvertedCostCurrency == nil ||
+			(spend.ConvertedCostCurrency != "" && spend.ConvertedCostCurrency != *u.ConvertedCostCurrency) {
+			converted = false
+			continue
+		}
+		spend.ConvertedCostCurrency = *u.ConvertedCostCurrency
+		convertedCostAmount += *u.ConvertedCostAmount
+	}
+
+	if converted {
+		spend.ConvertedCostAmount = &convertedCostAmount
+	} else {
+		spend.ConvertedCostCurrency = ""
+	}
+	return spend
+}
+
+// LeasesOnDay returns the lease each account was used under during the day.
+// If an account changed hands during the day, its latest lease gets the day's usage,
+// as Cost Explorer can't tell the leases apart.
+func LeasesOnDay(leases lease.Leases, dayStart time.Time, dayEnd time.Time) []*lease.Lease {
+	byAccount := map[string]*lease.Lease{}
+	accountIDs := []string{}
+	for i := range leases {
+		l := &leases[i]
+		if l.PrincipalID == nil || l.AccountID == nil || l.CreatedOn == nil || *l.CreatedOn > dayEnd.Unix() {
+			continue
+		}
+		inUse := l.Status != nil && (*l.Status == lease.StatusActive || *l.Status == lease.StatusFrozen)
+		if !inUse && (l.StatusModifiedOn == nil || *l.StatusModifiedOn < dayStart.Unix()) {
+			continue
+		}
+
+		current, ok := byAccount[*l.AccountID]
+		if !ok {
+			accountIDs = append(accountIDs, *l.AccountID)
+		}
+		if !ok || *l.CreatedOn > *current.CreatedOn {
+			byAccount[*l.AccountID] = l
+		}
+	}
+
+	dayLeases := make([]*lease.Lease, 0, len(accountIDs))
+	for _, accountID := range accountIDs {
+		dayLeases = append(dayLeases, byAccount[accountID])
+	}
+	return dayLeases
+}
+
+// GetUsageByLease returns the lease's usage records from the start date
+// to the end date, including both days, with a single query of the LeaseId index.
+func (db *DB) GetUsageByLease(leaseID string, startDate time.Time, endDate time.Time) ([]*Usage, error) {
+	usageStartDate := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, time.UTC)
+	usageEndDate := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, time.UTC)
+	if usageEndDate.Before(usageStartDate) {
+		return []*Usage{}, nil
+	}
+
+	keyCondition := expression.Key("LeaseId").Equal(expression.Value(leaseID)).
+		And(expression.Key("StartDate").Between(expression.Value(usageStartDate.Unix()), expression.Value(usageEndDate.Unix())))
+	usageRecords, err := db.queryUsageIndex(LeaseIndexName, keyCondition)
+	if err != nil {
+		log.Printf("Failed to query usage records for lease \"%s\": %s.", leaseID, err)
+		return nil, err
+	}
+	return usageRecords, nil
+}
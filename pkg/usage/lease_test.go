@@ -0,0 +1,133 @@
+package usage_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/usage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageID(t *testing.T) {
+	assert.Equal(t, "user1#lease1", usage.UsageID("user1", "lease1"))
+
+	record := usageRecord("user1", "123456789012", "lease1", time.Now(), 10)
+	assert.Equal(t, "user1#lease1", record.ID())
+}
+
+func TestNewLeaseSpend(t *testing.T) {
+	may14 := time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC)
+	may15 := may14.AddDate(0, 0, 1)
+	l := &lease.Lease{
+		ID:          aws.String("lease1"),
+		PrincipalID: aws.String("user1"),
+		AccountID:   aws.String("123456789012"),
+	}
+
+	converted := func(record *usage.Usage, amount float64, currency string) *usage.Usage {
+		record.ConvertedCostAmount = aws.Float64(amount)
+		record.ConvertedCostCurrency = aws.String(currency)
+		return record
+	}
+
+	tests := []struct {
+		name                     string
+		records                  []*usage.Usage
+		expCostAmount            float64
+		expConvertedCostAmount   *float64
+		expConvertedCostCurrency string
+	}{
+		{
+			name: "should total the daily usage",
+			records: []*usage.Usage{
+				converted(usageRecord("user1", "123456789012", "lease1", may14, 10), 5, "EUR"),
+				converted(usageRecord("user1", "123456789012", "lease1", may15, 4), 2, "EUR"),
+			},
+			expCostAmount:            14,
+			expConvertedCostAmount:   aws.Float64(7),
+			expConvertedCostCurrency: "EUR",
+		},
+		{
+			name: "should only total converted costs when every record was converted",
+			records: []*usage.Usage{
+				converted(usageRecord("user1", "123456789012", "lease1", may14, 10), 5, "EUR"),
+				usageRecord("user1", "123456789012", "lease1", may15, 4),
+			},
+			expCostAmount: 14,
+		},
+		{
+			name: "should total no usage",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spend := usage.NewLeaseSpend(l, tt.records, may14.Add(6*time.Hour), may15.Add(time.Hour))
+			assert.Equal(t, "lease1", spend.LeaseID)
+			assert.Equal(t, "user1", spend.PrincipalID)
+			assert.Equal(t, "123456789012", spend.AccountID)
+			assert.Equal(t, may14.Unix(), spend.StartDate)
+			assert.Equal(t, may15.Add(24*time.Hour-time.Second).Unix(), spend.EndDate)
+			assert.Equal(t, tt.expCostAmount, spend.CostAmount)
+			assert.Equal(t, tt.expConvertedCostAmount, spend.ConvertedCostAmount)
+			assert.Equal(t, tt.expConvertedCostCurrency, spend.ConvertedCostCurrency)
+			assert.Len(t, spend.Usage, len(tt.records))
+		})
+	}
+}
+
+func TestFindLease(t *testing.T) {
+	may14 := time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC)
+	leases := lease.Leases{
+		{
+			ID:          aws.String("lease1"),
+			PrincipalID: aws.String("user1"),
+			AccountID:   aws.String("123456789012"),
+			Status:      lease.StatusActive.StatusPtr(),
+			CreatedOn:   aws.Int64(may14.AddDate(0, 0, -5).Unix()),
+		},
+		{
+			// Re-created on the same account, the same day
+			ID:          aws.String("lease2"),
+			PrincipalID: aws.String("user1"),
+			AccountID:   aws.String("123456789012"),
+			Status:      lease.StatusActive.StatusPtr(),
+			CreatedOn:   aws.Int64(may14.Add(6 * time.Hour).Unix()),
+		},
+	}
+
+	record := usageRecord("user1", "123456789012", "lease2", may14, 10)
+	assert.Equal(t, "lease2", *usage.FindLease(record, leases).ID)
+
+	record.LeaseID = aws.String("lease3")
+	assert.Nil(t, usage.FindLease(record, leases), "records of other leases have no lease")
+}
+
+func TestLeasesOnDay(t *testing.T) {
+	dayStart := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+	dayEnd := time.Date(2024, 5, 15, 23, 59, 59, 0, time.UTC)
+
+	leases := lease.Leases{
+		{ID: aws.String("ended before"), PrincipalID: aws.String("user1"), AccountID: aws.String("1"),
+			Status: lease.StatusInactive.StatusPtr(), CreatedOn: aws.Int64(dayStart.AddDate(0, 0, -5).Unix()),
+			StatusModifiedOn: aws.Int64(dayStart.Add(-time.Hour).Unix())},
+		{ID: aws.String("created after"), PrincipalID: aws.String("user1"), AccountID: aws.String("2"),
+			Status: lease.StatusActive.StatusPtr(), CreatedOn: aws.Int64(dayEnd.Add(time.Hour).Unix())},
+		{ID: aws.String("ended during"), PrincipalID: aws.String("user2"), AccountID: aws.String("3"),
+			Status: lease.StatusInactive.StatusPtr(), CreatedOn: aws.Int64(dayStart.AddDate(0, 0, -5).Unix()),
+			StatusModifiedOn: aws.Int64(dayStart.Add(time.Hour).Unix())},
+		{ID: aws.String("frozen"), PrincipalID: aws.String("user3"), AccountID: aws.String("4"),
+			Status: lease.StatusFrozen.StatusPtr(), CreatedOn: aws.Int64(dayStart.AddDate(0, 0, -5).Unix()),
+			StatusModifiedOn: aws.Int64(dayStart.AddDate(0, 0, -2).Unix())},
+		{ID: aws.String("created during"), PrincipalID: aws.String("user4"), AccountID: aws.String("3"),
+			Status: lease.StatusActive.StatusPtr(), CreatedOn: aws.Int64(dayStart.Add(2 * time.Hour).Unix())},
+	}
+
+	ids := []string{}
+	for _, l := range usage.LeasesOnDay(leases, dayStart, dayEnd) {
+		ids = append(ids, *l.ID)
+	}
+	assert.Equal(t, []string{"created during", "frozen"}, ids)
+}
@@ -2,6 +2,7 @@
 
 package mocks
 
+import budget "github.com/Optum/dce/pkg/budget"
 import mock "github.com/stretchr/testify/mock"
 import time "time"
 import usage "github.com/Optum/dce/pkg/usage"
@@ -11,6 +12,50 @@ type DBer struct {
 	mock.Mock
 }
 
+// GetPrincipalBudgetNotified provides a mock function with given fields: principalID, period, currentTime
+func (_m *DBer) GetPrincipalBudgetNotified(principalID string, period *budget.Period, currentTime time.Time) ([]float64, error) {
+	ret := _m.Called(principalID, period, currentTime)
+
+	var r0 []float64
+	if rf, ok := ret.Get(0).(func(string, *budget.Period, time.Time) []float64); ok {
+		r0 = rf(principalID, period, currentTime)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]float64)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, *budget.Period, time.Time) error); ok {
+		r1 = rf(principalID, period, currentTime)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPrincipalSpend provides a mock function with given fields: principalID, period, currentTime
+func (_m *DBer) GetPrincipalSpend(principalID string, period *budget.Period, currentTime time.Time) (float64, error) {
+	ret := _m.Called(principalID, period, currentTime)
+
+	var r0 float64
+	if rf, ok := ret.Get(0).(func(string, *budget.Period, time.Time) float64); ok {
+		r0 = rf(principalID, period, currentTime)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, *budget.Period, time.Time) error); ok {
+		r1 = rf(principalID, period, currentTime)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUsageByDateRange provides a mock function with given fields: startDate, endDate
 func (_m *DBer) GetUsageByDateRange(startDate time.Time, endDate time.Time) ([]*usage.Usage, error) {
 	ret := _m.Called(startDate, endDate)
@@ -34,6 +79,29 @@ func (_m *DBer) GetUsageByDateRange(startDate time.Time, endDate time.Time) ([]*
 	return r0, r1
 }
 
+// GetUsageByLease provides a mock function with given fields: leaseID, startDate, endDate
+func (_m *DBer) GetUsageByLease(leaseID string, startDate time.Time, endDate time.Time) ([]*usage.Usage, error) {
+	ret := _m.Called(leaseID, startDate, endDate)
+
+	var r0 []*usage.Usage
+	if rf, ok := ret.Get(0).(func(string, time.Time, time.Time) []*usage.Usage); ok {
+		r0 = rf(leaseID, startDate, endDate)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*usage.Usage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, time.Time, time.Time) error); ok {
+		r1 = rf(leaseID, startDate, endDate)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUsageByPrincipal provides a mock function with given fields: startDate, principalID
 func (_m *DBer) GetUsageByPrincipal(startDate time.Time, principalID string) ([]*usage.Usage, error) {
 	ret := _m.Called(startDate, principalID)
@@ -57,6 +125,43 @@ func (_m *DBer) GetUsageByPrincipal(startDate time.Time, principalID string) ([]
 	return r0, r1
 }
 
+// GetUsageByPrincipalAndDateRange provides a mock function with given fields: principalID, startDate, endDate
+func (_m *DBer) GetUsageByPrincipalAndDateRange(principalID string, startDate time.Time, endDate time.Time) ([]*usage.Usage, error) {
+	ret := _m.Called(principalID, startDate, endDate)
+
+	var r0 []*usage.Usage
+	if rf, ok := ret.Get(0).(func(string, time.Time, time.Time) []*usage.Usage); ok {
+		r0 = rf(principalID, startDate, endDate)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*usage.Usage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, time.Time, time.Time) error); ok {
+		r1 = rf(principalID, startDate, endDate)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkPrincipalBudgetNotified provides a mock function with given fields: principalID, period, currentTime, thresholds
+func (_m *DBer) MarkPrincipalBudgetNotified(principalID string, period *budget.Period, currentTime time.Time, thresholds []float64) error {
+	ret := _m.Called(principalID, period, currentTime, thresholds)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, *budget.Period, time.Time, []float64) error); ok {
+		r0 = rf(principalID, period, currentTime, thresholds)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // PutUsage provides a mock function with given fields: input
 func (_m *DBer) PutUsage(input usage.Usage) error {
 	ret := _m.Called(input)
@@ -70,3 +175,17 @@ func (_m *DBer) PutUsage(input usage.Usage) error {
 
 	return r0
 }
+
+// PutUsageBatch provides a mock function with given fields: input
+func (_m *DBer) PutUsageBatch(input []usage.Usage) error {
+	ret := _m.Called(input)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]usage.Usage) error); ok {
+		r0 = rf(input)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -1,22 +1,31 @@
 package usage
 
 import (
+	"time"
+
+	"github.com/Optum/dce/pkg/currency"
 	"github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
 	validation "github.com/go-ozzo/ozzo-validation"
 )
 
 // Usage item
 type Usage struct {
-	PrincipalID     *string  `json:"principalId,omitempty" dynamodbav:"PrincipalId" schema:"principalId,omitempty"`              // User Principal ID
-	AccountID       *string  `json:"accountId,omitempty" dynamodbav:"AccountId,omitempty" schema:"accountId,omitempty"`          // AWS Account ID
-	StartDate       *int64   `json:"startDate,omitempty" dynamodbav:"StartDate" schema:"startDate,omitempty"`                    // Usage start date Epoch Timestamp
-	EndDate         *int64   `json:"endDate,omitempty" dynamodbav:"EndDate,omitempty" schema:"endDate,omitempty"`                // Usage ends date Epoch Timestamp
-	CostAmount      *float64 `json:"costAmount,omitempty" dynamodbav:"CostAmount,omitempty" schema:"costAmount,omitempty"`       // Cost Amount for given period
-	CostCurrency    *string  `json:"costCurrency,omitempty" dynamodbav:"CostCurrency,omitempty" schema:"costCurrency,omitempty"` // Cost currency
-	TimeToLive      *int64   `json:"timeToLive,omitempty" dynamodbav:"TimeToLive,omitempty" schema:"timeToLive,omitempty"`       // ttl attribute
-	Limit           *int64   `json:"-" dynamodbav:"-" schema:"limit,omitempty"`
-	NextStartDate   *int64   `json:"-" dynamodbav:"-" schema:"nextStartDate,omitempty"`
-	NextPrincipalID *string  `json:"-" dynamodbav:"-" schema:"nextPrincipalId,omitempty"`
+	PrincipalID           *string            `json:"principalId,omitempty" dynamodbav:"PrincipalId" schema:"principalId,omitempty"`              // User Principal ID
+	AccountID             *string            `json:"accountId,omitempty" dynamodbav:"AccountId,omitempty" schema:"accountId,omitempty"`          // AWS Account ID
+	LeaseID               *string            `json:"leaseId,omitempty" dynamodbav:"LeaseId,omitempty" schema:"leaseId,omitempty"`                // Lease ID
+	UsageID               *string            `json:"-" dynamodbav:"UsageId" schema:"-"`                                                          // Identifies the record among the day's usage, set when it's written, see UsageID
+	StartDate             *int64             `json:"startDate,omitempty" dynamodbav:"StartDate" schema:"startDate,omitempty"`                    // Usage start date Epoch Timestamp
+	EndDate               *int64             `json:"endDate,omitempty" dynamodbav:"EndDate,omitempty" schema:"endDate,omitempty"`                // Usage ends date Epoch Timestamp
+	CostAmount            *float64           `json:"costAmount,omitempty" dynamodbav:"CostAmount,omitempty" schema:"costAmount,omitempty"`       // Cost Amount for given period
+	CostCurrency          *string            `json:"costCurrency,omitempty" dynamodbav:"CostCurrency,omitempty" schema:"costCurrency,omitempty"` // Cost currency
+	ConvertedCostAmount   *float64           `json:"convertedCostAmount,omitempty" dynamodbav:"ConvertedCostAmount,omitempty" schema:"-"`        // Cost amount in the lease's budget currency
+	ConvertedCostCurrency *string            `json:"convertedCostCurrency,omitempty" dynamodbav:"ConvertedCostCurrency,omitempty" schema:"-"`    // Lease's budget currency
+	ServiceCosts          map[string]float64 `json:"serviceCosts,omitempty" dynamodbav:"ServiceCosts,omitempty" schema:"-"`                      // Cost amount by AWS service, in the cost currency
+	TimeToLive            *int64             `json:"timeToLive,omitempty" dynamodbav:"TimeToLive,omitempty" schema:"timeToLive,omitempty"`       // ttl attribute
+	Limit                 *int64             `json:"-" dynamodbav:"-" schema:"limit,omitempty"`
+	NextStartDate         *int64             `json:"-" dynamodbav:"-" schema:"nextStartDate,omitempty"`
+	NextPrincipalID       *string            `json:"-" dynamodbav:"-" schema:"nextPrincipalId,omitempty"`
 }
 
 // Validate the account data
@@ -40,11 +49,18 @@ func (u *Usage) Validate() error {
 type NewUsageInput struct {
 	PrincipalID  string
 	AccountID    string
+	LeaseID      string
 	StartDate    int64
 	EndDate      int64
 	CostAmount   float64
 	CostCurrency string
 	TimeToLive   int64
+	// Cost amount converted to the lease's budget currency.
+	// Only stored when ConvertedCostCurrency is set.
+	ConvertedCostAmount   float64
+	ConvertedCostCurrency string
+	// Cost amount by AWS service name, in the cost currency
+	ServiceCosts map[string]float64
 }
 
 // NewUsage creates a new instance of usage
@@ -58,6 +74,14 @@ func NewUsage(input NewUsageInput) (*Usage, error) {
 		CostAmount:   &input.CostAmount,
 		CostCurrency: &input.CostCurrency,
 		TimeToLive:   &input.TimeToLive,
+		ServiceCosts: input.ServiceCosts,
+	}
+	if input.LeaseID != "" {
+		new.LeaseID = &input.LeaseID
+	}
+	if input.ConvertedCostCurrency != "" {
+		new.ConvertedCostAmount = &input.ConvertedCostAmount
+		new.ConvertedCostCurrency = &input.ConvertedCostCurrency
 	}
 
 	err := new.Validate()
@@ -68,5 +92,72 @@ func NewUsage(input NewUsageInput) (*Usage, error) {
 
 }
 
+// NewLeaseUsageInput has the input to create the usage record of a lease
+type NewLeaseUsageInput struct {
+	Lease        *lease.Lease
+	StartDate    time.Time
+	EndDate      time.Time
+	TimeToLive   int64
+	ServiceCosts map[string]float64 // Spend of the lease's account by AWS service, in the converter's base currency
+	Converter    *currency.Converter
+}
+
+// NewLeaseUsage creates the usage record of a lease, from its account's spend by AWS service.
+// The cost is converted to the lease's budget currency too.
+func NewLeaseUsage(input NewLeaseUsageInput) (*Usage, error) {
+	costAmount := 0.0
+	for _, cost := range input.ServiceCosts {
+		costAmount = costAmount + cost
+	}
+
+	// Costs are reported in the cost currency, but the lease may budget in another one
+	costCurrency := input.Converter.BaseCurrency
+	leaseCurrency := costCurrency
+	if input.Lease.BudgetCurrency != nil && *input.Lease.BudgetCurrency != "" {
+		leaseCurrency = *input.Lease.BudgetCurrency
+	}
+	leaseCostAmount, err := input.Converter.Convert(costAmount, costCurrency, leaseCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	leaseID := ""
+	if input.Lease.ID != nil {
+		leaseID = *input.Lease.ID
+	}
+
+	return NewUsage(NewUsageInput{
+		StartDate:             input.StartDate.Unix(),
+		EndDate:               input.EndDate.Unix(),
+		PrincipalID:           *input.Lease.PrincipalID,
+		AccountID:             *input.Lease.AccountID,
+		LeaseID:               leaseID,
+		CostAmount:            costAmount,
+		CostCurrency:          costCurrency,
+		ConvertedCostAmount:   leaseCostAmount,
+		ConvertedCostCurrency: leaseCurrency,
+		TimeToLive:            input.TimeToLive,
+		ServiceCosts:          input.ServiceCosts,
+	})
+}
+
+// UsageID identifies a usage record among the day's usage records, which share its StartDate.
+// Each lease has its own record, so a principal with two leases on the same day has two.
+func UsageID(principalID string, leaseID string) string {
+	return principalID + "#" + leaseID
+}
+
+// ID returns the record's UsageID
+func (u *Usage) ID() string {
+	principalID, leaseID := "", ""
+	if u.PrincipalID != nil {
+		principalID = *u.PrincipalID
+	}
+	if u.LeaseID != nil {
+		leaseID = *u.LeaseID
+	}
+	return UsageID(principalID, leaseID)
+}
+
 // Usages is a list of type Usage
 type Usages []Usage
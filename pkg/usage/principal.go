@@ -0,0 +1,355 @@
+package usage
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/Optum/dce/pkg/budget"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// PrincipalIndexName is the Usage table index keyed by PrincipalId and StartDate
+const PrincipalIndexName = "PrincipalIdStartDate"
+
+// maxPrincipalUsageAttempts is the most times a principal's period total is re-read
+// and written again, when another writer updated it first
+const maxPrincipalUsageAttempts = 5
+
+// principalUsage is a principal's total usage for a principal budget period.
+// It's updated whenever the principal's usage is written, so principal budgets are checked with a single read.
+type principalUsage struct {
+	PrincipalID       string             `dynamodbav:"PrincipalId"`
+	PeriodKey         string             `dynamodbav:"PeriodKey"`       // Budget period type, start date and time zone, eg. WEEKLY#2024-05-12#UTC. See notificationPeriodKey for ROLLING periods
+	PeriodStart       int64              `dynamodbav:"PeriodStart"`     // Budget period start Epoch Timestamp
+	DailyCosts        map[string]float64 `dynamodbav:"DailyCosts"`      // Cost amount of each usage record, by its start date Epoch Timestamp and lease, see dailyCostKey
+	TotalCostAmount   float64            `dynamodbav:"TotalCostAmount"` // Total of the daily costs
+	CostCurrency      string             `dynamodbav:"CostCurrency,omitempty"`
+	NotificationsSent []float64          `dynamodbav:"NotificationsSent,omitempty"` // Principal budget threshold percentiles the principal was notified of in the period, highest first
+	Version           int64              `dynamodbav:"Version"`                     // Incremented on every write, so concurrent writes don't overwrite each other
+	TimeToLive        int64              `dynamodbav:"TimeToLive,omitempty"`
+}
+
+// principalPeriodKey identifies the budget period a day is in, eg. WEEKLY#2024-05-12#UTC.
+// Rolling periods start on a different day every day, so their totals can't be kept.
+func principalPeriodKey(period *budget.Period, day time.Time) (string, time.Time, bool) {
+	if period == nil || period.Type == budget.PeriodRolling {
+		return "", time.Time{}, false
+	}
+	start := period.Start(day)
+	return fmt.Sprintf("%s#%s#%s", period.Type, start.Format("2006-01-02"), period.Location), start, true
+}
+
+// notificationPeriodKey identifies the budget period principal budget notifications are recorded for.
+// Rolling periods don't have a start date, so their notifications are recorded once per principal, eg. ROLLING#30#UTC.
+func notificationPeriodKey(period *budget.Period, day time.Time) string {
+	key, _, ok := principalPeriodKey(period, day)
+	if !ok {
+		key = fmt.Sprintf("%s#%d#%s", period.Type, period.Days, period.Location)
+	}
+	return key
+}
+
+// usageDay is the day a usage record is for, in the time zone given.
+// Usage start dates are midnight UTC, and budget periods count whole days in their own time zone.
+func usageDay(startDate int64, location *time.Location) time.Time {
+	day := time.Unix(startDate, 0).UTC()
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, location)
+}
+
+// dailyCostKey identifies a usage record in a principal's period total, eg. 1715731200#<lease ID>
+func dailyCostKey(u Usage) string {
+	return strconv.FormatInt(*u.StartDate, 10) + "#" + *u.LeaseID
+}
+
+// applyDailyCosts sets the cost of each record, and totals them.
+// Returns false if the total already had those costs.
+func applyDailyCosts(total *principalUsage, records []Usage) bool {
+	if total.DailyCosts == nil {
+		total.DailyCosts = map[string]float64{}
+	}
+
+	changed := false
+	for _, u := range records {
+		key := dailyCostKey(u)
+		cost, ok := total.DailyCosts[key]
+		if !ok || cost != *u.CostAmount {
+			total.DailyCosts[key] = *u.CostAmount
+			changed = true
+		}
+		if u.CostCurrency != nil && total.CostCurrency != *u.CostCurrency {
+			total.CostCurrency = *u.CostCurrency
+			changed = true
+		}
+		// Keep the total as long as the usage it adds up
+		if u.TimeToLive != nil && *u.TimeToLive > total.TimeToLive {
+			total.TimeToLive = *u.TimeToLive
+		}
+	}
+
+	total.TotalCostAmount = 0
+	for _, cost := range total.DailyCosts {
+		total.TotalCostAmount += cost
+	}
+	return changed
+}
+
+// updatePrincipalUsage adds the usage records to their principal's budget period totals.
+// Totals are only kept when the DB has a PrincipalUsageTableName and principal budget period.
+func (db *DB) updatePrincipalUsage(records []Usage) error {
+	if db.PrincipalUsageTableName == "" || db.PrincipalBudgetPeriod == nil {
+		return nil
+	}
+
+	type periodRecords struct {
+		principalID string
+		key         string
+		start       time.Time
+		records     []Usage
+	}
+	periods := map[string]*periodRecords{}
+	keys := []string{}
+	for _, u := range records {
+		if u.PrincipalID == nil || u.StartDate == nil || u.CostAmount == nil {
+			continue
+		}
+		key, start, ok := principalPeriodKey(db.PrincipalBudgetPeriod, usageDay(*u.StartDate, db.PrincipalBudgetPeriod.Location))
+		if !ok {
+			return nil
+		}
+		id := *u.PrincipalID + "#" + key
+		if periods[id] == nil {
+			periods[id] = &periodRecords{principalID: *u.PrincipalID, key: key, start: start}
+			keys = append(keys, id)
+		}
+		periods[id].records = append(periods[id].records, u)
+	}
+
+	for _, id := range keys {
+		p := periods[id]
+		err := db.updatePrincipalPeriod(p.principalID, p.key, p.start, p.records)
+		if err != nil {
+			log.Printf("Failed to update usage total for principal %s, period %s: %s", p.principalID, p.key, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// updatePrincipalPeriod sets the records' costs in the principal's period total.
+// The first time a period's total is written, it's seeded from the principal's usage records,
+// so usage written before totals were kept still counts.
+func (db *DB) updatePrincipalPeriod(principalID string, key string, start time.Time, records []Usage) error {
+	for attempt := 1; attempt <= maxPrincipalUsageAttempts; attempt++ {
+		total, err := db.getPrincipalUsage(principalID, key)
+		if err != nil {
+			return err
+		}
+
+		condition := expression.Name("PrincipalId").AttributeNotExists()
+		if total == nil {
+			total = &principalUsage{
+				PrincipalID: principalID,
+				PeriodKey:   key,
+			}
+		} else {
+			condition = expression.Name("Version").Equal(expression.Value(total.Version))
+		}
+		// Notifications may be recorded before the period's first usage is written
+		seeded := total.PeriodStart == 0
+		if seeded {
+			total.PeriodStart = start.Unix()
+			existing, err := db.GetUsageByPrincipalAndDateRange(principalID, start, db.PrincipalBudgetPeriod.End(start).AddDate(0, 0, -1))
+			if err != nil {
+				return err
+			}
+			seed := []Usage{}
+			for _, u := range existing {
+				if u.StartDate != nil && u.CostAmount != nil {
+					seed = append(seed, *u)
+				}
+			}
+			applyDailyCosts(total, seed)
+		}
+
+		if !applyDailyCosts(total, records) && !seeded && total.Version > 0 {
+			return nil
+		}
+		total.Version++
+
+		item, err := dynamodbattribute.MarshalMap(total)
+		if err != nil {
+			return err
+		}
+		expr, err := expression.NewBuilder().WithCondition(condition).Build()
+		if err != nil {
+			return err
+		}
+		_, err = db.Client.PutItem(&dynamodb.PutItemInput{
+			TableName:                 aws.String(db.PrincipalUsageTableName),
+			Item:                      item,
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		})
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			// Another writer updated the total first
+			continue
+		}
+		return err
+	}
+	return fmt.Errorf("failed to update usage total for principal %s after %d attempts", principalID, maxPrincipalUsageAttempts)
+}
+
+// getPrincipalUsage returns the principal's period total, or nil if there isn't one
+func (db *DB) getPrincipalUsage(principalID string, key string) (*principalUsage, error) {
+	output, err := db.Client.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(db.PrincipalUsageTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"PrincipalId": {S: aws.String(principalID)},
+			"PeriodKey":   {S: aws.String(key)},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(output.Item) == 0 {
+		return nil, nil
+	}
+
+	total := &principalUsage{}
+	err = dynamodbattribute.UnmarshalMap(output.Item, total)
+	if err != nil {
+		return nil, err
+	}
+	return total, nil
+}
+
+// GetUsageByPrincipalAndDateRange returns the principal's usage records from the start date
+// to the end date, including both days, with a single query of the PrincipalId index.
+func (db *DB) GetUsageByPrincipalAndDateRange(principalID string, startDate time.Time, endDate time.Time) ([]*Usage, error) {
+	usageStartDate := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, time.UTC)
+	usageEndDate := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, time.UTC)
+	if usageEndDate.Before(usageStartDate) {
+		return []*Usage{}, nil
+	}
+
+	keyCondition := expression.Key("PrincipalId").Equal(expression.Value(principalID)).
+		And(expression.Key("StartDate").Between(expression.Value(usageStartDate.Unix()), expression.Value(usageEndDate.Unix())))
+	usageRecords, err := db.queryUsageIndex(PrincipalIndexName, keyCondition)
+	if err != nil {
+		log.Printf("Failed to query usage records for principal \"%s\": %s.", principalID, err)
+		return nil, err
+	}
+	return usageRecords, nil
+}
+
+// queryUsageIndex returns the usage records matching the key condition of one of the Usage table's indexes
+func (db *DB) queryUsageIndex(indexName string, keyCondition expression.KeyConditionBuilder) ([]*Usage, error) {
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	usageRecords := []*Usage{}
+	var unmarshalErr error
+	err = db.Client.QueryPages(&dynamodb.QueryInput{
+		TableName:                 aws.String(db.UsageTableName),
+		IndexName:                 aws.String(indexName),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			u, err := unmarshalUsageRecord(item)
+			if err != nil {
+				unmarshalErr = err
+				return false
+			}
+			usageRecords = append(usageRecords, u)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return usageRecords, unmarshalErr
+}
+
+// GetPrincipalSpend returns the principal's spend in the budget period, in the cost currency, up to the current time.
+// It reads the period's total when one is kept, and otherwise adds up the principal's usage records.
+func (db *DB) GetPrincipalSpend(principalID string, period *budget.Period, currentTime time.Time) (float64, error) {
+	if db.PrincipalUsageTableName != "" {
+		key, _, ok := principalPeriodKey(period, currentTime)
+		if ok {
+			total, err := db.getPrincipalUsage(principalID, key)
+			if err != nil {
+				return 0, err
+			}
+			if total != nil && total.PeriodStart != 0 {
+				return total.TotalCostAmount, nil
+			}
+		}
+	}
+
+	usageRecords, err := db.GetUsageByPrincipalAndDateRange(principalID, period.Start(currentTime), currentTime.In(period.Location))
+	if err != nil {
+		return 0, err
+	}
+	spend := 0.0
+	for _, u := range usageRecords {
+		if u.CostAmount != nil {
+			spend += *u.CostAmount
+		}
+	}
+	return spend, nil
+}
+
+// GetPrincipalBudgetNotified returns the principal budget threshold percentiles
+// the principal was notified of in the budget period, highest first.
+func (db *DB) GetPrincipalBudgetNotified(principalID string, period *budget.Period, currentTime time.Time) ([]float64, error) {
+	if db.PrincipalUsageTableName == "" {
+		return nil, fmt.Errorf("principal budget notifications are recorded in the PrincipalUsage table, which isn't configured")
+	}
+	total, err := db.getPrincipalUsage(principalID, notificationPeriodKey(period, currentTime))
+	if err != nil || total == nil {
+		return nil, err
+	}
+	return total.NotificationsSent, nil
+}
+
+// MarkPrincipalBudgetNotified records the principal budget threshold percentiles
+// the principal was notified of in the budget period, replacing the ones recorded before.
+// It's recorded once per principal, so a principal with several leases is only notified once.
+func (db *DB) MarkPrincipalBudgetNotified(principalID string, period *budget.Period, currentTime time.Time, thresholds []float64) error {
+	if db.PrincipalUsageTableName == "" {
+		return fmt.Errorf("principal budget notifications are recorded in the PrincipalUsage table, which isn't configured")
+	}
+	if thresholds == nil {
+		thresholds = []float64{}
+	}
+	// Bump the version, so a concurrent usage total update doesn't overwrite the thresholds
+	update := expression.Set(expression.Name("NotificationsSent"), expression.Value(thresholds)).
+		Set(expression.Name("Version"), expression.Plus(expression.IfNotExists(expression.Name("Version"), expression.Value(0)), expression.Value(1)))
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	if err != nil {
+		return err
+	}
+	_, err = db.Client.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(db.PrincipalUsageTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"PrincipalId": {S: aws.String(principalID)},
+			"PeriodKey":   {S: aws.String(notificationPeriodKey(period, currentTime))},
+		},
+		UpdateExpression:          expr.Update(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	return err
+}
@@ -0,0 +1,109 @@
+package usage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Optum/dce/pkg/budget"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipalPeriodKey(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	assert.Nil(t, err)
+	may15 := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		period   *budget.Period
+		day      time.Time
+		expKey   string
+		expStart time.Time
+		expOk    bool
+	}{
+		{
+			name:     "should key weekly periods by their first day",
+			period:   &budget.Period{Type: budget.PeriodWeekly, WeekStart: time.Sunday, Location: time.UTC},
+			day:      may15,
+			expKey:   "WEEKLY#2024-05-12#UTC",
+			expStart: time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC),
+			expOk:    true,
+		},
+		{
+			name:     "should key periods in their time zone",
+			period:   &budget.Period{Type: budget.PeriodMonthly, Location: chicago},
+			day:      usageDay(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC).Unix(), chicago),
+			expKey:   "MONTHLY#2024-06-01#America/Chicago",
+			expStart: time.Date(2024, 6, 1, 0, 0, 0, 0, chicago),
+			expOk:    true,
+		},
+		{
+			name:   "should not key rolling periods",
+			period: &budget.Period{Type: budget.PeriodRolling, Days: 30, Location: time.UTC},
+			day:    may15,
+		},
+		{
+			name: "should not key without a period",
+			day:  may15,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, start, ok := principalPeriodKey(tt.period, tt.day)
+			assert.Equal(t, tt.expOk, ok)
+			assert.Equal(t, tt.expKey, key)
+			assert.True(t, tt.expStart.Equal(start), "expected start %s, got %s", tt.expStart, start)
+		})
+	}
+}
+
+func TestNotificationPeriodKey(t *testing.T) {
+	may15 := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+
+	weekly := &budget.Period{Type: budget.PeriodWeekly, WeekStart: time.Sunday, Location: time.UTC}
+	assert.Equal(t, "WEEKLY#2024-05-12#UTC", notificationPeriodKey(weekly, may15), "notifications are recorded with the period's total")
+
+	rolling := &budget.Period{Type: budget.PeriodRolling, Days: 30, Location: time.UTC}
+	assert.Equal(t, "ROLLING#30#UTC", notificationPeriodKey(rolling, may15))
+	assert.Equal(t, "ROLLING#30#UTC", notificationPeriodKey(rolling, may15.AddDate(0, 0, 1)), "rolling periods keep a single record")
+}
+
+func TestApplyDailyCosts(t *testing.T) {
+	may14 := time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC).Unix()
+	may15 := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC).Unix()
+
+	total := &principalUsage{}
+	changed := applyDailyCosts(total, []Usage{
+		{StartDate: aws.Int64(may14), LeaseID: aws.String("lease1"), CostAmount: aws.Float64(10), CostCurrency: aws.String("USD"), TimeToLive: aws.Int64(100)},
+		{StartDate: aws.Int64(may15), LeaseID: aws.String("lease1"), CostAmount: aws.Float64(5), CostCurrency: aws.String("USD"), TimeToLive: aws.Int64(200)},
+	})
+	assert.True(t, changed)
+	assert.Equal(t, 15.0, total.TotalCostAmount)
+	assert.Equal(t, "USD", total.CostCurrency)
+	assert.Equal(t, int64(200), total.TimeToLive)
+
+	// Writing the same usage again doesn't change the total
+	changed = applyDailyCosts(total, []Usage{
+		{StartDate: aws.Int64(may15), LeaseID: aws.String("lease1"), CostAmount: aws.Float64(5), CostCurrency: aws.String("USD"), TimeToLive: aws.Int64(200)},
+	})
+	assert.False(t, changed)
+	assert.Equal(t, 15.0, total.TotalCostAmount)
+
+	// Updated usage replaces the day's cost, rather than adding to it
+	changed = applyDailyCosts(total, []Usage{
+		{StartDate: aws.Int64(may15), LeaseID: aws.String("lease1"), CostAmount: aws.Float64(8), CostCurrency: aws.String("USD")},
+	})
+	assert.True(t, changed)
+	assert.Equal(t, 18.0, total.TotalCostAmount)
+	assert.Equal(t, map[string]float64{"1715644800#lease1": 10, "1715731200#lease1": 8}, total.DailyCosts)
+
+	// Each lease's usage counts, when the principal has two leases on the same day
+	changed = applyDailyCosts(total, []Usage{
+		{StartDate: aws.Int64(may15), LeaseID: aws.String("lease2"), CostAmount: aws.Float64(4), CostCurrency: aws.String("USD")},
+	})
+	assert.True(t, changed)
+	assert.Equal(t, 22.0, total.TotalCostAmount)
+	assert.Equal(t, 4.0, total.DailyCosts["1715731200#lease2"])
+}
@@ -83,7 +83,7 @@ func (a *Service) Create(data *Usage) (*Usage, error) {
 		}
 	}
 
-	new, err := NewUsage(NewUsageInput{
+	input := NewUsageInput{
 		StartDate:    *data.StartDate,
 		PrincipalID:  *data.PrincipalID,
 		AccountID:    *data.AccountID,
@@ -91,7 +91,13 @@ func (a *Service) Create(data *Usage) (*Usage, error) {
 		CostAmount:   *data.CostAmount,
 		CostCurrency: *data.CostCurrency,
 		TimeToLive:   *data.TimeToLive,
-	})
+		ServiceCosts: data.ServiceCosts,
+	}
+	if data.ConvertedCostAmount != nil && data.ConvertedCostCurrency != nil {
+		input.ConvertedCostAmount = *data.ConvertedCostAmount
+		input.ConvertedCostCurrency = *data.ConvertedCostCurrency
+	}
+	new, err := NewUsage(input)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,241 @@
+package usage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
+)
+
+// Dimensions usage summaries may be grouped by
+const (
+	GroupByPrincipal = "principal"
+	GroupByAccount   = "account"
+	GroupByLease     = "lease"
+	GroupByDay       = "day"
+	GroupByWeek      = "week"
+	GroupByMonth     = "month"
+	// GroupByMetadataPrefix groups by a lease Metadata key, eg. "metadata.costCenter"
+	GroupByMetadataPrefix = "metadata."
+)
+
+// defaultSummaryLimit is the number of groups in a page of a usage summary, unless a limit is given
+const defaultSummaryLimit = 100
+
+// maxSummaryDays is the longest date range a usage summary may cover
+const maxSummaryDays = 366
+
+// LeaseLister lists leases, so usage can be grouped by lease
+type LeaseLister interface {
+	ListPages(query *lease.Lease, fn func(*lease.Leases) bool) error
+}
+
+// SummaryInput has the filters, groupings and page of a usage summary
+type SummaryInput struct {
+	StartDate   time.Time
+	EndDate     time.Time
+	PrincipalID string
+	AccountID   string
+	GroupBy     []string
+	Limit       int64
+	Offset      int64
+}
+
+// SummaryGroup is the total cost of the usage in a group
+type SummaryGroup struct {
+	Keys       map[string]string `json:"keys"`       // Value of each grouping, eg. {"principal": "jdoe", "month": "2024-05"}
+	CostAmount float64           `json:"costAmount"` // Total cost of the group's usage
+}
+
+// Summary is a page of the groups of a usage summary
+type Summary struct {
+	StartDate       int64          `json:"startDate"`       // Summary start date Epoch Timestamp
+	EndDate         int64          `json:"endDate"`         // Summary end date Epoch Timestamp
+	GroupBy         []string       `json:"groupBy"`         // Dimensions the usage is grouped by
+	Groups          []SummaryGroup `json:"groups"`          // Page of the groups
+	TotalCostAmount float64        `json:"totalCostAmount"` // Total cost of all the groups, not only this page
+	CostCurrency    string         `json:"costCurrency"`    // Cost currency
+	NextOffset      *int64         `json:"-"`               // Offset of the next page, if there is one
+}
+
+// Validate the summary input
+func (input *SummaryInput) Validate() error {
+	if input.EndDate.Before(input.StartDate) {
+		return errors.NewValidation("usage", fmt.Errorf("startDate must be before endDate"))
+	}
+	if input.EndDate.Sub(input.StartDate) > maxSummaryDays*24*time.Hour {
+		return errors.NewValidation("usage", fmt.Errorf("usage may only be summarized for up to %d days", maxSummaryDays))
+	}
+	if input.Limit < 0 || input.Offset < 0 {
+		return errors.NewValidation("usage", fmt.Errorf("limit and offset must not be negative"))
+	}
+	for _, groupBy := range input.GroupBy {
+		switch {
+		case groupBy == GroupByPrincipal, groupBy == GroupByAccount, groupBy == GroupByLease,
+			groupBy == GroupByDay, groupBy == GroupByWeek, groupBy == GroupByMonth:
+		case strings.HasPrefix(groupBy, GroupByMetadataPrefix) && len(groupBy) > len(GroupByMetadataPrefix):
+		default:
+			return errors.NewValidation("usage", fmt.Errorf("unknown groupBy %q, expected one of %s, %s, %s, %s, %s, %s or %s<key>",
+				groupBy, GroupByPrincipal, GroupByAccount, GroupByLease, GroupByDay, GroupByWeek, GroupByMonth, GroupByMetadataPrefix))
+		}
+	}
+	return nil
+}
+
+// needsLeases is true when the usage is grouped by its lease, or the lease's metadata
+func (input *SummaryInput) needsLeases() bool {
+	for _, groupBy := range input.GroupBy {
+		if groupBy == GroupByLease || strings.HasPrefix(groupBy, GroupByMetadataPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUsageSummary totals the usage between the dates, for each group.
+// The leases are only listed when grouping by lease or lease metadata.
+func (db *DB) GetUsageSummary(input SummaryInput, leaseSvc LeaseLister) (*Summary, error) {
+	err := input.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := db.GetUsageByDateRange(input.StartDate, input.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	leases := lease.Leases{}
+	if input.needsLeases() {
+		query := &lease.Lease{}
+		if input.PrincipalID != "" {
+			query.PrincipalID = &input.PrincipalID
+		}
+		if input.AccountID != "" {
+			query.AccountID = &input.AccountID
+		}
+		err = leaseSvc.ListPages(query, func(page *lease.Leases) bool {
+			leases = append(leases, *page...)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return Summarize(records, leases, input), nil
+}
+
+// Summarize totals the usage records matching the input's filters, for each group,
+// and returns the input's page of the groups, ordered by their keys.
+// Usage is attributed to the principal's lease of the account at the time.
+func Summarize(records []*Usage, leases lease.Leases, input SummaryInput) *Summary {
+	summary := &Summary{
+		StartDate: input.StartDate.Unix(),
+		EndDate:   input.EndDate.Unix(),
+		GroupBy:   input.GroupBy,
+		Groups:    []SummaryGroup{},
+	}
+
+	groups := map[string]*SummaryGroup{}
+	for _, record := range records {
+		if record.PrincipalID == nil || record.AccountID == nil || record.StartDate == nil || record.CostAmount == nil {
+			continue
+		}
+		if input.PrincipalID != "" && *record.PrincipalID != input.PrincipalID {
+			continue
+		}
+		if input.AccountID != "" && *record.AccountID != input.AccountID {
+			continue
+		}
+		if summary.CostCurrency == "" && record.CostCurrency != nil {
+			summary.CostCurrency = *record.CostCurrency
+		}
+
+		keys := summaryKeys(record, FindLease(record, leases), input.GroupBy)
+		id := groupID(keys, input.GroupBy)
+		if groups[id] == nil {
+			groups[id] = &SummaryGroup{Keys: keys}
+		}
+		groups[id].CostAmount += *record.CostAmount
+		summary.TotalCostAmount += *record.CostAmount
+	}
+
+	ids := make([]string, 0, len(groups))
+	for id := range groups {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultSummaryLimit
+	}
+	for i := input.Offset; i < int64(len(ids)) && i < input.Offset+limit; i++ {
+		summary.Groups = append(summary.Groups, *groups[ids[i]])
+	}
+	if next := input.Offset + limit; next < int64(len(ids)) {
+		summary.NextOffset = &next
+	}
+
+	return summary
+}
+
+// summaryKeys returns the value of each grouping for the usage record
+func summaryKeys(record *Usage, l *lease.Lease, groupBy []string) map[string]string {
+	day := time.Unix(*record.StartDate, 0).UTC()
+	keys := map[string]string{}
+	for _, dimension := range groupBy {
+		switch {
+		case dimension == GroupByPrincipal:
+			keys[dimension] = *record.PrincipalID
+		case dimension == GroupByAccount:
+			keys[dimension] = *record.AccountID
+		case dimension == GroupByDay:
+			keys[dimension] = day.Format("2006-01-02")
+		case dimension == GroupByWeek:
+			year, week := day.ISOWeek()
+			keys[dimension] = fmt.Sprintf("%d-W%02d", year, week)
+		case dimension == GroupByMonth:
+			keys[dimension] = day.Format("2006-01")
+		case dimension == GroupByLease:
+			keys[dimension] = ""
+			if record.LeaseID != nil {
+				keys[dimension] = *record.LeaseID
+			}
+		case strings.HasPrefix(dimension, GroupByMetadataPrefix):
+			keys[dimension] = ""
+			if l != nil {
+				if value, ok := l.Metadata[strings.TrimPrefix(dimension, GroupByMetadataPrefix)]; ok && value != nil {
+					keys[dimension] = fmt.Sprint(value)
+				}
+			}
+		}
+	}
+	return keys
+}
+
+// groupID identifies a group by its keys, in the order they're grouped by
+func groupID(keys map[string]string, groupBy []string) string {
+	values := make([]string, len(groupBy))
+	for i, dimension := range groupBy {
+		values[i] = keys[dimension]
+	}
+	return strings.Join(values, "\x00")
+}
+
+// FindLease returns the record's lease, or nil if it isn't one of the leases
+func FindLease(record *Usage, leases lease.Leases) *lease.Lease {
+	if record.LeaseID == nil {
+		return nil
+	}
+	for i := range leases {
+		if leases[i].ID != nil && *leases[i].ID == *record.LeaseID {
+			return &leases[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package usage_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/usage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func usageRecord(principalID string, accountID string, leaseID string, day time.Time, cost float64) *usage.Usage {
+	return &usage.Usage{
+		PrincipalID:  aws.String(principalID),
+		AccountID:    aws.String(accountID),
+		LeaseID:      aws.String(leaseID),
+		StartDate:    aws.Int64(day.Unix()),
+		CostAmount:   aws.Float64(cost),
+		CostCurrency: aws.String("USD"),
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	may14 := time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC)
+	may20 := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	june3 := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+
+	records := []*usage.Usage{
+		usageRecord("user1", "123456789012", "lease1", may14, 10),
+		usageRecord("user1", "123456789012", "lease1", may20, 20),
+		usageRecord("user1", "123456789012", "lease2", june3, 5),
+		// The lease was deleted
+		usageRecord("user2", "210987654321", "lease3", may20, 7),
+	}
+	leases := lease.Leases{
+		{
+			ID:               aws.String("lease1"),
+			PrincipalID:      aws.String("user1"),
+			AccountID:        aws.String("123456789012"),
+			Status:           lease.StatusInactive.StatusPtr(),
+			CreatedOn:        aws.Int64(may14.Add(6 * time.Hour).Unix()),
+			StatusModifiedOn: aws.Int64(may20.Add(12 * time.Hour).Unix()),
+			Metadata:         map[string]interface{}{"costCenter": "1234"},
+		},
+		{
+			ID:          aws.String("lease2"),
+			PrincipalID: aws.String("user1"),
+			AccountID:   aws.String("123456789012"),
+			Status:      lease.StatusActive.StatusPtr(),
+			CreatedOn:   aws.Int64(june3.Unix()),
+			Metadata:    map[string]interface{}{"costCenter": 5678},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		input     usage.SummaryInput
+		expGroups []usage.SummaryGroup
+		expTotal  float64
+		expNext   *int64
+	}{
+		{
+			name:  "should total usage without groupings",
+			input: usage.SummaryInput{},
+			expGroups: []usage.SummaryGroup{
+				{Keys: map[string]string{}, CostAmount: 42},
+			},
+			expTotal: 42,
+		},
+		{
+			name:  "should group by principal and month",
+			input: usage.SummaryInput{GroupBy: []string{usage.GroupByPrincipal, usage.GroupByMonth}},
+			expGroups: []usage.SummaryGroup{
+				{Keys: map[string]string{"principal": "user1", "month": "2024-05"}, CostAmount: 30},
+				{Keys: map[string]string{"principal": "user1", "month": "2024-06"}, CostAmount: 5},
+				{Keys: map[string]string{"principal": "user2", "month": "2024-05"}, CostAmount: 7},
+			},
+			expTotal: 42,
+		},
+		{
+			name:  "should group by week, filtered by account",
+			input: usage.SummaryInput{AccountID: "123456789012", GroupBy: []string{usage.GroupByWeek}},
+			expGroups: []usage.SummaryGroup{
+				{Keys: map[string]string{"week": "2024-W20"}, CostAmount: 10},
+				{Keys: map[string]string{"week": "2024-W21"}, CostAmount: 20},
+				{Keys: map[string]string{"week": "2024-W23"}, CostAmount: 5},
+			},
+			expTotal: 35,
+		},
+		{
+			name:  "should group by lease and its metadata",
+			input: usage.SummaryInput{GroupBy: []string{"metadata.costCenter", usage.GroupByLease}},
+			expGroups: []usage.SummaryGroup{
+				{Keys: map[string]string{"metadata.costCenter": "", "lease": "lease3"}, CostAmount: 7},
+				{Keys: map[string]string{"metadata.costCenter": "1234", "lease": "lease1"}, CostAmount: 30},
+				{Keys: map[string]string{"metadata.costCenter": "5678", "lease": "lease2"}, CostAmount: 5},
+			},
+			expTotal: 42,
+		},
+		{
+			name:  "should page the groups, and total all of them",
+			input: usage.SummaryInput{GroupBy: []string{usage.GroupByDay}, Limit: 2},
+			expGroups: []usage.SummaryGroup{
+				{Keys: map[string]string{"day": "2024-05-14"}, CostAmount: 10},
+				{Keys: map[string]string{"day": "2024-05-20"}, CostAmount: 27},
+			},
+			expTotal: 42,
+			expNext:  aws.Int64(2),
+		},
+		{
+			name:  "should return the last page",
+			input: usage.SummaryInput{GroupBy: []string{usage.GroupByDay}, Limit: 2, Offset: 2},
+			expGroups: []usage.SummaryGroup{
+				{Keys: map[string]string{"day": "2024-06-03"}, CostAmount: 5},
+			},
+			expTotal: 42,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := usage.Summarize(records, leases, tt.input)
+
+			assert.Equal(t, tt.expGroups, summary.Groups)
+			assert.Equal(t, tt.expTotal, summary.TotalCostAmount)
+			assert.Equal(t, "USD", summary.CostCurrency)
+			assert.Equal(t, tt.expNext, summary.NextOffset)
+		})
+	}
+}
+
+func TestSummaryInputValidate(t *testing.T) {
+	startDate := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		input  usage.SummaryInput
+		expErr bool
+	}{
+		{
+			name:  "should accept every grouping",
+			input: usage.SummaryInput{StartDate: startDate, EndDate: startDate.AddDate(0, 1, 0), GroupBy: []string{"principal", "account", "lease", "day", "week", "month", "metadata.costCenter"}},
+		},
+		{
+			name:   "should fail for an unknown grouping",
+			input:  usage.SummaryInput{StartDate: startDate, EndDate: startDate, GroupBy: []string{"service"}},
+			expErr: true,
+		},
+		{
+			name:   "should fail for a metadata grouping without a key",
+			input:  usage.SummaryInput{StartDate: startDate, EndDate: startDate, GroupBy: []string{"metadata."}},
+			expErr: true,
+		},
+		{
+			name:   "should fail when the end date is before the start date",
+			input:  usage.SummaryInput{StartDate: startDate, EndDate: startDate.AddDate(0, 0, -1)},
+			expErr: true,
+		},
+		{
+			name:   "should fail for more than a year",
+			input:  usage.SummaryInput{StartDate: startDate, EndDate: startDate.AddDate(2, 0, 0)},
+			expErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.input.Validate()
+			if tt.expErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Optum/dce/pkg/budget"
 	"github.com/Optum/dce/pkg/common"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -29,18 +30,34 @@ type DB struct {
 	SortKeyName      string
 	// Use Consistent Reads when scanning or querying.  When possbile.
 	ConsistentRead bool
+	// Name of the table of each principal's usage total by budget period.
+	// Totals are only kept when it's set.
+	PrincipalUsageTableName string
+	// Budget period principal usage is totalled by
+	PrincipalBudgetPeriod *budget.Period
 }
 
 // The DBer interface includes all methods used by the DB struct to interact with
 // Usage DynamoDB. This is useful if we want to mock the DB service.
 type DBer interface {
 	PutUsage(input Usage) error
+	PutUsageBatch(input []Usage) error
 	GetUsageByDateRange(startDate time.Time, endDate time.Time) ([]*Usage, error)
 	GetUsageByPrincipal(startDate time.Time, principalID string) ([]*Usage, error)
+	GetUsageByPrincipalAndDateRange(principalID string, startDate time.Time, endDate time.Time) ([]*Usage, error)
+	GetUsageByLease(leaseID string, startDate time.Time, endDate time.Time) ([]*Usage, error)
+	GetPrincipalSpend(principalID string, period *budget.Period, currentTime time.Time) (float64, error)
+	GetPrincipalBudgetNotified(principalID string, period *budget.Period, currentTime time.Time) ([]float64, error)
+	MarkPrincipalBudgetNotified(principalID string, period *budget.Period, currentTime time.Time, thresholds []float64) error
 }
 
 // PutUsage adds an item to Usage DB
 func (db *DB) PutUsage(input Usage) error {
+	err := requireLease(input)
+	if err != nil {
+		return err
+	}
+	input.UsageID = aws.String(input.ID())
 	item, err := dynamodbattribute.MarshalMap(input)
 	if err != nil {
 		errorMessage := fmt.Sprintf("Failed to add usage record for start date \"%d\" and PrincipalID \"%s\": %s.", *input.StartDate, *input.PrincipalID, err)
@@ -54,7 +71,86 @@ func (db *DB) PutUsage(input Usage) error {
 			Item:      item,
 		},
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	return db.updatePrincipalUsage([]Usage{input})
+}
+
+// requireLease returns an error if the usage record doesn't have a lease, as records are identified by their lease
+func requireLease(u Usage) error {
+	if u.LeaseID == nil || *u.LeaseID == "" {
+		return fmt.Errorf("usage record for start date \"%d\" and PrincipalID \"%s\" has no lease", *u.StartDate, *u.PrincipalID)
+	}
+	return nil
+}
+
+// maxBatchWriteItems is the most items DynamoDB writes in a single BatchWriteItem call
+const maxBatchWriteItems = 25
+
+// maxBatchWriteAttempts is the most times unprocessed items are retried, before giving up
+const maxBatchWriteAttempts = 5
+
+// PutUsageBatch adds the items to Usage DB, in as few calls as possible.
+// Later items replace earlier ones for the same start date and usage ID, as they would with PutUsage.
+func (db *DB) PutUsageBatch(input []Usage) error {
+	requests := []*dynamodb.WriteRequest{}
+	records := []Usage{}
+	requestIndex := map[string]int{}
+	for _, u := range input {
+		err := requireLease(u)
+		if err != nil {
+			return err
+		}
+		u.UsageID = aws.String(u.ID())
+		item, err := dynamodbattribute.MarshalMap(u)
+		if err != nil {
+			errorMessage := fmt.Sprintf("Failed to add usage record for start date \"%d\" and PrincipalID \"%s\": %s.", *u.StartDate, *u.PrincipalID, err)
+			log.Print(errorMessage)
+			return err
+		}
+
+		// A batch may not write the same item twice
+		request := &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: item}}
+		key := fmt.Sprintf("%d-%s", *u.StartDate, *u.UsageID)
+		if i, ok := requestIndex[key]; ok {
+			requests[i] = request
+			records[i] = u
+			continue
+		}
+		requestIndex[key] = len(requests)
+		requests = append(requests, request)
+		records = append(records, u)
+	}
+
+	for start := 0; start < len(requests); start += maxBatchWriteItems {
+		end := start + maxBatchWriteItems
+		if end > len(requests) {
+			end = len(requests)
+		}
+
+		unprocessed := map[string][]*dynamodb.WriteRequest{db.UsageTableName: requests[start:end]}
+		for attempt := 1; len(unprocessed) > 0; attempt++ {
+			if attempt > maxBatchWriteAttempts {
+				return fmt.Errorf("failed to add %d usage records after %d attempts", len(unprocessed[db.UsageTableName]), maxBatchWriteAttempts)
+			}
+			if attempt > 1 {
+				// Back off, so DynamoDB has capacity to process the rest
+				time.Sleep(time.Duration(attempt*attempt) * 50 * time.Millisecond)
+			}
+
+			output, err := db.Client.BatchWriteItem(&dynamodb.BatchWriteItemInput{
+				RequestItems: unprocessed,
+			})
+			if err != nil {
+				log.Printf("Failed to add usage records: %s", err)
+				return err
+			}
+			unprocessed = output.UnprocessedItems
+		}
+	}
+
+	return db.updatePrincipalUsage(records)
 }
 
 // GetUsageByDateRange returns usage amount for all leases for input date range
@@ -73,26 +169,19 @@ func (db *DB) GetUsageByDateRange(startDate time.Time, endDate time.Time) ([]*Us
 		return nil, nil
 	}
 
+	// StartDate is the table's partition key, so each day is a separate query
 	for {
 
-		var resp, err = db.Client.Query(getQueryInput(db.UsageTableName, usageStartDate, nil, db.ConsistentRead))
+		err := db.Client.QueryPages(getQueryInput(db.UsageTableName, usageStartDate, nil, db.ConsistentRead),
+			func(page *dynamodb.QueryOutput, lastPage bool) bool {
+				scanOutput = append(scanOutput, page)
+				return true
+			})
 		if err != nil {
 			errorMessage := fmt.Sprintf("Failed to query usage record for start date \"%s\": %s.", startDate, err)
 			log.Print(errorMessage)
 			return nil, err
 		}
-		scanOutput = append(scanOutput, resp)
-
-		// pagination
-		for len(resp.LastEvaluatedKey) > 0 {
-			var resp, err = db.Client.Query(getQueryInput(db.UsageTableName, usageStartDate, resp.LastEvaluatedKey, db.ConsistentRead))
-			if err != nil {
-				errorMessage := fmt.Sprintf("Failed to query usage record for start date \"%s\": %s.", startDate, err)
-				log.Print(errorMessage)
-				return nil, err
-			}
-			scanOutput = append(scanOutput, resp)
-		}
 
 		// increment startdate by a day
 		usageStartDate = usageStartDate.AddDate(0, 0, 1)
@@ -119,12 +208,11 @@ func (db *DB) GetUsageByDateRange(startDate time.Time, endDate time.Time) ([]*Us
 	return usageRecords, nil
 }
 
-// GetUsageByPrincipal returns usage amount for all leases for input Principal
+// GetUsageByPrincipal returns usage amount for all leases for input Principal,
+// from the start date until today
 // startDate is epoch Unix date
 func (db *DB) GetUsageByPrincipal(startDate time.Time, principalID string) ([]*Usage, error) {
 
-	output := make([]*Usage, 0)
-
 	// Convert startDate to the start time of that day
 	usageStartDate := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, time.UTC)
 	currentDate := time.Now()
@@ -136,38 +224,7 @@ func (db *DB) GetUsageByPrincipal(startDate time.Time, principalID string) ([]*U
 		return nil, nil
 	}
 
-	for {
-		var resp, err = db.Client.GetItem(getInputForGetUsageByPrincipalID(db, usageStartDate, principalID, db.ConsistentRead))
-		if err != nil {
-			errorMessage := fmt.Sprintf("Failed to query usage record for start date \"%s\": %s.", startDate, err)
-			log.Print(errorMessage)
-			return nil, err
-		}
-
-		// Don't append if the item is empty or doesn't exist
-		if len(resp.Item) > 0 {
-			item := Usage{}
-
-			err = dynamodbattribute.UnmarshalMap(resp.Item, &item)
-			if err != nil {
-				errorMessage := fmt.Sprintf("Failed to unmarshal Record, %v", err)
-				log.Print(errorMessage)
-				return nil, err
-			}
-
-			output = append(output, &item)
-		}
-
-		// increment startdate by a day
-		usageStartDate = usageStartDate.AddDate(0, 0, 1)
-
-		// continue to get usage till usageEndDate
-		if usageEndDate.Sub(usageStartDate) < 0 {
-			break
-		}
-	}
-
-	return output, nil
+	return db.GetUsageByPrincipalAndDateRange(principalID, usageStartDate, usageEndDate)
 }
 
 // GetUsageInput contains the filtering criteria for the GetUsage scan.
@@ -229,7 +286,7 @@ func (db *DB) GetUsage(input GetUsageInput) (GetUsageOutput, error) {
 			if k == "StartDate" {
 				scanInput.ExclusiveStartKey[k] = &dynamodb.AttributeValue{N: aws.String(v)}
 			}
-			if k == "PrincipalId" {
+			if k == "UsageId" {
 				scanInput.ExclusiveStartKey[k] = &dynamodb.AttributeValue{S: aws.String(v)}
 			}
 		}
@@ -286,21 +343,32 @@ Requires env vars for:
 
 - AWS_CURRENT_REGION
 - USAGE_CACHE_DB
+
+And optionally PRINCIPAL_USAGE_DB, to keep each principal's usage total
+for the PRINCIPAL_BUDGET_PERIOD.
 */
 func NewFromEnv() (*DB, error) {
 	awsSession, err := session.NewSession()
 	if err != nil {
 		return nil, err
 	}
-	return New(
+	db := New(
 		dynamodb.New(
 			awsSession,
 			aws.NewConfig().WithRegion(common.RequireEnv("AWS_CURRENT_REGION")),
 		),
 		common.RequireEnv("USAGE_CACHE_DB"),
 		"StartDate",
-		"PrincipalId",
-	), nil
+		"UsageId",
+	)
+	db.PrincipalUsageTableName = common.GetEnv("PRINCIPAL_USAGE_DB", "")
+	if db.PrincipalUsageTableName != "" {
+		db.PrincipalBudgetPeriod, err = budget.NewPeriodFromEnv()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return db, nil
 }
 
 func unmarshalUsageRecord(dbResult map[string]*dynamodb.AttributeValue) (*Usage, error) {
@@ -334,20 +402,3 @@ func getQueryInput(tableName string, startDate time.Time, startKey map[string]*d
 		ConsistentRead: aws.Bool(consistentRead),
 	}
 }
-
-// getInputForGetUsageByPrincipalID returns a GetItemInput for given inputs
-func getInputForGetUsageByPrincipalID(d *DB, startDate time.Time, principalID string, consistentRead bool) *dynamodb.GetItemInput {
-	getItemInput := dynamodb.GetItemInput{
-		TableName: aws.String(d.UsageTableName),
-		Key: map[string]*dynamodb.AttributeValue{
-			d.PartitionKeyName: {
-				N: aws.String(strconv.FormatInt(startDate.Unix(), 10)),
-			},
-			d.SortKeyName: {
-				S: aws.String(principalID),
-			},
-		},
-		ConsistentRead: aws.Bool(consistentRead),
-	}
-	return &getItemInput
-}
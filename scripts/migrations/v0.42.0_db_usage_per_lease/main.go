@@ -0,0 +1,231 @@
+/*
+Migration for v0.42.0
+
+v0.42.0 records usage per lease, in the LeaseUsage table, keyed by StartDate and
+a UsageId of <PrincipalId>#<LeaseId>. The Usage table is keyed by StartDate and
+PrincipalId, and DynamoDB does not support changing a table's keys, so this
+script copies its records to the LeaseUsage table.
+
+Each record is given the principal's lease of the account which was in use on
+the record's day, or if none was, the principal's latest lease of the account.
+Records of accounts the principal never leased are left out.
+
+Records are written the same way usage is collected, so they're added to the
+principals' budget period totals in the PrincipalUsage table too. Usage
+collected between upgrading and running this script is kept: its totals are
+updated with the copied records, rather than seeded from them.
+
+It is intended to be run once, straight after upgrading:
+
+	go run ./scripts/migrations/v0.42.0_db_usage_per_lease
+
+This script requires 4 environment variables to be set for its use:
+
+	export AWS_CURRENT_REGION=us-east-1  - The region the tables reside in
+	export LEASE_DB=Leases               - Name of the Leases table
+	export SOURCE_USAGE_DB=Usage         - Name of the Usage table, copied from
+	export USAGE_CACHE_DB=LeaseUsage     - Name of the LeaseUsage table, copied to
+
+And, to update the principal usage totals, the PRINCIPAL_USAGE_DB table name and
+the PRINCIPAL_BUDGET_PERIOD settings DCE is deployed with.
+*/
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Optum/dce/pkg/budget"
+	"github.com/Optum/dce/pkg/common"
+	errors2 "github.com/Optum/dce/pkg/errors"
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/usage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/pkg/errors"
+)
+
+func main() {
+	// Configure DynamoDB client
+	awsSession := session.Must(session.NewSession())
+	dynDB := dynamodb.New(
+		awsSession,
+		aws.NewConfig().WithRegion(common.RequireEnv("AWS_CURRENT_REGION")),
+	)
+
+	// Write through the usage DB, so principal usage totals are kept up to date
+	usageDB := usage.New(dynDB, common.RequireEnv("USAGE_CACHE_DB"), "StartDate", "UsageId")
+	usageDB.PrincipalUsageTableName = common.GetEnv("PRINCIPAL_USAGE_DB", "")
+	if usageDB.PrincipalUsageTableName != "" {
+		period, err := budget.NewPeriodFromEnv()
+		if err != nil {
+			log.Fatalf("Invalid principal budget period: %s", err)
+		}
+		usageDB.PrincipalBudgetPeriod = period
+	}
+
+	err := migrate(&migrateInput{
+		db:             dynDB,
+		usageDB:        usageDB,
+		leaseTableName: common.RequireEnv("LEASE_DB"),
+		srcTableName:   common.RequireEnv("SOURCE_USAGE_DB"),
+		dstTableName:   usageDB.UsageTableName,
+	})
+	if err != nil {
+		log.Fatalf("Migration failed: %s", err)
+	}
+}
+
+type migrateInput struct {
+	db             dynamodbiface.DynamoDBAPI
+	usageDB        usage.DBer
+	leaseTableName string
+	srcTableName   string
+	dstTableName   string
+}
+
+// batchSize is the number of records written to the LeaseUsage table at a time
+const batchSize = 25
+
+func migrate(input *migrateInput) error {
+	// Dump lease records
+	leases := lease.Leases{}
+	err := scan(input.db, input.leaseTableName, func(items []map[string]*dynamodb.AttributeValue) error {
+		page := lease.Leases{}
+		err := dynamodbattribute.UnmarshalListOfMaps(items, &page)
+		leases = append(leases, page...)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// Dump usage records
+	records := []usage.Usage{}
+	err = scan(input.db, input.srcTableName, func(items []map[string]*dynamodb.AttributeValue) error {
+		page := []usage.Usage{}
+		err := dynamodbattribute.UnmarshalListOfMaps(items, &page)
+		records = append(records, page...)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// Give each record its lease
+	leaseRecords := []usage.Usage{}
+	for i, record := range records {
+		leaseRecord, ok := newLeaseUsage(record, leases)
+		if !ok {
+			log.Printf("Skipped record %d/%d, as %s never leased %s",
+				i+1, len(records), aws.StringValue(record.PrincipalID), aws.StringValue(record.AccountID))
+			continue
+		}
+		leaseRecords = append(leaseRecords, leaseRecord)
+	}
+	skipped := len(records) - len(leaseRecords)
+
+	// Create records in the new table
+	var deferredErrors []error
+	failed := 0
+	for start := 0; start < len(leaseRecords); start += batchSize {
+		end := start + batchSize
+		if end > len(leaseRecords) {
+			end = len(leaseRecords)
+		}
+		batch := leaseRecords[start:end]
+
+		err := input.usageDB.PutUsageBatch(batch)
+		if err != nil {
+			deferredErrors = append(deferredErrors, err)
+			failed += len(batch)
+			log.Printf(`
+Failed to put records %d-%d/%d to %s
+Error: %s
+Items: %v
+`, start+1, end, len(leaseRecords), input.dstTableName, err, batch)
+			continue
+		}
+		log.Printf("Migrated records %d-%d/%d from %s to %s",
+			start+1, end, len(leaseRecords), input.srcTableName, input.dstTableName)
+	}
+	log.Printf("Migrated %d/%d records, skipped %d", len(leaseRecords)-failed, len(records), skipped)
+
+	// Handle deferred errors
+	if len(deferredErrors) > 0 {
+		return errors2.NewMultiError(
+			fmt.Sprintf(
+				"%d/%d migrations to %s failed",
+				failed, len(records), input.dstTableName,
+			),
+			deferredErrors,
+		)
+	}
+	return nil
+}
+
+// scan reads every page of the table
+func scan(db dynamodbiface.DynamoDBAPI, tableName string, fn func(items []map[string]*dynamodb.AttributeValue) error) error {
+	var pageErr error
+	err := db.ScanPages(&dynamodb.ScanInput{
+		TableName: aws.String(tableName),
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		pageErr = fn(page.Items)
+		return pageErr == nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "Scan failed for %s", tableName)
+	}
+	if pageErr != nil {
+		return errors.Wrapf(pageErr, "Unmarshal failed for %s", tableName)
+	}
+	return nil
+}
+
+// newLeaseUsage returns the usage record with its lease, and whether its lease was found
+func newLeaseUsage(record usage.Usage, leases lease.Leases) (usage.Usage, bool) {
+	if record.PrincipalID == nil || record.AccountID == nil || record.StartDate == nil {
+		return record, false
+	}
+	l := findLease(record, leases)
+	if l == nil {
+		return record, false
+	}
+
+	record.LeaseID = l.ID
+	record.UsageID = aws.String(usage.UsageID(*record.PrincipalID, *l.ID))
+	return record, true
+}
+
+// findLease returns the principal's lease of the account which was in use on the record's day,
+// or if none was, the principal's latest lease of the account
+func findLease(record usage.Usage, leases lease.Leases) *lease.Lease {
+	var latest *lease.Lease
+	for i := range leases {
+		l := &leases[i]
+		if l.ID == nil || l.PrincipalID == nil || l.AccountID == nil ||
+			*l.PrincipalID != *record.PrincipalID || *l.AccountID != *record.AccountID {
+			continue
+		}
+
+		createdOn := int64(0)
+		if l.CreatedOn != nil {
+			createdOn = *l.CreatedOn
+		}
+		inUse := l.Status != nil && (*l.Status == lease.StatusActive || *l.Status == lease.StatusFrozen)
+		// Usage is recorded by day, so leases created during the day count from its start
+		createdDay := time.Unix(createdOn, 0).UTC().Truncate(24 * time.Hour).Unix()
+		if *record.StartDate >= createdDay && (inUse || l.StatusModifiedOn == nil || *record.StartDate <= *l.StatusModifiedOn) {
+			return l
+		}
+
+		if latest == nil || (latest.CreatedOn != nil && createdOn > *latest.CreatedOn) {
+			latest = l
+		}
+	}
+	return latest
+}
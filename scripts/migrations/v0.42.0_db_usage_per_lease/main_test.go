@@ -0,0 +1,137 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Optum/dce/pkg/lease"
+	"github.com/Optum/dce/pkg/usage"
+	usageMocks "github.com/Optum/dce/pkg/usage/mocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// scanDB serves each table's items from a single Scan page
+type scanDB struct {
+	dynamodbiface.DynamoDBAPI
+	tables map[string][]map[string]*dynamodb.AttributeValue
+}
+
+func (db *scanDB) ScanPages(input *dynamodb.ScanInput, fn func(*dynamodb.ScanOutput, bool) bool) error {
+	fn(&dynamodb.ScanOutput{Items: db.tables[*input.TableName]}, true)
+	return nil
+}
+
+func TestMigrate(t *testing.T) {
+	may14 := time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC)
+	leaseItems, err := dynamodbattribute.MarshalList(lease.Leases{
+		{
+			ID:          aws.String("lease1"),
+			PrincipalID: aws.String("user1"),
+			AccountID:   aws.String("123456789012"),
+			Status:      lease.StatusActive.StatusPtr(),
+			CreatedOn:   aws.Int64(may14.AddDate(0, 0, -5).Unix()),
+		},
+	})
+	require.Nil(t, err)
+	usageItems, err := dynamodbattribute.MarshalList([]usage.Usage{
+		{PrincipalID: aws.String("user1"), AccountID: aws.String("123456789012"),
+			StartDate: aws.Int64(may14.Unix()), CostAmount: aws.Float64(10)},
+		{PrincipalID: aws.String("user2"), AccountID: aws.String("123456789012"),
+			StartDate: aws.Int64(may14.Unix()), CostAmount: aws.Float64(20)},
+	})
+	require.Nil(t, err)
+	tables := map[string][]map[string]*dynamodb.AttributeValue{}
+	for name, items := range map[string][]*dynamodb.AttributeValue{"Leases": leaseItems, "Usage": usageItems} {
+		for _, item := range items {
+			tables[name] = append(tables[name], item.M)
+		}
+	}
+
+	usageDB := &usageMocks.DBer{}
+	usageDB.On("PutUsageBatch", mock.MatchedBy(func(records []usage.Usage) bool {
+		return len(records) == 1 && *records[0].LeaseID == "lease1" && *records[0].CostAmount == 10
+	})).Return(nil)
+
+	err = migrate(&migrateInput{
+		db:             &scanDB{tables: tables},
+		usageDB:        usageDB,
+		leaseTableName: "Leases",
+		srcTableName:   "Usage",
+		dstTableName:   "LeaseUsage",
+	})
+	assert.Nil(t, err)
+	usageDB.AssertExpectations(t)
+}
+
+func TestNewLeaseUsage(t *testing.T) {
+	may14 := time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC)
+	leases := lease.Leases{
+		{
+			ID:               aws.String("lease1"),
+			PrincipalID:      aws.String("user1"),
+			AccountID:        aws.String("123456789012"),
+			Status:           lease.StatusInactive.StatusPtr(),
+			CreatedOn:        aws.Int64(may14.AddDate(0, 0, -5).Unix()),
+			StatusModifiedOn: aws.Int64(may14.Add(6 * time.Hour).Unix()),
+		},
+		{
+			// Re-created on the same account, the next day
+			ID:          aws.String("lease2"),
+			PrincipalID: aws.String("user1"),
+			AccountID:   aws.String("123456789012"),
+			Status:      lease.StatusActive.StatusPtr(),
+			CreatedOn:   aws.Int64(may14.AddDate(0, 0, 1).Add(6 * time.Hour).Unix()),
+		},
+	}
+
+	tests := []struct {
+		name       string
+		record     usage.Usage
+		expLeaseID string
+		expOk      bool
+	}{
+		{
+			name: "should get the lease in use on the record's day",
+			record: usage.Usage{PrincipalID: aws.String("user1"), AccountID: aws.String("123456789012"),
+				StartDate: aws.Int64(may14.Unix())},
+			expLeaseID: "lease1",
+			expOk:      true,
+		},
+		{
+			name: "should get the lease created during the record's day",
+			record: usage.Usage{PrincipalID: aws.String("user1"), AccountID: aws.String("123456789012"),
+				StartDate: aws.Int64(may14.AddDate(0, 0, 1).Unix())},
+			expLeaseID: "lease2",
+			expOk:      true,
+		},
+		{
+			name: "should get the latest lease when none was in use",
+			record: usage.Usage{PrincipalID: aws.String("user1"), AccountID: aws.String("123456789012"),
+				StartDate: aws.Int64(may14.AddDate(0, 0, -10).Unix())},
+			expLeaseID: "lease2",
+			expOk:      true,
+		},
+		{
+			name: "should skip accounts the principal never leased",
+			record: usage.Usage{PrincipalID: aws.String("user2"), AccountID: aws.String("123456789012"),
+				StartDate: aws.Int64(may14.Unix())},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, ok := newLeaseUsage(tt.record, leases)
+			assert.Equal(t, tt.expOk, ok)
+			if tt.expOk {
+				assert.Equal(t, tt.expLeaseID, *record.LeaseID)
+				assert.Equal(t, usage.UsageID(*tt.record.PrincipalID, tt.expLeaseID), *record.UsageID)
+			}
+		})
+	}
+}
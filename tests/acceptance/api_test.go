@@ -91,7 +91,7 @@ func TestApi(t *testing.T) {
 		),
 		tfOut["usage_table_name"].(string),
 		"StartDate",
-		"PrincipalId",
+		"UsageId",
 	)
 
 	sqsSvc = sqs.New(
@@ -2555,6 +2555,7 @@ func createUsage(t *testing.T, apiURL string, usageSvc usage.DBer) {
 			usage.NewUsageInput{
 				PrincipalID:  testPrincipalID,
 				AccountID:    testAccountID,
+				LeaseID:      "TestLease1",
 				StartDate:    startDate.Unix(),
 				EndDate:      endDate.Unix(),
 				CostAmount:   2000.00,
@@ -83,7 +83,7 @@ func TestUpdateLeaseStatusLambda(t *testing.T) {
 		),
 		tfOut["usage_table_name"].(string),
 		"StartDate",
-		"PrincipalId",
+		"UsageId",
 	)
 
 	sqsSvc = sqs.New(
@@ -290,7 +290,7 @@ func TestUpdateLeaseStatusLambda(t *testing.T) {
 		waitForAccountStatus(t, apiURL, accountID, "Ready")
 
 		// Create a lease for above created account
-		apiRequest(t, &apiRequestInput{
+		leaseResp := apiRequest(t, &apiRequestInput{
 			method: "POST",
 			url:    apiURL + "/leases",
 			json: struct {
@@ -305,9 +305,10 @@ func TestUpdateLeaseStatusLambda(t *testing.T) {
 				assert.Equal(r, 201, apiResp.StatusCode)
 			},
 		})
+		leaseID := parseResponseJSON(t, leaseResp)["id"].(string)
 
 		// create usage for this lease and account
-		createUsageForInputAmount(t, apiURL, accountID, usageSvc, 200.00)
+		createUsageForInputAmount(t, apiURL, accountID, leaseID, usageSvc, 200.00)
 
 		// Invoke update_lease_status lambda
 		request := db.Lease{
@@ -360,7 +361,7 @@ func TestUpdateLeaseStatusLambda(t *testing.T) {
 		waitForAccountStatus(t, apiURL, accountID, "Ready")
 
 		// Create a lease for above created account
-		apiRequest(t, &apiRequestInput{
+		leaseResp := apiRequest(t, &apiRequestInput{
 			method: "POST",
 			url:    apiURL + "/leases",
 			json: struct {
@@ -375,9 +376,10 @@ func TestUpdateLeaseStatusLambda(t *testing.T) {
 				assert.Equal(r, 201, apiResp.StatusCode)
 			},
 		})
+		leaseID := parseResponseJSON(t, leaseResp)["id"].(string)
 
 		// create usage for this lease and account
-		createUsageForInputAmount(t, apiURL, accountID, usageSvc, 2000.00)
+		createUsageForInputAmount(t, apiURL, accountID, leaseID, usageSvc, 2000.00)
 
 		// Invoke update_lease_status lambda
 		request := db.Lease{
@@ -430,7 +432,7 @@ func TestUpdateLeaseStatusLambda(t *testing.T) {
 		waitForAccountStatus(t, apiURL, accountID, "Ready")
 
 		// Create a lease for above created account
-		apiRequest(t, &apiRequestInput{
+		leaseResp := apiRequest(t, &apiRequestInput{
 			method: "POST",
 			url:    apiURL + "/leases",
 			json: struct {
@@ -445,9 +447,10 @@ func TestUpdateLeaseStatusLambda(t *testing.T) {
 				assert.Equal(r, 201, apiResp.StatusCode)
 			},
 		})
+		leaseID := parseResponseJSON(t, leaseResp)["id"].(string)
 
 		// create usage for this lease and account
-		createUsageForInputAmount(t, apiURL, accountID, usageSvc, 2000.00)
+		createUsageForInputAmount(t, apiURL, accountID, leaseID, usageSvc, 2000.00)
 
 		// Invoke update_lease_status lambda
 		request := db.Lease{
@@ -477,7 +480,7 @@ func TestUpdateLeaseStatusLambda(t *testing.T) {
 
 }
 
-func createUsageForInputAmount(t *testing.T, apiURL string, accountID string, usageSvc usage.DBer, costAmount float64) []*usage.Usage {
+func createUsageForInputAmount(t *testing.T, apiURL string, accountID string, leaseID string, usageSvc usage.DBer, costAmount float64) []*usage.Usage {
 	// Create usage
 	// Setup usage dates
 	const ttl int = 3
@@ -504,6 +507,7 @@ func createUsageForInputAmount(t *testing.T, apiURL string, accountID string, us
 			usage.NewUsageInput{
 				PrincipalID:  testPrincipalID,
 				AccountID:    testAccountID,
+				LeaseID:      leaseID,
 				StartDate:    startDate.Unix(),
 				EndDate:      endDate.Unix(),
 				CostAmount:   costAmount,
@@ -32,7 +32,7 @@ func TestUsageDb(t *testing.T) {
 		),
 		tfOut["usage_table_name"].(string),
 		"StartDate",
-		"PrincipalId",
+		"UsageId",
 	)
 
 	// For testing purposes support consistent reads
@@ -43,7 +43,7 @@ func TestUsageDb(t *testing.T) {
 
 	apiURL := tfOut["api_url"].(string)
 	// create usage for this lease and account
-	expectedUsage := createUsageForInputAmount(t, apiURL, "123456789012", dbSvc, 20.00)
+	expectedUsage := createUsageForInputAmount(t, apiURL, "123456789012", "TestLease1", dbSvc, 20.00)
 
 	t.Run("Verify Get Usage By Date Range", func(t *testing.T) {
 
@@ -204,8 +204,8 @@ func truncateUsageTable(t *testing.T, dbSvc *usage.DB) {
 		deleteRequests = append(deleteRequests, &dynamodb.WriteRequest{
 			DeleteRequest: &dynamodb.DeleteRequest{
 				Key: map[string]*dynamodb.AttributeValue{
-					"StartDate":   item["StartDate"],
-					"PrincipalId": item["PrincipalId"],
+					"StartDate": item["StartDate"],
+					"UsageId":   item["UsageId"],
 				},
 			},
 		})